@@ -3,6 +3,7 @@ package shared
 import (
 	_ "embed"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -161,6 +162,9 @@ func TestBundleLibFuzzer(t *testing.T, dir string, cifuzz string, cifuzzEnv []st
 
 		// define handlers
 		server.Handlers["/v1/projects"] = mockserver.ReturnResponse(t, mockserver.ProjectsJSON)
+		// The mock server doesn't support chunked uploads, so the bundle
+		// is uploaded in a single request
+		server.Handlers[fmt.Sprintf("/v2/projects/%s/artifacts/uploads", projectName)] = http.NotFound
 		server.Handlers[fmt.Sprintf("/v2/projects/%s/artifacts/import", projectName)] = mockserver.ReturnResponse(t,
 			fmt.Sprintf(`{"display-name": "test-artifacts", "resource-name": %q}`, artifactsName),
 		)
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
-	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -19,12 +18,12 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
-	"golang.org/x/net/proxy"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/term"
 
 	"code-intelligence.com/cifuzz/internal/cmd/remoterun/progress"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/transport"
 	"code-intelligence.com/cifuzz/internal/version"
 	"code-intelligence.com/cifuzz/pkg/log"
 	"code-intelligence.com/cifuzz/util/stringutil"
@@ -95,6 +94,9 @@ func WrapConnectionError(err error) error {
 type APIClient struct {
 	Server    string
 	UserAgent string
+	// RetryPolicy is used for idempotent requests. If nil, failed
+	// requests are not retried.
+	RetryPolicy *RetryPolicy
 }
 
 var FeaturedProjectsOrganization = "organizations/1"
@@ -106,8 +108,9 @@ type Artifact struct {
 
 func NewClient(server string) *APIClient {
 	return &APIClient{
-		Server:    server,
-		UserAgent: "cifuzz/" + version.Version + " " + runtime.GOOS + "-" + runtime.GOARCH,
+		Server:      server,
+		UserAgent:   "cifuzz/" + version.Version + " " + runtime.GOOS + "-" + runtime.GOARCH,
+		RetryPolicy: defaultRetryPolicy(),
 	}
 }
 
@@ -220,7 +223,9 @@ func ProjectNameToNID(projectName string) (string, error) {
 	return nid, nil
 }
 
-func (client *APIClient) UploadBundle(path string, projectName string, token string) (*Artifact, error) {
+// uploadBundleInOneRequest uploads the bundle in a single multipart
+// request. It's used for servers which don't support chunked uploads.
+func (client *APIClient) uploadBundleInOneRequest(path string, projectName string, token string) (*Artifact, error) {
 
 	projectName = ConvertProjectNameForUseWithAPIV1V2(projectName)
 
@@ -306,7 +311,11 @@ func (client *APIClient) UploadBundle(path string, projectName string, token str
 		req.Header.Set("Content-Type", m.FormDataContentType())
 		req.Header.Add("Authorization", "Bearer "+token)
 
-		httpClient := &http.Client{Transport: getCustomTransport()}
+		httpTransport, err := getCustomTransport()
+		if err != nil {
+			return err
+		}
+		httpClient := &http.Client{Transport: httpTransport}
 		resp, err := httpClient.Do(req)
 		if err != nil {
 			return errors.WithStack(err)
@@ -368,32 +377,98 @@ func (client *APIClient) StartRemoteFuzzingRun(artifact *Artifact, token string)
 }
 
 // sendRequest sends a request to the API server with a timeout.
+// Idempotent requests which fail with a transient error are retried
+// according to the retry policy of the client.
 func (client *APIClient) sendRequest(method string, endpoint string, body []byte, token string, timeout time.Duration) (*http.Response, error) {
-	url, err := url.JoinPath(client.Server, endpoint)
+	return client.send(context.Background(), &request{
+		method:   method,
+		endpoint: endpoint,
+		body:     body,
+		token:    token,
+		timeout:  timeout,
+	})
+}
+
+// request describes a request sent via APIClient.send.
+type request struct {
+	method   string
+	endpoint string
+	body     []byte
+	token    string
+	timeout  time.Duration
+	// contentType defaults to application/json
+	contentType string
+	header      http.Header
+	// wrapBody, if set, is used to wrap the reader of the request body
+	// on every attempt, e.g. to display the upload progress
+	wrapBody func(io.Reader) io.Reader
+}
+
+func (client *APIClient) send(ctx context.Context, r *request) (*http.Response, error) {
+	url, err := url.JoinPath(client.Server, r.endpoint)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), method, url, bytes.NewReader(body))
-	if err != nil {
-		return nil, errors.WithStack(err)
+	contentType := r.contentType
+	if contentType == "" {
+		contentType = "application/json"
+		log.Debugf("Sending HTTP request: %s %s\n%s", r.method, r.endpoint, r.body)
+	} else {
+		log.Debugf("Sending HTTP request: %s %s (%d bytes of %s)", r.method, r.endpoint, len(r.body), contentType)
 	}
 
-	req.Header.Set("User-Agent", client.UserAgent)
-	req.Header.Add("Authorization", "Bearer "+token)
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
+	retryPolicy := client.RetryPolicy
+	if retryPolicy == nil || !isIdempotent(r.method) {
+		retryPolicy = &RetryPolicy{}
+	}
 
-	log.Debugf("Sending HTTP request: %s %s\n%s", method, endpoint, body)
-	httpClient := &http.Client{Transport: getCustomTransport(), Timeout: timeout}
-	resp, err := httpClient.Do(req)
+	httpTransport, err := getCustomTransport()
 	if err != nil {
-		return nil, WrapConnectionError(errors.WithStack(err))
+		return nil, err
 	}
+	httpClient := &http.Client{Transport: httpTransport, Timeout: r.timeout}
+	for attempt := 0; ; attempt++ {
+		var body io.Reader = bytes.NewReader(r.body)
+		if r.wrapBody != nil {
+			body = r.wrapBody(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, r.method, url, body)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
 
-	log.Debugf("Received response for HTTP request: %d %s", resp.StatusCode, endpoint)
+		req.Header.Set("User-Agent", client.UserAgent)
+		req.Header.Add("Authorization", "Bearer "+r.token)
+		req.Header.Add("Accept", "application/json")
+		req.Header.Add("Content-Type", contentType)
+		for key, values := range r.header {
+			for _, value := range values {
+				req.Header.Add(key, value)
+			}
+		}
 
-	return resp, nil
+		resp, err := httpClient.Do(req)
+		if attempt < retryPolicy.MaxRetries && isRetryable(ctx, resp, err) {
+			if err != nil {
+				log.Debugf("HTTP request %s %s failed: %v", r.method, r.endpoint, err)
+			} else {
+				log.Debugf("HTTP request %s %s failed: %s", r.method, r.endpoint, resp.Status)
+			}
+			discardResponse(resp)
+			err = retryPolicy.wait(ctx, attempt, resp)
+			if err != nil {
+				return nil, WrapConnectionError(err)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, WrapConnectionError(errors.WithStack(err))
+		}
+
+		log.Debugf("Received response for HTTP request: %d %s", resp.StatusCode, r.endpoint)
+		return resp, nil
+	}
 }
 
 // IsTokenValid checks if the token is valid by querying the API server.
@@ -443,20 +518,8 @@ func ValidateAndNormalizeServerURL(server string) (string, error) {
 	return url, nil
 }
 
-func getCustomTransport() *http.Transport {
-	// it is not possible to use the default Proxy Environment because
-	// of https://github.com/golang/go/issues/24135
-	dialer := proxy.FromEnvironment()
-	dialContext := func(ctx context.Context, network, address string) (net.Conn, error) {
-		conn, err := dialer.Dial(network, address)
-		if err != nil {
-			// This error is being returned to the http package and we
-			// don't know if it could have side effects with an added stack
-			// trace
-			// nolint: wrapcheck
-			return nil, err
-		}
-		return conn, nil
-	}
-	return &http.Transport{DialContext: dialContext}
+// getCustomTransport returns the transport used for all requests to the
+// API server, which respects the configured proxy and certificates.
+func getCustomTransport() (*http.Transport, error) {
+	return transport.Default()
 }
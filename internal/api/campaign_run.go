@@ -71,7 +71,7 @@ func (client *APIClient) CreateCampaignRun(project string, token string, fuzzTar
 	}
 	var engine string
 	switch buildSystem {
-	case config.BuildSystemBazel, config.BuildSystemCMake, config.BuildSystemOther:
+	case config.BuildSystemBazel, config.BuildSystemCargo, config.BuildSystemCMake, config.BuildSystemMeson, config.BuildSystemOther:
 		fuzzTargetConfig.CAPIFuzzTarget = &CAPIFuzzTarget{APIFuzzTarget: apiFuzzTarget}
 		engine = "LIBFUZZER"
 	case config.BuildSystemMaven, config.BuildSystemGradle:
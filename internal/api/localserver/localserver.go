@@ -0,0 +1,625 @@
+// Package localserver implements a local stand-in for the parts of the
+// CI Sense API which are used by cifuzz to upload bundles, campaign
+// runs and findings. All data is stored in a directory on the local
+// filesystem, which allows using cifuzz in air-gapped environments and
+// testing uploads without a CI Sense instance.
+package localserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/api"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+const (
+	findingsFile     = "findings.json"
+	campaignRunsDir  = "campaign_runs"
+	artifactsDir     = "artifacts"
+	projectsDir      = "projects"
+	uploadsDir       = "uploads"
+	artifactsFormKey = "fuzzing-artifacts"
+)
+
+// Server serves the CI Sense API endpoints used by cifuzz from a
+// directory on the local filesystem.
+type Server struct {
+	// Dir is the directory in which projects, bundles, campaign runs
+	// and findings are stored.
+	Dir string
+	// Token is the API access token which clients have to provide.
+	// If empty, any non-empty token is accepted.
+	Token string
+	// ChunkSize is the chunk size requested from clients for chunked
+	// uploads. If zero, the client's default is used.
+	ChunkSize int64
+
+	// mutex guards read-modify-write operations on the stored files
+	mutex sync.Mutex
+}
+
+func New(dir string, token string) (*Server, error) {
+	err := os.MkdirAll(filepath.Join(dir, projectsDir), 0o755)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &Server{Dir: dir, Token: token}, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debugf("Local server: %s %s", r.Method, r.URL.Path)
+
+	if !s.isAuthorized(r) {
+		s.writeError(w, http.StatusUnauthorized, "Invalid API access token")
+		return
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	var err error
+	switch {
+	// GET/POST /v1/projects
+	case len(segments) == 2 && segments[0] == "v1" && segments[1] == "projects":
+		switch r.Method {
+		case http.MethodGet:
+			err = s.listProjects(w)
+		case http.MethodPost:
+			err = s.createProject(w, r)
+		default:
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+	// POST /v2/projects/<project>/artifacts/import
+	case len(segments) == 5 && segments[0] == "v2" && segments[1] == "projects" &&
+		segments[3] == artifactsDir && segments[4] == "import" && r.Method == http.MethodPost:
+		err = s.importArtifact(w, r, segments[2])
+
+	// OPTIONS/POST /v2/projects/<project>/artifacts/uploads
+	case len(segments) == 5 && segments[0] == "v2" && segments[1] == "projects" &&
+		segments[3] == artifactsDir && segments[4] == uploadsDir:
+		switch r.Method {
+		case http.MethodOptions:
+			// Announce support for chunked uploads
+			w.Header().Set("Allow", "OPTIONS, POST")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case http.MethodPost:
+			err = s.createUpload(w, r, segments[2])
+		default:
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+	// GET/PUT /v2/projects/<project>/artifacts/uploads/<upload>
+	// POST /v2/projects/<project>/artifacts/uploads/<upload>:complete
+	case len(segments) == 6 && segments[0] == "v2" && segments[1] == "projects" &&
+		segments[3] == artifactsDir && segments[4] == uploadsDir:
+		switch {
+		case r.Method == http.MethodGet:
+			err = s.getUpload(w, segments[2], segments[5])
+		case r.Method == http.MethodPut:
+			err = s.uploadChunk(w, r, segments[2], segments[5])
+		case r.Method == http.MethodPost && strings.HasSuffix(segments[5], ":complete"):
+			err = s.completeUpload(w, segments[2], strings.TrimSuffix(segments[5], ":complete"))
+		default:
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+	// POST /v1/projects/<project>/artifacts/<artifact>:run
+	case len(segments) == 5 && segments[0] == "v1" && segments[1] == "projects" &&
+		segments[3] == artifactsDir && strings.HasSuffix(segments[4], ":run") && r.Method == http.MethodPost:
+		err = s.startRun(w, segments[2], strings.TrimSuffix(segments[4], ":run"))
+
+	// POST /v1/projects/<project>/campaign_runs
+	case len(segments) == 4 && segments[0] == "v1" && segments[1] == "projects" &&
+		segments[3] == campaignRunsDir && r.Method == http.MethodPost:
+		err = s.createCampaignRun(w, r, segments[2])
+
+	// GET/POST /v1/projects/<project>/findings
+	case len(segments) == 4 && segments[0] == "v1" && segments[1] == "projects" && segments[3] == "findings":
+		switch r.Method {
+		case http.MethodGet:
+			err = s.listFindings(w, segments[2])
+		case http.MethodPost:
+			err = s.uploadFindings(w, r, segments[2])
+		default:
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+	default:
+		s.writeError(w, http.StatusNotFound, "Not supported by the local server: "+r.Method+" "+r.URL.Path)
+		return
+	}
+
+	if err != nil {
+		log.Errorf(err, "Local server: %s %s failed: %v", r.Method, r.URL.Path, err.Error())
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (s *Server) isAuthorized(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if s.Token == "" {
+		return token != ""
+	}
+	return token == s.Token
+}
+
+func (s *Server) listProjects(w http.ResponseWriter) error {
+	entries, err := os.ReadDir(filepath.Join(s.Dir, projectsDir))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	projects := []*api.Project{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name, err := url.PathUnescape(entry.Name())
+		if err != nil {
+			log.Warnf("Ignoring invalid project directory %s", entry.Name())
+			continue
+		}
+		projects = append(projects, &api.Project{
+			Name:        api.ConvertProjectNameForUseWithAPIV1V2(name),
+			DisplayName: name,
+		})
+	}
+
+	return writeJSON(w, map[string][]*api.Project{"projects": projects})
+}
+
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request) error {
+	var body api.ProjectBody
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return nil
+	}
+	if body.Project.DisplayName == "" {
+		s.writeError(w, http.StatusBadRequest, "Missing project display name")
+		return nil
+	}
+
+	name := body.Project.DisplayName
+	_, err = s.projectDir(name)
+	if err != nil {
+		return err
+	}
+
+	apiName := api.ConvertProjectNameForUseWithAPIV1V2(name)
+	return writeJSON(w, &api.ProjectResponse{
+		Name: apiName,
+		Done: true,
+		Response: &api.Response{
+			Name:        apiName,
+			DisplayName: name,
+		},
+	})
+}
+
+func (s *Server) importArtifact(w http.ResponseWriter, r *http.Request, project string) error {
+	file, header, err := r.FormFile(artifactsFormKey)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "Missing form file "+artifactsFormKey+": "+err.Error())
+		return nil
+	}
+	defer file.Close()
+
+	// The client uses the local path of the bundle as the file name
+	artifact, err := s.storeArtifact(project, header.Filename, func(path string) error {
+		f, err := os.Create(path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer f.Close()
+		_, err = io.Copy(f, file)
+		return errors.WithStack(err)
+	})
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, artifact)
+}
+
+// storeArtifact creates a new artifact in the given project and calls
+// store to write the bundle to the path of the artifact.
+func (s *Server) storeArtifact(project string, fileName string, store func(path string) error) (*api.Artifact, error) {
+	dir, err := s.projectDir(project)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	artifactDir := filepath.Join(dir, artifactsDir, id)
+	err = os.MkdirAll(artifactDir, 0o755)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	bundlePath := filepath.Join(artifactDir, filepath.Base(filepath.FromSlash(fileName)))
+	err = store(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Stored bundle for project %s in %s", project, bundlePath)
+
+	return &api.Artifact{
+		DisplayName:  filepath.Base(bundlePath),
+		ResourceName: api.ConvertProjectNameForUseWithAPIV1V2(project) + "/" + artifactsDir + "/" + id,
+	}, nil
+}
+
+// upload is the state of a chunked upload, which is stored next to
+// the uploaded data.
+type upload struct {
+	FileName string `json:"file-name"`
+	Size     int64  `json:"size"`
+}
+
+func (s *Server) createUpload(w http.ResponseWriter, r *http.Request, project string) error {
+	var body api.UploadRequest
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil || body.FileName == "" || body.Size < 0 {
+		s.writeError(w, http.StatusBadRequest, "Invalid upload request")
+		return nil
+	}
+
+	dir, err := s.projectDir(project)
+	if err != nil {
+		return err
+	}
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	uploadDir := filepath.Join(dir, uploadsDir, id)
+	err = os.MkdirAll(uploadDir, 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = writeJSONFile(filepath.Join(uploadDir, "upload.json"), &upload{FileName: body.FileName, Size: body.Size})
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(uploadDir, "data"), nil, 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return writeJSON(w, &api.UploadStatus{UploadID: id, ChunkSize: s.ChunkSize})
+}
+
+func (s *Server) getUpload(w http.ResponseWriter, project string, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, dataPath, err := s.readUpload(w, project, id)
+	if err != nil || dataPath == "" {
+		return err
+	}
+	offset, err := fileSize(dataPath)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, &api.UploadStatus{UploadID: id, Offset: offset, ChunkSize: s.ChunkSize})
+}
+
+func (s *Server) uploadChunk(w http.ResponseWriter, r *http.Request, project string, id string) error {
+	var start, end, size int64
+	_, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &size)
+	if err != nil || start < 0 || end < start {
+		s.writeError(w, http.StatusBadRequest, "Invalid Content-Range header")
+		return nil
+	}
+	chunk, err := io.ReadAll(r.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if int64(len(chunk)) != end-start+1 {
+		s.writeError(w, http.StatusBadRequest, "Chunk size doesn't match Content-Range header")
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	u, dataPath, err := s.readUpload(w, project, id)
+	if err != nil || dataPath == "" {
+		return err
+	}
+	if size != u.Size || end >= u.Size {
+		s.writeError(w, http.StatusBadRequest, "Content-Range doesn't match upload size")
+		return nil
+	}
+	offset, err := fileSize(dataPath)
+	if err != nil {
+		return err
+	}
+	// Chunks have to be uploaded in order, but may overlap with data
+	// which was already received
+	if start > offset {
+		s.writeError(w, http.StatusConflict, fmt.Sprintf("Expected chunk at offset %d", offset))
+		return nil
+	}
+
+	f, err := os.OpenFile(dataPath, os.O_WRONLY, 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	_, err = f.WriteAt(chunk, start)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return writeJSON(w, &api.UploadStatus{UploadID: id, Offset: max(offset, end+1), ChunkSize: s.ChunkSize})
+}
+
+func (s *Server) completeUpload(w http.ResponseWriter, project string, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	u, dataPath, err := s.readUpload(w, project, id)
+	if err != nil || dataPath == "" {
+		return err
+	}
+	offset, err := fileSize(dataPath)
+	if err != nil {
+		return err
+	}
+	if offset != u.Size {
+		s.writeError(w, http.StatusConflict, fmt.Sprintf("Upload is incomplete (%d of %d bytes)", offset, u.Size))
+		return nil
+	}
+
+	artifact, err := s.storeArtifact(project, u.FileName, func(path string) error {
+		return errors.WithStack(os.Rename(dataPath, path))
+	})
+	if err != nil {
+		return err
+	}
+	err = os.RemoveAll(filepath.Dir(dataPath))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return writeJSON(w, artifact)
+}
+
+// readUpload returns the upload with the given ID and the path of its
+// data. If the upload doesn't exist, an error response is written and
+// an empty path is returned.
+func (s *Server) readUpload(w http.ResponseWriter, project string, id string) (*upload, string, error) {
+	dir, err := s.projectDir(project)
+	if err != nil {
+		return nil, "", err
+	}
+	uploadDir := filepath.Join(dir, uploadsDir, filepath.Base(id))
+	bytes, err := os.ReadFile(filepath.Join(uploadDir, "upload.json"))
+	if os.IsNotExist(err) {
+		s.writeError(w, http.StatusNotFound, "Upload not found: "+id)
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	u := &upload{}
+	err = json.Unmarshal(bytes, u)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+	return u, filepath.Join(uploadDir, "data"), nil
+}
+
+// startRun records a request to run the given artifact. The local
+// server can't execute fuzzing runs, so only the campaign run name is
+// returned to the client.
+func (s *Server) startRun(w http.ResponseWriter, project string, artifact string) error {
+	dir, err := s.projectDir(project)
+	if err != nil {
+		return err
+	}
+	exists, err := fileutil.Exists(filepath.Join(dir, artifactsDir, artifact))
+	if err != nil {
+		return err
+	}
+	if !exists {
+		s.writeError(w, http.StatusNotFound, "Artifact not found: "+artifact)
+		return nil
+	}
+
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	log.Infof("Fuzzing runs are not supported by the local server, not running artifact %s", artifact)
+
+	return writeJSON(w, map[string]string{
+		"name": api.ConvertProjectNameForUseWithAPIV1V2(project) + "/" + campaignRunsDir + "/" + id,
+	})
+}
+
+func (s *Server) createCampaignRun(w http.ResponseWriter, r *http.Request, project string) error {
+	var body api.CampaignRunBody
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil || body.CampaignRun == nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid campaign run")
+		return nil
+	}
+
+	dir, err := s.projectDir(project)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Join(dir, campaignRunsDir), 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	path := filepath.Join(dir, campaignRunsDir, url.PathEscape(filepath.Base(body.CampaignRun.Name))+".json")
+	err = writeJSONFile(path, &body)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, &body)
+}
+
+func (s *Server) listFindings(w http.ResponseWriter, project string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	findings, err := s.readFindings(project)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, findings)
+}
+
+func (s *Server) uploadFindings(w http.ResponseWriter, r *http.Request, project string) error {
+	var body api.Findings
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid findings: "+err.Error())
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	findings, err := s.readFindings(project)
+	if err != nil {
+		return err
+	}
+	for _, finding := range body.Findings {
+		if finding.Timestamp == "" {
+			finding.Timestamp = time.Now().Format(time.RFC3339)
+		}
+		// The client doesn't set the display name of the fuzz target
+		// when uploading findings, but expects it when downloading them
+		if finding.FuzzTargetDisplayName == "" {
+			finding.FuzzTargetDisplayName = finding.FuzzTarget
+		}
+		findings.Findings = append(findings.Findings, finding)
+	}
+	sort.SliceStable(findings.Findings, func(i, j int) bool {
+		return findings.Findings[i].Timestamp < findings.Findings[j].Timestamp
+	})
+
+	dir, err := s.projectDir(project)
+	if err != nil {
+		return err
+	}
+	err = writeJSONFile(filepath.Join(dir, findingsFile), findings)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, map[string]any{})
+}
+
+func (s *Server) readFindings(project string) (*api.Findings, error) {
+	dir, err := s.projectDir(project)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := &api.Findings{Findings: []api.Finding{}}
+	bytes, err := os.ReadFile(filepath.Join(dir, findingsFile))
+	if os.IsNotExist(err) {
+		return findings, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = json.Unmarshal(bytes, findings)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse findings of project %s", project)
+	}
+	return findings, nil
+}
+
+// projectDir returns the directory of the given (unescaped) project
+// name and creates it
+// if it doesn't exist yet. Projects are created implicitly, so that
+// uploads don't require setting up projects first.
+func (s *Server) projectDir(project string) (string, error) {
+	if project == "" || project == "." || project == ".." {
+		return "", errors.Errorf("Invalid project name %q", project)
+	}
+	dir := filepath.Join(s.Dir, projectsDir, url.PathEscape(project))
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return dir, nil
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(map[string]string{"code": http.StatusText(status), "message": msg})
+	if err != nil {
+		log.Warnf("Local server: failed to write response: %v", err)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) error {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(bytes)
+	return errors.WithStack(err)
+}
+
+func writeJSONFile(path string, v any) error {
+	bytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = os.WriteFile(path, bytes, 0o644)
+	return errors.WithStack(err)
+}
+
+func fileSize(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return info.Size(), nil
+}
+
+func newID() (string, error) {
+	randBytes := make([]byte, 8)
+	_, err := rand.Read(randBytes)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return hex.EncodeToString(randBytes), nil
+}
@@ -0,0 +1,178 @@
+package localserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/api"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/internal/testutil"
+	findingPkg "code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/parser/libfuzzer/stacktrace"
+)
+
+const token = "local-token"
+
+func TestLocalServer(t *testing.T) {
+	dir := testutil.MkdirTemp(t, "", "local-server-test-")
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	server, err := New(filepath.Join(dir, "data"), token)
+	require.NoError(t, err)
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	client := api.NewClient(httpServer.URL)
+
+	// An invalid token is rejected
+	_, err = client.ListProjects("invalid")
+	var apiErr *api.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, 401, apiErr.StatusCode)
+
+	projects, err := client.ListProjects(token)
+	require.NoError(t, err)
+	require.Empty(t, projects)
+
+	project, err := client.CreateProject("my project", token)
+	require.NoError(t, err)
+	require.Equal(t, "projects/my%20project", project.Name)
+
+	projects, err = client.ListProjects(token)
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	require.Equal(t, "my project", projects[0].Name)
+
+	// Upload a bundle and start a run for it
+	bundlePath := filepath.Join(dir, "fuzz_tests.tar.gz")
+	err = os.WriteFile(bundlePath, []byte("bundle"), 0o644)
+	require.NoError(t, err)
+	artifact, err := client.UploadBundle(bundlePath, "my project", token)
+	require.NoError(t, err)
+	require.Equal(t, "fuzz_tests.tar.gz", artifact.DisplayName)
+	require.FileExists(t, filepath.Join(server.Dir, "projects", "my%20project", "artifacts",
+		filepath.Base(artifact.ResourceName), "fuzz_tests.tar.gz"))
+
+	runName, err := client.StartRemoteFuzzingRun(artifact, token)
+	require.NoError(t, err)
+	require.Contains(t, runName, "projects/my%20project/campaign_runs/")
+
+	// Upload a campaign run and a finding
+	campaignRunName, fuzzingRunName, err := client.CreateCampaignRun("my project", token, "my_fuzz_test", config.BuildSystemCMake, nil, nil)
+	require.NoError(t, err)
+	matches, err := filepath.Glob(filepath.Join(server.Dir, "projects", "my%20project", "campaign_runs", "*.json"))
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+
+	finding := &findingPkg.Finding{
+		Name:      "funky_fox",
+		Type:      findingPkg.ErrorTypeCrash,
+		InputData: []byte("input"),
+		Details:   "heap-buffer-overflow",
+		StackTrace: []*stacktrace.StackFrame{
+			{Function: "foo", SourceFile: "foo.cpp", Line: 1, Column: 2},
+		},
+	}
+	err = client.UploadFinding("my project", "my_fuzz_test", campaignRunName, fuzzingRunName, finding, token)
+	require.NoError(t, err)
+
+	findings, err := client.DownloadRemoteFindings("my project", token)
+	require.NoError(t, err)
+	require.Len(t, findings.Findings, 1)
+
+	remoteFinding, err := client.GetRemoteFinding("funky_fox", "my project", token)
+	require.NoError(t, err)
+	require.Equal(t, "my_fuzz_test", remoteFinding.FuzzTest)
+	require.Equal(t, finding.InputData, remoteFinding.InputData)
+	require.Equal(t, finding.StackTrace, remoteFinding.StackTrace)
+
+	// Projects without findings return an empty list
+	findings, err = client.DownloadRemoteFindings("other project", token)
+	require.NoError(t, err)
+	require.Empty(t, findings.Findings)
+}
+
+func TestChunkedUpload(t *testing.T) {
+	dir := testutil.MkdirTemp(t, "", "local-server-test-")
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	server, err := New(filepath.Join(dir, "data"), token)
+	require.NoError(t, err)
+	server.ChunkSize = 4
+
+	// Fail some of the chunk uploads, either with a transient error
+	// which is retried or with an error which aborts the upload
+	var chunkRanges []string
+	failures := map[int]int{}
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			chunkRanges = append(chunkRanges, r.Header.Get("Content-Range"))
+			if status, ok := failures[len(chunkRanges)]; ok {
+				w.WriteHeader(status)
+				return
+			}
+		}
+		server.ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+	client := api.NewClient(httpServer.URL)
+	client.RetryPolicy = &api.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	bundlePath := filepath.Join(dir, "fuzz_tests.tar.gz")
+	err = os.WriteFile(bundlePath, []byte("0123456789"), 0o644)
+	require.NoError(t, err)
+
+	failures[2] = http.StatusServiceUnavailable
+	failures[3] = http.StatusInternalServerError
+	_, err = client.UploadBundle(bundlePath, "my-project", token)
+	require.Error(t, err)
+	require.Equal(t, []string{"bytes 0-3/10", "bytes 4-7/10", "bytes 4-7/10"}, chunkRanges)
+
+	// The next upload resumes after the last successful chunk
+	chunkRanges = nil
+	failures = map[int]int{}
+	artifact, err := client.UploadBundle(bundlePath, "my-project", token)
+	require.NoError(t, err)
+	require.Equal(t, []string{"bytes 4-7/10", "bytes 8-9/10"}, chunkRanges)
+
+	content, err := os.ReadFile(filepath.Join(server.Dir, "projects", "my-project", "artifacts",
+		filepath.Base(artifact.ResourceName), "fuzz_tests.tar.gz"))
+	require.NoError(t, err)
+	require.Equal(t, "0123456789", string(content))
+}
+
+func TestUploadWithoutChunkedUploadSupport(t *testing.T) {
+	// Servers which don't know the chunked upload endpoint respond
+	// with different errors
+	for _, status := range []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnsupportedMediaType} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			dir := testutil.MkdirTemp(t, "", "local-server-test-")
+			t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+			server, err := New(filepath.Join(dir, "data"), token)
+			require.NoError(t, err)
+
+			httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "/artifacts/uploads") {
+					w.WriteHeader(status)
+					return
+				}
+				server.ServeHTTP(w, r)
+			}))
+			defer httpServer.Close()
+			client := api.NewClient(httpServer.URL)
+
+			bundlePath := filepath.Join(dir, "fuzz_tests.tar.gz")
+			err = os.WriteFile(bundlePath, []byte("bundle"), 0o644)
+			require.NoError(t, err)
+
+			artifact, err := client.UploadBundle(bundlePath, "my-project", token)
+			require.NoError(t, err)
+			require.FileExists(t, filepath.Join(server.Dir, "projects", "my-project", "artifacts",
+				filepath.Base(artifact.ResourceName), "fuzz_tests.tar.gz"))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+
+	"code-intelligence.com/cifuzz/pkg/log"
+)
+
+// MaxRetriesKey is the viper key of the number of times failed API
+// requests are retried, which can be set via cifuzz.yaml, the
+// --api-max-retries flag or the MaxRetriesEnv environment variable.
+const MaxRetriesKey = "api-max-retries"
+
+const MaxRetriesEnv = "CIFUZZ_API_MAX_RETRIES"
+
+const (
+	defaultMaxRetries     = 5
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 30 * time.Second
+)
+
+// RetryPolicy defines how often and how long to wait before failed API
+// requests are retried. The delay between two attempts grows
+// exponentially, starting at BaseDelay and being capped at MaxDelay,
+// with full jitter applied to avoid synchronized retries of multiple
+// clients.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+func defaultRetryPolicy() *RetryPolicy {
+	policy := &RetryPolicy{
+		MaxRetries: defaultMaxRetries,
+		BaseDelay:  defaultRetryBaseDelay,
+		MaxDelay:   defaultRetryMaxDelay,
+	}
+
+	// BindEnv only returns an error if no key is specified
+	_ = viper.BindEnv(MaxRetriesKey, MaxRetriesEnv)
+	if viper.IsSet(MaxRetriesKey) {
+		value := viper.GetString(MaxRetriesKey)
+		maxRetries, err := strconv.Atoi(value)
+		if err != nil || maxRetries < 0 {
+			log.Warnf("Ignoring invalid value of %s: %q", MaxRetriesKey, value)
+		} else {
+			policy.MaxRetries = maxRetries
+		}
+	}
+
+	return policy
+}
+
+// delay returns the time to wait before the given retry attempt
+// (starting at 0). If the server specified a delay via the Retry-After
+// header, that delay is used instead (but still capped at MaxDelay).
+func (p *RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, p.MaxDelay)
+		}
+	}
+
+	backoff := p.MaxDelay
+	// Avoid overflowing the duration for large numbers of attempts
+	if attempt < 32 {
+		backoff = min(p.BaseDelay<<attempt, p.MaxDelay)
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	//nolint:gosec // The jitter doesn't need to be cryptographically secure
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// wait waits before the given retry attempt. It returns an error if the
+// context is cancelled before the delay elapsed.
+func (p *RetryPolicy) wait(ctx context.Context, attempt int, resp *http.Response) error {
+	delay := p.delay(attempt, resp)
+	log.Debugf("Retrying in %s (attempt %d/%d)", delay.Round(time.Millisecond), attempt+1, p.MaxRetries)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isIdempotent returns true if sending a request with the given method
+// multiple times has the same effect as sending it once, which makes it
+// safe to retry.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isRetryable returns true if the request failed with an error which
+// is likely transient.
+func isRetryable(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		// The request was cancelled by us, e.g. because the user
+		// pressed Ctrl+C, so there's no point in retrying it
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// discardResponse reads and closes the body of a response which isn't
+// used, so that the underlying connection can be reused.
+func discardResponse(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
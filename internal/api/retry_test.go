@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryDelay(t *testing.T) {
+	policy := &RetryPolicy{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	for attempt := 0; attempt < 40; attempt++ {
+		delay := policy.delay(attempt, nil)
+		require.GreaterOrEqual(t, delay, time.Duration(0))
+		require.LessOrEqual(t, delay, policy.MaxDelay)
+		if attempt < 3 {
+			require.LessOrEqual(t, delay, policy.BaseDelay<<attempt)
+		}
+	}
+
+	// The Retry-After header takes precedence, but is capped
+	resp := &http.Response{Header: http.Header{"Retry-After": {"3"}}}
+	require.Equal(t, 3*time.Second, policy.delay(0, resp))
+	resp.Header.Set("Retry-After", "3600")
+	require.Equal(t, policy.MaxDelay, policy.delay(0, resp))
+}
+
+func TestSendRequestRetries(t *testing.T) {
+	var requests, failures int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"projects": []}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.RetryPolicy = &RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	// Idempotent requests are retried
+	failures = 2
+	_, err := client.ListProjects("token")
+	require.NoError(t, err)
+	require.Equal(t, 3, requests)
+
+	// Other requests are not
+	requests, failures = 0, 2
+	_, err = client.CreateProject("my-project", "token")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	require.Equal(t, 1, requests)
+
+	// Requests fail once the retries are exhausted
+	requests, failures = 0, 10
+	_, err = client.ListProjects("token")
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, 4, requests)
+}
+
+func TestDefaultRetryPolicy_MaxRetries(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	require.Equal(t, defaultMaxRetries, defaultRetryPolicy().MaxRetries)
+
+	viper.Set(MaxRetriesKey, 2)
+	require.Equal(t, 2, defaultRetryPolicy().MaxRetries)
+
+	// Invalid values are ignored
+	viper.Set(MaxRetriesKey, "-1")
+	require.Equal(t, defaultMaxRetries, defaultRetryPolicy().MaxRetries)
+}
@@ -0,0 +1,385 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/term"
+
+	"code-intelligence.com/cifuzz/internal/cmd/remoterun/progress"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/pkg/log"
+)
+
+// DefaultUploadChunkSize is the size of the chunks in which bundles are
+// uploaded if the server doesn't specify a chunk size.
+const DefaultUploadChunkSize = 16 * 1024 * 1024
+
+// Chunked uploads work as follows:
+//
+//  0. OPTIONS v2/<project>/artifacts/uploads checks whether the server
+//     supports chunked uploads, which it announces by listing POST in
+//     the Allow header of the response. Otherwise, the bundle is
+//     uploaded in a single request via v2/<project>/artifacts/import.
+//  1. POST v2/<project>/artifacts/uploads with an UploadRequest creates
+//     an upload session and returns an UploadStatus.
+//  2. PUT v2/<project>/artifacts/uploads/<id> with a Content-Range
+//     header uploads a chunk and returns the new UploadStatus. Chunks
+//     must be uploaded in order, but re-uploading data which the server
+//     already received is allowed, which makes the request idempotent.
+//  3. POST v2/<project>/artifacts/uploads/<id>:complete imports the
+//     uploaded bundle and returns the Artifact.
+//
+// GET v2/<project>/artifacts/uploads/<id> returns the UploadStatus,
+// which is used to resume an interrupted upload.
+
+type UploadRequest struct {
+	FileName string `json:"file-name"`
+	Size     int64  `json:"size"`
+}
+
+type UploadStatus struct {
+	UploadID  string `json:"upload-id"`
+	Offset    int64  `json:"offset"`
+	ChunkSize int64  `json:"chunk-size,omitempty"`
+}
+
+// uploadState is persisted while a bundle is uploaded, so that the
+// upload can be resumed by the next invocation if it's interrupted.
+type uploadState struct {
+	Server   string    `json:"server"`
+	Project  string    `json:"project"`
+	UploadID string    `json:"upload-id"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod-time"`
+}
+
+// UploadBundle uploads the bundle at the given path to the given
+// project. If the server supports chunked uploads, the bundle is
+// uploaded in chunks, which are retried if they fail with a transient
+// error. If an upload is interrupted, the next upload of the same
+// bundle resumes it. Otherwise, the bundle is uploaded in a single
+// request.
+func (client *APIClient) UploadBundle(path string, projectName string, token string) (*Artifact, error) {
+	supported, err := client.supportsChunkedUploads(projectName, token)
+	if err != nil {
+		return nil, err
+	}
+	if !supported {
+		log.Debugf("Server doesn't support chunked uploads, uploading bundle in a single request")
+		return client.uploadBundleInOneRequest(path, projectName, token)
+	}
+
+	signalHandlerCtx, cancelSignalHandler := context.WithCancel(context.Background())
+	routines, routinesCtx := errgroup.WithContext(context.Background())
+
+	// Cancel the upload when receiving a termination signal
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
+	defer signal.Stop(sigs)
+	routines.Go(func() error {
+		select {
+		case <-signalHandlerCtx.Done():
+			return nil
+		case s := <-sigs:
+			log.Warnf("Received %s", s.String())
+			return cmdutils.NewSignalError(s.(syscall.Signal))
+		}
+	})
+
+	var artifact *Artifact
+	routines.Go(func() error {
+		defer cancelSignalHandler()
+		var err error
+		artifact, err = client.uploadBundleInChunks(routinesCtx, path, projectName, token)
+		return err
+	})
+
+	err = routines.Wait()
+	if err != nil {
+		// Routines.Wait() returns our own errors so it should already have
+		// a stack trace and doesn't need to have one added
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	return artifact, nil
+}
+
+// supportsChunkedUploads returns true if the server announces support
+// for chunked uploads. Any response other than a successful one which
+// lists POST in the Allow header means that it's not supported, so
+// that servers which don't know the endpoint (and respond with e.g.
+// 400, 404 or 415) get the bundle in a single request.
+func (client *APIClient) supportsChunkedUploads(projectName string, token string) (bool, error) {
+	project := ConvertProjectNameForUseWithAPIV1V2(projectName)
+	resp, err := client.send(context.Background(), &request{
+		method:   http.MethodOptions,
+		endpoint: "v2/" + project + "/artifacts/uploads",
+		token:    token,
+		timeout:  30 * time.Second,
+	})
+	if err != nil {
+		return false, err
+	}
+	discardResponse(resp)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false, nil
+	}
+	for _, value := range resp.Header.Values("Allow") {
+		for _, method := range strings.Split(value, ",") {
+			if strings.TrimSpace(method) == http.MethodPost {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (client *APIClient) uploadBundleInChunks(ctx context.Context, path string, projectName string, token string) (*Artifact, error) {
+	project := ConvertProjectNameForUseWithAPIV1V2(projectName)
+
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+
+	state := &uploadState{
+		Server:  client.Server,
+		Project: project,
+		Size:    fileInfo.Size(),
+		ModTime: fileInfo.ModTime(),
+	}
+	statePath, err := uploadStatePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	status := client.resumeUpload(ctx, statePath, state, token)
+	if status == nil {
+		status, err = client.createUpload(ctx, project, fileInfo, token)
+		if err != nil {
+			return nil, err
+		}
+		state.UploadID = status.UploadID
+		err = writeUploadState(statePath, state)
+		if err != nil {
+			// Not being able to resume the upload later shouldn't
+			// prevent the upload
+			log.Warnf("Failed to store upload state: %v", err)
+		}
+	}
+
+	chunkSize := status.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultUploadChunkSize
+	}
+
+	var bar *progress.ChunkedProgress
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Println("Uploading...")
+		bar = progress.NewChunkedProgress(state.Size, "Upload complete")
+	}
+
+	chunk := make([]byte, chunkSize)
+	offset := status.Offset
+	for offset < state.Size {
+		n, err := f.ReadAt(chunk[:min(chunkSize, state.Size-offset)], offset)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, errors.WithStack(err)
+		}
+		status, err = client.uploadChunk(ctx, project, state, chunk[:n], offset, bar, token)
+		if err != nil {
+			return nil, err
+		}
+		offset = status.Offset
+	}
+
+	if bar != nil {
+		err = bar.Done()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	artifact, err := APIRequest[Artifact](&RequestConfig{
+		Client:       client,
+		Method:       "POST",
+		Token:        token,
+		PathSegments: []string{"v2", project, "artifacts", "uploads", state.UploadID + ":complete"},
+		// Importing large bundles can take a while
+		Timeout: 5 * time.Minute,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.Remove(statePath)
+	if err != nil {
+		log.Warnf("Failed to remove upload state: %v", err)
+	}
+
+	return artifact, nil
+}
+
+func (client *APIClient) createUpload(ctx context.Context, project string, fileInfo os.FileInfo, token string) (*UploadStatus, error) {
+	body, err := json.Marshal(&UploadRequest{FileName: fileInfo.Name(), Size: fileInfo.Size()})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	resp, err := client.send(ctx, &request{
+		method:   "POST",
+		endpoint: "v2/" + project + "/artifacts/uploads",
+		body:     body,
+		token:    token,
+		timeout:  30 * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseUploadStatus(resp)
+}
+
+// resumeUpload returns the status of a previous upload of the same
+// bundle or nil if there is no such upload which can be resumed.
+func (client *APIClient) resumeUpload(ctx context.Context, statePath string, state *uploadState, token string) *UploadStatus {
+	previous, err := readUploadState(statePath)
+	if err != nil {
+		log.Debugf("Not resuming upload: %v", err)
+		return nil
+	}
+	if previous == nil || previous.Server != state.Server || previous.Project != state.Project ||
+		previous.Size != state.Size || !previous.ModTime.Equal(state.ModTime) {
+		return nil
+	}
+
+	resp, err := client.send(ctx, &request{
+		method:   "GET",
+		endpoint: "v2/" + state.Project + "/artifacts/uploads/" + previous.UploadID,
+		token:    token,
+		timeout:  30 * time.Second,
+	})
+	if err != nil {
+		log.Debugf("Not resuming upload: %v", err)
+		return nil
+	}
+	status, err := parseUploadStatus(resp)
+	if err != nil {
+		log.Debugf("Not resuming upload: %v", err)
+		return nil
+	}
+
+	log.Infof("Resuming previous upload at %d of %d bytes", status.Offset, state.Size)
+	state.UploadID = previous.UploadID
+	return status
+}
+
+func (client *APIClient) uploadChunk(ctx context.Context, project string, state *uploadState, chunk []byte, offset int64, bar *progress.ChunkedProgress, token string) (*UploadStatus, error) {
+	r := &request{
+		method:      "PUT",
+		endpoint:    "v2/" + project + "/artifacts/uploads/" + state.UploadID,
+		body:        chunk,
+		token:       token,
+		timeout:     5 * time.Minute,
+		contentType: "application/octet-stream",
+		header: http.Header{
+			"Content-Range": {fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(len(chunk))-1, state.Size)},
+		},
+	}
+	if bar != nil {
+		r.wrapBody = func(reader io.Reader) io.Reader {
+			return bar.NewReader(reader, offset)
+		}
+	}
+
+	resp, err := client.send(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	status, err := parseUploadStatus(resp)
+	if err != nil {
+		return nil, err
+	}
+	if status.Offset <= offset {
+		return nil, errors.Errorf("Server didn't accept uploaded chunk at offset %d", offset)
+	}
+	return status, nil
+}
+
+func parseUploadStatus(resp *http.Response) (*UploadStatus, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseToAPIError(resp)
+	}
+
+	status := &UploadStatus{}
+	err := json.NewDecoder(resp.Body).Decode(status)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse upload status")
+	}
+	return status, nil
+}
+
+// uploadStatePath returns the path of the file in which the state of
+// uploads of the given bundle is stored.
+func uploadStatePath(bundlePath string) (string, error) {
+	absPath, err := filepath.Abs(bundlePath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	hash := sha256.Sum256([]byte(absPath))
+	return filepath.Join(cacheDir, "cifuzz", "uploads", hex.EncodeToString(hash[:])+".json"), nil
+}
+
+func readUploadState(path string) (*uploadState, error) {
+	bytes, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	state := &uploadState{}
+	err = json.Unmarshal(bytes, state)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return state, nil
+}
+
+func writeUploadState(path string, state *uploadState) error {
+	bytes, err := json.Marshal(state)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = os.WriteFile(path, bytes, 0o644)
+	return errors.WithStack(err)
+}
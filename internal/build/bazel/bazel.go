@@ -1,6 +1,7 @@
 package bazel
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/fs"
@@ -14,6 +15,7 @@ import (
 
 	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/pkg/dependencies"
 	"code-intelligence.com/cifuzz/pkg/log"
 	"code-intelligence.com/cifuzz/pkg/runfiles"
@@ -25,11 +27,20 @@ import (
 type BuilderOptions struct {
 	ProjectDir string
 	Args       []string
+	Engine     config.Engine
 	NumJobs    uint
 	Stdout     io.Writer
 	Stderr     io.Writer
 	TempDir    string
 	Verbose    bool
+	// The output base used by the bazel commands of the builder. If
+	// not set, bazel's default output base is used. Bazel only executes
+	// one command per output base at a time, so builds which should run
+	// concurrently must use different output bases.
+	OutputBase string
+	// The context of the bazel commands, which are killed when it's
+	// done. Defaults to context.Background().
+	Context context.Context
 }
 
 func (opts *BuilderOptions) Validate() error {
@@ -50,6 +61,15 @@ func (opts *BuilderOptions) Validate() error {
 		panic("TempDir is not set")
 	}
 
+	// Build for libFuzzer unless another engine was specified
+	if opts.Engine == "" {
+		opts.Engine = config.Libfuzzer
+	}
+
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
+
 	return nil
 }
 
@@ -96,7 +116,7 @@ func (b *Builder) BuildForRun(fuzzTests []string) ([]*build.BuildResult, error)
 		// allow users to specify either "foo" or "foo_bin", so we check
 		// if the fuzz test name  appended with "_bin" is a valid target
 		// and use that in that case
-		cmd := exec.Command("bazel", "query", fuzzTests[i]+"_bin")
+		cmd := b.command("query", fuzzTests[i]+"_bin")
 		err := cmd.Run()
 		if err == nil {
 			binLabels = append(binLabels, fuzzTests[i]+"_bin")
@@ -111,7 +131,7 @@ func (b *Builder) BuildForRun(fuzzTests []string) ([]*build.BuildResult, error)
 	// binding allows access to all artifacts in the sandbox.
 	// When building via bazel, the "output_base" directory contains
 	// all artifacts, so we use that as the BuildDir.
-	cmd := exec.Command("bazel", "info", "output_base")
+	cmd := b.command("info", "output_base")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
@@ -126,12 +146,21 @@ func (b *Builder) BuildForRun(fuzzTests []string) ([]*build.BuildResult, error)
 	if err != nil {
 		return nil, err
 	}
+	if b.Engine == config.AFLPlusPlus {
+		buildEnv, err = b.setAFLPlusPlusEnv(buildEnv)
+		if err != nil {
+			return nil, err
+		}
+	}
 	commonFlags := []string{
 		"--repo_env=CC=" + envutil.Getenv(buildEnv, "CC"),
 		"--repo_env=CXX=" + envutil.Getenv(buildEnv, "CXX"),
 		// Don't use the LLVM from Xcode
 		"--repo_env=BAZEL_USE_CPP_ONLY_TOOLCHAIN=1",
 	}
+	if b.Engine == config.AFLPlusPlus {
+		commonFlags = append(commonFlags, ossFuzzRepoEnvFlags(buildEnv)...)
+	}
 	if b.NumJobs != 0 {
 		commonFlags = append(commonFlags, "--jobs", fmt.Sprint(b.NumJobs))
 	}
@@ -152,16 +181,29 @@ func (b *Builder) BuildForRun(fuzzTests []string) ([]*build.BuildResult, error)
 		// Disable source fortification, which is currently not supported
 		// in combination with ASan, see https://github.com/google/sanitizers/issues/247
 		"--copt", "-U_FORTIFY_SOURCE",
-		// Build with libFuzzer
-		"--@rules_fuzzing//fuzzing:cc_engine=@rules_fuzzing//fuzzing/engines:libfuzzer",
-		"--@rules_fuzzing//fuzzing:cc_engine_instrumentation=libfuzzer",
-		// Build with ASan and UBSan instrumentation
-		"--@rules_fuzzing//fuzzing:cc_engine_sanitizer=asan-ubsan",
-		// Link in our additional libFuzzer logic that dumps inputs for non-fatal crashes.
-		"--@cifuzz//:__internal_has_libfuzzer",
 		"--verbose_failures",
 		"--script_path=" + fuzzScript,
 	}
+	if b.Engine == config.AFLPlusPlus {
+		runFlags = append(runFlags,
+			// Build with the OSS-Fuzz engine, which uses the AFL++
+			// compiler wrappers and the AFL++ driver configured via
+			// the repo_env flags above. ASan and UBSan are enabled via
+			// FUZZING_CFLAGS.
+			"--@rules_fuzzing//fuzzing:cc_engine=@rules_fuzzing_oss_fuzz//:oss_fuzz_engine",
+			"--@rules_fuzzing//fuzzing:cc_engine_instrumentation=oss-fuzz",
+		)
+	} else {
+		runFlags = append(runFlags,
+			// Build with libFuzzer
+			"--@rules_fuzzing//fuzzing:cc_engine=@rules_fuzzing//fuzzing/engines:libfuzzer",
+			"--@rules_fuzzing//fuzzing:cc_engine_instrumentation=libfuzzer",
+			// Build with ASan and UBSan instrumentation
+			"--@rules_fuzzing//fuzzing:cc_engine_sanitizer=asan-ubsan",
+			// Link in our additional libFuzzer logic that dumps inputs for non-fatal crashes.
+			"--@cifuzz//:__internal_has_libfuzzer",
+		)
+	}
 
 	if os.Getenv("BAZEL_SUBCOMMANDS") != "" {
 		runFlags = append(runFlags, "--subcommands")
@@ -173,7 +215,7 @@ func (b *Builder) BuildForRun(fuzzTests []string) ([]*build.BuildResult, error)
 	args = append(args, b.Args...)
 	args = append(args, binLabels...)
 
-	cmd = exec.Command("bazel", args...)
+	cmd = b.command(args...)
 	cmd.Stdout = b.Stdout
 	cmd.Stderr = b.Stderr
 	if err != nil {
@@ -190,7 +232,7 @@ func (b *Builder) BuildForRun(fuzzTests []string) ([]*build.BuildResult, error)
 
 	for _, fuzzTest := range fuzzTests {
 		// Turn the fuzz test label into a valid path
-		path, err := PathFromLabel(fuzzTest, commonFlags)
+		path, err := b.pathFromLabel(fuzzTest, commonFlags)
 		if err != nil {
 			return nil, err
 		}
@@ -218,7 +260,16 @@ func (b *Builder) BuildForBundle(sanitizers []string, fuzzTests []string) ([]*bu
 		return nil, err
 	}
 
-	env, err = b.setLibFuzzerEnv(env)
+	// Coverage builds are never instrumented for fuzzing, so they
+	// are built with clang regardless of the engine
+	isCoverageBuild := len(sanitizers) == 1 && sanitizers[0] == "coverage"
+	if b.Engine == config.AFLPlusPlus && !isCoverageBuild {
+		env, err = b.setAFLPlusPlusEnv(env)
+	} else if build.IsSanitizerVariant(sanitizers) {
+		env, err = b.setLibFuzzerEnv(env, build.LibFuzzerSanitizerVariantCFlags(sanitizers[0]))
+	} else {
+		env, err = b.setLibFuzzerEnv(env, build.LibFuzzerCFlags())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -229,15 +280,10 @@ func (b *Builder) BuildForBundle(sanitizers []string, fuzzTests []string) ([]*bu
 	commonFlags := []string{
 		"--repo_env=CC=" + envutil.Getenv(env, "CC"),
 		"--repo_env=CXX=" + envutil.Getenv(env, "CXX"),
-		"--repo_env=FUZZING_CFLAGS=" + envutil.Getenv(env, "FUZZING_CFLAGS"),
-		"--repo_env=FUZZING_CXXFLAGS=" + envutil.Getenv(env, "FUZZING_CXXFLAGS"),
-		"--repo_env=LIB_FUZZING_ENGINE=" + envutil.Getenv(env, "LIB_FUZZING_ENGINE"),
 		// Don't use the LLVM from Xcode
 		"--repo_env=BAZEL_USE_CPP_ONLY_TOOLCHAIN=1",
-		// rules_fuzzing only links in the UBSan C++ runtime when the
-		// sanitizer is set to "undefined"
-		"--repo_env=SANITIZER=undefined",
 	}
+	commonFlags = append(commonFlags, ossFuzzRepoEnvFlags(env)...)
 	if b.NumJobs != 0 {
 		commonFlags = append(commonFlags, "--jobs", fmt.Sprint(b.NumJobs))
 	}
@@ -257,7 +303,7 @@ func (b *Builder) BuildForBundle(sanitizers []string, fuzzTests []string) ([]*bu
 	}
 
 	// Add sanitizer-specific flags
-	if len(sanitizers) == 1 && sanitizers[0] == "coverage" {
+	if isCoverageBuild {
 		llvmCov, err := runfiles.Finder.LLVMCovPath()
 		if err != nil {
 			return nil, err
@@ -288,6 +334,10 @@ func (b *Builder) BuildForBundle(sanitizers []string, fuzzTests []string) ([]*bu
 				// via the FUZZING_CFLAGS environment variable. These
 				// variables are then picked up by the OSS-Fuzz engine
 				// instrumentation.
+			case "memory", "thread":
+				// Same as above, but MSan and TSan are built as
+				// separate variants, because they can't be combined
+				// with ASan.
 			default:
 				panic(fmt.Sprintf("Invalid sanitizer: %q", sanitizer))
 			}
@@ -298,6 +348,11 @@ func (b *Builder) BuildForBundle(sanitizers []string, fuzzTests []string) ([]*bu
 	args = append(args, commonFlags...)
 	args = append(args, b.Args...)
 	args = append(args, buildAndCQueryFlags...)
+	if b.OutputBase != "" {
+		// Don't let the convenience symlinks in the workspace (like
+		// bazel-bin) point to the custom output base
+		args = append(args, "--symlink_prefix=/")
+	}
 
 	// We have to build the "*_oss_fuzz" target defined by the
 	// cc_fuzz_test rule
@@ -307,7 +362,7 @@ func (b *Builder) BuildForBundle(sanitizers []string, fuzzTests []string) ([]*bu
 	}
 	args = append(args, labels...)
 
-	cmd := exec.Command("bazel", args...)
+	cmd := b.command(args...)
 	cmd.Stdout = b.Stdout
 	cmd.Stderr = b.Stderr
 	log.Debugf("Command: %s", cmd.String())
@@ -325,7 +380,7 @@ func (b *Builder) BuildForBundle(sanitizers []string, fuzzTests []string) ([]*bu
 		args = append(args, commonFlags...)
 		args = append(args, buildAndCQueryFlags...)
 		args = append(args, fuzzTest+"_oss_fuzz")
-		cmd = exec.Command("bazel", args...)
+		cmd = b.command(args...)
 		out, err := cmd.Output()
 		if err != nil {
 			return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
@@ -342,7 +397,7 @@ func (b *Builder) BuildForBundle(sanitizers []string, fuzzTests []string) ([]*bu
 			return nil, errors.WithMessagef(err, "Failed to extract archive %s to %s", ossFuzzArchive, extractedDir)
 		}
 
-		path, err := PathFromLabel(fuzzTest, commonFlags)
+		path, err := b.pathFromLabel(fuzzTest, commonFlags)
 		if err != nil {
 			return nil, err
 		}
@@ -402,11 +457,10 @@ func (b *Builder) BuildForBundle(sanitizers []string, fuzzTests []string) ([]*bu
 	return results, nil
 }
 
-func (b *Builder) setLibFuzzerEnv(env []string) ([]string, error) {
+func (b *Builder) setLibFuzzerEnv(env []string, cflags []string) ([]string, error) {
 	var err error
 
 	// Set FUZZING_CFLAGS and FUZZING_CXXFLAGS.
-	cflags := build.LibFuzzerCFlags()
 	env, err = envutil.Setenv(env, "FUZZING_CFLAGS", strings.Join(cflags, " "))
 	if err != nil {
 		return nil, err
@@ -426,6 +480,48 @@ func (b *Builder) setLibFuzzerEnv(env []string) ([]string, error) {
 	return env, nil
 }
 
+// setAFLPlusPlusEnv sets the environment variables which configure the
+// OSS-Fuzz engine to build with the AFL++ compiler wrappers and link in
+// the AFL++ driver.
+func (b *Builder) setAFLPlusPlusEnv(env []string) ([]string, error) {
+	env, err := build.AFLPlusPlusBuildEnv(env)
+	if err != nil {
+		return nil, err
+	}
+
+	cflags := build.AFLPlusPlusCFlags()
+	env, err = envutil.Setenv(env, "FUZZING_CFLAGS", strings.Join(cflags, " "))
+	if err != nil {
+		return nil, err
+	}
+	env, err = envutil.Setenv(env, "FUZZING_CXXFLAGS", strings.Join(cflags, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	// The AFL++ compiler wrappers replace libFuzzer with the AFL++
+	// driver when linking with -fsanitize=fuzzer
+	env, err = envutil.Setenv(env, "LIB_FUZZING_ENGINE", "-fsanitize=fuzzer")
+	if err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// ossFuzzRepoEnvFlags returns the flags which pass the environment
+// variables used by the OSS-Fuzz engine on to bazel.
+func ossFuzzRepoEnvFlags(env []string) []string {
+	return []string{
+		"--repo_env=FUZZING_CFLAGS=" + envutil.Getenv(env, "FUZZING_CFLAGS"),
+		"--repo_env=FUZZING_CXXFLAGS=" + envutil.Getenv(env, "FUZZING_CXXFLAGS"),
+		"--repo_env=LIB_FUZZING_ENGINE=" + envutil.Getenv(env, "LIB_FUZZING_ENGINE"),
+		// rules_fuzzing only links in the UBSan C++ runtime when the
+		// sanitizer is set to "undefined"
+		"--repo_env=SANITIZER=undefined",
+	}
+}
+
 // PathFromLabel turns a bazel label into a valid path, which can for
 // example be used to create the fuzz test's corpus directory.
 // Flags which should be passed to the `bazel query` command can be
@@ -435,7 +531,20 @@ func PathFromLabel(label string, flags []string) (string, error) {
 	// Get a canonical form of label via `bazel query`
 	args := append([]string{"query"}, flags...)
 	args = append(args, label)
-	cmd := exec.Command("bazel", args...)
+	return pathFromLabel(exec.Command("bazel", args...))
+}
+
+// pathFromLabel is like PathFromLabel but uses the output base and the
+// context of the builder
+func (b *Builder) pathFromLabel(label string, flags []string) (string, error) {
+	args := append([]string{"query"}, flags...)
+	args = append(args, label)
+	return pathFromLabel(b.command(args...))
+}
+
+// pathFromLabel runs the specified `bazel query` command and turns the
+// canonical label it prints into a path
+func pathFromLabel(cmd *exec.Cmd) (string, error) {
 	log.Debugf("Command: %s", cmd.String())
 	out, err := cmd.Output()
 	if err != nil {
@@ -455,6 +564,17 @@ func PathFromLabel(label string, flags []string) (string, error) {
 	return res, nil
 }
 
+// command returns a bazel command with the specified arguments which
+// uses the output base and the context of the builder
+func (b *Builder) command(args ...string) *exec.Cmd {
+	if b.OutputBase != "" {
+		// --output_base is a startup option, so it must precede the
+		// command
+		args = append([]string{"--output_base=" + b.OutputBase}, args...)
+	}
+	return exec.CommandContext(b.Context, "bazel", args...)
+}
+
 // Parses formatted bazel query --output=build output such as:
 //
 //	git_repository(
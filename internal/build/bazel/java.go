@@ -0,0 +1,214 @@
+package bazel
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/pkg/java"
+	"code-intelligence.com/cifuzz/pkg/log"
+)
+
+// The manifest attribute which the java_fuzz_test rule uses to tell
+// Jazzer which class contains the fuzz test
+const jazzerTargetClassManifest = "Jazzer-Fuzz-Target-Class"
+
+// The class file of Jazzer's main class, which must be contained in
+// the class path of the fuzz test
+const jazzerMainClassFile = "com/code_intelligence/jazzer/Jazzer.class"
+
+// IsJavaFuzzTest returns true if the specified label refers to a target
+// defined by the java_fuzz_test rule provided by rules_fuzzing:
+// https://github.com/bazelbuild/rules_fuzzing/blob/master/docs/java-fuzzing-rules.md#java_fuzz_test
+func IsJavaFuzzTest(label string) (bool, error) {
+	cmd := exec.Command("bazel", "query", fmt.Sprintf("attr(generator_function, java_fuzz_test, %s)", label))
+	log.Debugf("Command: %s", cmd.String())
+	out, err := cmd.Output()
+	if err != nil {
+		return false, cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+	return strings.TrimSpace(string(out)) != "", nil
+}
+
+// BuildJava builds the specified fuzz tests with bazel. It expects
+// labels of targets of the java_fuzz_test rule provided by
+// rules_fuzzing. The java_fuzz_test rule defines a java_binary target
+// which contains the fuzz test and all its dependencies. We build the
+// deploy JAR of that target and use it as the class path of the fuzz
+// test, which allows running it with Jazzer without going through the
+// launcher script generated by rules_fuzzing.
+func (b *Builder) BuildJava(fuzzTests []string) ([]*build.JavaBuildResult, error) {
+	// Like for cc_fuzz_test, we allow users to specify either "foo"
+	// or "foo_bin". We don't modify the caller's slice.
+	fuzzTests = append([]string{}, fuzzTests...)
+	for i := range fuzzTests {
+		fuzzTests[i] = strings.TrimSuffix(fuzzTests[i], "_bin")
+	}
+
+	// To avoid part of the loading and/or analysis phase to rerun, we
+	// use the same flags for the bazel build and cquery commands
+	flags := []string{"--verbose_failures"}
+	if b.NumJobs != 0 {
+		flags = append(flags, "--jobs", fmt.Sprint(b.NumJobs))
+	}
+	if os.Getenv("BAZEL_SUBCOMMANDS") != "" {
+		flags = append(flags, "--subcommands")
+	}
+	flags = append(flags, b.Args...)
+
+	var deployJarLabels []string
+	for _, fuzzTest := range fuzzTests {
+		binaryLabel, err := javaBinaryLabel(fuzzTest)
+		if err != nil {
+			return nil, err
+		}
+		deployJarLabels = append(deployJarLabels, binaryLabel+"_deploy.jar")
+	}
+
+	args := []string{"build"}
+	args = append(args, flags...)
+	args = append(args, deployJarLabels...)
+	cmd := b.command(args...)
+	cmd.Stdout = b.Stdout
+	cmd.Stderr = b.Stderr
+	log.Debugf("Command: %s", cmd.String())
+	err := cmd.Run()
+	if err != nil {
+		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+
+	// The paths returned by cquery are relative to the execution root
+	cmd = b.command("info", "execution_root")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+	executionRoot := strings.TrimSpace(string(out))
+
+	// See BuildForRun for why we use the output base as BuildDir
+	cmd = b.command("info", "output_base")
+	out, err = cmd.Output()
+	if err != nil {
+		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+	buildDir := strings.TrimSpace(string(out))
+
+	// Assemble the build results
+	var results []*build.JavaBuildResult
+
+	for i, fuzzTest := range fuzzTests {
+		args := []string{"cquery", "--output=starlark", "--starlark:expr=target.files.to_list()[0].path"}
+		args = append(args, flags...)
+		args = append(args, deployJarLabels[i])
+		cmd = b.command(args...)
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
+		}
+		deployJar := filepath.Join(executionRoot, strings.TrimSpace(string(out)))
+
+		targetClass, err := javaTargetClass(deployJar)
+		if err != nil {
+			return nil, err
+		}
+
+		path, err := PathFromLabel(fuzzTest, nil)
+		if err != nil {
+			return nil, err
+		}
+		seedCorpus := filepath.Join(b.ProjectDir, path+"_inputs")
+		generatedCorpusBasename := "." + filepath.Base(path) + "_cifuzz_corpus"
+		generatedCorpus := filepath.Join(b.ProjectDir, filepath.Dir(path), generatedCorpusBasename)
+
+		result := &build.JavaBuildResult{
+			TargetClass: targetClass,
+			BuildResult: &build.BuildResult{
+				GeneratedCorpus: generatedCorpus,
+				SeedCorpus:      seedCorpus,
+				BuildDir:        buildDir,
+				RuntimeDeps:     []string{deployJar},
+			},
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// javaBinaryLabel returns the label of the java_binary target which
+// the java_fuzz_test rule defines for the specified fuzz test.
+func javaBinaryLabel(fuzzTest string) (string, error) {
+	// All targets defined by the java_fuzz_test rule are in the same
+	// package and have the name of the fuzz test as generator_name
+	canonicalLabel, err := canonicalLabel(fuzzTest)
+	if err != nil {
+		return "", err
+	}
+	_, name, found := strings.Cut(canonicalLabel, ":")
+	if !found {
+		return "", errors.Errorf("Invalid label: %s", canonicalLabel)
+	}
+	query := fmt.Sprintf(`kind(java_binary, attr(generator_name, "^%s$", siblings(%s)))`, name, canonicalLabel)
+	cmd := exec.Command("bazel", "query", query)
+	log.Debugf("Command: %s", cmd.String())
+	out, err := cmd.Output()
+	if err != nil {
+		return "", cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+	labels := strings.Fields(string(out))
+	if len(labels) != 1 {
+		return "", errors.Errorf("%s is not a java_fuzz_test target", fuzzTest)
+	}
+	return labels[0], nil
+}
+
+// javaTargetClass returns the name of the class containing the fuzz
+// test from the manifest of the deploy JAR. It also checks that Jazzer
+// is included in the deploy JAR, because we use it as the class path
+// when running the fuzz test.
+func javaTargetClass(deployJar string) (string, error) {
+	manifest, err := java.ReadManifest(deployJar)
+	if err != nil {
+		return "", err
+	}
+	// We don't fall back to the Main-Class attribute, because the
+	// java_fuzz_test rule sets it to the Jazzer driver, not to the
+	// class of the fuzz test
+	targetClass := manifest[jazzerTargetClassManifest]
+	if targetClass == "" {
+		return "", errors.Errorf(`Failed to determine the fuzz test class of %s,
+please set the target_class attribute of the java_fuzz_test`, deployJar)
+	}
+
+	zipReader, err := zip.OpenReader(deployJar)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer zipReader.Close()
+	f, err := zipReader.Open(jazzerMainClassFile)
+	if err != nil {
+		return "", errors.Errorf(`Jazzer was not found in the class path of %s,
+please add Jazzer (e.g. "@maven//:com_code_intelligence_jazzer") to the
+runtime_deps of the java_fuzz_test`, targetClass)
+	}
+	f.Close()
+
+	return targetClass, nil
+}
+
+func canonicalLabel(label string) (string, error) {
+	cmd := exec.Command("bazel", "query", label)
+	log.Debugf("Command: %s", cmd.String())
+	out, err := cmd.Output()
+	if err != nil {
+		return "", cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
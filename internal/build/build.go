@@ -1,10 +1,16 @@
 package build
 
 import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
 	"os"
 	"runtime"
+	"strings"
 
 	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
 
 	"code-intelligence.com/cifuzz/util/envutil"
 )
@@ -40,6 +46,43 @@ type CBuildResult struct {
 // JavaBuildResult contains the fields needed to run or bundle a Java (or other JVM language) project which has been built
 type JavaBuildResult struct {
 	*BuildResult
+	// The name of the class containing the fuzz test, if it's
+	// determined by the build
+	TargetClass string
+}
+
+// BuildDirName returns the name of the build directory for a build with
+// the specified sanitizers and user arguments, relative to the directory
+// of the fuzzing engine.
+func BuildDirName(sanitizers []string, args []string) (string, error) {
+	sanitizersSegment := strings.Join(sanitizers, "+")
+	if sanitizersSegment == "" {
+		sanitizersSegment = "none"
+	}
+
+	if len(args) == 0 {
+		return sanitizersSegment, nil
+	}
+
+	// Add the hash of all user arguments to the build dir name in order to
+	// create different build directories for different combinations of arguments
+	hash := sha256.New()
+	for _, arg := range args {
+		// Prepend the length of each argument in order to differentiate
+		// between arguments like {"foo", "bar"} and {"foobar"}
+		err := binary.Write(hash, binary.BigEndian, uint32(len(arg)))
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		err = binary.Write(hash, binary.BigEndian, []byte(arg))
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+	// Use only the first 8 characters in order to prevent errors on
+	// Windows, which cannot handle long file paths.
+	hashString := base32.StdEncoding.EncodeToString(hash.Sum(nil))[:8]
+	return fmt.Sprintf("%s-%s", sanitizersSegment, hashString), nil
 }
 
 func CommonBuildEnv() ([]string, error) {
@@ -86,6 +129,27 @@ func CommonBuildEnv() ([]string, error) {
 	return env, nil
 }
 
+// AFLPlusPlusBuildEnv sets the C/C++ compiler to the compiler wrappers
+// of AFL++ (if not already set), which add the AFL++ instrumentation.
+// When linking with -fsanitize=fuzzer, the wrappers link in the AFL++
+// driver for libFuzzer-style fuzz tests instead of libFuzzer itself.
+func AFLPlusPlusBuildEnv(env []string) ([]string, error) {
+	var err error
+	if val := envutil.GetEnvWithPathSubstring(env, "CC", "afl-"); val == "" {
+		env, err = envutil.Setenv(env, "CC", "afl-clang-fast")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if val := envutil.GetEnvWithPathSubstring(env, "CXX", "afl-"); val == "" {
+		env, err = envutil.Setenv(env, "CXX", "afl-clang-fast++")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return env, nil
+}
+
 var commonCFlags = []string{
 	// Keep debug symbols
 	"-g",
@@ -104,29 +168,97 @@ func LibFuzzerCFlags() []string {
 	// These flags must not contain spaces, because the environment
 	// variables that are set to these flags are space separated.
 	// Note: Keep in sync with share/cmake/cifuzz-functions.cmake
-	return append(commonCFlags, []string{
+	cflags := append(commonCFlags, []string{
 		// ----- Flags used to build with libFuzzer -----
 		// Compile with edge coverage and compare instrumentation. We
 		// use fuzzer-no-link here instead of -fsanitize=fuzzer because
 		// CFLAGS are often also passed to the linker, which would cause
 		// errors if the build includes tools which have a main function.
 		"-fsanitize=fuzzer-no-link",
-
-		// ----- Flags used to build with ASan -----
-		// Build with instrumentation for ASan and UBSan and link in
-		// their runtime
-		"-fsanitize=address,undefined",
-		// To support recovering from ASan findings
-		"-fsanitize-recover=address",
-		// Use additional error detectors for use-after-scope bugs
-		// TODO: Evaluate the slow down caused by this flag
-		// TODO: Check if there are other additional error detectors
-		//       which we want to use
-		"-fsanitize-address-use-after-scope",
-		// Disable source fortification, which is currently not supported
-		// in combination with ASan, see https://github.com/google/sanitizers/issues/247
-		"-U_FORTIFY_SOURCE",
 	}...)
+	return append(cflags, sanitizerCFlags...)
+}
+
+// LibFuzzerNoSanitizerCFlags returns the flags used to build with
+// libFuzzer, but without any sanitizers.
+func LibFuzzerNoSanitizerCFlags() []string {
+	return append(commonCFlags, "-fsanitize=fuzzer-no-link")
+}
+
+// AFLPlusPlusCFlags returns the flags used to build with AFL++. These
+// don't contain any flags for the fuzzing instrumentation, because the
+// AFL++ compiler wrappers add that on their own.
+func AFLPlusPlusCFlags() []string {
+	return append(commonCFlags, sanitizerCFlags...)
+}
+
+var sanitizerCFlags = []string{
+	// ----- Flags used to build with ASan -----
+	// Build with instrumentation for ASan and UBSan and link in
+	// their runtime
+	"-fsanitize=address,undefined",
+	// To support recovering from ASan findings
+	"-fsanitize-recover=address",
+	// Use additional error detectors for use-after-scope bugs
+	// TODO: Evaluate the slow down caused by this flag
+	// TODO: Check if there are other additional error detectors
+	//       which we want to use
+	"-fsanitize-address-use-after-scope",
+	// Disable source fortification, which is currently not supported
+	// in combination with ASan, see https://github.com/google/sanitizers/issues/247
+	"-U_FORTIFY_SOURCE",
+}
+
+// The sanitizers which can be used for additional, opt-in fuzzing
+// builds. In contrast to ASan and UBSan, MSan and TSan can neither be
+// combined with ASan nor with each other, so each of them is built as a
+// separate variant.
+var SanitizerVariants = []string{"memory", "thread"}
+
+var sanitizerVariantCFlags = map[string][]string{
+	// ----- Flags used to build with MSan -----
+	"memory": {
+		// Build with instrumentation for MSan and link in its runtime
+		"-fsanitize=memory",
+		// Report where uninitialized values were created, which is
+		// printed as a second stack trace in MSan reports
+		"-fsanitize-memory-track-origins",
+	},
+	// ----- Flags used to build with TSan -----
+	"thread": {
+		// Build with instrumentation for TSan and link in its runtime
+		"-fsanitize=thread",
+	},
+}
+
+// LibFuzzerSanitizerVariantCFlags returns the flags used to build with
+// libFuzzer and one of the SanitizerVariants instead of ASan and UBSan.
+func LibFuzzerSanitizerVariantCFlags(sanitizer string) []string {
+	sanitizerFlags, ok := sanitizerVariantCFlags[sanitizer]
+	if !ok {
+		panic(fmt.Sprintf("Invalid sanitizer: %q", sanitizer))
+	}
+	cflags := append(commonCFlags, "-fsanitize=fuzzer-no-link")
+	return append(cflags, sanitizerFlags...)
+}
+
+// SanitizerVariantLDFlags returns the flags which link in the runtime
+// of one of the SanitizerVariants.
+func SanitizerVariantLDFlags(sanitizer string) []string {
+	if _, ok := sanitizerVariantCFlags[sanitizer]; !ok {
+		panic(fmt.Sprintf("Invalid sanitizer: %q", sanitizer))
+	}
+	return []string{"-fsanitize=" + sanitizer}
+}
+
+// IsSanitizerVariant returns true if the sanitizers consist of a single
+// one of the SanitizerVariants.
+func IsSanitizerVariant(sanitizers []string) bool {
+	if len(sanitizers) != 1 {
+		return false
+	}
+	_, ok := sanitizerVariantCFlags[sanitizers[0]]
+	return ok
 }
 
 func CoverageCFlags(clangVersion *semver.Version) []string {
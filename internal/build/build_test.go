@@ -37,3 +37,23 @@ func TestCommonBuildEnv_ClangDontOverwrite(t *testing.T) {
 	assert.Equal(t, "/my/clang", envutil.Getenv(env, "CC"))
 	assert.Equal(t, "/my/clang++", envutil.Getenv(env, "CXX"))
 }
+
+func TestLibFuzzerSanitizerVariantCFlags(t *testing.T) {
+	cflags := LibFuzzerSanitizerVariantCFlags("memory")
+	assert.Contains(t, cflags, "-fsanitize=fuzzer-no-link")
+	assert.Contains(t, cflags, "-fsanitize=memory")
+	assert.NotContains(t, cflags, "-fsanitize=address,undefined")
+
+	cflags = LibFuzzerSanitizerVariantCFlags("thread")
+	assert.Contains(t, cflags, "-fsanitize=thread")
+	assert.NotContains(t, cflags, "-fsanitize=memory")
+
+	assert.Panics(t, func() { LibFuzzerSanitizerVariantCFlags("address") })
+}
+
+func TestIsSanitizerVariant(t *testing.T) {
+	assert.True(t, IsSanitizerVariant([]string{"memory"}))
+	assert.True(t, IsSanitizerVariant([]string{"thread"}))
+	assert.False(t, IsSanitizerVariant([]string{"address", "undefined"}))
+	assert.False(t, IsSanitizerVariant([]string{"coverage"}))
+}
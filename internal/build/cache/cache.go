@@ -0,0 +1,530 @@
+// Package cache allows reusing the results of a previous build if
+// neither the sources, nor the build flags, nor the toolchain changed
+// since then.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/otiai10/copy"
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/internal/version"
+	"code-intelligence.com/cifuzz/pkg/dependencies"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/vcs"
+	"code-intelligence.com/cifuzz/util/fileutil"
+	"code-intelligence.com/cifuzz/util/sliceutil"
+)
+
+// The name of the file in a cache slot which stores the fingerprint
+// and the build results
+const entryFileName = "entry.json"
+
+// Directories with these names at the top level of the project
+// directory contain build outputs or downloaded dependencies, so we
+// don't include them in the fingerprint if the project is not part of
+// a Git repository. Otherwise, Git's ignore rules decide.
+var ignoredDirNames = []string{
+	"build",
+	"node_modules",
+	"target",
+}
+
+// Directories of version control systems, which are never included in
+// the fingerprint
+var vcsDirNames = []string{".git", ".hg", ".svn"}
+
+// Characters which separate the arguments of a build command
+const buildCommandSeparators = " \t\n;&|()<>"
+
+// Environment variables which influence the build
+var envVars = []string{
+	"CC", "CXX", "CFLAGS", "CXXFLAGS", "LDFLAGS", "JAVA_HOME",
+}
+
+// Key identifies a build configuration
+type Key struct {
+	ProjectDir  string
+	BuildSystem string
+	// Everything apart from the sources and the toolchain which
+	// influences the build result, e.g. the sanitizers, the build
+	// arguments and the fuzz tests
+	Flags []string
+	// The build command of build system type "other". Apart from the
+	// command itself, the files it refers to (e.g. build scripts
+	// outside of the project directory) are part of the fingerprint.
+	BuildCommand string
+	// If true, the artifacts of the build are copied to the cache,
+	// which is required if they are removed or overwritten by
+	// subsequent builds
+	CopyArtifacts bool
+}
+
+// Cache stores the results of the last build of a build configuration.
+// All methods can be called on a nil *Cache, in which case nothing is
+// cached.
+type Cache struct {
+	key         *Key
+	dir         string
+	fingerprint string
+}
+
+type entry struct {
+	Fingerprint string
+	Results     json.RawMessage
+	// The modification times of all files referenced by the build
+	// results, used to detect whether they were changed by another
+	// build
+	ModTimes map[string]int64
+}
+
+// Result is the type of build results which can be cached
+type Result interface {
+	build.BuildResult | build.CBuildResult | build.JavaBuildResult
+}
+
+// New returns a cache for the build configuration identified by the
+// key. It computes the fingerprint of the sources and the toolchain,
+// so it should be called right before the build.
+func New(key *Key) (*Cache, error) {
+	// Each build configuration has a single slot in the cache, which
+	// is overwritten by each build
+	slot, err := hashStrings(append([]string{key.BuildSystem, key.BuildCommand}, key.Flags...))
+	if err != nil {
+		return nil, err
+	}
+	slot = slot[:16]
+
+	fingerprint, err := Fingerprint(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cache{
+		key:         key,
+		dir:         filepath.Join(key.ProjectDir, ".cifuzz-build", "cache", slot),
+		fingerprint: fingerprint,
+	}, nil
+}
+
+// Fingerprint computes a hash of the sources, the flags and the
+// toolchain of the build configuration identified by the key. All files
+// in the project directory which are not ignored by Git are considered
+// sources.
+func Fingerprint(key *Key) (string, error) {
+	values := []string{version.Version, key.BuildSystem, key.BuildCommand}
+	values = append(values, key.Flags...)
+
+	for _, dep := range toolchain(key.BuildSystem) {
+		v, err := dependencies.Version(dep, key.ProjectDir)
+		if err != nil {
+			return "", err
+		}
+		values = append(values, string(dep)+"="+v.String())
+	}
+
+	for _, name := range envVars {
+		values = append(values, name+"="+os.Getenv(name))
+	}
+
+	sources, err := sourceFiles(key.ProjectDir)
+	if err != nil {
+		return "", err
+	}
+	for _, source := range sources {
+		hash, err := hashFile(filepath.Join(key.ProjectDir, source))
+		if err != nil {
+			return "", err
+		}
+		values = append(values, filepath.ToSlash(source)+"="+hash)
+	}
+
+	for _, file := range buildCommandFiles(key.ProjectDir, key.BuildCommand) {
+		hash, err := hashFile(file)
+		if err != nil {
+			return "", err
+		}
+		values = append(values, "build-command:"+filepath.ToSlash(file)+"="+hash)
+	}
+
+	return hashStrings(values)
+}
+
+// Load returns the cached results of the last build of the build
+// configuration if the fingerprint didn't change and the artifacts
+// still exist unchanged.
+func Load[R Result](c *Cache) ([]*R, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	bytes, err := os.ReadFile(filepath.Join(c.dir, entryFileName))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Debugf("Failed to read build cache: %+v", err)
+		}
+		return nil, false
+	}
+	var e entry
+	err = json.Unmarshal(bytes, &e)
+	if err != nil {
+		log.Debugf("Failed to parse build cache: %+v", errors.WithStack(err))
+		return nil, false
+	}
+	if e.Fingerprint != c.fingerprint {
+		return nil, false
+	}
+
+	var results []*R
+	err = json.Unmarshal(e.Results, &results)
+	if err != nil {
+		log.Debugf("Failed to parse build cache: %+v", errors.WithStack(err))
+		return nil, false
+	}
+
+	for _, result := range results {
+		for _, path := range artifacts(baseResult(result)) {
+			modTime, err := modTime(path)
+			if err != nil || modTime != e.ModTimes[path] {
+				log.Debugf("Not using cached build results, %s was changed or removed", path)
+				return nil, false
+			}
+		}
+	}
+
+	return results, true
+}
+
+// Store stores the results of a build of the build configuration. If
+// the key has CopyArtifacts set, the artifacts are copied to the cache
+// and the paths in the results are changed to point to the copies.
+func Store[R Result](c *Cache, results []*R) error {
+	if c == nil {
+		return nil
+	}
+
+	err := os.MkdirAll(c.dir, 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	e := entry{
+		Fingerprint: c.fingerprint,
+		ModTimes:    map[string]int64{},
+	}
+
+	var artifactsDir string
+	if c.key.CopyArtifacts {
+		// The results might refer to the artifacts of the previous
+		// build in the cache, so those are only removed after the
+		// artifacts were copied to a new directory
+		artifactsDir, err = os.MkdirTemp(c.dir, "artifacts-")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	for i, result := range results {
+		if c.key.CopyArtifacts {
+			err = copyArtifacts(baseResult(result), filepath.Join(artifactsDir, strconv.Itoa(i)))
+			if err != nil {
+				return err
+			}
+		}
+		for _, path := range artifacts(baseResult(result)) {
+			e.ModTimes[path], err = modTime(path)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Remove the artifacts of the previous build
+	oldArtifactsDirs, err := filepath.Glob(filepath.Join(c.dir, "artifacts-*"))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, dir := range oldArtifactsDirs {
+		if dir == artifactsDir {
+			continue
+		}
+		err = os.RemoveAll(dir)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	e.Results, err = json.Marshal(results)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	bytes, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(filepath.Join(c.dir, entryFileName), bytes, 0o644))
+}
+
+// toolchain returns the dependencies whose versions influence the
+// result of a build with the specified build system
+func toolchain(buildSystem string) []dependencies.Key {
+	compiler := dependencies.Clang
+	if runtime.GOOS == "windows" {
+		compiler = dependencies.VisualStudio
+	}
+
+	switch buildSystem {
+	case config.BuildSystemCMake:
+		return []dependencies.Key{dependencies.CMake, compiler}
+	case config.BuildSystemMeson:
+		return []dependencies.Key{dependencies.Meson, compiler}
+	case config.BuildSystemBazel:
+		return []dependencies.Key{dependencies.Bazel, compiler}
+	case config.BuildSystemOther:
+		return []dependencies.Key{compiler}
+	// The versions of the cifuzz Maven extension and Gradle plugin
+	// determine the version of Jazzer, unless it's overridden in the
+	// build files
+	case config.BuildSystemMaven:
+		return []dependencies.Key{dependencies.Maven, dependencies.MavenExtension, dependencies.Java}
+	case config.BuildSystemGradle:
+		return []dependencies.Key{dependencies.Gradle, dependencies.GradlePlugin, dependencies.Java}
+	}
+	return nil
+}
+
+// sourceFiles returns the paths of all files in the project directory
+// which are not ignored by Git, relative to the project directory and
+// sorted. If the project directory is not part of a Git repository,
+// all files except for the ones in the build output directories at the
+// top level of the project directory are returned. Files in the
+// directories created by cifuzz are excluded in both cases.
+func sourceFiles(projectDir string) ([]string, error) {
+	files, err := vcs.GitListFiles(projectDir)
+	if err != nil {
+		log.Debugf("Not using Git to list the source files: %v", err)
+		files, err = allFiles(projectDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var sources []string
+	for _, file := range files {
+		dirs := strings.Split(filepath.ToSlash(filepath.Dir(file)), "/")
+		if slices.ContainsFunc(dirs, isIgnoredDir) {
+			continue
+		}
+		// Symlinks are skipped because Bazel creates symlinks to its
+		// output directories in the project directory
+		info, err := os.Lstat(filepath.Join(projectDir, file))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		sources = append(sources, file)
+	}
+	sort.Strings(sources)
+	return sources, nil
+}
+
+// allFiles returns the paths of all files in the project directory,
+// relative to the project directory
+func allFiles(projectDir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if d.IsDir() {
+			if path == projectDir {
+				return nil
+			}
+			if isIgnoredDir(d.Name()) ||
+				filepath.Dir(path) == projectDir && sliceutil.Contains(ignoredDirNames, d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relPath, err := filepath.Rel(projectDir, path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		files = append(files, relPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// isIgnoredDir returns true if the directory with the specified name
+// belongs to a version control system or was created by cifuzz, like
+// the build cache itself and the generated corpus directories
+func isIgnoredDir(name string) bool {
+	return sliceutil.Contains(vcsDirNames, name) ||
+		strings.HasPrefix(name, ".cifuzz") ||
+		strings.HasSuffix(name, "_cifuzz_corpus")
+}
+
+// buildCommandFiles returns the absolute paths of the existing files
+// which the build command refers to, e.g. "build.sh" in
+// "bash ./build.sh --fuzz" or "../common.mk" in "make -f ../common.mk".
+// Relative paths are resolved relative to the project directory, which
+// is the working directory of the build command.
+func buildCommandFiles(projectDir string, buildCommand string) []string {
+	var files []string
+	for _, arg := range strings.FieldsFunc(buildCommand, func(r rune) bool {
+		return strings.ContainsRune(buildCommandSeparators, r)
+	}) {
+		arg = strings.Trim(arg, `"'`)
+		// Handle arguments like "--file=build.mk" and "SCRIPT=build.sh"
+		if _, value, found := strings.Cut(arg, "="); found {
+			arg = value
+		}
+		if arg == "" {
+			continue
+		}
+		path := filepath.FromSlash(arg)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectDir, path)
+		}
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if !sliceutil.Contains(files, path) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// artifacts returns the paths of the files which are produced by the
+// build and needed to run the fuzz test
+func artifacts(result *build.BuildResult) []string {
+	var paths []string
+	if result.Executable != "" {
+		paths = append(paths, result.Executable)
+	}
+	return append(paths, result.RuntimeDeps...)
+}
+
+// copyArtifacts copies the executable and the runtime dependencies to
+// the specified directory, keeping the directory structure of files
+// below the build directory, and makes the build directory point to
+// the specified directory.
+func copyArtifacts(result *build.BuildResult, dir string) error {
+	newPath := func(path string) (string, error) {
+		isBelow, err := fileutil.IsBelow(path, result.BuildDir)
+		if err != nil {
+			return "", err
+		}
+		if !isBelow {
+			// Files outside of the build directory (e.g. system
+			// libraries) are not produced by the build
+			return path, nil
+		}
+		relPath, err := filepath.Rel(result.BuildDir, path)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		newPath := filepath.Join(dir, relPath)
+		// Copy the target of symlinks, so that the copy is valid even
+		// if the symlink is relative
+		resolvedPath, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		err = copy.Copy(resolvedPath, newPath)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		return newPath, nil
+	}
+
+	var err error
+	if result.Executable != "" {
+		result.Executable, err = newPath(result.Executable)
+		if err != nil {
+			return err
+		}
+	}
+	for i, dep := range result.RuntimeDeps {
+		result.RuntimeDeps[i], err = newPath(dep)
+		if err != nil {
+			return err
+		}
+	}
+	result.BuildDir = dir
+	return nil
+}
+
+func baseResult(result any) *build.BuildResult {
+	switch r := result.(type) {
+	case *build.BuildResult:
+		return r
+	case *build.CBuildResult:
+		return r.BuildResult
+	case *build.JavaBuildResult:
+		return r.BuildResult
+	}
+	panic(errors.Errorf("Unsupported build result type %T", result))
+}
+
+func modTime(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return info.ModTime().UnixNano(), nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer f.Close()
+	hash := sha256.New()
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func hashStrings(values []string) (string, error) {
+	hash := sha256.New()
+	for _, value := range values {
+		// Prepend the length of each value in order to differentiate
+		// between values like {"foo", "bar"} and {"foobar"}
+		err := binary.Write(hash, binary.BigEndian, uint32(len(value)))
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		_, err = hash.Write([]byte(value))
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
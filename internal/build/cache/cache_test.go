@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/dependencies"
+)
+
+func TestFingerprint(t *testing.T) {
+	dependencies.TestMockAllDeps(t)
+
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "src", "foo.cpp"), "int foo() { return 0; }")
+	key := &Key{ProjectDir: projectDir, BuildSystem: config.BuildSystemOther}
+
+	fingerprint, err := Fingerprint(key)
+	require.NoError(t, err)
+
+	// Files in the build output directories at the top level of the
+	// project directory and in directories created by cifuzz don't
+	// influence the fingerprint
+	writeFile(t, filepath.Join(projectDir, "build", "foo.o"), "foo")
+	writeFile(t, filepath.Join(projectDir, "node_modules", "foo", "index.js"), "foo")
+	writeFile(t, filepath.Join(projectDir, ".git", "HEAD"), "foo")
+	writeFile(t, filepath.Join(projectDir, ".cifuzz-build", "cache", "entry.json"), "foo")
+	writeFile(t, filepath.Join(projectDir, "src", ".foo_fuzz_test_cifuzz_corpus", "input"), "foo")
+	unchanged, err := Fingerprint(key)
+	require.NoError(t, err)
+	assert.Equal(t, fingerprint, unchanged)
+
+	// All other files influence the fingerprint, independent of their
+	// name and location
+	previous := fingerprint
+	for _, file := range []string{
+		filepath.Join("src", "foo.cpp"),
+		filepath.Join("src", "build", "generated.c"),
+		filepath.Join("scripts", "build.sh"),
+		".bazelrc",
+		"Cargo.toml",
+		filepath.Join("proto", "foo.proto"),
+		filepath.Join("src", "asm.S"),
+	} {
+		writeFile(t, filepath.Join(projectDir, file), "changed")
+		changed, err := Fingerprint(key)
+		require.NoError(t, err)
+		assert.NotEqual(t, previous, changed, file)
+		previous = changed
+	}
+
+	// Changing the flags changes the fingerprint
+	key.Flags = []string{"--sanitizers=address"}
+	changedFlags, err := Fingerprint(key)
+	require.NoError(t, err)
+	assert.NotEqual(t, previous, changedFlags)
+	previous = changedFlags
+
+	// Changing a file the build command refers to changes the
+	// fingerprint, even if it's outside of the project directory
+	buildScript := filepath.Join(t.TempDir(), "build.sh")
+	writeFile(t, buildScript, "make")
+	key.BuildCommand = "CC=clang bash '" + buildScript + "' --fuzz"
+	changedCommand, err := Fingerprint(key)
+	require.NoError(t, err)
+	assert.NotEqual(t, previous, changedCommand)
+	writeFile(t, buildScript, "make -j")
+	changedScript, err := Fingerprint(key)
+	require.NoError(t, err)
+	assert.NotEqual(t, changedCommand, changedScript)
+}
+
+func TestFingerprint_GitIgnore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	dependencies.TestMockAllDeps(t)
+
+	projectDir := t.TempDir()
+	out, err := exec.Command("git", "init", projectDir).CombinedOutput()
+	require.NoError(t, err, string(out))
+	writeFile(t, filepath.Join(projectDir, ".gitignore"), "/out/\n*.log\n")
+	writeFile(t, filepath.Join(projectDir, "src", "foo.cpp"), "int foo() { return 0; }")
+	key := &Key{ProjectDir: projectDir, BuildSystem: config.BuildSystemOther}
+
+	fingerprint, err := Fingerprint(key)
+	require.NoError(t, err)
+
+	// Files which are ignored by Git don't influence the fingerprint
+	writeFile(t, filepath.Join(projectDir, "out", "foo.o"), "foo")
+	writeFile(t, filepath.Join(projectDir, "src", "build.log"), "foo")
+	unchanged, err := Fingerprint(key)
+	require.NoError(t, err)
+	assert.Equal(t, fingerprint, unchanged)
+
+	// Untracked files which are not ignored do
+	writeFile(t, filepath.Join(projectDir, "src", "bar.cpp"), "int bar() { return 0; }")
+	changed, err := Fingerprint(key)
+	require.NoError(t, err)
+	assert.NotEqual(t, fingerprint, changed)
+
+	// In a Git repository, files in top-level directories like "build"
+	// are only excluded if Git ignores them
+	writeFile(t, filepath.Join(projectDir, "build", "config.h"), "#define FOO 1")
+	changedAgain, err := Fingerprint(key)
+	require.NoError(t, err)
+	assert.NotEqual(t, changed, changedAgain)
+}
+
+func TestLoadStore(t *testing.T) {
+	dependencies.TestMockAllDeps(t)
+
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "foo.cpp"), "int foo() { return 0; }")
+	buildDir := t.TempDir()
+	executable := filepath.Join(buildDir, "bin", "foo_fuzztest")
+	writeFile(t, executable, "foo")
+
+	key := &Key{
+		ProjectDir:    projectDir,
+		BuildSystem:   config.BuildSystemOther,
+		Flags:         []string{"address"},
+		CopyArtifacts: true,
+	}
+	c, err := New(key)
+	require.NoError(t, err)
+
+	_, found := Load[build.CBuildResult](c)
+	assert.False(t, found)
+
+	results := []*build.CBuildResult{{
+		Name: "foo_fuzztest",
+		BuildResult: &build.BuildResult{
+			Executable: executable,
+			BuildDir:   buildDir,
+		},
+	}}
+	err = Store(c, results)
+	require.NoError(t, err)
+
+	// The executable was copied to the cache
+	cachedExecutable := results[0].Executable
+	assert.NotEqual(t, executable, cachedExecutable)
+	assert.FileExists(t, cachedExecutable)
+	assert.Equal(t, filepath.Join("bin", "foo_fuzztest"), mustRel(t, results[0].BuildDir, cachedExecutable))
+
+	// The artifacts of the original build are not needed anymore
+	require.NoError(t, os.RemoveAll(buildDir))
+
+	c, err = New(key)
+	require.NoError(t, err)
+	cachedResults, found := Load[build.CBuildResult](c)
+	require.True(t, found)
+	assert.Equal(t, results, cachedResults)
+
+	// The cache is not used if the artifacts were changed
+	require.NoError(t, os.Chtimes(cachedExecutable, time.Unix(1, 0), time.Unix(1, 0)))
+	_, found = Load[build.CBuildResult](c)
+	assert.False(t, found)
+
+	// The cache is not used if the sources were changed
+	require.NoError(t, Store(c, cachedResults))
+	writeFile(t, filepath.Join(projectDir, "foo.cpp"), "int foo() { return 1; }")
+	c, err = New(key)
+	require.NoError(t, err)
+	_, found = Load[build.CBuildResult](c)
+	assert.False(t, found)
+
+	// A nil cache never contains any results
+	_, found = Load[build.CBuildResult](nil)
+	assert.False(t, found)
+	require.NoError(t, Store[build.CBuildResult](nil, results))
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func mustRel(t *testing.T, base string, path string) string {
+	rel, err := filepath.Rel(base, path)
+	require.NoError(t, err)
+	return rel
+}
@@ -0,0 +1,302 @@
+// Package cargo provides support for Rust fuzz targets which are
+// managed by cargo-fuzz, i.e. which are declared in fuzz/Cargo.toml and
+// use the libfuzzer-sys crate.
+package cargo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/ldd"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/util/stringutil"
+)
+
+// The sanitizers supported by `cargo fuzz build --sanitizer`
+var supportedSanitizers = []string{"address", "leak", "memory", "thread"}
+
+var hostTriplePattern = regexp.MustCompile(`(?m)^host:\s*(\S+)$`)
+
+// FuzzDir returns the directory of the fuzz crate which cargo-fuzz
+// creates in the project directory.
+func FuzzDir(projectDir string) string {
+	return filepath.Join(projectDir, "fuzz")
+}
+
+// SeedCorpus returns the corpus directory which cargo-fuzz uses for the
+// fuzz target by convention.
+func SeedCorpus(projectDir string, fuzzTarget string) string {
+	return filepath.Join(FuzzDir(projectDir), "corpus", fuzzTarget)
+}
+
+type manifest struct {
+	Bin []*manifestBin `toml:"bin"`
+}
+
+type manifestBin struct {
+	Name string `toml:"name"`
+	// The path of the source file relative to the fuzz crate
+	Path string `toml:"path"`
+}
+
+// ListFuzzTargets returns the names of the fuzz targets declared in the
+// fuzz/Cargo.toml of the project.
+func ListFuzzTargets(projectDir string) ([]string, error) {
+	bins, err := readManifest(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var fuzzTargets []string
+	for _, bin := range bins {
+		fuzzTargets = append(fuzzTargets, bin.Name)
+	}
+	sort.Strings(fuzzTargets)
+	return fuzzTargets, nil
+}
+
+// FuzzTargetForSourceFile returns the name of the fuzz target which is
+// built from the given source file.
+func FuzzTargetForSourceFile(projectDir string, sourceFile string) (string, error) {
+	bins, err := readManifest(projectDir)
+	if err != nil {
+		return "", err
+	}
+
+	if !filepath.IsAbs(sourceFile) {
+		sourceFile = filepath.Join(projectDir, sourceFile)
+	}
+	for _, bin := range bins {
+		if filepath.Join(FuzzDir(projectDir), filepath.FromSlash(bin.Path)) == filepath.Clean(sourceFile) {
+			return bin.Name, nil
+		}
+	}
+	return "", errors.New("no fuzz test found")
+}
+
+func readManifest(projectDir string) ([]*manifestBin, error) {
+	manifestPath := filepath.Join(FuzzDir(projectDir), "Cargo.toml")
+	f, err := os.Open(manifestPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, cmdutils.WrapIncorrectUsageError(errors.Errorf(
+				"%s not found. Please create the fuzz crate with `cargo fuzz init`", manifestPath))
+		}
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+
+	return parseManifest(f)
+}
+
+// parseManifest returns the binary targets of the given Cargo.toml,
+// which are the fuzz targets in case of a fuzz crate.
+func parseManifest(r io.Reader) ([]*manifestBin, error) {
+	var m manifest
+	err := toml.NewDecoder(r).Decode(&m)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse fuzz/Cargo.toml")
+	}
+
+	var bins []*manifestBin
+	for _, bin := range m.Bin {
+		if bin.Name == "" {
+			continue
+		}
+		if bin.Path == "" {
+			// The default path of binary targets, see
+			// https://doc.rust-lang.org/cargo/reference/cargo-targets.html#target-auto-discovery
+			bin.Path = "src/bin/" + bin.Name + ".rs"
+		}
+		bins = append(bins, bin)
+	}
+	return bins, nil
+}
+
+type BuilderOptions struct {
+	ProjectDir string
+	// Additional arguments passed to `cargo fuzz build`
+	Args       []string
+	Sanitizers []string
+
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+func (opts *BuilderOptions) Validate() error {
+	// Check that the project dir is set
+	if opts.ProjectDir == "" {
+		return errors.New("ProjectDir is not set")
+	}
+	// Check that the project dir exists and can be accessed
+	_, err := os.Stat(opts.ProjectDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if len(opts.Sanitizers) != 1 {
+		return errors.Errorf("cargo-fuzz supports exactly one sanitizer per build, got %q", opts.Sanitizers)
+	}
+	if !stringutil.Contains(supportedSanitizers, opts.Sanitizers[0]) {
+		return errors.Errorf("Invalid sanitizer for cargo-fuzz: %q", opts.Sanitizers[0])
+	}
+
+	return nil
+}
+
+type Builder struct {
+	*BuilderOptions
+	env []string
+}
+
+func NewBuilder(opts *BuilderOptions) (*Builder, error) {
+	err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Builder{BuilderOptions: opts}
+	b.env, err = build.CommonBuildEnv()
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Build builds the specified fuzz target via `cargo fuzz build`
+func (b *Builder) Build(fuzzTarget string) (*build.CBuildResult, error) {
+	fuzzTargets, err := ListFuzzTargets(b.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+	if !stringutil.Contains(fuzzTargets, fuzzTarget) {
+		return nil, cmdutils.WrapIncorrectUsageError(errors.Errorf(
+			"Fuzz target %q not found in fuzz/Cargo.toml, valid fuzz targets are: %s",
+			fuzzTarget, strings.Join(fuzzTargets, ", ")))
+	}
+
+	// We pass the target triple explicitly to know where cargo puts
+	// the executable
+	triple, err := hostTriple()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{
+		"fuzz", "build",
+		"--sanitizer", b.Sanitizers[0],
+		"--target", triple,
+		// Enable debug assertions and overflow checks, so that they
+		// are reported as findings
+		"--debug-assertions",
+	}
+	args = append(args, b.Args...)
+	args = append(args, fuzzTarget)
+
+	cmd := exec.Command("cargo", args...)
+	cmd.Dir = b.ProjectDir
+	cmd.Env = b.env
+	cmd.Stdout = b.Stdout
+	cmd.Stderr = b.Stderr
+	log.Debugf("Working directory: %s", cmd.Dir)
+	log.Debugf("Command: %s", cmd.String())
+	err = cmd.Run()
+	if err != nil {
+		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+
+	targetDir, err := targetDirectory(b.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+	executable := filepath.Join(targetDir, triple, b.profile(), fuzzTarget)
+	_, err = os.Stat(executable)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not find executable for fuzz target %q", fuzzTarget)
+	}
+
+	runtimeDeps, err := ldd.NonSystemSharedLibraries(executable)
+	if err != nil {
+		return nil, err
+	}
+
+	return &build.CBuildResult{
+		Name:       fuzzTarget,
+		ProjectDir: b.ProjectDir,
+		Sanitizers: b.Sanitizers,
+		BuildResult: &build.BuildResult{
+			Executable:      executable,
+			GeneratedCorpus: filepath.Join(b.ProjectDir, ".cifuzz-corpus", fuzzTarget),
+			SeedCorpus:      SeedCorpus(b.ProjectDir, fuzzTarget),
+			BuildDir:        targetDir,
+			RuntimeDeps:     runtimeDeps,
+		},
+	}, nil
+}
+
+// profile returns the name of the cargo profile directory the fuzz
+// target is built in. cargo-fuzz builds in release mode unless --dev is
+// passed.
+func (b *Builder) profile() string {
+	for _, arg := range b.Args {
+		if arg == "--dev" || arg == "-D" {
+			return "debug"
+		}
+	}
+	return "release"
+}
+
+func hostTriple() (string, error) {
+	cmd := exec.Command("rustc", "-vV")
+	log.Debugf("Command: %s", cmd.String())
+	out, err := cmd.Output()
+	if err != nil {
+		return "", cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+	matches := hostTriplePattern.FindSubmatch(out)
+	if matches == nil {
+		return "", errors.Errorf("Failed to determine host target triple from output of %q:\n%s", cmd.String(), out)
+	}
+	return string(matches[1]), nil
+}
+
+// targetDirectory returns the directory in which cargo stores the build
+// artifacts of the fuzz crate, which can be changed by the user via
+// the cargo config or environment variables.
+func targetDirectory(projectDir string) (string, error) {
+	cmd := exec.Command("cargo", "metadata", "--no-deps", "--format-version", "1",
+		"--manifest-path", filepath.Join(FuzzDir(projectDir), "Cargo.toml"))
+	cmd.Dir = projectDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	log.Debugf("Command: %s", cmd.String())
+	out, err := cmd.Output()
+	if err != nil {
+		log.Print(stderr.String())
+		return "", cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+
+	var metadata struct {
+		TargetDirectory string `json:"target_directory"`
+	}
+	err = json.Unmarshal(out, &metadata)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to parse output of 'cargo metadata'")
+	}
+	if metadata.TargetDirectory == "" {
+		return "", errors.Errorf("No target directory in output of %q", cmd.String())
+	}
+	return metadata.TargetDirectory, nil
+}
@@ -0,0 +1,50 @@
+package cargo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseManifest(t *testing.T) {
+	manifest := `
+[package]
+name = "project-fuzz"
+version = "0.0.0"
+publish = false
+edition = "2021"
+
+[package.metadata]
+cargo-fuzz = true
+
+[dependencies]
+libfuzzer-sys = "0.4"
+
+[dependencies.project]
+path = ".."
+
+[[bin]]
+name = "parse_input"
+path = "fuzz_targets/parse_input.rs"
+test = false
+doc = false
+
+[[bin]]
+name = "decode"
+test = false
+doc = false
+`
+	bins, err := parseManifest(strings.NewReader(manifest))
+	require.NoError(t, err)
+	assert.Equal(t, []*manifestBin{
+		{Name: "parse_input", Path: "fuzz_targets/parse_input.rs"},
+		{Name: "decode", Path: "src/bin/decode.rs"},
+	}, bins)
+}
+
+func TestParseManifest_Invalid(t *testing.T) {
+	_, err := parseManifest(strings.NewReader("[[bin]\nname = "))
+	require.Error(t, err)
+}
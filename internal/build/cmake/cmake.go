@@ -3,9 +3,7 @@ package cmake
 import (
 	"bufio"
 	"bytes"
-	"crypto/sha256"
-	"encoding/base32"
-	"encoding/binary"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -19,6 +17,7 @@ import (
 
 	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/internal/ldd"
 	"code-intelligence.com/cifuzz/pkg/log"
 	"code-intelligence.com/cifuzz/util/fileutil"
@@ -47,10 +46,14 @@ type BuilderOptions struct {
 	ProjectDir string
 	Args       []string
 	Sanitizers []string
+	Engine     config.Engine
 	Parallel   ParallelOptions
 	Stdout     io.Writer
 	Stderr     io.Writer
 	BuildOnly  bool
+	// The context of the build commands, which are killed when it's
+	// done. Defaults to context.Background().
+	Context context.Context
 
 	FindRuntimeDeps bool
 }
@@ -65,6 +68,13 @@ func (opts *BuilderOptions) Validate() error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	// Build for libFuzzer unless another engine was specified
+	if opts.Engine == "" {
+		opts.Engine = config.Libfuzzer
+	}
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
 	return nil
 }
 
@@ -95,6 +105,12 @@ func NewBuilder(opts *BuilderOptions) (*Builder, error) {
 	if err != nil {
 		return nil, err
 	}
+	if b.Engine == config.AFLPlusPlus {
+		b.env, err = build.AFLPlusPlusBuildEnv(b.env)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return b, nil
 }
@@ -109,36 +125,11 @@ func (b *Builder) BuildDir() (string, error) {
 	// for the cache variables below in the path to the build directory.
 	// Currently, this includes the fuzzing engine, the choice of sanitizers
 	// and optional user arguments
-	sanitizersSegment := strings.Join(b.Sanitizers, "+")
-	if sanitizersSegment == "" {
-		sanitizersSegment = "none"
-	}
-
-	buildDir := sanitizersSegment
-
-	if len(b.Args) > 0 {
-		// Add the hash of all user arguments to the build dir name in order to
-		// create different build directories for different combinations of arguments
-		hash := sha256.New()
-		for _, arg := range b.Args {
-			// Prepend the length of each argument in order to differentiate
-			// between arguments like {"foo", "bar"} and {"foobar"}
-			err := binary.Write(hash, binary.BigEndian, uint32(len(arg)))
-			if err != nil {
-				return "", errors.WithStack(err)
-			}
-			err = binary.Write(hash, binary.BigEndian, []byte(arg))
-			if err != nil {
-				return "", errors.WithStack(err)
-			}
-		}
-		// Use only the first 8 characters in order to prevent errors on
-		// Windows, which cannot handle long file paths.
-		hashString := base32.StdEncoding.EncodeToString(hash.Sum(nil))[:8]
-		buildDir = fmt.Sprintf("%s-%s", sanitizersSegment, hashString)
+	buildDir, err := build.BuildDirName(b.Sanitizers, b.Args)
+	if err != nil {
+		return "", err
 	}
-
-	buildDir = filepath.Join(b.ProjectDir, ".cifuzz-build", "libfuzzer", buildDir)
+	buildDir = filepath.Join(b.ProjectDir, ".cifuzz-build", string(b.Engine), buildDir)
 
 	return buildDir, nil
 }
@@ -159,9 +150,13 @@ func (b *Builder) Configure() error {
 	}
 
 	cacheArgs := []string{
-		"-DCIFUZZ_ENGINE=libfuzzer",
+		"-DCIFUZZ_ENGINE=" + string(b.Engine),
 		"-DCIFUZZ_SANITIZERS=" + strings.Join(b.Sanitizers, ";"),
 		"-DCIFUZZ_TESTING:BOOL=ON",
+		// Write a compilation database to the build directory, so that
+		// IDEs and tools like clang-tidy can use the same flags as the
+		// fuzzing build. This is ignored by the Visual Studio generators.
+		"-DCMAKE_EXPORT_COMPILE_COMMANDS:BOOL=ON",
 	}
 	if runtime.GOOS != "windows" {
 		// CMAKE_BUILD_TYPE is ignored when building with MSBuild.
@@ -187,7 +182,7 @@ func (b *Builder) Configure() error {
 	args = append(args, b.Args...)
 	args = append(args, b.ProjectDir)
 
-	cmd := exec.Command("cmake", args...)
+	cmd := exec.CommandContext(b.Context, "cmake", args...)
 	cmd.Stdout = b.Stdout
 	cmd.Stderr = b.Stderr
 	cmd.Env = b.env
@@ -221,7 +216,7 @@ func (b *Builder) Build(fuzzTests []string) ([]*build.CBuildResult, error) {
 		}
 	}
 
-	cmd := exec.Command("cmake", flags...)
+	cmd := exec.CommandContext(b.Context, "cmake", flags...)
 	cmd.Stdout = b.Stdout
 	cmd.Stderr = b.Stderr
 	cmd.Env = b.env
@@ -334,7 +329,7 @@ func (b *Builder) getRuntimeDeps(fuzzTest string) ([]string, error) {
 		return nil, err
 	}
 
-	cmd := exec.Command(
+	cmd := exec.CommandContext(b.Context,
 		"cmake",
 		"--install",
 		buildDir,
@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/util/fileutil"
 )
 
@@ -77,4 +78,19 @@ func TestNewBuilder(t *testing.T) {
 	// Check that builder1 and builder3 have the same build directory
 	// (because they use the same engine and sanitizers)
 	require.Equal(t, buildDir1, buildDir3)
+
+	// Create another builder for AFL++
+	builder4, err := NewBuilder(&BuilderOptions{
+		ProjectDir: projectDir,
+		Sanitizers: []string{"sanitizer1", "sanitizer2"},
+		Engine:     config.AFLPlusPlus,
+		Stdout:     os.Stderr,
+		Stderr:     os.Stderr,
+	})
+	require.NoError(t, err)
+	buildDir4, err := builder4.BuildDir()
+	require.NoError(t, err)
+	require.DirExists(t, buildDir4)
+	expectedBuildDir4 := filepath.Join(projectDir, ".cifuzz-build", "aflpp", "sanitizer1+sanitizer2")
+	require.Equal(t, expectedBuildDir4, buildDir4)
 }
@@ -0,0 +1,119 @@
+// Package compiledb writes compilation databases in the JSON format
+// specified by https://clang.llvm.org/docs/JSONCompilationDatabase.html,
+// which is understood by IDEs and tools like clangd and clang-tidy.
+package compiledb
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FileName is the name which tools expect the compilation database to
+// have
+const FileName = "compile_commands.json"
+
+// Files with these extensions are compiled as translation units
+var sourceExtensions = []string{".c", ".cc", ".cpp", ".cxx", ".c++", ".m", ".mm"}
+
+// Command is an entry of a compilation database
+type Command struct {
+	// The working directory of the compilation
+	Directory string `json:"directory"`
+	// The compile command, including the compiler executable
+	Arguments []string `json:"arguments"`
+	// The main translation unit source processed by this compilation
+	File string `json:"file"`
+	// The name of the output created by this compilation, if specified
+	Output string `json:"output,omitempty"`
+}
+
+// CommandsFromArgs returns an entry for each source file compiled by
+// the compiler invocation with the specified arguments (including the
+// compiler executable) in the specified directory. It returns nil if no
+// source files are compiled, e.g. for link commands.
+func CommandsFromArgs(dir string, args []string) []*Command {
+	var output string
+	var sources []string
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if arg == "-o" && i+1 < len(args) {
+			output = args[i+1]
+			i++
+			continue
+		}
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		ext := filepath.Ext(arg)
+		for _, sourceExt := range sourceExtensions {
+			if ext == sourceExt {
+				sources = append(sources, arg)
+				break
+			}
+		}
+	}
+
+	var commands []*Command
+	for _, source := range sources {
+		commands = append(commands, &Command{
+			Directory: dir,
+			Arguments: args,
+			File:      source,
+			Output:    output,
+		})
+	}
+	return commands
+}
+
+// Merge adds the commands to the compilation database at the specified
+// path, replacing existing entries for the same source files. This
+// allows keeping the database complete for incremental builds, which
+// only recompile the changed source files. The database is created if
+// it doesn't exist yet.
+func Merge(path string, commands []*Command) error {
+	var existing []*Command
+	bytes, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.WithStack(err)
+	}
+	if err == nil {
+		err = json.Unmarshal(bytes, &existing)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to parse compilation database %s", path)
+		}
+	}
+
+	replaced := make(map[string]bool)
+	for _, command := range commands {
+		replaced[key(command)] = true
+	}
+	var merged []*Command
+	for _, command := range existing {
+		if !replaced[key(command)] {
+			merged = append(merged, command)
+		}
+	}
+	merged = append(merged, commands...)
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	bytes, err = json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(path, bytes, 0o644))
+}
+
+func key(command *Command) string {
+	file := command.File
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(command.Directory, file)
+	}
+	return filepath.Clean(file) + "\x00" + command.Output
+}
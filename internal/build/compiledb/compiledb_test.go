@@ -0,0 +1,53 @@
+package compiledb
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandsFromArgs(t *testing.T) {
+	commands := CommandsFromArgs("/src", []string{"clang", "-fsanitize=fuzzer", "-I", "include", "-c", "foo.c", "-o", "foo.o"})
+	require.Len(t, commands, 1)
+	assert.Equal(t, &Command{
+		Directory: "/src",
+		Arguments: []string{"clang", "-fsanitize=fuzzer", "-I", "include", "-c", "foo.c", "-o", "foo.o"},
+		File:      "foo.c",
+		Output:    "foo.o",
+	}, commands[0])
+
+	// Link commands don't compile any sources
+	commands = CommandsFromArgs("/src", []string{"clang++", "foo.o", "bar.o", "-o", "my_fuzz_test"})
+	assert.Empty(t, commands)
+
+	// Commands which compile multiple sources
+	commands = CommandsFromArgs("/src", []string{"clang++", "foo.cpp", "bar.cc", "-o", "my_fuzz_test"})
+	require.Len(t, commands, 2)
+	assert.Equal(t, "foo.cpp", commands[0].File)
+	assert.Equal(t, "bar.cc", commands[1].File)
+}
+
+func TestMerge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	foo := &Command{Directory: "/src", Arguments: []string{"clang", "-c", "foo.c"}, File: "foo.c"}
+	bar := &Command{Directory: "/src", Arguments: []string{"clang", "-c", "bar.c"}, File: "bar.c"}
+	err := Merge(path, []*Command{foo, bar})
+	require.NoError(t, err)
+
+	// Entries for the same source file are replaced
+	newFoo := &Command{Directory: "/src", Arguments: []string{"clang", "-O2", "-c", "/src/foo.c"}, File: "/src/foo.c"}
+	err = Merge(path, []*Command{newFoo})
+	require.NoError(t, err)
+
+	bytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var commands []*Command
+	err = json.Unmarshal(bytes, &commands)
+	require.NoError(t, err)
+	assert.Equal(t, []*Command{bar, newFoo}, commands)
+}
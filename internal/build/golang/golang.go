@@ -0,0 +1,262 @@
+// Package golang provides support for native Go fuzz tests, which are
+// built and run via `go test -fuzz`.
+package golang
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/util/envutil"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+// The names of native Go fuzz tests must start with "Fuzz", see
+// https://go.dev/doc/security/fuzz/#requirements
+var fuzzTestNamePattern = regexp.MustCompile(`^Fuzz\w*$`)
+
+// FuzzTest is a native Go fuzz test, which is identified by the import
+// path of its package and its name, e.g. "example.com/project/parser:FuzzParse".
+type FuzzTest struct {
+	// The import path of the package containing the fuzz test
+	Package string
+	// The directory of the package containing the fuzz test
+	Dir string
+	// The name of the fuzz test function
+	Name string
+}
+
+func (t *FuzzTest) String() string {
+	return t.Package + ":" + t.Name
+}
+
+// SeedCorpus returns the directory from which `go test` reads the seed
+// inputs of the fuzz test and to which it writes failing inputs.
+func (t *FuzzTest) SeedCorpus() string {
+	return filepath.Join(t.Dir, "testdata", "fuzz", t.Name)
+}
+
+// GeneratedCorpus returns the directory in the Go build cache in which
+// `go test -fuzz` stores the inputs it generated for the fuzz test.
+func (t *FuzzTest) GeneratedCorpus() (string, error) {
+	cmd := exec.Command("go", "env", "GOCACHE")
+	log.Debugf("Command: %s", cmd.String())
+	out, err := cmd.Output()
+	if err != nil {
+		return "", cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+	goCache := strings.TrimSpace(string(out))
+	if goCache == "" || goCache == "off" {
+		return "", errors.New("The Go build cache is disabled, which is required for fuzzing")
+	}
+	return filepath.Join(goCache, "fuzz", t.Package, t.Name), nil
+}
+
+// ListFuzzTests lists the fuzz tests of the given packages (or of all
+// packages of the module if none are given). The packages are
+// specified in the format accepted by `go test`, e.g. "./...".
+func ListFuzzTests(projectDir string, packages ...string) ([]*FuzzTest, error) {
+	if len(packages) == 0 {
+		packages = []string{"./..."}
+	}
+
+	// The directories of the packages are needed to find the seed
+	// corpus of the fuzz tests
+	packageDirs, err := PackageDirs(projectDir, packages...)
+	if err != nil {
+		return nil, err
+	}
+
+	// `go test -list` prints the names of the tests matching the regex
+	// without running them. We use the JSON output to be able to map
+	// the tests to their packages.
+	args := append([]string{"test", "-json", "-list", "^Fuzz", "-run", "^$"}, packages...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = projectDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	log.Debugf("Command: %s", cmd.String())
+	out, err := cmd.Output()
+	if err != nil {
+		log.Print(stderr.String())
+		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+
+	fuzzTestsByPackage, err := parseListOutput(bytes.NewReader(out))
+	if err != nil {
+		return nil, err
+	}
+
+	var fuzzTests []*FuzzTest
+	for importPath, names := range fuzzTestsByPackage {
+		for _, name := range names {
+			fuzzTests = append(fuzzTests, &FuzzTest{
+				Package: importPath,
+				Dir:     packageDirs[importPath],
+				Name:    name,
+			})
+		}
+	}
+	sort.Slice(fuzzTests, func(i, j int) bool {
+		return fuzzTests[i].String() < fuzzTests[j].String()
+	})
+	return fuzzTests, nil
+}
+
+// PackageDirs returns the directories of the given packages (or of all
+// packages of the module if none are given) by import path.
+func PackageDirs(projectDir string, packages ...string) (map[string]string, error) {
+	if len(packages) == 0 {
+		packages = []string{"./..."}
+	}
+
+	args := append([]string{"list", "-f", "{{.ImportPath}}\t{{.Dir}}"}, packages...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = projectDir
+	log.Debugf("Command: %s", cmd.String())
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+
+	packageDirs := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		importPath, dir, found := strings.Cut(line, "\t")
+		if found {
+			packageDirs[importPath] = dir
+		}
+	}
+	return packageDirs, nil
+}
+
+type testEvent struct {
+	Action  string
+	Package string
+	Output  string
+}
+
+// parseListOutput parses the output of `go test -json -list` and
+// returns the names of the listed fuzz tests by package.
+func parseListOutput(r io.Reader) (map[string][]string, error) {
+	fuzzTests := map[string][]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var event testEvent
+		err := json.Unmarshal(scanner.Bytes(), &event)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse output of 'go test -list': %s", scanner.Text())
+		}
+		if event.Action != "output" {
+			continue
+		}
+		// Apart from the test names, the output contains the status
+		// line of the package, e.g. "ok  \texample.com/project\t0.003s"
+		name := strings.TrimSpace(event.Output)
+		if fuzzTestNamePattern.MatchString(name) {
+			fuzzTests[event.Package] = append(fuzzTests[event.Package], name)
+		}
+	}
+	return fuzzTests, errors.WithStack(scanner.Err())
+}
+
+// ResolveFuzzTest returns the fuzz test identified by the given
+// identifier, which is either of the form "<import path>:<name>",
+// "<package dir>:<name>" or just "<name>" if the name is unique in
+// the module.
+func ResolveFuzzTest(projectDir string, identifier string) (*FuzzTest, error) {
+	pkg, name, found := strings.Cut(identifier, ":")
+	if !found {
+		name = identifier
+		pkg = ""
+	}
+	if !fuzzTestNamePattern.MatchString(name) {
+		return nil, cmdutils.WrapIncorrectUsageError(errors.Errorf(
+			"Invalid fuzz test name %q: The names of Go fuzz tests must start with \"Fuzz\"", name))
+	}
+
+	var fuzzTests []*FuzzTest
+	var err error
+	if pkg == "" {
+		fuzzTests, err = ListFuzzTests(projectDir)
+	} else {
+		fuzzTests, err = ListFuzzTests(projectDir, pkg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*FuzzTest
+	for _, fuzzTest := range fuzzTests {
+		if fuzzTest.Name == name {
+			matches = append(matches, fuzzTest)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, cmdutils.WrapIncorrectUsageError(errors.Errorf("Fuzz test %q not found", identifier))
+	case 1:
+		return matches[0], nil
+	default:
+		var candidates []string
+		for _, match := range matches {
+			candidates = append(candidates, match.String())
+		}
+		msg := fmt.Sprintf("Fuzz test %q is ambiguous, please specify one of:\n  %s",
+			identifier, strings.Join(candidates, "\n  "))
+		return nil, cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+}
+
+// Build compiles the test binary of the package containing the fuzz
+// test to report build errors before fuzzing is started and returns
+// the corpus directories of the fuzz test.
+func Build(projectDir string, fuzzTest *FuzzTest, stdout, stderr io.Writer) (*build.BuildResult, error) {
+	tempDir, err := os.MkdirTemp("", "cifuzz-go-build-")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(tempDir)
+
+	// Set CIFUZZ=1 to allow build constraints or tests to figure out
+	// that they are run by cifuzz
+	env, err := envutil.Setenv(os.Environ(), "CIFUZZ", "1")
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("go", "test", "-c", "-o", filepath.Join(tempDir, "fuzz.test"), fuzzTest.Package)
+	cmd.Dir = projectDir
+	cmd.Env = env
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	log.Debugf("Working directory: %s", cmd.Dir)
+	log.Debugf("Command: %s", cmd.String())
+	err = cmd.Run()
+	if err != nil {
+		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+
+	generatedCorpus, err := fuzzTest.GeneratedCorpus()
+	if err != nil {
+		return nil, err
+	}
+	return &build.BuildResult{
+		SeedCorpus:      fuzzTest.SeedCorpus(),
+		GeneratedCorpus: generatedCorpus,
+		BuildDir:        projectDir,
+	}, nil
+}
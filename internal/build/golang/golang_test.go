@@ -0,0 +1,37 @@
+package golang
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListOutput(t *testing.T) {
+	output := `{"Action":"start","Package":"example.com/project"}
+{"Action":"output","Package":"example.com/project","Output":"?   \texample.com/project\t[no test files]\n"}
+{"Action":"skip","Package":"example.com/project","Elapsed":0}
+{"Action":"start","Package":"example.com/project/parser"}
+{"Action":"output","Package":"example.com/project/parser","Output":"FuzzParse\n"}
+{"Action":"output","Package":"example.com/project/parser","Output":"FuzzFail\n"}
+{"Action":"output","Package":"example.com/project/parser","Output":"ok  \texample.com/project/parser\t0.003s\n"}
+{"Action":"pass","Package":"example.com/project/parser","Elapsed":0.004}
+{"Action":"start","Package":"example.com/project/lexer"}
+{"Action":"output","Package":"example.com/project/lexer","Output":"FuzzParse\n"}
+{"Action":"output","Package":"example.com/project/lexer","Output":"ok  \texample.com/project/lexer\t0.002s\n"}
+{"Action":"pass","Package":"example.com/project/lexer","Elapsed":0.003}
+`
+	fuzzTests, err := parseListOutput(strings.NewReader(output))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"example.com/project/parser": {"FuzzParse", "FuzzFail"},
+		"example.com/project/lexer":  {"FuzzParse"},
+	}, fuzzTests)
+}
+
+func TestFuzzTest_SeedCorpus(t *testing.T) {
+	fuzzTest := &FuzzTest{Package: "example.com/project/parser", Dir: "/project/parser", Name: "FuzzParse"}
+	assert.Equal(t, "example.com/project/parser:FuzzParse", fuzzTest.String())
+	assert.Equal(t, "/project/parser/testdata/fuzz/FuzzParse", strings.ReplaceAll(fuzzTest.SeedCorpus(), "\\", "/"))
+}
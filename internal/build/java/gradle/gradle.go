@@ -5,8 +5,10 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -26,6 +28,11 @@ var (
 	mainSourceFoldersRegex = regexp.MustCompile("(?m)^cifuzz.main.source-folders=(?P<mainSourceFolders>.*)$")
 	jazzerVersionRegex     = regexp.MustCompile("(?m)^cifuzz.deps.jazzer-version=(?P<jazzerVersion>.*)$")
 	pluginVersionRegex     = regexp.MustCompile(`(?m)^cifuzz.plugin.version=(?P<version>\d+.\d+[.\d]*)`)
+
+	settingsCommentRegex = regexp.MustCompile(`(?m)^\s*//.*$`)
+	// Matches both `include("a", "b")` and `include 'a', 'b'`
+	includeRegex       = regexp.MustCompile(`(?m)\binclude\s*(?:\(([^)]*)\)|\s([^\n]*))`)
+	quotedStringsRegex = regexp.MustCompile(`["']([^"']+)["']`)
 )
 
 func FindGradleWrapper(projectDir string) (string, error) {
@@ -44,9 +51,13 @@ type ParallelOptions struct {
 
 type BuilderOptions struct {
 	ProjectDir string
-	Parallel   ParallelOptions
-	Stdout     io.Writer
-	Stderr     io.Writer
+	// The subproject to build, specified by the path of its directory
+	// relative to the project directory. The root project is built if
+	// it's empty.
+	Module   string
+	Parallel ParallelOptions
+	Stdout   io.Writer
+	Stderr   io.Writer
 }
 
 func (opts *BuilderOptions) Validate() error {
@@ -78,7 +89,7 @@ func NewBuilder(opts *BuilderOptions) (*Builder, error) {
 }
 
 func (b *Builder) Build() (*build.BuildResult, error) {
-	deps, err := GetDependencies(b.ProjectDir)
+	deps, err := GetDependencies(b.ProjectDir, b.Module)
 	if err != nil {
 		return nil, err
 	}
@@ -92,8 +103,10 @@ func (b *Builder) Build() (*build.BuildResult, error) {
 	return result, nil
 }
 
-func GetDependencies(projectDir string) ([]string, error) {
-	cmd, err := buildGradleCommand(projectDir, []string{"cifuzzPrintTestClasspath", "-q"})
+// GetDependencies returns the test class path of the specified
+// subproject, or of the root project if module is empty
+func GetDependencies(projectDir string, module string) ([]string, error) {
+	cmd, err := buildGradleCommand(projectDir, []string{taskPath(module, "cifuzzPrintTestClasspath"), "-q"})
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +116,9 @@ func GetDependencies(projectDir string) ([]string, error) {
 		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
 	}
 	classpath := classpathRegex.FindStringSubmatch(string(output))
+	if classpath == nil {
+		return nil, errors.New("Unable to parse gradle test classpath from init script.")
+	}
 	deps := strings.Split(strings.TrimSpace(classpath[1]), string(os.PathListSeparator))
 
 	// Add jacoco cli and java agent JAR paths
@@ -170,6 +186,8 @@ func GetRootDirectory(projectDir string) (string, error) {
 	return rootDir, nil
 }
 
+// GetTestSourceSets returns the test source folders of the project
+// and all of its subprojects
 func GetTestSourceSets(projectDir string) ([]string, error) {
 	cmd, err := buildGradleCommand(projectDir, []string{"cifuzzPrintTestSourceFolders", "-q"})
 	if err != nil {
@@ -181,11 +199,16 @@ func GetTestSourceSets(projectDir string) ([]string, error) {
 	if err != nil {
 		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
 	}
-	result := testSourceFoldersRegex.FindStringSubmatch(string(output))
-	if result == nil {
+	// The task is executed in every subproject which applies the
+	// plugin, each of which prints its own source folders
+	results := testSourceFoldersRegex.FindAllStringSubmatch(string(output), -1)
+	if results == nil {
 		return nil, errors.New("Unable to parse gradle test sources.")
 	}
-	paths := strings.Split(strings.TrimSpace(result[1]), string(os.PathListSeparator))
+	var paths []string
+	for _, result := range results {
+		paths = append(paths, strings.Split(strings.TrimSpace(result[1]), string(os.PathListSeparator))...)
+	}
 
 	// only return valid paths
 	var sourceSets []string
@@ -203,6 +226,8 @@ func GetTestSourceSets(projectDir string) ([]string, error) {
 	return sourceSets, nil
 }
 
+// GetMainSourceSets returns the main source folders of the project
+// and all of its subprojects
 func GetMainSourceSets(projectDir string) ([]string, error) {
 	cmd, err := buildGradleCommand(projectDir, []string{"cifuzzPrintMainSourceFolders", "-q"})
 	if err != nil {
@@ -214,11 +239,14 @@ func GetMainSourceSets(projectDir string) ([]string, error) {
 	if err != nil {
 		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
 	}
-	result := mainSourceFoldersRegex.FindStringSubmatch(string(output))
-	if result == nil {
+	results := mainSourceFoldersRegex.FindAllStringSubmatch(string(output), -1)
+	if results == nil {
 		return nil, errors.New("Unable to parse gradle main sources.")
 	}
-	paths := strings.Split(strings.TrimSpace(result[1]), string(os.PathListSeparator))
+	var paths []string
+	for _, result := range results {
+		paths = append(paths, strings.Split(strings.TrimSpace(result[1]), string(os.PathListSeparator))...)
+	}
 
 	// only return valid paths
 	var sourceSets []string
@@ -286,3 +314,51 @@ func GetPluginVersion(projectDir string) (string, error) {
 
 	return match["version"], nil
 }
+
+// GetSubprojects returns the directories of the subprojects included in
+// the settings.gradle(.kts) of the project, relative to the project
+// directory. Only the default project layout, in which the directory of
+// the subproject ":a:b" is "a/b", is supported.
+func GetSubprojects(projectDir string) ([]string, error) {
+	var settings []byte
+	for _, name := range []string{"settings.gradle.kts", "settings.gradle"} {
+		bs, err := os.ReadFile(filepath.Join(projectDir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		settings = bs
+		break
+	}
+	if settings == nil {
+		return nil, nil
+	}
+
+	content := settingsCommentRegex.ReplaceAllString(string(settings), "")
+	seen := make(map[string]bool)
+	var subprojects []string
+	for _, include := range includeRegex.FindAllStringSubmatch(content, -1) {
+		args := include[1] + include[2]
+		for _, match := range quotedStringsRegex.FindAllStringSubmatch(args, -1) {
+			dir := strings.ReplaceAll(strings.TrimPrefix(match[1], ":"), ":", "/")
+			if dir == "" || seen[dir] {
+				continue
+			}
+			seen[dir] = true
+			subprojects = append(subprojects, dir)
+		}
+	}
+	sort.Strings(subprojects)
+	return subprojects, nil
+}
+
+// taskPath returns the path of the task in the specified subproject,
+// which is the task of the root project if module is empty
+func taskPath(module string, task string) string {
+	if module == "" {
+		return task
+	}
+	return ":" + strings.ReplaceAll(module, "/", ":") + ":" + task
+}
@@ -0,0 +1,59 @@
+package gradle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSubprojects(t *testing.T) {
+	testCases := map[string]struct {
+		fileName string
+		settings string
+	}{
+		"groovy": {
+			fileName: "settings.gradle",
+			settings: `rootProject.name = 'example'
+include 'app', ':libs:parser'
+// include 'disabled'
+includeBuild 'build-logic'
+`,
+		},
+		"kotlin": {
+			fileName: "settings.gradle.kts",
+			settings: `rootProject.name = "example"
+include(
+    "app",
+    "libs:parser",
+)
+includeBuild("build-logic")
+`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			err := os.WriteFile(filepath.Join(projectDir, tc.fileName), []byte(tc.settings), 0o644)
+			require.NoError(t, err)
+
+			subprojects, err := GetSubprojects(projectDir)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"app", "libs/parser"}, subprojects)
+		})
+	}
+}
+
+func TestGetSubprojects_NoSettings(t *testing.T) {
+	subprojects, err := GetSubprojects(t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, subprojects)
+}
+
+func TestTaskPath(t *testing.T) {
+	assert.Equal(t, "cifuzzPrintTestClasspath", taskPath("", "cifuzzPrintTestClasspath"))
+	assert.Equal(t, ":libs:parser:cifuzzPrintTestClasspath", taskPath("libs/parser", "cifuzzPrintTestClasspath"))
+}
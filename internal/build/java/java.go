@@ -1,40 +1,43 @@
 package java
 
 import (
+	"io/fs"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
 
 	"code-intelligence.com/cifuzz/internal/build/java/gradle"
 	"code-intelligence.com/cifuzz/internal/build/java/maven"
 	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/java"
 )
 
+// SourceDirs returns the main source directories of the project and,
+// in multi-module projects, of all of its modules
 func SourceDirs(projectDir string, buildSystem string) ([]string, error) {
 	if buildSystem == config.BuildSystemGradle {
 		return gradle.GetMainSourceSets(projectDir)
 	} else if buildSystem == config.BuildSystemMaven {
-		sourceDir, err := maven.GetSourceDir(projectDir)
-		if err != nil {
-			return nil, err
-		}
-		if sourceDir != "" {
-			return []string{sourceDir}, nil
-		}
-		return nil, nil
+		return maven.GetSourceDirs(projectDir)
+	} else if buildSystem == config.BuildSystemBazel {
+		return bazelSourceRoots(projectDir)
 	}
 	return []string{filepath.Join(projectDir, "src", "main")}, nil
 }
 
+// TestDirs returns the test source directories of the project and, in
+// multi-module projects, of all of its modules
 func TestDirs(projectDir string, buildSystem string) ([]string, error) {
 	if buildSystem == config.BuildSystemGradle {
 		return gradle.GetTestSourceSets(projectDir)
 	} else if buildSystem == config.BuildSystemMaven {
-		testDir, err := maven.GetTestDir(projectDir)
-		if err != nil {
-			return nil, err
-		}
-		if testDir != "" {
-			return []string{testDir}, nil
-		}
+		return maven.GetTestDirs(projectDir)
+	} else if buildSystem == config.BuildSystemBazel {
+		// Bazel doesn't distinguish between source and test
+		// directories, so all of them are returned by SourceDirs
 		return nil, nil
 	}
 	return []string{filepath.Join(projectDir, "src", "test")}, nil
@@ -57,3 +60,59 @@ func CheckOverriddenJazzerVersion(projectDir string, buildSystem string) {
 		maven.GetOverriddenJazzerVersion(projectDir)
 	}
 }
+
+// bazelSourceRoots returns the directories in the bazel workspace which
+// contain Java or Kotlin source files in their package directories.
+// Bazel doesn't require a fixed directory layout like Maven and Gradle,
+// so we derive the source roots from the package declarations.
+func bazelSourceRoots(projectDir string) ([]string, error) {
+	roots := make(map[string]bool)
+	err := filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if d.IsDir() {
+			// Skip hidden directories like .git. The convenience
+			// symlinks created by bazel (bazel-bin etc.) are not
+			// followed by WalkDir.
+			if path != projectDir && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if ext != ".java" && ext != ".kt" {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer f.Close()
+		packageName := java.GetPackageFromSource(f)
+
+		dir := filepath.Dir(path)
+		packageDir := filepath.FromSlash(strings.ReplaceAll(packageName, ".", "/"))
+		if packageName != "" {
+			if !strings.HasSuffix(dir, string(filepath.Separator)+packageDir) {
+				// The file is not located in its package directory, so
+				// there is no source root we could use
+				return nil
+			}
+			dir = strings.TrimSuffix(dir, string(filepath.Separator)+packageDir)
+		}
+		roots[dir] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for root := range roots {
+		res = append(res, root)
+	}
+	sort.Strings(res)
+	return res, nil
+}
@@ -1,6 +1,8 @@
 package java
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -18,3 +20,29 @@ func Test_RootDirectory(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, projectDir, rootDir)
 }
+
+func TestSourceDirs_Bazel(t *testing.T) {
+	projectDir := t.TempDir()
+	files := map[string]string{
+		"src/main/java/com/example/Parser.java":  "package com.example;\n",
+		"fuzz/java/com/example/ParserFuzz.java":  "// Copyright\npackage com.example;\n",
+		"tools/Misplaced.java":                   "package com.example.tools;\n",
+		".cache/java/com/example/Generated.java": "package com.example;\n",
+	}
+	for path, content := range files {
+		path = filepath.Join(projectDir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	sourceDirs, err := SourceDirs(projectDir, config.BuildSystemBazel)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(projectDir, "fuzz", "java"),
+		filepath.Join(projectDir, "src", "main", "java"),
+	}, sourceDirs)
+
+	testDirs, err := TestDirs(projectDir, config.BuildSystemBazel)
+	require.NoError(t, err)
+	assert.Empty(t, testDirs)
+}
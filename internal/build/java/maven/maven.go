@@ -6,7 +6,9 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -33,9 +35,13 @@ type ParallelOptions struct {
 
 type BuilderOptions struct {
 	ProjectDir string
-	Parallel   ParallelOptions
-	Stdout     io.Writer
-	Stderr     io.Writer
+	// The module to build, specified by the path of its directory
+	// relative to the project directory. The whole project is built if
+	// it's empty.
+	Module   string
+	Parallel ParallelOptions
+	Stdout   io.Writer
+	Stderr   io.Writer
 }
 
 func (opts *BuilderOptions) Validate() error {
@@ -67,7 +73,7 @@ func NewBuilder(opts *BuilderOptions) (*Builder, error) {
 }
 
 func (b *Builder) Build() (*build.BuildResult, error) {
-	deps, err := GetDependencies(b.ProjectDir, b.Parallel)
+	deps, err := GetDependencies(b.ProjectDir, b.Module, b.Parallel)
 	if err != nil {
 		return nil, err
 	}
@@ -81,7 +87,9 @@ func (b *Builder) Build() (*build.BuildResult, error) {
 	return result, nil
 }
 
-func GetDependencies(projectDir string, parallel ParallelOptions) ([]string, error) {
+// GetDependencies builds the specified module, or the whole project if
+// module is empty, and returns its test class path
+func GetDependencies(projectDir string, module string, parallel ParallelOptions) ([]string, error) {
 	var flags []string
 	if parallel.Enabled {
 		flags = append(flags, "-T")
@@ -94,6 +102,11 @@ func GetDependencies(projectDir string, parallel ParallelOptions) ([]string, err
 	}
 
 	args := append(flags, "test-compile", "-DcifuzzPrintTestClasspath")
+	if module != "" {
+		// Also build the modules the module depends on, which are not
+		// necessarily installed in the local repository
+		args = append(args, "--projects", module, "--also-make")
+	}
 	cmd := runMaven(projectDir, args)
 	output, err := cmd.Output()
 	if err != nil {
@@ -101,7 +114,17 @@ func GetDependencies(projectDir string, parallel ParallelOptions) ([]string, err
 		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
 	}
 
-	classpath := classpathRegex.FindStringSubmatch(string(output))
+	classpaths := classpathRegex.FindAllStringSubmatch(string(output), -1)
+	if classpaths == nil {
+		return nil, errors.New("Unable to parse maven test classpath.")
+	}
+	classpath := classpaths[0]
+	if module != "" {
+		// The class path is printed for every module in the reactor.
+		// The requested module is built after the modules it depends
+		// on, so its class path is the last one.
+		classpath = classpaths[len(classpaths)-1]
+	}
 	deps := strings.Split(strings.TrimSpace(classpath[1]), string(os.PathListSeparator))
 
 	// Add jacoco cli and java agent JAR paths
@@ -147,61 +170,126 @@ func GetRootDirectory(projectDir string) (string, error) {
 }
 
 // GetTestDir returns the value of <testSourceDirectory> for the fuzz project
-// (which may be one of the sub-modules in a multi-project)
+// (which may be one of the sub-modules in a multi-project), without the
+// test source directories of its own modules
 func GetTestDir(projectDir string) (string, error) {
-	cmd := runMaven(projectDir, []string{"validate", "-q", "-DcifuzzPrintTestSourceFolders"})
-	output, err := cmd.Output()
+	testDirs, err := getSourceFolders(projectDir, "-DcifuzzPrintTestSourceFolders", testSourceFoldersRegex, "--non-recursive")
 	if err != nil {
-		log.Debugf("%s\n", string(output))
-		return "", cmdutils.WrapExecError(errors.WithStack(err), cmd)
+		return "", err
 	}
-
-	result := testSourceFoldersRegex.FindStringSubmatch(string(output))
-	if result == nil {
-		return "", errors.New("Unable to parse maven test sources.")
+	if len(testDirs) == 0 {
+		return "", nil
 	}
-	testDir := strings.TrimSpace(result[1])
-	log.Debugf("Found Maven test source at: %s", testDir)
+	return testDirs[0], nil
+}
 
-	exists, err := fileutil.Exists(testDir)
+// GetTestDirs returns the values of <testSourceDirectory> for the fuzz
+// project and all of its modules
+func GetTestDirs(projectDir string) ([]string, error) {
+	return getSourceFolders(projectDir, "-DcifuzzPrintTestSourceFolders", testSourceFoldersRegex)
+}
+
+// GetSourceDir returns the value of <sourceDirectory> for the fuzz project
+// (which may be one of the sub-modules in a multi-project), without the
+// source directories of its own modules
+func GetSourceDir(projectDir string) (string, error) {
+	sourceDirs, err := getSourceFolders(projectDir, "-DcifuzzPrintMainSourceFolders", mainSourceFoldersRegex, "--non-recursive")
 	if err != nil {
 		return "", err
 	}
-	if exists {
-		return testDir, nil
+	if len(sourceDirs) == 0 {
+		return "", nil
 	}
-	log.Debugf("Ignoring Maven test source directory %s: directory does not exist", testDir)
+	return sourceDirs[0], nil
+}
 
-	return "", nil
+// GetSourceDirs returns the values of <sourceDirectory> for the fuzz
+// project and all of its modules
+func GetSourceDirs(projectDir string) ([]string, error) {
+	return getSourceFolders(projectDir, "-DcifuzzPrintMainSourceFolders", mainSourceFoldersRegex)
 }
 
-// GetSourceDir returns the value of <sourceDirectory> for the fuzz project
-// (which may be one of the sub-modules in a multi-project)
-func GetSourceDir(projectDir string) (string, error) {
-	cmd := runMaven(projectDir, []string{"validate", "-q", "-DcifuzzPrintMainSourceFolders"})
+// getSourceFolders returns the existing source folders printed by the
+// maven extension for each module in the reactor
+func getSourceFolders(projectDir string, property string, regex *regexp.Regexp, flags ...string) ([]string, error) {
+	args := append([]string{"validate", "-q", property}, flags...)
+	cmd := runMaven(projectDir, args)
 	output, err := cmd.Output()
 	if err != nil {
 		log.Debugf("%s\n", string(output))
-		return "", errors.WithMessagef(err, "Failed to get source directory of project")
+		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
 	}
 
-	result := mainSourceFoldersRegex.FindStringSubmatch(string(output))
-	if result == nil {
-		return "", errors.New("Unable to parse maven main sources.")
+	results := regex.FindAllStringSubmatch(string(output), -1)
+	if results == nil {
+		return nil, errors.New("Unable to parse maven source folders.")
 	}
-	sourceDir := strings.TrimSpace(result[1])
-	log.Debugf("Found Maven source at: %s", sourceDir)
 
-	exists, err := fileutil.Exists(sourceDir)
+	var dirs []string
+	for _, result := range results {
+		dir := strings.TrimSpace(result[1])
+		log.Debugf("Found Maven source folder at: %s", dir)
+
+		exists, err := fileutil.Exists(dir)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "Error checking if Maven source folder %s exists", dir)
+		}
+		if !exists {
+			log.Debugf("Ignoring Maven source folder %s: directory does not exist", dir)
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs, nil
+}
+
+// GetModules returns the directories of the modules declared in the
+// pom.xml of the project and, recursively, in the pom.xml files of
+// those modules, relative to the project directory
+func GetModules(projectDir string) ([]string, error) {
+	var modules []string
+	err := collectModules(projectDir, "", &modules)
 	if err != nil {
-		return "", errors.WithMessagef(err, "Error checking if Maven source directory %s exists", sourceDir)
+		return nil, err
 	}
-	if exists {
-		return sourceDir, nil
+	sort.Strings(modules)
+	return modules, nil
+}
+
+func collectModules(projectDir string, relDir string, modules *[]string) error {
+	pomPath := filepath.Join(projectDir, filepath.FromSlash(relDir), "pom.xml")
+	f, err := os.Open(pomPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
 	}
-	log.Debugf("Ignoring Maven source directory %s: directory does not exist", sourceDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	project, err := parseXML(f)
+	if err != nil {
+		return errors.WithMessagef(err, "Failed to parse %s", pomPath)
+	}
+
+	for _, module := range project.Modules.Module {
+		// A module can also be specified by the path of its pom.xml
+		module = filepath.ToSlash(strings.TrimSpace(module))
+		if strings.HasSuffix(module, ".xml") {
+			module = filepath.ToSlash(filepath.Dir(module))
+		}
+		moduleDir := filepath.ToSlash(filepath.Join(relDir, module))
+		if moduleDir == "." || strings.HasPrefix(moduleDir, "../") {
+			continue
+		}
 
-	return "", nil
+		*modules = append(*modules, moduleDir)
+		err = collectModules(projectDir, moduleDir, modules)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func GetOverriddenJazzerVersion(projectDir string) string {
@@ -2,6 +2,7 @@ package maven
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -51,3 +52,35 @@ func Test_GetSourceDir(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, filepath.Join(projectDir, newSourceDir), sourceDir)
 }
+
+func Test_GetModules(t *testing.T) {
+	projectDir := t.TempDir()
+	poms := map[string]string{
+		"pom.xml": `<project>
+	<modules>
+		<module>app</module>
+		<module>libs</module>
+	</modules>
+</project>`,
+		"app/pom.xml": `<project></project>`,
+		"libs/pom.xml": `<project>
+	<modules>
+		<module>parser/pom.xml</module>
+	</modules>
+</project>`,
+		"libs/parser/pom.xml": `<project></project>`,
+	}
+	for path, content := range poms {
+		path = filepath.Join(projectDir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	modules, err := GetModules(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "libs", "libs/parser"}, modules)
+
+	modules, err = GetModules(filepath.Join(projectDir, "app"))
+	require.NoError(t, err)
+	assert.Empty(t, modules)
+}
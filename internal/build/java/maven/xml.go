@@ -19,7 +19,10 @@ type Project struct {
 	Version     string   `xml:"version"`
 	Name        string   `xml:"name"`
 	Description string   `xml:"description"`
-	Properties  struct {
+	Modules     struct {
+		Module []string `xml:"module"`
+	} `xml:"modules"`
+	Properties struct {
 		Text                string `xml:",chardata"`
 		MavenCompilerTarget string `xml:"maven.compiler.target"`
 		MavenCompilerSource string `xml:"maven.compiler.source"`
@@ -0,0 +1,219 @@
+package java
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/build/java/gradle"
+	"code-intelligence.com/cifuzz/internal/build/java/maven"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+// Module is a Maven module or a Gradle subproject of a multi-module
+// project
+type Module struct {
+	// The path of the module directory relative to the root project
+	// directory, using forward slashes. It's the prefix of the fuzz
+	// tests of the module.
+	RelDir string
+	// The absolute path of the module directory
+	Dir string
+}
+
+// Modules returns the Maven modules or Gradle subprojects of the
+// project. Returns nil if the project doesn't have any.
+func Modules(projectDir string, buildSystem string) ([]*Module, error) {
+	var relDirs []string
+	var err error
+	switch buildSystem {
+	case config.BuildSystemMaven:
+		relDirs, err = maven.GetModules(projectDir)
+	case config.BuildSystemGradle:
+		relDirs, err = gradle.GetSubprojects(projectDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	projectDir, err = filepath.Abs(projectDir)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var modules []*Module
+	for _, relDir := range relDirs {
+		modules = append(modules, &Module{
+			RelDir: relDir,
+			Dir:    filepath.Join(projectDir, filepath.FromSlash(relDir)),
+		})
+	}
+	return modules, nil
+}
+
+// ClassFilesDir returns the directory into which the build system
+// compiles the classes of the project or module in the specified
+// directory
+func ClassFilesDir(dir string, buildSystem string) string {
+	if buildSystem == config.BuildSystemGradle {
+		return filepath.Join(dir, "build", "classes")
+	}
+	return filepath.Join(dir, "target", "classes")
+}
+
+// ClassFilesDirs returns the existing class files directories of the
+// project and all of its modules
+func ClassFilesDirs(projectDir string, buildSystem string) ([]string, error) {
+	modules, err := Modules(projectDir, buildSystem)
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{projectDir}
+	for _, module := range modules {
+		dirs = append(dirs, module.Dir)
+	}
+
+	var classFilesDirs []string
+	for _, dir := range dirs {
+		classFilesDir := ClassFilesDir(dir, buildSystem)
+		exists, err := fileutil.Exists(classFilesDir)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			classFilesDirs = append(classFilesDirs, classFilesDir)
+		}
+	}
+	return classFilesDirs, nil
+}
+
+// ModuleForDir returns the module which contains the specified absolute
+// path, or nil if it belongs to the root project
+func ModuleForDir(modules []*Module, path string) (*Module, error) {
+	var result *Module
+	for _, module := range modules {
+		isBelow, err := fileutil.IsBelow(path, module.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if !isBelow {
+			continue
+		}
+		// Modules can be nested, in which case the path belongs to
+		// the innermost module
+		if result == nil || len(module.Dir) > len(result.Dir) {
+			result = module
+		}
+	}
+	return result, nil
+}
+
+// ResolveFuzzTest returns the fuzz test qualified with the module which
+// contains it, i.e. "<module>/<class>[::<method>]". If the given fuzz
+// test is not qualified yet, the module is determined by searching the
+// test source directories of all modules for the source file of the
+// class. Fuzz tests of the root project are returned unchanged.
+func ResolveFuzzTest(projectDir string, buildSystem string, fuzzTest string) (string, error) {
+	modules, err := Modules(projectDir, buildSystem)
+	if err != nil {
+		return "", err
+	}
+
+	moduleDir, class := cmdutils.SeparateModuleAndTargetClass(fuzzTest)
+	if moduleDir != "" {
+		for _, module := range modules {
+			if module.RelDir == moduleDir {
+				return fuzzTest, nil
+			}
+		}
+		return "", cmdutils.WrapIncorrectUsageError(errors.Errorf(
+			"Module '%s' of fuzz test '%s' could not be found in the project directory '%s'",
+			moduleDir, fuzzTest, projectDir,
+		))
+	}
+	if len(modules) == 0 {
+		return fuzzTest, nil
+	}
+
+	testDirs, err := TestDirs(projectDir, buildSystem)
+	if err != nil {
+		return "", err
+	}
+	module, err := moduleOfClass(modules, testDirs, class)
+	if err != nil {
+		return "", err
+	}
+	if module == nil {
+		return fuzzTest, nil
+	}
+	return module.RelDir + "/" + fuzzTest, nil
+}
+
+// moduleOfClass returns the module with the test source directory which
+// contains the source file of the class, or nil if it's not contained
+// in the test source directory of any module
+func moduleOfClass(modules []*Module, testDirs []string, class string) (*Module, error) {
+	className, _ := cmdutils.SeparateTargetClassAndMethod(class)
+	classPath := filepath.FromSlash(strings.ReplaceAll(className, ".", "/"))
+
+	var result *Module
+	for _, testDir := range testDirs {
+		found := false
+		for _, ext := range []string{".java", ".kt"} {
+			exists, err := fileutil.Exists(filepath.Join(testDir, classPath+ext))
+			if err != nil {
+				return nil, err
+			}
+			found = found || exists
+		}
+		if !found {
+			continue
+		}
+
+		module, err := ModuleForDir(modules, testDir)
+		if err != nil {
+			return nil, err
+		}
+		if module == nil {
+			continue
+		}
+		if result != nil && result != module {
+			return nil, cmdutils.WrapIncorrectUsageError(errors.Errorf(
+				"The class '%s' exists in the modules '%s' and '%s', please specify the fuzz test as '<module>/%s'",
+				className, result.RelDir, module.RelDir, class,
+			))
+		}
+		result = module
+	}
+	return result, nil
+}
+
+// ListFuzzTestsByRegex returns the fuzz tests in the default test
+// source directories (src/test) of the project and of all of its
+// modules. The fuzz tests of modules are qualified with the module.
+func ListFuzzTestsByRegex(projectDir string, buildSystem string, prefixFilter string) ([]string, error) {
+	modules, err := Modules(projectDir, buildSystem)
+	if err != nil {
+		return nil, err
+	}
+
+	fuzzTests, err := cmdutils.ListJVMFuzzTestsByRegex([]string{filepath.Join(projectDir, "src", "test")}, prefixFilter)
+	if err != nil {
+		return nil, err
+	}
+	for _, module := range modules {
+		moduleFuzzTests, err := cmdutils.ListJVMFuzzTestsByRegex([]string{filepath.Join(module.Dir, "src", "test")}, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, fuzzTest := range moduleFuzzTests {
+			fuzzTest = module.RelDir + "/" + fuzzTest
+			if strings.HasPrefix(fuzzTest, prefixFilter) {
+				fuzzTests = append(fuzzTests, fuzzTest)
+			}
+		}
+	}
+	return fuzzTests, nil
+}
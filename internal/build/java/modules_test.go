@@ -0,0 +1,100 @@
+package java
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/config"
+)
+
+func TestModules(t *testing.T) {
+	projectDir := t.TempDir()
+	files := map[string]string{
+		"settings.gradle": "include 'app', 'libs:parser'\n",
+		"app/src/test/java/com/example/AppFuzzTest.java": `package com.example;
+class AppFuzzTest {
+	@FuzzTest
+	void fuzz(byte[] data) {}
+}`,
+		"libs/parser/src/test/kotlin/com/example/ParserFuzzTest.kt": `package com.example
+class ParserFuzzTest {
+	@FuzzTest
+	fun fuzz(data: ByteArray) {}
+}`,
+		"src/test/java/com/example/RootFuzzTest.java": `package com.example;
+class RootFuzzTest {
+	@FuzzTest
+	void fuzz(byte[] data) {}
+}`,
+	}
+	for path, content := range files {
+		path = filepath.Join(projectDir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	modules, err := Modules(projectDir, config.BuildSystemGradle)
+	require.NoError(t, err)
+	require.Len(t, modules, 2)
+	assert.Equal(t, "app", modules[0].RelDir)
+	assert.Equal(t, filepath.Join(projectDir, "libs", "parser"), modules[1].Dir)
+
+	module, err := ModuleForDir(modules, filepath.Join(projectDir, "libs", "parser", "src", "test", "kotlin"))
+	require.NoError(t, err)
+	assert.Equal(t, modules[1], module)
+	module, err = ModuleForDir(modules, filepath.Join(projectDir, "src", "test", "java"))
+	require.NoError(t, err)
+	assert.Nil(t, module)
+
+	testDirs := []string{
+		filepath.Join(projectDir, "app", "src", "test", "java"),
+		filepath.Join(projectDir, "libs", "parser", "src", "test", "kotlin"),
+		filepath.Join(projectDir, "src", "test", "java"),
+	}
+	module, err = moduleOfClass(modules, testDirs, "com.example.ParserFuzzTest::fuzz")
+	require.NoError(t, err)
+	assert.Equal(t, modules[1], module)
+	module, err = moduleOfClass(modules, testDirs, "com.example.RootFuzzTest")
+	require.NoError(t, err)
+	assert.Nil(t, module)
+
+	// Qualified fuzz tests are only checked for an existing module
+	fuzzTest, err := ResolveFuzzTest(projectDir, config.BuildSystemGradle, "libs/parser/com.example.ParserFuzzTest")
+	require.NoError(t, err)
+	assert.Equal(t, "libs/parser/com.example.ParserFuzzTest", fuzzTest)
+	_, err = ResolveFuzzTest(projectDir, config.BuildSystemGradle, "libs/lexer/com.example.LexerFuzzTest")
+	require.Error(t, err)
+
+	fuzzTests, err := ListFuzzTestsByRegex(projectDir, config.BuildSystemGradle, "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"com.example.RootFuzzTest::fuzz",
+		"app/com.example.AppFuzzTest::fuzz",
+		"libs/parser/com.example.ParserFuzzTest::fuzz",
+	}, fuzzTests)
+
+	fuzzTests, err = ListFuzzTestsByRegex(projectDir, config.BuildSystemGradle, "libs/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"libs/parser/com.example.ParserFuzzTest::fuzz"}, fuzzTests)
+}
+
+func TestClassFilesDirs(t *testing.T) {
+	projectDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "settings.gradle"), []byte("include 'app', 'libs:parser', 'docs'\n"), 0o644))
+	for _, dir := range []string{"app/build/classes", "libs/parser/build/classes", "docs"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(projectDir, filepath.FromSlash(dir)), 0o755))
+	}
+
+	// Only the existing class files directories of the root project
+	// and its modules are returned
+	classFilesDirs, err := ClassFilesDirs(projectDir, config.BuildSystemGradle)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(projectDir, "app", "build", "classes"),
+		filepath.Join(projectDir, "libs", "parser", "build", "classes"),
+	}, classFilesDirs)
+}
@@ -0,0 +1,623 @@
+package meson
+
+import (
+	"context"
+	"debug/elf"
+	"debug/macho"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/build/other"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/ldd"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/runfiles"
+	"code-intelligence.com/cifuzz/util/envutil"
+	"code-intelligence.com/cifuzz/util/fileutil"
+	"code-intelligence.com/cifuzz/util/sliceutil"
+)
+
+// The Meson build type to use for fuzzing runs, which corresponds to
+// the RelWithDebInfo configuration we use for CMake projects
+const mesonBuildType = "debugoptimized"
+
+// Warning: Changing these will lead to a breaking change!
+const (
+	// FuzzTestArgsOption is the name of the project option which holds
+	// the compiler arguments of the fuzz tests. It must be declared in
+	// the meson_options.txt of the project and passed to the c_args and
+	// cpp_args of the fuzz test executables.
+	FuzzTestArgsOption = "cifuzz_fuzz_test_args"
+
+	// FuzzTestLinkArgsOption is the name of the project option which
+	// holds the linker arguments of the fuzz tests. It must be declared
+	// in the meson_options.txt of the project and passed to the
+	// link_args of the fuzz test executables.
+	FuzzTestLinkArgsOption = "cifuzz_fuzz_test_link_args"
+)
+
+// The files in which Meson projects declare their options. Meson 1.1
+// added meson.options as the preferred name.
+var optionsFiles = []string{"meson.options", "meson_options.txt"}
+
+type ParallelOptions struct {
+	Enabled bool
+	NumJobs uint
+}
+
+type BuilderOptions struct {
+	ProjectDir string
+	Args       []string
+	Sanitizers []string
+	Parallel   ParallelOptions
+	Stdout     io.Writer
+	Stderr     io.Writer
+	BuildOnly  bool
+	// The context of the build commands, which are killed when it's
+	// done. Defaults to context.Background().
+	Context context.Context
+
+	FindRuntimeDeps bool
+
+	RunfilesFinder runfiles.RunfilesFinder
+}
+
+func (opts *BuilderOptions) Validate() error {
+	// Check that the project dir is set
+	if opts.ProjectDir == "" {
+		return errors.New("ProjectDir is not set")
+	}
+	// Check that the project dir exists and can be accessed
+	_, err := os.Stat(opts.ProjectDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if opts.RunfilesFinder == nil {
+		opts.RunfilesFinder = runfiles.Finder
+	}
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
+
+	return nil
+}
+
+type Builder struct {
+	*BuilderOptions
+	env []string
+}
+
+// A target as listed by "meson introspect --targets"
+type target struct {
+	Name          string          `json:"name"`
+	Type          string          `json:"type"`
+	DefinedIn     string          `json:"defined_in"`
+	Filename      []string        `json:"filename"`
+	TargetSources []*targetSource `json:"target_sources"`
+}
+
+type targetSource struct {
+	Parameters []string `json:"parameters"`
+	Sources    []string `json:"sources"`
+}
+
+func NewBuilder(opts *BuilderOptions) (*Builder, error) {
+	err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Builder{BuilderOptions: opts}
+
+	// Ensure that the build directory exists.
+	buildDir, err := b.BuildDir()
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(buildDir, 0755)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	b.env, err = build.CommonBuildEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	// The flags are the same as the ones the build system type "other"
+	// passes to the build command, we pass them to Meson via options
+	// in the configure step.
+	if len(opts.Sanitizers) == 1 && opts.Sanitizers[0] == "coverage" {
+		b.env, err = other.SetCoverageEnv(b.env, b.RunfilesFinder)
+	} else if build.IsSanitizerVariant(opts.Sanitizers) {
+		b.env, err = other.SetLibFuzzerSanitizerVariantEnv(b.env, b.RunfilesFinder, opts.Sanitizers[0])
+	} else if len(opts.Sanitizers) == 0 {
+		b.env, err = other.SetLibFuzzerNoSanitizerEnv(b.env, b.RunfilesFinder)
+	} else {
+		for _, sanitizer := range opts.Sanitizers {
+			if sanitizer != "address" && sanitizer != "undefined" {
+				panic(fmt.Sprintf("Invalid sanitizer: %q", sanitizer))
+			}
+		}
+		b.env, err = other.SetLibFuzzerEnv(b.env, b.RunfilesFinder)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (b *Builder) Opts() *BuilderOptions {
+	return b.BuilderOptions
+}
+
+func (b *Builder) BuildDir() (string, error) {
+	// Like for CMake, we use a separate build directory for each
+	// combination of sanitizers and user arguments, so that switching
+	// between them doesn't require a full rebuild.
+	buildDir, err := build.BuildDirName(b.Sanitizers, b.Args)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(b.ProjectDir, ".cifuzz-build", "meson", buildDir), nil
+}
+
+// Configure calls "meson setup" to configure the build directory with
+// the compiler and linker flags needed for fuzzing. If the build
+// directory was configured before, it's reconfigured, so that changes
+// to the flags or the user arguments take effect.
+func (b *Builder) Configure() error {
+	buildDir, err := b.BuildDir()
+	if err != nil {
+		return err
+	}
+
+	err = b.checkFuzzTestOptionsDeclared()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"setup"}
+	configured, err := fileutil.Exists(filepath.Join(buildDir, "meson-private", "coredata.dat"))
+	if err != nil {
+		return err
+	}
+	if configured {
+		args = append(args, "--reconfigure")
+	}
+	args = append(args,
+		"--buildtype="+mesonBuildType,
+		// Shared libraries built with sanitizers reference symbols of
+		// the sanitizer runtime, which is only linked into the
+		// executables, so we must allow undefined symbols.
+		"-Db_lundef=false",
+	)
+	options := []struct {
+		name   string
+		envVar string
+	}{
+		{"c_args", "CFLAGS"},
+		{"cpp_args", "CXXFLAGS"},
+		{"c_link_args", "LDFLAGS"},
+		{"cpp_link_args", "LDFLAGS"},
+		{FuzzTestArgsOption, other.EnvFuzzTestCXXFlags},
+		{FuzzTestLinkArgsOption, other.EnvFuzzTestLDFlags},
+	}
+	for _, option := range options {
+		value, err := arrayOption(strings.Fields(envutil.Getenv(b.env, option.envVar)))
+		if err != nil {
+			return err
+		}
+		args = append(args, fmt.Sprintf("-D%s=%s", option.name, value))
+	}
+	args = append(args, b.Args...)
+	args = append(args, buildDir, b.ProjectDir)
+
+	cmd := exec.CommandContext(b.Context, "meson", args...)
+	cmd.Stdout = b.Stdout
+	cmd.Stderr = b.Stderr
+	cmd.Env = b.env
+	cmd.Dir = b.ProjectDir
+	log.Debugf("Working directory: %s", cmd.Dir)
+	log.Debugf("Command: %s", cmd.String())
+	err = cmd.Run()
+	if err != nil {
+		return cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+	return nil
+}
+
+// Build builds the specified fuzz tests with Meson. The fuzz tests must
+// not contain duplicates.
+func (b *Builder) Build(fuzzTests []string) ([]*build.CBuildResult, error) {
+	buildDir, err := b.BuildDir()
+	if err != nil {
+		return nil, err
+	}
+
+	fuzzTestTargets, err := b.fuzzTestTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []*target
+	args := []string{"compile", "-C", buildDir}
+	if b.Parallel.Enabled && b.Parallel.NumJobs != 0 {
+		args = append(args, "-j", fmt.Sprint(b.Parallel.NumJobs))
+	}
+	for _, fuzzTest := range fuzzTests {
+		t, err := findTarget(fuzzTestTargets, fuzzTest)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+
+		targetPath, err := b.targetPath(t)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, targetPath)
+	}
+
+	cmd := exec.CommandContext(b.Context, "meson", args...)
+	cmd.Stdout = b.Stdout
+	cmd.Stderr = b.Stderr
+	cmd.Env = b.env
+	log.Debugf("Command: %s", cmd.String())
+	err = cmd.Run()
+	if err != nil {
+		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+
+	if b.BuildOnly {
+		return nil, nil
+	}
+
+	var results []*build.CBuildResult
+	for _, t := range targets {
+		if len(t.Filename) == 0 {
+			return nil, errors.Errorf("Meson didn't report the executable of fuzz test %q", t.Name)
+		}
+		executable := t.Filename[0]
+
+		// The seed corpus and the dictionary are expected next to the
+		// meson.build file which defines the fuzz test
+		sourceDir := filepath.Dir(t.DefinedIn)
+		seedCorpus := filepath.Join(sourceDir, t.Name+"_inputs")
+		dict := filepath.Join(sourceDir, t.Name+".dict")
+
+		var runtimeDeps []string
+		if b.FindRuntimeDeps {
+			if runtime.GOOS == "linux" {
+				runtimeDeps, err = ldd.NonSystemSharedLibraries(executable)
+			} else {
+				runtimeDeps, err = b.getRuntimeDeps(executable)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		generatedCorpus := filepath.Join(b.ProjectDir, ".cifuzz-corpus", t.Name)
+		result := &build.CBuildResult{
+			Name:       t.Name,
+			ProjectDir: b.ProjectDir,
+			Sanitizers: b.Sanitizers,
+			BuildResult: &build.BuildResult{
+				Executable:      executable,
+				GeneratedCorpus: generatedCorpus,
+				SeedCorpus:      seedCorpus,
+				Dictionary:      dict,
+				BuildDir:        buildDir,
+				RuntimeDeps:     runtimeDeps,
+			},
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// ListFuzzTests lists all fuzz tests defined in the Meson project after
+// Configure has been run.
+func (b *Builder) ListFuzzTests() ([]string, error) {
+	targets, err := b.fuzzTestTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	var fuzzTests []string
+	for _, t := range targets {
+		fuzzTests = append(fuzzTests, t.Name)
+	}
+	fuzzTests = sliceutil.RemoveDuplicates(fuzzTests)
+	return fuzzTests, nil
+}
+
+// ListConfiguredFuzzTests lists the fuzz tests of all build directories
+// of the Meson project in the project directory which were configured
+// by cifuzz before, e.g. by "cifuzz reload". In contrast to
+// ListFuzzTests, it doesn't require a Builder and is cheap enough to be
+// used for shell completion.
+func ListConfiguredFuzzTests(projectDir string) ([]string, error) {
+	targets, err := configuredFuzzTestTargets(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var fuzzTests []string
+	for _, t := range targets {
+		fuzzTests = append(fuzzTests, t.Name)
+	}
+	return sliceutil.RemoveDuplicates(fuzzTests), nil
+}
+
+// FuzzTestForSourceFile returns the name of the fuzz test which is
+// compiled from the specified source file, which is either absolute or
+// relative to the project directory. Like ListConfiguredFuzzTests, it
+// only considers build directories which were configured before.
+func FuzzTestForSourceFile(projectDir string, path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectDir, path)
+	}
+	targets, err := configuredFuzzTestTargets(projectDir)
+	if err != nil {
+		return "", err
+	}
+	t := findTargetForSourceFile(targets, path)
+	if t == nil {
+		return "", errors.New("no fuzz test found")
+	}
+	return t.Name, nil
+}
+
+func findTargetForSourceFile(targets []*target, path string) *target {
+	for _, t := range targets {
+		for _, source := range t.TargetSources {
+			for _, s := range source.Sources {
+				if filepath.Clean(s) == filepath.Clean(path) {
+					return t
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func configuredFuzzTestTargets(projectDir string) ([]*target, error) {
+	introspectionFiles, err := filepath.Glob(filepath.Join(projectDir, ".cifuzz-build", "meson", "*", "meson-info", "intro-targets.json"))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(introspectionFiles) == 0 {
+		return nil, nil
+	}
+	cifuzzIncludePath, err := runfiles.Finder.CIFuzzIncludePath()
+	if err != nil {
+		return nil, err
+	}
+
+	var fuzzTestTargets []*target
+	for _, path := range introspectionFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		targets, err := parseTargets(data)
+		if err != nil {
+			return nil, err
+		}
+		fuzzTestTargets = append(fuzzTestTargets, filterFuzzTestTargets(targets, cifuzzIncludePath)...)
+	}
+	return fuzzTestTargets, nil
+}
+
+// fuzzTestTargets returns the targets of the fuzz tests, which are the
+// executables which are compiled with the include path of cifuzz, i.e.
+// which use the FuzzTestArgsOption.
+func (b *Builder) fuzzTestTargets() ([]*target, error) {
+	targets, err := b.introspectTargets()
+	if err != nil {
+		return nil, err
+	}
+	cifuzzIncludePath, err := b.RunfilesFinder.CIFuzzIncludePath()
+	if err != nil {
+		return nil, err
+	}
+	return filterFuzzTestTargets(targets, cifuzzIncludePath), nil
+}
+
+func filterFuzzTestTargets(targets []*target, cifuzzIncludePath string) []*target {
+	includeArg := "-I" + cifuzzIncludePath
+
+	var fuzzTestTargets []*target
+	for _, t := range targets {
+		if t.Type != "executable" {
+			continue
+		}
+		for _, source := range t.TargetSources {
+			if sliceutil.Contains(source.Parameters, includeArg) {
+				fuzzTestTargets = append(fuzzTestTargets, t)
+				break
+			}
+		}
+	}
+	return fuzzTestTargets
+}
+
+func findTarget(targets []*target, name string) (*target, error) {
+	var found *target
+	for _, t := range targets {
+		if t.Name != name {
+			continue
+		}
+		if found != nil {
+			return nil, errors.Errorf("The name of fuzz test %q is ambiguous, it's defined in %s and %s",
+				name, found.DefinedIn, t.DefinedIn)
+		}
+		found = t
+	}
+	if found == nil {
+		return nil, errors.Errorf(`Fuzz test %q not found. Make sure that the fuzz test
+is an executable which uses the %q and %q options:
+
+    executable('%s', ...,
+      cpp_args: get_option('%s'),
+      link_args: get_option('%s'))`,
+			name, FuzzTestArgsOption, FuzzTestLinkArgsOption, name, FuzzTestArgsOption, FuzzTestLinkArgsOption)
+	}
+	return found, nil
+}
+
+// introspectTargets reads the targets of the project from the
+// introspection data which Meson writes to the build directory in the
+// configure step.
+func (b *Builder) introspectTargets() ([]*target, error) {
+	buildDir, err := b.BuildDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(buildDir, "meson-info", "intro-targets.json")
+	log.Debugf("Reading Meson introspection data from %s", path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return parseTargets(data)
+}
+
+func parseTargets(data []byte) ([]*target, error) {
+	var targets []*target
+	err := json.Unmarshal(data, &targets)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse Meson introspection data")
+	}
+	return targets, nil
+}
+
+// targetPath returns the path of the target as expected by
+// "meson compile", which is the directory of the target's meson.build
+// file relative to the project directory, followed by its name and type.
+func (b *Builder) targetPath(t *target) (string, error) {
+	relDir, err := filepath.Rel(b.ProjectDir, filepath.Dir(t.DefinedIn))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return fmt.Sprintf("%s:%s", filepath.ToSlash(filepath.Join(relDir, t.Name)), t.Type), nil
+}
+
+// getRuntimeDeps returns the paths of the shared libraries built by the
+// project which the given executable (transitively) depends on.
+func (b *Builder) getRuntimeDeps(executable string) ([]string, error) {
+	targets, err := b.introspectTargets()
+	if err != nil {
+		return nil, err
+	}
+	sharedLibraries := map[string]string{}
+	for _, t := range targets {
+		if t.Type != "shared library" {
+			continue
+		}
+		for _, filename := range t.Filename {
+			sharedLibraries[filepath.Base(filename)] = filename
+		}
+	}
+
+	var runtimeDeps []string
+	queue := []string{executable}
+	for len(queue) > 0 {
+		var libs []string
+		libs, err = importedLibraries(queue[0])
+		if err != nil {
+			return nil, err
+		}
+		queue = queue[1:]
+		for _, lib := range libs {
+			// Libraries which are not built by the project are system
+			// libraries, which we don't consider as runtime dependencies
+			path, ok := sharedLibraries[filepath.Base(lib)]
+			if !ok || sliceutil.Contains(runtimeDeps, path) {
+				continue
+			}
+			runtimeDeps = append(runtimeDeps, path)
+			queue = append(queue, path)
+		}
+	}
+	return runtimeDeps, nil
+}
+
+// importedLibraries returns the shared libraries the Mach-O or ELF
+// binary at the specified path depends on.
+func importedLibraries(path string) ([]string, error) {
+	machoFile, err := macho.Open(path)
+	if err == nil {
+		defer machoFile.Close()
+		libs, err := machoFile.ImportedLibraries()
+		return libs, errors.WithStack(err)
+	}
+	var formatErr *macho.FormatError
+	if !errors.As(err, &formatErr) {
+		return nil, errors.WithStack(err)
+	}
+
+	elfFile, err := elf.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer elfFile.Close()
+	libs, err := elfFile.ImportedLibraries()
+	return libs, errors.WithStack(err)
+}
+
+// checkFuzzTestOptionsDeclared returns an error with instructions if the
+// project doesn't declare the options via which we pass the flags of
+// the fuzz tests, because Meson fails with an error about unknown
+// options in that case.
+func (b *Builder) checkFuzzTestOptionsDeclared() error {
+	for _, optionsFile := range optionsFiles {
+		content, err := os.ReadFile(filepath.Join(b.ProjectDir, optionsFile))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if strings.Contains(string(content), FuzzTestArgsOption) &&
+			strings.Contains(string(content), FuzzTestLinkArgsOption) {
+			return nil
+		}
+	}
+	return errors.Errorf(`The Meson project doesn't declare the options for fuzz tests.
+Please add the following lines to the meson_options.txt file of the project:
+
+    option('%s', type: 'array', value: [],
+      description: 'Compiler arguments of the fuzz tests, set by cifuzz')
+    option('%s', type: 'array', value: [],
+      description: 'Linker arguments of the fuzz tests, set by cifuzz')`,
+		FuzzTestArgsOption, FuzzTestLinkArgsOption)
+}
+
+// arrayOption returns the value of a Meson array option containing the
+// specified elements. We use the list syntax instead of the comma
+// separated syntax, because some of our flags contain commas.
+func arrayOption(elements []string) (string, error) {
+	if elements == nil {
+		elements = []string{}
+	}
+	value, err := json.Marshal(elements)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(value), nil
+}
@@ -0,0 +1,112 @@
+package meson
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/build/other"
+	"code-intelligence.com/cifuzz/pkg/mocks"
+	"code-intelligence.com/cifuzz/util/envutil"
+)
+
+const introspectionData = `[
+  {
+    "name": "mylib",
+    "id": "mylib@sha",
+    "type": "shared library",
+    "defined_in": "/project/src/meson.build",
+    "filename": ["/project/build/src/libmylib.so"],
+    "target_sources": [{"language": "c", "parameters": ["-I../src", "-O2"]}]
+  },
+  {
+    "name": "parser_fuzz_test",
+    "id": "parser_fuzz_test@exe",
+    "type": "executable",
+    "defined_in": "/project/fuzz/meson.build",
+    "filename": ["/project/build/fuzz/parser_fuzz_test"],
+    "target_sources": [{"language": "cpp", "parameters": ["-I../fuzz", "-I/opt/cifuzz/include", "-O2"], "sources": ["/project/fuzz/parser_fuzz_test.cpp"]}]
+  },
+  {
+    "name": "app",
+    "id": "app@exe",
+    "type": "executable",
+    "defined_in": "/project/meson.build",
+    "filename": ["/project/build/app"],
+    "target_sources": [{"language": "c", "parameters": ["-O2"]}]
+  }
+]`
+
+func TestFilterFuzzTestTargets(t *testing.T) {
+	targets, err := parseTargets([]byte(introspectionData))
+	require.NoError(t, err)
+	require.Len(t, targets, 3)
+
+	fuzzTestTargets := filterFuzzTestTargets(targets, "/opt/cifuzz/include")
+	require.Len(t, fuzzTestTargets, 1)
+	assert.Equal(t, "parser_fuzz_test", fuzzTestTargets[0].Name)
+	assert.Equal(t, "/project/fuzz/meson.build", fuzzTestTargets[0].DefinedIn)
+	assert.Equal(t, []string{"/project/build/fuzz/parser_fuzz_test"}, fuzzTestTargets[0].Filename)
+
+	_, err = findTarget(fuzzTestTargets, "app")
+	require.Error(t, err)
+
+	target := findTargetForSourceFile(fuzzTestTargets, "/project/fuzz/parser_fuzz_test.cpp")
+	require.NotNil(t, target)
+	assert.Equal(t, "parser_fuzz_test", target.Name)
+	assert.Nil(t, findTargetForSourceFile(fuzzTestTargets, "/project/src/parser.c"))
+}
+
+func TestParseTargets_Invalid(t *testing.T) {
+	_, err := parseTargets([]byte("{"))
+	require.Error(t, err)
+}
+
+func TestArrayOption(t *testing.T) {
+	value, err := arrayOption([]string{"-fsanitize=address,undefined", "-g"})
+	require.NoError(t, err)
+	assert.Equal(t, `["-fsanitize=address,undefined","-g"]`, value)
+
+	value, err = arrayOption(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "[]", value)
+}
+
+func TestCheckFuzzTestOptionsDeclared(t *testing.T) {
+	projectDir := t.TempDir()
+	b := &Builder{BuilderOptions: &BuilderOptions{ProjectDir: projectDir}}
+
+	require.Error(t, b.checkFuzzTestOptionsDeclared())
+
+	options := "option('cifuzz_fuzz_test_args', type: 'array', value: [])\n" +
+		"option('cifuzz_fuzz_test_link_args', type: 'array', value: [])\n"
+	err := os.WriteFile(filepath.Join(projectDir, "meson_options.txt"), []byte(options), 0o644)
+	require.NoError(t, err)
+	require.NoError(t, b.checkFuzzTestOptionsDeclared())
+}
+
+func TestNewBuilder_NoSanitizers(t *testing.T) {
+	finderMock := &mocks.RunfilesFinderMock{}
+	finderMock.On("CIFuzzIncludePath").Return(filepath.Join("cifuzz", "include"), nil)
+	finderMock.On("DumperPath").Return(filepath.Join("lib", "dumper.o"), nil)
+
+	b, err := NewBuilder(&BuilderOptions{
+		ProjectDir:     t.TempDir(),
+		Sanitizers:     []string{},
+		RunfilesFinder: finderMock,
+	})
+	require.NoError(t, err)
+
+	cflags := strings.Fields(envutil.Getenv(b.env, "CFLAGS"))
+	assert.Contains(t, cflags, "-fsanitize=fuzzer-no-link")
+	for _, flag := range cflags {
+		assert.NotContains(t, flag, "address")
+		assert.NotContains(t, flag, "undefined")
+	}
+	assert.Empty(t, envutil.Getenv(b.env, "LDFLAGS"))
+	assert.Equal(t, "-fsanitize=fuzzer", envutil.Getenv(b.env, other.EnvFuzzTestLDFlags))
+}
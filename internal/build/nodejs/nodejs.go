@@ -0,0 +1,400 @@
+// Package nodejs provides support for Node.js projects, which can be
+// monorepos consisting of multiple npm, yarn or pnpm workspace packages
+// containing Jazzer.js fuzz tests.
+package nodejs
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mattn/go-zglob"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v3"
+
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+const (
+	PackageManagerNPM  = "npm"
+	PackageManagerYarn = "yarn"
+	PackageManagerPNPM = "pnpm"
+)
+
+// Package is a workspace package of a Node.js project
+type Package struct {
+	// The name specified in the package.json of the package
+	Name string
+	// The absolute path of the package directory
+	Dir string
+	// The path of the package directory relative to the project
+	// directory, using forward slashes. It's the prefix of the fuzz
+	// tests of the package.
+	RelDir string
+}
+
+type packageJSON struct {
+	Name            string            `json:"name"`
+	PackageManager  string            `json:"packageManager"`
+	Workspaces      json.RawMessage   `json:"workspaces"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+}
+
+type pnpmWorkspace struct {
+	Packages []string `yaml:"packages"`
+}
+
+// DetectPackageManager returns the package manager used by the project,
+// which is the one specified in the "packageManager" field of the
+// package.json (used by Corepack) or else the one whose lock file
+// exists. It defaults to npm.
+func DetectPackageManager(projectDir string) (string, error) {
+	pkg, err := readPackageJSON(projectDir)
+	if err != nil {
+		return "", err
+	}
+	if pkg != nil && pkg.PackageManager != "" {
+		name, _, _ := strings.Cut(pkg.PackageManager, "@")
+		switch name {
+		case PackageManagerNPM, PackageManagerYarn, PackageManagerPNPM:
+			return name, nil
+		}
+	}
+
+	lockFiles := []struct {
+		file           string
+		packageManager string
+	}{
+		{"pnpm-lock.yaml", PackageManagerPNPM},
+		{"pnpm-workspace.yaml", PackageManagerPNPM},
+		{"yarn.lock", PackageManagerYarn},
+	}
+	for _, lockFile := range lockFiles {
+		exists, err := fileutil.Exists(filepath.Join(projectDir, lockFile.file))
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return lockFile.packageManager, nil
+		}
+	}
+
+	return PackageManagerNPM, nil
+}
+
+// JestCommand returns the command which executes the Jest binary
+// installed by the specified package manager
+func JestCommand(packageManager string) []string {
+	switch packageManager {
+	case PackageManagerYarn:
+		return append(packageManagerCommand(packageManager), "run", "jest")
+	case PackageManagerPNPM:
+		return append(packageManagerCommand(packageManager), "exec", "jest")
+	default:
+		return []string{"npx", "jest"}
+	}
+}
+
+// InstallCommand returns the command which installs the dependencies
+// of the project with the specified package manager. If the project
+// has a lock file, the exact versions from the lock file are installed.
+func InstallCommand(projectDir string, packageManager string) ([]string, error) {
+	var lockFiles []string
+	var args []string
+	switch packageManager {
+	case PackageManagerYarn:
+		lockFiles = []string{"yarn.lock"}
+		args = []string{"install", "--frozen-lockfile"}
+	case PackageManagerPNPM:
+		lockFiles = []string{"pnpm-lock.yaml"}
+		args = []string{"install", "--frozen-lockfile"}
+	default:
+		lockFiles = []string{"package-lock.json", "npm-shrinkwrap.json"}
+		args = []string{"ci"}
+	}
+
+	hasLockFile, err := HasLockFile(projectDir, lockFiles...)
+	if err != nil {
+		return nil, err
+	}
+	if !hasLockFile {
+		args = []string{"install"}
+	}
+	return append(packageManagerCommand(packageManager), args...), nil
+}
+
+// HasLockFile returns true if the project has one of the specified lock
+// files or, if none are specified, the lock file of any of the
+// supported package managers
+func HasLockFile(projectDir string, lockFiles ...string) (bool, error) {
+	if len(lockFiles) == 0 {
+		lockFiles = []string{"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml"}
+	}
+	for _, lockFile := range lockFiles {
+		exists, err := fileutil.Exists(filepath.Join(projectDir, lockFile))
+		if err != nil {
+			return false, err
+		}
+		if exists {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DependenciesInstalled returns true if the dependencies of the root
+// package and of all workspace packages are installed, i.e. if every
+// dependency specified in their package.json is found in the
+// node_modules directory of the package or of one of its parent
+// directories inside the project directory.
+func DependenciesInstalled(projectDir string) (bool, error) {
+	exists, err := fileutil.Exists(filepath.Join(projectDir, "node_modules"))
+	if err != nil || !exists {
+		return false, err
+	}
+
+	packages, err := WorkspacePackages(projectDir)
+	if err != nil {
+		return false, err
+	}
+	dirs := []string{projectDir}
+	for _, pkg := range packages {
+		dirs = append(dirs, pkg.Dir)
+	}
+
+	for _, dir := range dirs {
+		pkg, err := readPackageJSON(dir)
+		if err != nil {
+			return false, err
+		}
+		if pkg == nil {
+			continue
+		}
+		var dependencies []string
+		dependencies = append(dependencies, maps.Keys(pkg.Dependencies)...)
+		dependencies = append(dependencies, maps.Keys(pkg.DevDependencies)...)
+		for _, dependency := range dependencies {
+			installed, err := isInstalled(projectDir, dir, dependency)
+			if err != nil || !installed {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+// isInstalled returns true if the dependency is found in the
+// node_modules directory of dir or one of its parent directories, up
+// to the project directory, like Node.js resolves it
+func isInstalled(projectDir string, dir string, dependency string) (bool, error) {
+	for {
+		exists, err := fileutil.Exists(filepath.Join(dir, "node_modules", filepath.FromSlash(dependency)))
+		if err != nil || exists {
+			return exists, err
+		}
+		if dir == projectDir || filepath.Dir(dir) == dir {
+			return false, nil
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// packageManagerCommand returns the command which runs the package
+// manager. yarn and pnpm are run via Corepack, which is shipped with
+// Node.js, if they are not installed.
+func packageManagerCommand(packageManager string) []string {
+	if packageManager == PackageManagerNPM {
+		return []string{"npm"}
+	}
+	if _, err := exec.LookPath(packageManager); err != nil {
+		return []string{"corepack", packageManager}
+	}
+	return []string{packageManager}
+}
+
+// WorkspacePackages returns the workspace packages of the project,
+// which are specified in the "workspaces" field of the package.json
+// (npm and yarn) or in the pnpm-workspace.yaml (pnpm). The root package
+// is not included. Returns nil if the project doesn't use workspaces.
+func WorkspacePackages(projectDir string) ([]*Package, error) {
+	patterns, err := workspacePatterns(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var includes, excludes []string
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			excludes = append(excludes, strings.TrimPrefix(pattern, "!"))
+		} else {
+			includes = append(includes, pattern)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var packages []*Package
+	for _, include := range includes {
+		// use zglob to support globbing in windows
+		matches, err := zglob.Glob(filepath.Join(projectDir, filepath.FromSlash(include), "package.json"))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, errors.WithStack(err)
+		}
+		for _, match := range matches {
+			dir := filepath.Dir(match)
+			relDir, err := filepath.Rel(projectDir, dir)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			relDir = filepath.ToSlash(relDir)
+			if relDir == "." || seen[relDir] || isInNodeModules(relDir) || isExcluded(relDir, excludes) {
+				continue
+			}
+			seen[relDir] = true
+
+			pkg, err := readPackageJSON(dir)
+			if err != nil {
+				return nil, err
+			}
+			packages = append(packages, &Package{Name: pkg.Name, Dir: dir, RelDir: relDir})
+		}
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].RelDir < packages[j].RelDir
+	})
+	return packages, nil
+}
+
+// PackageForFile returns the workspace package which contains the file
+// with the specified absolute path, or nil if the file belongs to the
+// root package
+func PackageForFile(packages []*Package, path string) (*Package, error) {
+	var result *Package
+	for _, pkg := range packages {
+		isBelow, err := fileutil.IsBelow(path, pkg.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if !isBelow {
+			continue
+		}
+		// Workspace packages can be nested, in which case the file
+		// belongs to the innermost package
+		if result == nil || len(pkg.Dir) > len(result.Dir) {
+			result = pkg
+		}
+	}
+	return result, nil
+}
+
+// ResolveFuzzTest splits a fuzz test identifier of the form
+// "<package dir>/<test path pattern>" into the directory of the
+// workspace package in which the fuzz test must be executed and the
+// test path pattern which is passed to Jest. If the fuzz test doesn't
+// belong to a workspace package, the project directory and the
+// unchanged fuzz test are returned.
+func ResolveFuzzTest(projectDir string, fuzzTest string) (string, string, error) {
+	packages, err := WorkspacePackages(projectDir)
+	if err != nil {
+		return "", "", err
+	}
+
+	var result *Package
+	for _, pkg := range packages {
+		if !strings.HasPrefix(fuzzTest, pkg.RelDir+"/") {
+			continue
+		}
+		if result == nil || len(pkg.RelDir) > len(result.RelDir) {
+			result = pkg
+		}
+	}
+	if result == nil {
+		return projectDir, fuzzTest, nil
+	}
+	return result.Dir, strings.TrimPrefix(fuzzTest, result.RelDir+"/"), nil
+}
+
+func workspacePatterns(projectDir string) ([]string, error) {
+	// pnpm doesn't support the "workspaces" field of the package.json
+	pnpmWorkspacePath := filepath.Join(projectDir, "pnpm-workspace.yaml")
+	bytes, err := os.ReadFile(pnpmWorkspacePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, errors.WithStack(err)
+	}
+	if err == nil {
+		var workspace pnpmWorkspace
+		err = yaml.Unmarshal(bytes, &workspace)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse %s", pnpmWorkspacePath)
+		}
+		return workspace.Packages, nil
+	}
+
+	pkg, err := readPackageJSON(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	if pkg == nil || len(pkg.Workspaces) == 0 {
+		return nil, nil
+	}
+
+	// The workspaces are either specified as an array of patterns or,
+	// in yarn classic, as an object with a "packages" field
+	var patterns []string
+	err = json.Unmarshal(pkg.Workspaces, &patterns)
+	if err == nil {
+		return patterns, nil
+	}
+	var workspaces struct {
+		Packages []string `json:"packages"`
+	}
+	err = json.Unmarshal(pkg.Workspaces, &workspaces)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse the workspaces in %s", filepath.Join(projectDir, "package.json"))
+	}
+	return workspaces.Packages, nil
+}
+
+// readPackageJSON parses the package.json in the specified directory.
+// Returns nil if it doesn't exist.
+func readPackageJSON(dir string) (*packageJSON, error) {
+	path := filepath.Join(dir, "package.json")
+	bytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var pkg packageJSON
+	err = json.Unmarshal(bytes, &pkg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse %s", path)
+	}
+	return &pkg, nil
+}
+
+func isInNodeModules(relDir string) bool {
+	for _, component := range strings.Split(relDir, "/") {
+		if component == "node_modules" {
+			return true
+		}
+	}
+	return false
+}
+
+func isExcluded(relDir string, excludes []string) bool {
+	for _, exclude := range excludes {
+		matched, err := zglob.Match(exclude, relDir)
+		if err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package nodejs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectPackageManager(t *testing.T) {
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "package.json"), `{"name": "root"}`)
+	packageManager, err := DetectPackageManager(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, PackageManagerNPM, packageManager)
+
+	writeFile(t, filepath.Join(projectDir, "yarn.lock"), "")
+	packageManager, err = DetectPackageManager(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, PackageManagerYarn, packageManager)
+
+	// The "packageManager" field takes precedence over the lock files
+	writeFile(t, filepath.Join(projectDir, "package.json"), `{"name": "root", "packageManager": "pnpm@8.6.0"}`)
+	packageManager, err = DetectPackageManager(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, PackageManagerPNPM, packageManager)
+}
+
+func TestDependenciesInstalled(t *testing.T) {
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "package.json"), `{"name": "root", "workspaces": ["packages/*"], "devDependencies": {"jest": "^29"}}`)
+	writeFile(t, filepath.Join(projectDir, "packages", "parser", "package.json"), `{"name": "parser", "dependencies": {"@scope/lexer": "^1", "acorn": "^8"}}`)
+
+	installed, err := DependenciesInstalled(projectDir)
+	require.NoError(t, err)
+	assert.False(t, installed)
+
+	// The dependencies of the workspace package are missing
+	writeFile(t, filepath.Join(projectDir, "node_modules", "jest", "package.json"), "{}")
+	installed, err = DependenciesInstalled(projectDir)
+	require.NoError(t, err)
+	assert.False(t, installed)
+
+	// Dependencies are found in the node_modules of the package and of
+	// the project directory
+	writeFile(t, filepath.Join(projectDir, "node_modules", "@scope", "lexer", "package.json"), "{}")
+	writeFile(t, filepath.Join(projectDir, "packages", "parser", "node_modules", "acorn", "package.json"), "{}")
+	installed, err = DependenciesInstalled(projectDir)
+	require.NoError(t, err)
+	assert.True(t, installed)
+}
+
+func TestWorkspacePackages(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name: "npm",
+			files: map[string]string{
+				"package.json": `{"name": "root", "workspaces": ["packages/*", "apps/web"]}`,
+			},
+		},
+		{
+			name: "yarn classic",
+			files: map[string]string{
+				"package.json": `{"name": "root", "workspaces": {"packages": ["packages/*", "apps/web"]}}`,
+			},
+		},
+		{
+			name: "pnpm",
+			files: map[string]string{
+				"package.json":        `{"name": "root"}`,
+				"pnpm-workspace.yaml": "packages:\n  - 'packages/*'\n  - 'apps/**'\n  - '!apps/legacy'\n",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			for path, content := range tc.files {
+				writeFile(t, filepath.Join(projectDir, path), content)
+			}
+			writeFile(t, filepath.Join(projectDir, "packages", "parser", "package.json"), `{"name": "@my/parser"}`)
+			writeFile(t, filepath.Join(projectDir, "packages", "lexer", "package.json"), `{"name": "@my/lexer"}`)
+			writeFile(t, filepath.Join(projectDir, "apps", "web", "package.json"), `{"name": "web"}`)
+			writeFile(t, filepath.Join(projectDir, "apps", "legacy", "package.json"), `{"name": "legacy"}`)
+			// Directories without a package.json are not packages
+			require.NoError(t, os.MkdirAll(filepath.Join(projectDir, "packages", "docs"), 0o755))
+
+			packages, err := WorkspacePackages(projectDir)
+			require.NoError(t, err)
+			assert.Equal(t, []*Package{
+				{Name: "web", Dir: filepath.Join(projectDir, "apps", "web"), RelDir: "apps/web"},
+				{Name: "@my/lexer", Dir: filepath.Join(projectDir, "packages", "lexer"), RelDir: "packages/lexer"},
+				{Name: "@my/parser", Dir: filepath.Join(projectDir, "packages", "parser"), RelDir: "packages/parser"},
+			}, packages)
+
+			pkg, err := PackageForFile(packages, filepath.Join(projectDir, "packages", "parser", "src", "parser.fuzz.js"))
+			require.NoError(t, err)
+			assert.Equal(t, packages[2], pkg)
+			pkg, err = PackageForFile(packages, filepath.Join(projectDir, "test", "root.fuzz.js"))
+			require.NoError(t, err)
+			assert.Nil(t, pkg)
+
+			packageDir, testPathPattern, err := ResolveFuzzTest(projectDir, "packages/parser/ParserFuzz")
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(projectDir, "packages", "parser"), packageDir)
+			assert.Equal(t, "ParserFuzz", testPathPattern)
+
+			packageDir, testPathPattern, err = ResolveFuzzTest(projectDir, "RootFuzz")
+			require.NoError(t, err)
+			assert.Equal(t, projectDir, packageDir)
+			assert.Equal(t, "RootFuzz", testPathPattern)
+		})
+	}
+}
+
+func TestWorkspacePackages_NoWorkspaces(t *testing.T) {
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "package.json"), `{"name": "root"}`)
+
+	packages, err := WorkspacePackages(projectDir)
+	require.NoError(t, err)
+	assert.Empty(t, packages)
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
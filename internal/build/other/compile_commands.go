@@ -0,0 +1,175 @@
+package other
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/build/compiledb"
+	"code-intelligence.com/cifuzz/util/envutil"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+const (
+	// envCompileCommandsDir holds the directory in which the compiler
+	// wrappers record the compiler invocations
+	envCompileCommandsDir = "CIFUZZ_COMPILE_COMMANDS_DIR"
+
+	// The compiler wrappers run the compilers which CC and CXX were
+	// set to before they were replaced by the wrappers
+	envWrappedCC  = "CIFUZZ_WRAPPED_CC"
+	envWrappedCXX = "CIFUZZ_WRAPPED_CXX"
+)
+
+// The compiler wrapper records its arguments and working directory in
+// a new file, NUL-separated, and then executes the wrapped compiler.
+// The variable holding the wrapped compiler is expanded without quotes,
+// so that it can contain a compiler launcher like "ccache clang" or
+// arguments like "clang --target=x86_64-linux-gnu".
+const compilerWrapperScript = `#!/bin/sh
+if f=$(mktemp "$%[1]s/XXXXXXXX" 2>/dev/null); then
+  printf '%%s\0' "%[2]s" "$PWD" "$@" > "$f"
+fi
+exec $%[2]s "$@"
+`
+
+// compileCommandsRecorder records the compiler invocations of the build
+// command via compiler wrappers, in order to create a compilation
+// database for the fuzzing configuration. That's necessary because the
+// fuzzing configuration is passed to the build command via environment
+// variables, which IDEs and tools like clang-tidy don't know about.
+type compileCommandsRecorder struct {
+	tempDir    string
+	recordsDir string
+	compilers  map[string]string
+}
+
+// newCompileCommandsRecorder creates the compiler wrappers and returns
+// the environment in which they are used as C and C++ compilers.
+func newCompileCommandsRecorder(env []string) (*compileCommandsRecorder, []string, error) {
+	cc := envutil.Getenv(env, "CC")
+	cxx := envutil.Getenv(env, "CXX")
+	if cc == "" || cxx == "" {
+		return nil, env, nil
+	}
+
+	tempDir, err := os.MkdirTemp("", "cifuzz-compile-commands-")
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	r := &compileCommandsRecorder{
+		tempDir:    tempDir,
+		recordsDir: filepath.Join(tempDir, "records"),
+		compilers: map[string]string{
+			envWrappedCC:  cc,
+			envWrappedCXX: cxx,
+		},
+	}
+	err = os.Mkdir(r.recordsDir, 0o755)
+	if err != nil {
+		r.cleanup()
+		return nil, nil, errors.WithStack(err)
+	}
+
+	wrappers := map[string]string{
+		"CC":  envWrappedCC,
+		"CXX": envWrappedCXX,
+	}
+	for compilerVar, wrappedVar := range wrappers {
+		wrapper := filepath.Join(tempDir, strings.ToLower(compilerVar))
+		script := fmt.Sprintf(compilerWrapperScript, envCompileCommandsDir, wrappedVar)
+		err = os.WriteFile(wrapper, []byte(script), 0o755)
+		if err != nil {
+			r.cleanup()
+			return nil, nil, errors.WithStack(err)
+		}
+		env, err = envutil.Setenv(env, wrappedVar, r.compilers[wrappedVar])
+		if err != nil {
+			r.cleanup()
+			return nil, nil, err
+		}
+		env, err = envutil.Setenv(env, compilerVar, wrapper)
+		if err != nil {
+			r.cleanup()
+			return nil, nil, err
+		}
+	}
+	env, err = envutil.Setenv(env, envCompileCommandsDir, r.recordsDir)
+	if err != nil {
+		r.cleanup()
+		return nil, nil, err
+	}
+
+	return r, env, nil
+}
+
+// commands returns the compile commands of all recorded compiler
+// invocations which compiled source files
+func (r *compileCommandsRecorder) commands() ([]*compiledb.Command, error) {
+	entries, err := os.ReadDir(r.recordsDir)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var commands []*compiledb.Command
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(r.recordsDir, entry.Name()))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		fields := strings.Split(string(bytes.TrimSuffix(content, []byte{0})), "\x00")
+		if len(fields) < 2 {
+			// The record is incomplete, e.g. because the compiler
+			// wrapper was killed
+			continue
+		}
+		args := strings.Fields(r.compilers[fields[0]])
+		args = append(args, fields[2:]...)
+		commands = append(commands, compiledb.CommandsFromArgs(fields[1], args)...)
+	}
+
+	// The records have random names, so we sort the commands to get a
+	// deterministic compilation database
+	sort.SliceStable(commands, func(i, j int) bool {
+		if commands[i].Directory != commands[j].Directory {
+			return commands[i].Directory < commands[j].Directory
+		}
+		return commands[i].File < commands[j].File
+	})
+	return commands, nil
+}
+
+func (r *compileCommandsRecorder) cleanup() {
+	fileutil.Cleanup(r.tempDir)
+}
+
+// CompilationDatabasePath returns the path of the compilation database
+// for the fuzzing configuration of the builder
+func (b *Builder) CompilationDatabasePath() (string, error) {
+	dirName, err := build.BuildDirName(b.Sanitizers, nil)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(b.ProjectDir, ".cifuzz-build", "other", dirName, compiledb.FileName), nil
+}
+
+func (b *Builder) writeCompilationDatabase(recorder *compileCommandsRecorder) error {
+	commands, err := recorder.commands()
+	if err != nil {
+		return err
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+	path, err := b.CompilationDatabasePath()
+	if err != nil {
+		return err
+	}
+	return compiledb.Merge(path, commands)
+}
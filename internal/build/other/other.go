@@ -1,24 +1,30 @@
 package other
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 
+	"github.com/mattn/go-zglob"
 	"github.com/pkg/errors"
 
 	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/ldd"
+	"code-intelligence.com/cifuzz/pkg/binary"
 	"code-intelligence.com/cifuzz/pkg/dependencies"
 	"code-intelligence.com/cifuzz/pkg/log"
 	"code-intelligence.com/cifuzz/pkg/runfiles"
 	"code-intelligence.com/cifuzz/util/envutil"
 	"code-intelligence.com/cifuzz/util/fileutil"
+	"code-intelligence.com/cifuzz/util/sliceutil"
 )
 
 // Warning: Changing these will lead to a breaking change!
@@ -53,10 +59,20 @@ type BuilderOptions struct {
 	BuildCommand string
 	CleanCommand string
 	Sanitizers   []string
+	// Write a compilation database for the fuzzing configuration by
+	// recording the compiler invocations of the build command
+	CompilationDatabase bool
 
 	RunfilesFinder runfiles.RunfilesFinder
 	Stdout         io.Writer
 	Stderr         io.Writer
+	// The directory in which the build and clean commands are executed
+	// and in which the fuzz test executables are searched. Defaults to
+	// the current working directory.
+	WorkDir string
+	// The context of the build and clean commands, which are killed
+	// when it's done. Defaults to context.Background().
+	Context context.Context
 }
 
 func (opts *BuilderOptions) Validate() error {
@@ -74,6 +90,17 @@ func (opts *BuilderOptions) Validate() error {
 		opts.RunfilesFinder = runfiles.Finder
 	}
 
+	if opts.WorkDir == "" {
+		opts.WorkDir, err = os.Getwd()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
+
 	return nil
 }
 
@@ -99,6 +126,8 @@ func NewBuilder(opts *BuilderOptions) (*Builder, error) {
 	// be passed to the build commands by the build system.
 	if len(opts.Sanitizers) == 1 && opts.Sanitizers[0] == "coverage" {
 		b.env, err = SetCoverageEnv(b.env, b.RunfilesFinder)
+	} else if build.IsSanitizerVariant(opts.Sanitizers) {
+		b.env, err = SetLibFuzzerSanitizerVariantEnv(b.env, b.RunfilesFinder, opts.Sanitizers[0])
 	} else {
 		for _, sanitizer := range opts.Sanitizers {
 			if sanitizer != "address" && sanitizer != "undefined" {
@@ -123,18 +152,42 @@ func (b *Builder) Build(fuzzTest string) (*build.CBuildResult, error) {
 		return nil, err
 	}
 
+	// Record the compiler invocations to create a compilation database
+	// for the fuzzing configuration
+	var recorder *compileCommandsRecorder
+	env := b.env
+	if b.CompilationDatabase {
+		recorder, env, err = newCompileCommandsRecorder(b.env)
+		if err != nil {
+			return nil, err
+		}
+		if recorder != nil {
+			defer recorder.cleanup()
+		}
+	}
+
 	// Run the build command
-	cmd := exec.Command("/bin/sh", "-c", b.BuildCommand)
+	cmd := exec.CommandContext(b.Context, "/bin/sh", "-c", b.BuildCommand)
+	cmd.Dir = b.WorkDir
 	cmd.Stdout = b.Stdout
 	cmd.Stderr = b.Stderr
-	cmd.Env = b.env
+	cmd.Env = env
 	log.Debugf("Build Command: %s", cmd.String())
 	err = cmd.Run()
 	if err != nil {
 		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
 	}
 
-	executable, err := findFuzzTestExecutable(fuzzTest)
+	if recorder != nil {
+		// A missing compilation database only affects editor tooling,
+		// so we don't fail the build if it can't be written
+		err = b.writeCompilationDatabase(recorder)
+		if err != nil {
+			log.Warnf("Failed to write compilation database: %v", err)
+		}
+	}
+
+	executable, err := findFuzzTestExecutable(b.WorkDir, fuzzTest)
 	if err != nil {
 		return nil, err
 	}
@@ -152,11 +205,6 @@ func (b *Builder) Build(fuzzTest string) (*build.CBuildResult, error) {
 		return nil, err
 	}
 
-	wd, err := os.Getwd()
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-
 	generatedCorpus := filepath.Join(b.ProjectDir, ".cifuzz-corpus", fuzzTest)
 	return &build.CBuildResult{
 		Name:       fuzzTest,
@@ -167,7 +215,7 @@ func (b *Builder) Build(fuzzTest string) (*build.CBuildResult, error) {
 			GeneratedCorpus: generatedCorpus,
 			SeedCorpus:      seedCorpus,
 			Dictionary:      dictionary,
-			BuildDir:        wd,
+			BuildDir:        b.WorkDir,
 			RuntimeDeps:     runtimeDeps,
 		},
 	}, nil
@@ -186,7 +234,8 @@ func (b *Builder) Clean() error {
 	}
 
 	// Run the clean command
-	cmd := exec.Command("/bin/sh", "-c", b.CleanCommand)
+	cmd := exec.CommandContext(b.Context, "/bin/sh", "-c", b.CleanCommand)
+	cmd.Dir = b.WorkDir
 	cmd.Stdout = b.Stdout
 	cmd.Stderr = b.Stderr
 	cmd.Env = b.env
@@ -231,6 +280,29 @@ func (b *Builder) setCleanCommandEnv() error {
 }
 
 func SetLibFuzzerEnv(env []string, finder runfiles.RunfilesFinder) ([]string, error) {
+	ldflags := []string{
+		// ----- Flags used to build with ASan -----
+		// Link ASan and UBSan runtime
+		"-fsanitize=address,undefined",
+	}
+	return setLibFuzzerEnv(env, finder, build.LibFuzzerCFlags(), ldflags, true)
+}
+
+// SetLibFuzzerSanitizerVariantEnv is like SetLibFuzzerEnv, but builds
+// with MSan or TSan instead of ASan and UBSan.
+func SetLibFuzzerSanitizerVariantEnv(env []string, finder runfiles.RunfilesFinder, sanitizer string) ([]string, error) {
+	cflags := build.LibFuzzerSanitizerVariantCFlags(sanitizer)
+	ldflags := build.SanitizerVariantLDFlags(sanitizer)
+	return setLibFuzzerEnv(env, finder, cflags, ldflags, true)
+}
+
+// SetLibFuzzerNoSanitizerEnv is like SetLibFuzzerEnv, but builds
+// without any sanitizers.
+func SetLibFuzzerNoSanitizerEnv(env []string, finder runfiles.RunfilesFinder) ([]string, error) {
+	return setLibFuzzerEnv(env, finder, build.LibFuzzerNoSanitizerCFlags(), nil, false)
+}
+
+func setLibFuzzerEnv(env []string, finder runfiles.RunfilesFinder, cflags, ldflags []string, withSanitizers bool) ([]string, error) {
 	var err error
 	env, err = setEnvWithDebugMsg(env, EnvBuildStep, "fuzzing")
 	if err != nil {
@@ -238,7 +310,6 @@ func SetLibFuzzerEnv(env []string, finder runfiles.RunfilesFinder) ([]string, er
 	}
 
 	// Set CFLAGS and CXXFLAGS
-	cflags := build.LibFuzzerCFlags()
 	env, err = setEnvWithDebugMsg(env, "CFLAGS", strings.Join(cflags, " "))
 	if err != nil {
 		return nil, err
@@ -248,11 +319,6 @@ func SetLibFuzzerEnv(env []string, finder runfiles.RunfilesFinder) ([]string, er
 		return nil, err
 	}
 
-	ldflags := []string{
-		// ----- Flags used to build with ASan -----
-		// Link ASan and UBSan runtime
-		"-fsanitize=address,undefined",
-	}
 	env, err = setEnvWithDebugMsg(env, "LDFLAGS", strings.Join(ldflags, " "))
 	if err != nil {
 		return nil, err
@@ -279,27 +345,27 @@ func SetLibFuzzerEnv(env []string, finder runfiles.RunfilesFinder) ([]string, er
 	// Users should pass the environment variable FUZZ_TEST_LDFLAGS to
 	// the linker command building the fuzz test. For libfuzzer, we set
 	// it to "-fsanitize=fuzzer" to build a libfuzzer binary.
-	// We also link in an additional object to ensure that non-fatal
-	// sanitizer findings still have an input attached.
-	// See src/dumper.c for details.
+	// When building with sanitizers, we also link in an additional
+	// object to ensure that non-fatal sanitizer findings still have an
+	// input attached. See src/dumper.c for details.
 	var fuzzTestLdflags []string
-	if runtime.GOOS != "darwin" {
+	if withSanitizers && runtime.GOOS != "darwin" {
 		// Redirect calls to __sanitizer_set_death_callback to our implemented
 		// __wrap__sanitizer_set_death_callback (in dumper.c/.cpp) to modify
 		// the behavior of the original libfuzzer function
 		fuzzTestLdflags = append(fuzzTestLdflags, "-Wl,--wrap=__sanitizer_set_death_callback")
 	}
-
-	dumper, err := finder.DumperPath()
-	if err != nil {
-		return nil, err
-	}
-	fuzzTestLdflags = append(fuzzTestLdflags,
-		// Build with instrumentation for Fuzzing
-		"-fsanitize=fuzzer",
+	// Build with instrumentation for Fuzzing
+	fuzzTestLdflags = append(fuzzTestLdflags, "-fsanitize=fuzzer")
+	if withSanitizers {
+		dumper, err := finder.DumperPath()
+		if err != nil {
+			return nil, err
+		}
 		// Path to the dumper of CI Fuzz which ensures that non-fatal sanitizer
 		// findings still have an input attached
-		dumper)
+		fuzzTestLdflags = append(fuzzTestLdflags, dumper)
+	}
 	env, err = setEnvWithDebugMsg(env, EnvFuzzTestLDFlags, strings.Join(fuzzTestLdflags, " "))
 	if err != nil {
 		return nil, err
@@ -375,9 +441,13 @@ func SetCoverageEnv(env []string, finder runfiles.RunfilesFinder) ([]string, err
 	return env, nil
 }
 
-func findFuzzTestExecutable(fuzzTest string) (string, error) {
-	if exists, _ := fileutil.Exists(fuzzTest); exists {
-		absPath, err := filepath.Abs(fuzzTest)
+func findFuzzTestExecutable(dir string, fuzzTest string) (string, error) {
+	path := fuzzTest
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if exists, _ := fileutil.Exists(path); exists {
+		absPath, err := filepath.Abs(path)
 		if err != nil {
 			return "", errors.WithStack(err)
 		}
@@ -386,7 +456,7 @@ func findFuzzTestExecutable(fuzzTest string) (string, error) {
 	}
 
 	var executable string
-	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -422,6 +492,89 @@ func findFuzzTestExecutable(fuzzTest string) (string, error) {
 	return absPath, nil
 }
 
+// ListFuzzTests returns the names of the fuzz tests of the project. The
+// patterns are the entries of the "fuzz-tests" setting, which are
+// either names of fuzz tests or glob patterns (relative to the project
+// directory) matching fuzz test executables. Only matches which define
+// LLVMFuzzerTestOneInput are listed. If no patterns are
+// specified, the project directory is searched for executables which
+// define LLVMFuzzerTestOneInput, so only fuzz tests which were built
+// before are found in that case.
+func ListFuzzTests(projectDir string, patterns []string) ([]string, error) {
+	var fuzzTests []string
+	if len(patterns) > 0 {
+		for _, pattern := range patterns {
+			if !strings.ContainsAny(pattern, "*?[") {
+				fuzzTests = append(fuzzTests, pattern)
+				continue
+			}
+			matches, err := zglob.Glob(filepath.Join(projectDir, pattern))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, errors.Wrapf(err, "Invalid pattern %q in the fuzz-tests setting", pattern)
+			}
+			sort.Strings(matches)
+			for _, match := range matches {
+				isFuzzTest, err := isFuzzTestExecutable(match)
+				if err != nil {
+					return nil, err
+				}
+				if isFuzzTest {
+					fuzzTests = append(fuzzTests, filepath.Base(match))
+				}
+			}
+		}
+		return sliceutil.RemoveDuplicates(fuzzTests), nil
+	}
+
+	err := filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if d.IsDir() {
+			// Skip hidden directories like .git and .cifuzz-corpus and
+			// dependencies of JavaScript tooling
+			if path != projectDir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		isFuzzTest, err := isFuzzTestExecutable(path)
+		if err != nil {
+			return err
+		}
+		if isFuzzTest {
+			log.Debugf("Found fuzz test executable %s", path)
+			fuzzTests = append(fuzzTests, filepath.Base(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to search through project to find fuzz test executables")
+	}
+	fuzzTests = sliceutil.RemoveDuplicates(fuzzTests)
+	sort.Strings(fuzzTests)
+	return fuzzTests, nil
+}
+
+// isFuzzTestExecutable returns true if the path is an executable which
+// defines LLVMFuzzerTestOneInput
+func isFuzzTestExecutable(path string) (bool, error) {
+	if !isExecutableFile(path) || fileutil.IsSharedLibrary(path) {
+		return false, nil
+	}
+	return binary.IsFuzzTest(path)
+}
+
+// isExecutableFile returns true if the path is a regular file which
+// has some executable bit set
+func isExecutableFile(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return info.Mode().IsRegular() && info.Mode()&0111 != 0
+}
+
 func setEnvWithDebugMsg(env []string, key, value string) ([]string, error) {
 	log.Debugf("Setting ENV: %s=%s", key, value)
 	env, err := envutil.Setenv(env, key, value)
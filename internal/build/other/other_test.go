@@ -2,7 +2,10 @@ package other
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"testing"
@@ -10,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"code-intelligence.com/cifuzz/internal/build/compiledb"
 	"code-intelligence.com/cifuzz/internal/builder"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/pkg/mocks"
@@ -78,6 +82,72 @@ func TestEnvsSetInBuild(t *testing.T) {
 	assert.Contains(t, output.String(), fmt.Sprintf("%s=%s", "CIFUZZ_BUILD_STEP", "coverage"), "CIFUZZ_BUILD_STEP for coverage is not set correctly in environment")
 }
 
+func TestCompilationDatabase(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	repoRoot, err := builder.FindProjectDir()
+	require.NoError(t, err)
+	finderMock := defaultFinderMock(t, repoRoot)
+
+	// Use fake compilers which don't produce any output. The C compiler
+	// is specified with an argument, which it requires to be passed.
+	compilersDir := filepath.Join(t.TempDir(), "fake-compilers")
+	err = os.Mkdir(compilersDir, 0o755)
+	require.NoError(t, err)
+	fakeCompilers := map[string]string{
+		"clang":   "#!/bin/sh\n[ \"$1\" = --target=x86_64-linux-gnu ]\n",
+		"clang++": "#!/bin/sh\n",
+	}
+	for name, script := range fakeCompilers {
+		err = os.WriteFile(filepath.Join(compilersDir, name), []byte(script), 0o755)
+		require.NoError(t, err)
+	}
+	t.Setenv("CC", filepath.Join(compilersDir, "clang")+" --target=x86_64-linux-gnu")
+	t.Setenv("CXX", filepath.Join(compilersDir, "clang++"))
+
+	projectDir := t.TempDir()
+	b, err := NewBuilder(&BuilderOptions{
+		ProjectDir:     projectDir,
+		BuildCommand:   `$CC $CFLAGS -c foo.c -o foo.o && $CXX $CXXFLAGS $FUZZ_TEST_CXXFLAGS -c "my fuzz test.cpp" && $CXX foo.o -o my_fuzz_test`,
+		RunfilesFinder: finderMock,
+		Sanitizers:     []string{"address", "undefined"},
+	})
+	require.NoError(t, err)
+	cmdutils.CurrentInvocation = &cmdutils.Invocation{Command: "test"}
+
+	// No compilation database is written unless it was requested
+	_, err = b.Build("my_fuzz_test")
+	require.NoError(t, err)
+	path, err := b.CompilationDatabasePath()
+	require.NoError(t, err)
+	require.NoFileExists(t, path)
+
+	b.CompilationDatabase = true
+	_, err = b.Build("my_fuzz_test")
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var commands []*compiledb.Command
+	err = json.Unmarshal(content, &commands)
+	require.NoError(t, err)
+
+	// The link command is not part of the compilation database
+	require.Len(t, commands, 2)
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, wd, commands[0].Directory)
+	assert.Equal(t, filepath.Join(compilersDir, "clang"), commands[0].Arguments[0])
+	assert.Equal(t, "--target=x86_64-linux-gnu", commands[0].Arguments[1])
+	assert.Contains(t, commands[0].Arguments, "-fsanitize=fuzzer-no-link")
+	assert.Equal(t, "foo.c", commands[0].File)
+	assert.Equal(t, filepath.Join(compilersDir, "clang++"), commands[1].Arguments[0])
+	assert.Contains(t, commands[1].Arguments, "-I"+filepath.Join(repoRoot, "include"))
+	assert.Equal(t, "my fuzz test.cpp", commands[1].File)
+}
+
 // regression test for CLI-1128
 // environment variables for c/cxx flags should enclosed by single quotes
 func TestNoQuotesOnEnv(t *testing.T) {
@@ -98,3 +168,46 @@ func TestNoQuotesOnEnv(t *testing.T) {
 	assert.NotContains(t, envutil.Getenv(env, EnvFuzzTestCFlags), "'")
 	assert.NotContains(t, envutil.Getenv(env, EnvFuzzTestCXXFlags), "'")
 }
+
+func TestListFuzzTests(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+	if _, err := exec.LookPath("cc"); err != nil {
+		t.Skip("No C compiler found")
+	}
+
+	projectDir := t.TempDir()
+	source := filepath.Join(projectDir, "fuzz_test.c")
+	err := os.WriteFile(source, []byte(`
+#include <stddef.h>
+#include <stdint.h>
+int LLVMFuzzerTestOneInput(const uint8_t *data, size_t size) { return 0; }
+int main() { return 0; }
+`), 0o644)
+	require.NoError(t, err)
+	buildDir := filepath.Join(projectDir, "build", "fuzz")
+	err = os.MkdirAll(buildDir, 0o755)
+	require.NoError(t, err)
+	out, err := exec.Command("cc", "-o", filepath.Join(buildDir, "parser_fuzz_test"), source).CombinedOutput()
+	require.NoError(t, err, string(out))
+	// Executables which are not fuzz tests and fuzz tests in hidden
+	// directories are not listed
+	err = os.WriteFile(filepath.Join(buildDir, "build.sh"), []byte("#!/bin/sh\n"), 0o755)
+	require.NoError(t, err)
+	hiddenDir := filepath.Join(projectDir, ".cifuzz-build")
+	err = os.MkdirAll(hiddenDir, 0o755)
+	require.NoError(t, err)
+	out, err = exec.Command("cc", "-o", filepath.Join(hiddenDir, "hidden_fuzz_test"), source).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	fuzzTests, err := ListFuzzTests(projectDir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"parser_fuzz_test"}, fuzzTests)
+
+	// Names are listed as they are and patterns are matched against
+	// the fuzz test executables in the project directory
+	fuzzTests, err = ListFuzzTests(projectDir, []string{"my_fuzz_test", "build/**/*", "out/*_fuzz_test"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"my_fuzz_test", "parser_fuzz_test"}, fuzzTests)
+}
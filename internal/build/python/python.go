@@ -0,0 +1,143 @@
+// Package python provides support for Python fuzz tests which use
+// Atheris, i.e. scripts which pass a fuzz target to atheris.Setup and
+// start fuzzing via atheris.Fuzz.
+package python
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+// Directories which contain installed packages or caches instead of
+// sources of the project
+var ignoredDirs = []string{
+	"__pycache__",
+	"node_modules",
+	"site-packages",
+	"venv",
+}
+
+// ListFuzzTests returns the paths of the fuzz tests in the project,
+// relative to the project directory and in slash-separated form.
+func ListFuzzTests(projectDir string) ([]string, error) {
+	var fuzzTests []string
+	err := filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != projectDir && isIgnoredDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".py" {
+			return nil
+		}
+
+		isFuzzTest, err := IsFuzzTest(path)
+		if err != nil {
+			return err
+		}
+		if isFuzzTest {
+			relPath, err := filepath.Rel(projectDir, path)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			fuzzTests = append(fuzzTests, filepath.ToSlash(relPath))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	sort.Strings(fuzzTests)
+	return fuzzTests, nil
+}
+
+func isIgnoredDir(name string) bool {
+	// Hidden directories include the .cifuzz-* directories and
+	// virtual environments named .venv
+	if strings.HasPrefix(name, ".") {
+		return true
+	}
+	for _, dir := range ignoredDirs {
+		if name == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFuzzTest returns true if the given Python file sets up and starts
+// an Atheris fuzz target.
+func IsFuzzTest(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return bytes.Contains(content, []byte("atheris.Setup(")) &&
+		bytes.Contains(content, []byte("atheris.Fuzz(")), nil
+}
+
+// Name returns a name for the fuzz test which is a valid path, which is
+// its path relative to the project directory without the file extension.
+func Name(projectDir string, fuzzTest string) (string, error) {
+	if filepath.IsAbs(fuzzTest) {
+		var err error
+		fuzzTest, err = filepath.Rel(projectDir, fuzzTest)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+	return strings.TrimSuffix(filepath.Clean(fuzzTest), ".py"), nil
+}
+
+// BuildResult returns the paths needed to run the given fuzz test,
+// which is the path of a Python script relative to the project
+// directory. Python fuzz tests don't have to be built.
+func BuildResult(projectDir string, fuzzTest string) (*build.BuildResult, error) {
+	script := fuzzTest
+	if !filepath.IsAbs(script) {
+		script = filepath.Join(projectDir, script)
+	}
+	exists, err := fileutil.Exists(script)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, cmdutils.WrapIncorrectUsageError(errors.Errorf("Fuzz test %s does not exist", fuzzTest))
+	}
+	isFuzzTest, err := IsFuzzTest(script)
+	if err != nil {
+		return nil, err
+	}
+	if !isFuzzTest {
+		return nil, cmdutils.WrapIncorrectUsageError(errors.Errorf(
+			"%s is not an Atheris fuzz test: it must call atheris.Setup and atheris.Fuzz", fuzzTest))
+	}
+
+	name, err := Name(projectDir, script)
+	if err != nil {
+		return nil, err
+	}
+	base := strings.TrimSuffix(script, ".py")
+	return &build.BuildResult{
+		Executable:      script,
+		GeneratedCorpus: filepath.Join(projectDir, ".cifuzz-corpus", name),
+		SeedCorpus:      base + "_inputs",
+		Dictionary:      base + ".dict",
+		BuildDir:        projectDir,
+	}, nil
+}
@@ -0,0 +1,34 @@
+package python
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFuzzTests(t *testing.T) {
+	projectDir, err := filepath.Abs(filepath.Join("testdata", "project"))
+	require.NoError(t, err)
+
+	fuzzTests, err := ListFuzzTests(projectDir)
+	require.NoError(t, err)
+	// Scripts in virtual environments and modules which don't call
+	// atheris.Fuzz are not listed
+	assert.Equal(t, []string{"fuzz_main.py", "tests/fuzz_parse.py"}, fuzzTests)
+}
+
+func TestBuildResult(t *testing.T) {
+	projectDir, err := filepath.Abs(filepath.Join("testdata", "project"))
+	require.NoError(t, err)
+
+	result, err := BuildResult(projectDir, filepath.Join("tests", "fuzz_parse.py"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(projectDir, "tests", "fuzz_parse.py"), result.Executable)
+	assert.Equal(t, filepath.Join(projectDir, ".cifuzz-corpus", "tests", "fuzz_parse"), result.GeneratedCorpus)
+	assert.Equal(t, filepath.Join(projectDir, "tests", "fuzz_parse_inputs"), result.SeedCorpus)
+
+	_, err = BuildResult(projectDir, filepath.Join("parser", "__init__.py"))
+	assert.Error(t, err)
+}
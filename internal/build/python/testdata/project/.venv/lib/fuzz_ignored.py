@@ -0,0 +1,15 @@
+import sys
+
+import atheris
+
+with atheris.instrument_imports():
+    from parser import parse
+
+
+def TestOneInput(data):
+    parse(data)
+
+
+if __name__ == "__main__":
+    atheris.Setup(sys.argv, TestOneInput)
+    atheris.Fuzz()
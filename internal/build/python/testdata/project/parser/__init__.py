@@ -0,0 +1,3 @@
+def parse(data):
+    if data.startswith(b"FUZZ"):
+        raise ValueError("unexpected input")
@@ -20,6 +20,7 @@ type ArchiveWriter interface {
 	WriteFile(string, string) error
 	WriteDir(string, string) error
 	WriteHardLink(string, string) error
+	WriteSymlink(string, string) error
 	GetSourcePath(string) string
 	HasFileEntry(string) bool
 	Headers() []*tar.Header
@@ -39,6 +40,9 @@ func (w *NullArchiveWriter) WriteDir(string, string) error {
 func (w *NullArchiveWriter) WriteHardLink(string, string) error {
 	return nil
 }
+func (w *NullArchiveWriter) WriteSymlink(string, string) error {
+	return nil
+}
 func (w *NullArchiveWriter) GetSourcePath(string) string {
 	return ""
 }
@@ -185,6 +189,34 @@ func (w *TarArchiveWriter) WriteHardLink(target string, linkname string) error {
 	return nil
 }
 
+// WriteSymlink adds a symbolic link header to the archive. When the
+// archive is extracted, a symlink with the name archivePath which
+// points to target is created. The target must be a relative path.
+func (w *TarArchiveWriter) WriteSymlink(archivePath string, target string) error {
+	archivePath = filepath.ToSlash(archivePath)
+	if filepath.IsAbs(target) {
+		return errors.Errorf("symlink target must be relative: %s -> %s", archivePath, target)
+	}
+	existingAbsPath, conflict := w.manifest[archivePath]
+	if conflict {
+		return errors.Errorf("conflict for archive path %q: %q and symlink to %q", archivePath, existingAbsPath, target)
+	}
+
+	header := &tar.Header{
+		Typeflag: tar.TypeSymlink,
+		Name:     archivePath,
+		Linkname: filepath.ToSlash(target),
+		Mode:     0o777,
+	}
+	err := w.WriteHeader(header)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	w.headers = append(w.headers, header)
+	w.manifest[archivePath] = target
+	return nil
+}
+
 // WriteDir traverses sourceDir recursively and writes all regular files
 // and symlinks to the archive.
 func (w *TarArchiveWriter) WriteDir(archiveBasePath string, sourceDir string) error {
@@ -203,6 +203,57 @@ func TestDuplicateFileContent(t *testing.T) {
 	require.Equal(t, expectedSize, actualSize)
 }
 
+func TestSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Creating symlinks requires privileges on Windows")
+	}
+	testFile := filepath.Join("testdata", "archive_test", "dir1", "dir2", "test.txt")
+
+	writeArchive := func(symlinks map[string]string) string {
+		archivePath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+		f, err := os.Create(archivePath)
+		require.NoError(t, err)
+		defer f.Close()
+		writer := bufio.NewWriter(f)
+		archiveWriter := NewTarArchiveWriter(writer, true)
+		err = archiveWriter.WriteFile("pkg/test.txt", testFile)
+		require.NoError(t, err)
+		for linkname, target := range symlinks {
+			err = archiveWriter.WriteSymlink(linkname, target)
+			require.NoError(t, err)
+		}
+		require.NoError(t, archiveWriter.Close())
+		require.NoError(t, writer.Flush())
+		return archivePath
+	}
+
+	archivePath := writeArchive(map[string]string{
+		"node_modules/pkg": "../pkg",
+	})
+	dir := t.TempDir()
+	err := Extract(archivePath, dir)
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(dir, "node_modules", "pkg", "test.txt"))
+	require.NoError(t, err)
+	expectedContent, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	require.Equal(t, expectedContent, content)
+
+	// Absolute symlinks can't be added to the archive
+	err = NewTarArchiveWriter(bufio.NewWriter(&strings.Builder{}), false).WriteSymlink("link", "/etc")
+	require.Error(t, err)
+
+	// Symlinks which point outside of the archive are not extracted
+	for _, symlinks := range []map[string]string{
+		{"link": ".."},
+		{"pkg/link": "../../etc"},
+		{"self": ".", "escape": "self/../.."},
+	} {
+		err = Extract(writeArchive(symlinks), t.TempDir())
+		require.Error(t, err)
+	}
+}
+
 // Use a struct instead of a map to allow multiple entries with the same
 // archive / source path.
 type fileEntry struct {
@@ -0,0 +1,129 @@
+package archive
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/otiai10/copy"
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+// CorpusArchiveExt is the file extension of corpus archives created by
+// WriteCorpusArchive.
+const CorpusArchiveExt = ".tar.gz"
+
+var corpusKeyUnsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// CorpusKey returns the key under which the corpus of the fuzzer with
+// the given name is stored. Fuzzer names can contain characters which
+// are not valid in file names (e.g. Bazel labels or Java fuzz test
+// names like "com.example.FuzzTest::fuzz"), so these are replaced.
+func CorpusKey(fuzzerName string) string {
+	key := corpusKeyUnsafeChars.ReplaceAllString(fuzzerName, "_")
+	return strings.Trim(key, "_")
+}
+
+// WriteCorpusArchive writes the contents of corpusDir to a
+// gzip-compressed tar archive named after the corpus key of the fuzzer
+// in outputDir. The inputs are stored in a top-level directory named
+// after the corpus key, so that archives of multiple fuzzers can be
+// extracted into the same directory. It returns the path of the
+// created archive.
+func WriteCorpusArchive(corpusDir, fuzzerName, outputDir string) (string, error) {
+	key := CorpusKey(fuzzerName)
+	if key == "" {
+		return "", errors.Errorf("invalid fuzzer name for corpus archive: %q", fuzzerName)
+	}
+
+	err := os.MkdirAll(outputDir, 0o755)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	// Write to a temporary file first and rename it afterwards, so
+	// that an interrupted export doesn't leave a truncated archive
+	// which would break subsequent imports.
+	archivePath := filepath.Join(outputDir, key+CorpusArchiveExt)
+	f, err := os.CreateTemp(outputDir, key+"-*.tmp")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(f.Name())
+
+	bufWriter := bufio.NewWriter(f)
+	archiveWriter := NewTarArchiveWriter(bufWriter, true)
+	err = archiveWriter.WriteDir(key, corpusDir)
+	if err != nil {
+		f.Close()
+		return "", err
+	}
+	err = archiveWriter.Close()
+	if err != nil {
+		f.Close()
+		return "", err
+	}
+	err = bufWriter.Flush()
+	if err != nil {
+		f.Close()
+		return "", errors.WithStack(err)
+	}
+	err = f.Close()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	err = os.Rename(f.Name(), archivePath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return archivePath, nil
+}
+
+// ImportCorpus copies the corpora found at path into dest. The path
+// can either be a single corpus archive or a directory containing
+// corpus archives (as written by WriteCorpusArchive) and/or corpus
+// directories named after the fuzz tests (e.g. extracted corpus
+// archives). After the import, dest contains one directory per corpus
+// key.
+func ImportCorpus(path, dest string) error {
+	if !fileutil.IsDir(path) {
+		return Extract(path, dest)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, entry := range entries {
+		entryPath := filepath.Join(path, entry.Name())
+		if entry.IsDir() {
+			key := CorpusKey(entry.Name())
+			if key == "" {
+				log.Debugf("Skipping %s, not a valid corpus directory name", entryPath)
+				continue
+			}
+			err = copy.Copy(entryPath, filepath.Join(dest, key))
+			if err != nil {
+				return errors.Wrapf(err, "Failed to copy corpus directory %s", entryPath)
+			}
+			continue
+		}
+		if !strings.HasSuffix(entry.Name(), CorpusArchiveExt) {
+			log.Debugf("Skipping %s, not a corpus archive", entryPath)
+			continue
+		}
+		err = Extract(entryPath, dest)
+		if err != nil {
+			return errors.WithMessagef(err, "Failed to extract corpus archive %s", entry.Name())
+		}
+	}
+
+	return nil
+}
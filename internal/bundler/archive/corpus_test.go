@@ -0,0 +1,77 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/testutil"
+)
+
+func TestCorpusKey(t *testing.T) {
+	assert.Equal(t, "my_fuzz_test", CorpusKey("my_fuzz_test"))
+	assert.Equal(t, "src_parser_fuzz_test", CorpusKey("//src/parser:fuzz_test"))
+	assert.Equal(t, "com.example.FuzzTest_myFuzzTest", CorpusKey("com.example.FuzzTest::myFuzzTest"))
+}
+
+func TestWriteAndImportCorpus(t *testing.T) {
+	corpusDir := testutil.MkdirTemp(t, "", "corpus-*")
+	err := os.WriteFile(filepath.Join(corpusDir, "input1"), []byte("foo"), 0o644)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(corpusDir, "input2"), []byte("bar"), 0o644)
+	require.NoError(t, err)
+
+	outputDir := testutil.MkdirTemp(t, "", "corpus-archives-*")
+	archivePath, err := WriteCorpusArchive(corpusDir, "//src:fuzz_test", outputDir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(outputDir, "src_fuzz_test.tar.gz"), archivePath)
+	_, err = WriteCorpusArchive(corpusDir, "com.example.FuzzTest::fuzz", outputDir)
+	require.NoError(t, err)
+
+	// Only the corpus archives should be left in the output directory
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// Extract a single archive
+	dest := testutil.MkdirTemp(t, "", "corpus-extract-*")
+	err = ImportCorpus(archivePath, dest)
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(dest, "src_fuzz_test", "input1"))
+	require.NoError(t, err)
+	assert.Equal(t, "foo", string(content))
+	assert.NoDirExists(t, filepath.Join(dest, "com.example.FuzzTest_fuzz"))
+
+	// Extract all archives in the directory
+	dest = testutil.MkdirTemp(t, "", "corpus-extract-*")
+	err = ImportCorpus(outputDir, dest)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dest, "src_fuzz_test", "input2"))
+	assert.FileExists(t, filepath.Join(dest, "com.example.FuzzTest_fuzz", "input2"))
+}
+
+func TestImportCorpus_Directories(t *testing.T) {
+	// A directory containing both a corpus archive and a plain corpus
+	// directory named after a fuzz test
+	corpusDir := testutil.MkdirTemp(t, "", "corpus-*")
+	err := os.WriteFile(filepath.Join(corpusDir, "input1"), []byte("foo"), 0o644)
+	require.NoError(t, err)
+	path := testutil.MkdirTemp(t, "", "corpus-import-*")
+	_, err = WriteCorpusArchive(corpusDir, "archived_fuzz_test", path)
+	require.NoError(t, err)
+	err = os.MkdirAll(filepath.Join(path, "my fuzz test"), 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(path, "my fuzz test", "input2"), []byte("bar"), 0o644)
+	require.NoError(t, err)
+
+	dest := testutil.MkdirTemp(t, "", "corpus-extract-*")
+	err = ImportCorpus(path, dest)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dest, "archived_fuzz_test", "input1"))
+	content, err := os.ReadFile(filepath.Join(dest, "my_fuzz_test", "input2"))
+	require.NoError(t, err)
+	assert.Equal(t, "bar", string(content))
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"code-intelligence.com/cifuzz/internal/build/bazel"
 	"code-intelligence.com/cifuzz/internal/bundler/archive"
 	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/pkg/log"
@@ -45,6 +46,13 @@ func (b *Bundler) Bundle() (*BundleResult, error) {
 	}
 	defer fileutil.Cleanup(b.opts.tempDir)
 
+	if b.opts.CorpusFrom != "" {
+		err = b.importCorpus()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var bundle *os.File
 	bundle, err = b.createEmptyBundle()
 	if err != nil {
@@ -65,10 +73,14 @@ func (b *Bundler) Bundle() (*BundleResult, error) {
 
 	var fuzzers []*archive.Fuzzer
 	switch b.opts.BuildSystem {
-	case config.BuildSystemCMake, config.BuildSystemBazel, config.BuildSystemOther:
+	case config.BuildSystemCMake, config.BuildSystemMeson, config.BuildSystemOther:
 		fuzzers, err = newLibfuzzerBundler(b.opts, archiveWriter).bundle()
+	case config.BuildSystemBazel:
+		fuzzers, err = b.bundleBazel(archiveWriter)
 	case config.BuildSystemMaven, config.BuildSystemGradle:
 		fuzzers, err = newJazzerBundler(b.opts, archiveWriter).bundle()
+	case config.BuildSystemNodeJS:
+		fuzzers, err = newJazzerJSBundler(b.opts, archiveWriter).bundle()
 	default:
 		err = errors.Errorf("Unknown build system for bundler: %s", b.opts.BuildSystem)
 	}
@@ -76,7 +88,7 @@ func (b *Bundler) Bundle() (*BundleResult, error) {
 		return nil, err
 	}
 
-	dockerImageUsedInBundle := b.determineDockerImageForBundle()
+	dockerImageUsedInBundle := b.determineDockerImageForBundle(fuzzers)
 	err = b.createMetadataFileInArchive(fuzzers, archiveWriter, dockerImageUsedInBundle)
 	if err != nil {
 		return nil, err
@@ -130,9 +142,9 @@ func (b *Bundler) Bundle() (*BundleResult, error) {
 
 	fuzzTestNames := []string{}
 	for _, fuzzer := range fuzzers {
-		if fuzzer.Engine == "LIBFUZZER" {
+		if fuzzer.Engine == "LIBFUZZER" || fuzzer.Engine == "AFLPLUSPLUS" {
 			fuzzTestNames = append(fuzzTestNames, fuzzer.Target)
-		} else if fuzzer.Engine == "JAVA_LIBFUZZER" {
+		} else if fuzzer.Engine == "JAVA_LIBFUZZER" || fuzzer.Engine == "JAVASCRIPT_LIBFUZZER" {
 			fuzzTestNames = append(fuzzTestNames, fuzzer.Name)
 		}
 	}
@@ -140,6 +152,26 @@ func (b *Bundler) Bundle() (*BundleResult, error) {
 	return &BundleResult{bundle.Name(), fuzzTestNames}, nil
 }
 
+// importCorpus copies the corpus archives or directories specified via
+// --corpus-from into the temp dir, so that the inputs can be added to the seeds of
+// the corresponding fuzz tests.
+func (b *Bundler) importCorpus() error {
+	importedCorpusDir := filepath.Join(b.opts.tempDir, "imported-corpus")
+	err := os.Mkdir(importedCorpusDir, 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	log.Debugf("Importing corpus from %s", b.opts.CorpusFrom)
+	err = archive.ImportCorpus(b.opts.CorpusFrom, importedCorpusDir)
+	if err != nil {
+		return errors.WithMessagef(err, "Failed to import corpus from %s", b.opts.CorpusFrom)
+	}
+	b.opts.importedCorpusDir = importedCorpusDir
+
+	return nil
+}
+
 func (b *Bundler) createEmptyBundle() (*os.File, error) {
 	archiveExt := ".tar.gz"
 
@@ -166,17 +198,73 @@ func (b *Bundler) createEmptyBundle() (*os.File, error) {
 	return bundle, nil
 }
 
-func (b *Bundler) determineDockerImageForBundle() string {
+// bundleBazel bundles the cc_fuzz_test targets with the libFuzzer
+// bundler and the java_fuzz_test targets with the Jazzer bundler.
+func (b *Bundler) bundleBazel(archiveWriter archive.ArchiveWriter) ([]*archive.Fuzzer, error) {
+	var ccFuzzTests, javaFuzzTests []string
+	for _, fuzzTest := range b.opts.FuzzTests {
+		isJava, err := bazel.IsJavaFuzzTest(fuzzTest)
+		if err != nil {
+			return nil, err
+		}
+		if isJava {
+			javaFuzzTests = append(javaFuzzTests, fuzzTest)
+		} else {
+			ccFuzzTests = append(ccFuzzTests, fuzzTest)
+		}
+	}
+
+	var fuzzers []*archive.Fuzzer
+	if len(ccFuzzTests) > 0 {
+		ccOpts := *b.opts
+		ccOpts.FuzzTests = ccFuzzTests
+		ccFuzzers, err := newLibfuzzerBundler(&ccOpts, archiveWriter).bundle()
+		if err != nil {
+			return nil, err
+		}
+		fuzzers = append(fuzzers, ccFuzzers...)
+	}
+	if len(javaFuzzTests) > 0 {
+		javaOpts := *b.opts
+		javaOpts.FuzzTests = javaFuzzTests
+		javaFuzzers, err := newJazzerBundler(&javaOpts, archiveWriter).bundle()
+		if err != nil {
+			return nil, err
+		}
+		fuzzers = append(fuzzers, javaFuzzers...)
+	}
+	return fuzzers, nil
+}
+
+func (b *Bundler) determineDockerImageForBundle(fuzzers []*archive.Fuzzer) string {
 	dockerImageUsedInBundle := b.opts.DockerImage
+	if dockerImageUsedInBundle == "" && b.opts.BuildSystem == config.BuildSystemBazel && len(fuzzers) > 0 {
+		// Bazel bundles which only contain java_fuzz_test targets
+		// should use a Docker image with Java like Maven and Gradle
+		onlyJava := true
+		for _, fuzzer := range fuzzers {
+			if fuzzer.Engine != "JAVA_LIBFUZZER" {
+				onlyJava = false
+				break
+			}
+		}
+		if onlyJava {
+			dockerImageUsedInBundle = "eclipse-temurin:20"
+		}
+	}
 	if dockerImageUsedInBundle == "" {
 		switch b.opts.BuildSystem {
-		case config.BuildSystemCMake, config.BuildSystemBazel, config.BuildSystemOther:
-			// Use default cifuzz Ubuntu Docker image for CMake, Bazel, and other build systems
+		case config.BuildSystemCMake, config.BuildSystemMeson, config.BuildSystemBazel, config.BuildSystemOther:
+			// Use default cifuzz Ubuntu Docker image for CMake, Meson, Bazel, and other build systems
 			// including all needed dependencies
 			dockerImageUsedInBundle = "cifuzz/cifuzz-ubuntu:latest"
 		case config.BuildSystemMaven, config.BuildSystemGradle:
 			// Maven and Gradle should use a Docker image with Java
 			dockerImageUsedInBundle = "eclipse-temurin:20"
+		case config.BuildSystemNodeJS:
+			// Node.js projects need a Docker image with Node.js and
+			// npm, which also ships Corepack to run yarn and pnpm
+			dockerImageUsedInBundle = "node:lts"
 		}
 	}
 
@@ -280,6 +368,27 @@ func (b *Bundler) getCodeRevision() *archive.CodeRevision {
 	return revision
 }
 
+// importedCorpusDir returns the directory containing the imported
+// corpus of the given fuzz test or an empty string if no corpus was
+// imported for it.
+func importedCorpusDir(opts *Opts, fuzzTestName string) (string, error) {
+	if opts.importedCorpusDir == "" {
+		return "", nil
+	}
+
+	dir := filepath.Join(opts.importedCorpusDir, archive.CorpusKey(fuzzTestName))
+	exists, err := fileutil.Exists(dir)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		log.Debugf("No imported corpus found for fuzz test %s", fuzzTestName)
+		return "", nil
+	}
+
+	return dir, nil
+}
+
 func prepareSeeds(seedCorpusDirs []string, archiveSeedsDir string, archiveWriter archive.ArchiveWriter) error {
 	var targetDirs []string
 	for _, sourceDir := range seedCorpusDirs {
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/internal/testutil"
 )
 
@@ -75,6 +76,23 @@ func TestParsingAdditionalFilesArguments(t *testing.T) {
 	}
 }
 
+func TestValidateSanitizers(t *testing.T) {
+	opts := &Opts{BuildSystem: config.BuildSystemCMake, Sanitizers: []string{"thread", "thread"}}
+	if runtime.GOOS != "windows" {
+		require.NoError(t, opts.validateSanitizers())
+		assert.Equal(t, []string{"thread"}, opts.Sanitizers)
+	}
+
+	opts = &Opts{BuildSystem: config.BuildSystemCMake, Sanitizers: []string{"address"}}
+	require.Error(t, opts.validateSanitizers())
+
+	opts = &Opts{BuildSystem: config.BuildSystemCMake, Engine: config.AFLPlusPlus, Sanitizers: []string{"thread"}}
+	require.Error(t, opts.validateSanitizers())
+
+	opts = &Opts{BuildSystem: config.BuildSystemMaven, Sanitizers: []string{"thread"}}
+	require.Error(t, opts.validateSanitizers())
+}
+
 // If an error occurs during bundling there should be no
 // broken bundle file left
 func TestRemoveBundleOnError(t *testing.T) {
@@ -6,12 +6,15 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 
 	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/build/bazel"
+	"code-intelligence.com/cifuzz/internal/build/cache"
 	javaBuild "code-intelligence.com/cifuzz/internal/build/java"
 	"code-intelligence.com/cifuzz/internal/build/java/gradle"
 	"code-intelligence.com/cifuzz/internal/build/java/maven"
@@ -32,6 +35,16 @@ const runtimeDepsPath = "runtime_deps"
 type jazzerBundler struct {
 	opts          *Opts
 	archiveWriter archive.ArchiveWriter
+
+	// The archive paths of the runtime dependency files which were
+	// already added to the archive, by their source paths. Fuzz tests
+	// which share a runtime dependency also share the archive path.
+	runtimeDepArchivePaths map[string]string
+	// Used to generate unique artifact names
+	artifactsMap map[string]uint
+	// The archive path of the dictionary, which is shared by all fuzz
+	// tests
+	archiveDict string
 }
 
 func newJazzerBundler(opts *Opts, archiveWriter archive.ArchiveWriter) *jazzerBundler {
@@ -41,7 +54,7 @@ func newJazzerBundler(opts *Opts, archiveWriter archive.ArchiveWriter) *jazzerBu
 	if opts.BuildStdout == nil {
 		opts.BuildStdout = os.Stdout
 	}
-	return &jazzerBundler{opts, archiveWriter}
+	return &jazzerBundler{opts: opts, archiveWriter: archiveWriter}
 }
 
 func (b *jazzerBundler) bundle() ([]*archive.Fuzzer, error) {
@@ -50,69 +63,199 @@ func (b *jazzerBundler) bundle() ([]*archive.Fuzzer, error) {
 		return nil, err
 	}
 
-	buildResult, err := b.runBuild()
+	if b.opts.BuildSystem == config.BuildSystemBazel {
+		return b.bundleBazel()
+	}
+
+	modules, err := javaBuild.Modules(b.opts.ProjectDir, b.opts.BuildSystem)
+	if err != nil {
+		return nil, err
+	}
+	if len(modules) > 0 {
+		return b.bundleModules(modules)
+	}
+
+	buildResult, err := b.runBuild("")
 	if err != nil {
 		return nil, err
 	}
 
-	fuzzTests, targetMethods, err := b.fuzzTestIdentifier(buildResult.RuntimeDeps)
+	fuzzTests, targetMethods, err := b.fuzzTestIdentifier(b.opts.FuzzTests, buildResult.RuntimeDeps)
 	if err != nil {
 		return nil, err
 	}
 
 	log.Info("Creating bundle...")
 
-	return b.assembleArtifacts(fuzzTests, targetMethods, buildResult.RuntimeDeps)
+	err = b.writeSharedFiles()
+	if err != nil {
+		return nil, err
+	}
+	return b.assembleArtifacts("", fuzzTests, targetMethods, buildResult.RuntimeDeps)
 }
 
-func (b *jazzerBundler) assembleArtifacts(fuzzTests []string, targetMethods []string, runtimeDeps []string) ([]*archive.Fuzzer, error) {
+// bundleModules bundles the fuzz tests of a multi-module project. Each
+// module containing fuzz tests to bundle is built separately, because
+// its fuzz tests are executed with the class path of the module.
+func (b *jazzerBundler) bundleModules(modules []*javaBuild.Module) ([]*archive.Fuzzer, error) {
+	fuzzTestsByModule, err := b.moduleFuzzTests(modules)
+	if err != nil {
+		return nil, err
+	}
+
+	var moduleDirs []string
+	for moduleDir := range fuzzTestsByModule {
+		moduleDirs = append(moduleDirs, moduleDir)
+	}
+	sort.Strings(moduleDirs)
+
+	type moduleArtifacts struct {
+		fuzzTests     []string
+		targetMethods []string
+		runtimeDeps   []string
+	}
+	artifacts := make(map[string]*moduleArtifacts)
+	for _, moduleDir := range moduleDirs {
+		buildResult, err := b.runBuild(moduleDir)
+		if err != nil {
+			return nil, err
+		}
+
+		fuzzTests, targetMethods, err := b.fuzzTestIdentifier(fuzzTestsByModule[moduleDir], buildResult.RuntimeDeps)
+		if err != nil {
+			return nil, err
+		}
+		artifacts[moduleDir] = &moduleArtifacts{fuzzTests, targetMethods, buildResult.RuntimeDeps}
+	}
+
+	log.Info("Creating bundle...")
+
+	err = b.writeSharedFiles()
+	if err != nil {
+		return nil, err
+	}
 	var fuzzers []*archive.Fuzzer
+	for _, moduleDir := range moduleDirs {
+		a := artifacts[moduleDir]
+		moduleFuzzers, err := b.assembleArtifacts(moduleDir, a.fuzzTests, a.targetMethods, a.runtimeDeps)
+		if err != nil {
+			return nil, err
+		}
+		fuzzers = append(fuzzers, moduleFuzzers...)
+	}
+	return fuzzers, nil
+}
 
-	var archiveDict string
-	if b.opts.Dictionary != "" {
-		archiveDict = "dict"
-		err := b.archiveWriter.WriteFile(archiveDict, b.opts.Dictionary)
+// moduleFuzzTests returns the fuzz tests to bundle by the directory of
+// the module containing them, which is empty for the root project. If
+// no fuzz tests were specified, the classes of all fuzz tests found in
+// the test source directories of the modules are returned.
+func (b *jazzerBundler) moduleFuzzTests(modules []*javaBuild.Module) (map[string][]string, error) {
+	fuzzTestsByModule := make(map[string][]string)
+
+	if len(b.opts.FuzzTests) > 0 {
+		for _, fuzzTest := range b.opts.FuzzTests {
+			fuzzTest, err := javaBuild.ResolveFuzzTest(b.opts.ProjectDir, b.opts.BuildSystem, fuzzTest)
+			if err != nil {
+				return nil, err
+			}
+			moduleDir, class := cmdutils.SeparateModuleAndTargetClass(fuzzTest)
+			fuzzTestsByModule[moduleDir] = append(fuzzTestsByModule[moduleDir], class)
+		}
+		return fuzzTestsByModule, nil
+	}
+
+	testDirs, err := javaBuild.TestDirs(b.opts.ProjectDir, b.opts.BuildSystem)
+	if err != nil {
+		return nil, err
+	}
+	for _, testDir := range testDirs {
+		fuzzTests, err := cmdutils.ListJVMFuzzTestsByRegex([]string{testDir}, "")
 		if err != nil {
 			return nil, err
 		}
+		if len(fuzzTests) == 0 {
+			continue
+		}
+
+		module, err := javaBuild.ModuleForDir(modules, testDir)
+		if err != nil {
+			return nil, err
+		}
+		var moduleDir string
+		if module != nil {
+			moduleDir = module.RelDir
+		}
+		for _, fuzzTest := range fuzzTests {
+			class, _ := cmdutils.SeparateTargetClassAndMethod(fuzzTest)
+			if !sliceutil.Contains(fuzzTestsByModule[moduleDir], class) {
+				fuzzTestsByModule[moduleDir] = append(fuzzTestsByModule[moduleDir], class)
+			}
+		}
+	}
+	if len(fuzzTestsByModule) == 0 {
+		return nil, cmdutils.WrapIncorrectUsageError(
+			errors.Errorf("No fuzz test could be found in the project directory '%s'", b.opts.ProjectDir),
+		)
+	}
+	return fuzzTestsByModule, nil
+}
+
+// writeSharedFiles adds the dictionary and the source map, which are
+// shared by all fuzz tests, to the archive
+func (b *jazzerBundler) writeSharedFiles() error {
+	if b.opts.Dictionary != "" {
+		b.archiveDict = "dict"
+		err := b.archiveWriter.WriteFile(b.archiveDict, b.opts.Dictionary)
+		if err != nil {
+			return err
+		}
 	}
 
 	// add source map to archive
 	sourceDirs, err := javaBuild.SourceDirs(b.opts.ProjectDir, b.opts.BuildSystem)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	testDirs, err := javaBuild.TestDirs(b.opts.ProjectDir, b.opts.BuildSystem)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	// In case of multi-module projects the project root directory is
 	// determined by the build system.
 	rootDir, err := javaBuild.RootDirectory(b.opts.ProjectDir, b.opts.BuildSystem)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	sourceMap, err := sourcemap.CreateSourceMap(rootDir, append(sourceDirs, testDirs...))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if len(sourceMap.JavaPackages) > 0 {
 		jsonSourceMap, err := json.Marshal(sourceMap)
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return errors.WithStack(err)
 		}
 		sourceMapName := "source_map.json"
 		sourceMapPath := filepath.Join(b.opts.tempDir, sourceMapName)
 		err = os.WriteFile(sourceMapPath, jsonSourceMap, 0644)
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return errors.WithStack(err)
 		}
 		err = b.archiveWriter.WriteFile(sourceMapName, sourceMapPath)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
+	return nil
+}
+
+// assembleArtifacts adds the runtime dependencies of the fuzz tests to
+// the archive and creates their fuzzers. The names of fuzz tests of
+// modules are prefixed with the module directory.
+func (b *jazzerBundler) assembleArtifacts(moduleDir string, fuzzTests []string, targetMethods []string, runtimeDeps []string) ([]*archive.Fuzzer, error) {
+	var fuzzers []*archive.Fuzzer
 
 	// Iterate over build results to fill archive and create fuzzers
 	for i := range fuzzTests {
@@ -120,9 +263,12 @@ func (b *jazzerBundler) assembleArtifacts(fuzzTests []string, targetMethods []st
 		if targetMethods[i] != "" {
 			fuzzTestName = fuzzTestName + "::" + targetMethods[i]
 		}
+		if moduleDir != "" {
+			fuzzTestName = moduleDir + "/" + fuzzTestName
+		}
 
 		// copy seeds for every fuzz test
-		archiveSeedsDir, err := b.copySeeds()
+		archiveSeedsDir, err := b.copySeeds(fuzzTestName)
 		if err != nil {
 			return nil, err
 		}
@@ -146,9 +292,6 @@ func (b *jazzerBundler) assembleArtifacts(fuzzTests []string, targetMethods []st
 			archiveManifestPath,
 		}
 
-		// this map is used to generate unique artifact names
-		artifactsMap := make(map[string]uint)
-
 		for _, runtimeDep := range runtimeDeps {
 			log.Debugf("runtime dept: %s", runtimeDep)
 
@@ -180,9 +323,7 @@ func (b *jazzerBundler) assembleArtifacts(fuzzTests []string, targetMethods []st
 			} else {
 				// If the current runtime dependency is a file, we generate
 				// a unique artifact name and add it to the archive.
-				artifactName := getUniqueArtifactName(runtimeDep, artifactsMap)
-				archivePath := filepath.Join(runtimeDepsPath, artifactName)
-				err = b.archiveWriter.WriteFile(archivePath, runtimeDep)
+				archivePath, err := b.addRuntimeDepFile(runtimeDep)
 				if err != nil {
 					return nil, err
 				}
@@ -206,7 +347,7 @@ func (b *jazzerBundler) assembleArtifacts(fuzzTests []string, targetMethods []st
 			Name:         fuzzTestName,
 			Engine:       "JAVA_LIBFUZZER",
 			ProjectDir:   b.opts.ProjectDir,
-			Dictionary:   archiveDict,
+			Dictionary:   b.archiveDict,
 			Seeds:        archiveSeedsDir,
 			RuntimePaths: runtimePaths,
 			EngineOptions: archive.EngineOptions{
@@ -221,14 +362,46 @@ func (b *jazzerBundler) assembleArtifacts(fuzzTests []string, targetMethods []st
 	return fuzzers, nil
 }
 
-func (b *jazzerBundler) copySeeds() (string, error) {
-	// Add seeds from user-specified seed corpus dirs (if any)
-	// to the seeds directory in the archive
+// addRuntimeDepFile adds the specified runtime dependency file to the
+// archive, unless it was already added for another fuzz test, and
+// returns its path in the archive.
+func (b *jazzerBundler) addRuntimeDepFile(runtimeDep string) (string, error) {
+	if b.runtimeDepArchivePaths == nil {
+		b.runtimeDepArchivePaths = make(map[string]string)
+		b.artifactsMap = make(map[string]uint)
+	}
+	if archivePath, found := b.runtimeDepArchivePaths[runtimeDep]; found {
+		return archivePath, nil
+	}
+
+	artifactName := getUniqueArtifactName(runtimeDep, b.artifactsMap)
+	archivePath := filepath.Join(runtimeDepsPath, artifactName)
+	err := b.archiveWriter.WriteFile(archivePath, runtimeDep)
+	if err != nil {
+		return "", err
+	}
+	b.runtimeDepArchivePaths[runtimeDep] = archivePath
+	return archivePath, nil
+}
+
+func (b *jazzerBundler) copySeeds(fuzzTestName string) (string, error) {
+	// Add seeds from user-specified seed corpus dirs (if any) and the
+	// corpus imported via --corpus-from (if any) to the seeds directory
+	// in the archive
 	// TODO: Isn't this missing the seed corpus from the build result?
+	seedCorpusDirs := append([]string{}, b.opts.SeedCorpusDirs...)
+	importedCorpus, err := importedCorpusDir(b.opts, fuzzTestName)
+	if err != nil {
+		return "", err
+	}
+	if importedCorpus != "" {
+		seedCorpusDirs = append(seedCorpusDirs, importedCorpus)
+	}
+
 	var archiveSeedsDir string
-	if len(b.opts.SeedCorpusDirs) > 0 {
+	if len(seedCorpusDirs) > 0 {
 		archiveSeedsDir = "seeds"
-		err := prepareSeeds(b.opts.SeedCorpusDirs, archiveSeedsDir, b.archiveWriter)
+		err := prepareSeeds(seedCorpusDirs, archiveSeedsDir, b.archiveWriter)
 		if err != nil {
 			return "", err
 		}
@@ -244,6 +417,8 @@ func (b *jazzerBundler) checkDependencies() error {
 		deps = []dependencies.Key{dependencies.Java, dependencies.Maven}
 	case config.BuildSystemGradle:
 		deps = []dependencies.Key{dependencies.Java, dependencies.Gradle}
+	case config.BuildSystemBazel:
+		deps = []dependencies.Key{dependencies.Java, dependencies.Bazel}
 	}
 	err := dependencies.Check(deps, b.opts.ProjectDir)
 	if err != nil {
@@ -252,7 +427,56 @@ func (b *jazzerBundler) checkDependencies() error {
 	return nil
 }
 
-func (b *jazzerBundler) runBuild() (*build.BuildResult, error) {
+// buildCacheFlags returns the flags which identify the build of the
+// specified module in the build cache. Apart from the bundle options
+// which influence the build, these are the versions of the JDK and of
+// Jazzer. The default Jazzer version is determined by the version of
+// the cifuzz Maven extension or Gradle plugin, which is part of the
+// toolchain of the build cache, but it can be overridden.
+func (b *jazzerBundler) buildCacheFlags(moduleDir string) []string {
+	flags := []string{
+		"bundle",
+		moduleDir,
+		fmt.Sprintf("%q", b.opts.BuildSystemArgs),
+	}
+
+	javaVersion, err := dependencies.Version(dependencies.Java, b.opts.ProjectDir)
+	if err != nil {
+		log.Debugf("Failed to determine the Java version: %+v", err)
+	} else {
+		flags = append(flags, "java="+javaVersion.String())
+	}
+
+	var jazzerVersion string
+	switch b.opts.BuildSystem {
+	case config.BuildSystemMaven:
+		jazzerVersion = maven.GetOverriddenJazzerVersion(b.opts.ProjectDir)
+	case config.BuildSystemGradle:
+		jazzerVersion = gradle.GetOverriddenJazzerVersion(b.opts.ProjectDir)
+	}
+	return append(flags, "jazzer="+jazzerVersion)
+}
+
+// runBuild builds the specified module, or the whole project if
+// moduleDir is empty, and returns its class path
+func (b *jazzerBundler) runBuild(moduleDir string) (*build.BuildResult, error) {
+	var c *cache.Cache
+	if b.opts.BuildCache {
+		var err error
+		c, err = cache.New(&cache.Key{
+			ProjectDir:  b.opts.ProjectDir,
+			BuildSystem: b.opts.BuildSystem,
+			Flags:       b.buildCacheFlags(moduleDir),
+		})
+		if err != nil {
+			log.Debugf("Not using the build cache: %+v", err)
+		}
+	}
+	if cachedResults, found := cache.Load[build.BuildResult](c); found && len(cachedResults) == 1 {
+		log.Info("Using cached build results")
+		return cachedResults[0], nil
+	}
+
 	var buildResult *build.BuildResult
 	switch b.opts.BuildSystem {
 	case config.BuildSystemMaven:
@@ -263,6 +487,7 @@ func (b *jazzerBundler) runBuild() (*build.BuildResult, error) {
 
 		builder, err := maven.NewBuilder(&maven.BuilderOptions{
 			ProjectDir: b.opts.ProjectDir,
+			Module:     moduleDir,
 			Parallel: maven.ParallelOptions{
 				Enabled: viper.IsSet("build-jobs"),
 				NumJobs: b.opts.NumBuildJobs,
@@ -286,6 +511,7 @@ func (b *jazzerBundler) runBuild() (*build.BuildResult, error) {
 
 		builder, err := gradle.NewBuilder(&gradle.BuilderOptions{
 			ProjectDir: b.opts.ProjectDir,
+			Module:     moduleDir,
 			Parallel: gradle.ParallelOptions{
 				Enabled: viper.IsSet("build-jobs"),
 				NumJobs: b.opts.NumBuildJobs,
@@ -303,9 +529,76 @@ func (b *jazzerBundler) runBuild() (*build.BuildResult, error) {
 		}
 	}
 
+	err := cache.Store(c, []*build.BuildResult{buildResult})
+	if err != nil {
+		log.Debugf("Failed to store the build results in the build cache: %+v", err)
+	}
+
 	return buildResult, nil
 }
 
+// bundleBazel builds the java_fuzz_test targets specified as fuzz tests
+// with bazel and bundles all fuzz tests found in their target classes.
+// Each java_fuzz_test has its own class path, which consists of the
+// deploy JAR built by bazel.
+func (b *jazzerBundler) bundleBazel() ([]*archive.Fuzzer, error) {
+	builder, err := bazel.NewBuilder(&bazel.BuilderOptions{
+		ProjectDir: b.opts.ProjectDir,
+		Args:       b.opts.BuildSystemArgs,
+		NumJobs:    b.opts.NumBuildJobs,
+		Stdout:     b.opts.BuildStdout,
+		Stderr:     b.opts.BuildStderr,
+		TempDir:    b.opts.tempDir,
+		Verbose:    viper.GetBool("verbose"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	buildResults, err := builder.BuildJava(b.opts.FuzzTests)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Creating bundle...")
+
+	err = b.writeSharedFiles()
+	if err != nil {
+		return nil, err
+	}
+	var fuzzers []*archive.Fuzzer
+	for _, buildResult := range buildResults {
+		validFuzzTests, err := cmdutils.ListJVMFuzzTests([]string{buildResult.TargetClass}, buildResult.RuntimeDeps)
+		if err != nil {
+			return nil, err
+		}
+
+		var fuzzTests []string
+		var targetMethods []string
+		for _, fuzzTest := range validFuzzTests {
+			if fuzzTest == "" {
+				continue
+			}
+			class, targetMethod := cmdutils.SeparateTargetClassAndMethod(fuzzTest)
+			fuzzTests = append(fuzzTests, class)
+			targetMethods = append(targetMethods, targetMethod)
+		}
+		if len(fuzzTests) == 0 {
+			return nil, cmdutils.WrapIncorrectUsageError(
+				errors.Errorf("No fuzz test could be found for the given class: %s", buildResult.TargetClass),
+			)
+		}
+
+		fuzzersOfTarget, err := b.assembleArtifacts("", fuzzTests, targetMethods, buildResult.RuntimeDeps)
+		if err != nil {
+			return nil, err
+		}
+		fuzzers = append(fuzzers, fuzzersOfTarget...)
+	}
+
+	return fuzzers, nil
+}
+
 // create a manifest.jar to configure jazzer
 func (b *jazzerBundler) createManifestJar(targetClass string, targetMethod string) (string, error) {
 	// create directory for fuzzer specific files
@@ -336,8 +629,9 @@ func (b *jazzerBundler) createManifestJar(targetClass string, targetMethod strin
 }
 
 // fuzzTestIdentifier extracts all fuzz tests and their target
-// methods from the fuzz tests given to the bundler.
-func (b *jazzerBundler) fuzzTestIdentifier(runtimeDeps []string) ([]string, []string, error) {
+// methods from the given fuzz tests, or from the whole class path if
+// no fuzz tests are given.
+func (b *jazzerBundler) fuzzTestIdentifier(requestedFuzzTests []string, runtimeDeps []string) ([]string, []string, error) {
 	var err error
 
 	allValidFuzzTests, err := cmdutils.ListJVMFuzzTests(nil, runtimeDeps)
@@ -353,7 +647,7 @@ func (b *jazzerBundler) fuzzTestIdentifier(runtimeDeps []string) ([]string, []st
 	var fuzzTests []string
 	var targetMethods []string
 
-	if len(b.opts.FuzzTests) == 0 {
+	if len(requestedFuzzTests) == 0 {
 		// If bundle is called without any arguments,
 		// we want to bundle every fuzz test
 		for _, fuzzTest := range allValidFuzzTests {
@@ -362,7 +656,7 @@ func (b *jazzerBundler) fuzzTestIdentifier(runtimeDeps []string) ([]string, []st
 			targetMethods = append(targetMethods, targetMethod)
 		}
 	} else {
-		for _, fuzzTest := range b.opts.FuzzTests {
+		for _, fuzzTest := range requestedFuzzTests {
 			// Catch already specified target methods early
 			if strings.Contains(fuzzTest, "::") {
 				// Check first that the fuzz test actually exists
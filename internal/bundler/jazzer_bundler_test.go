@@ -49,7 +49,7 @@ func TestAssembleArtifactsJava_Fuzzing(t *testing.T) {
 		ProjectDir: projectDir,
 		tempDir:    tempDir,
 	}, archiveWriter)
-	fuzzers, err := b.assembleArtifacts(fuzzTests, targetMethods, runtimeDeps)
+	fuzzers, err := b.assembleArtifacts("", fuzzTests, targetMethods, runtimeDeps)
 	require.NoError(t, err)
 
 	err = archiveWriter.Close()
@@ -145,7 +145,7 @@ func TestAssembleArtifactsJava_WindowsForwardSlashes(t *testing.T) {
 		ProjectDir: projectDir,
 	}, archiveWriter)
 
-	fuzzers, err := b.assembleArtifacts(fuzzTests, targetMethods, runtimeDeps)
+	fuzzers, err := b.assembleArtifacts("", fuzzTests, targetMethods, runtimeDeps)
 	require.NoError(t, err)
 
 	for _, fuzzer := range fuzzers {
@@ -223,7 +223,7 @@ func TestAssembleArtifacts_TargetMethodValidPath(t *testing.T) {
 		ProjectDir: projectDir,
 	}, &archive.NullArchiveWriter{})
 
-	fuzzers, err := b.assembleArtifacts(fuzzTests, targetMethods, nil)
+	fuzzers, err := b.assembleArtifacts("", fuzzTests, targetMethods, nil)
 	require.NoError(t, err)
 
 	require.Len(t, fuzzers, 1)
@@ -232,6 +232,26 @@ func TestAssembleArtifacts_TargetMethodValidPath(t *testing.T) {
 	assert.Equal(t, fuzzers[0].Name, "com.example.FuzzTest::myFuzzTest")
 }
 
+func TestAssembleArtifacts_Module(t *testing.T) {
+	projectDir := filepath.Join("testdata", "jazzer", "project")
+	fuzzTests := []string{"com.example.FuzzTest"}
+	targetMethods := []string{"myFuzzTest"}
+
+	tempDir := testutil.MkdirTemp(t, "", "bundle-*")
+
+	b := newJazzerBundler(&Opts{
+		tempDir:    tempDir,
+		ProjectDir: projectDir,
+	}, &archive.NullArchiveWriter{})
+
+	fuzzers, err := b.assembleArtifacts("libs/parser", fuzzTests, targetMethods, nil)
+	require.NoError(t, err)
+
+	require.Len(t, fuzzers, 1)
+	assert.Equal(t, "libs/parser/com.example.FuzzTest::myFuzzTest", fuzzers[0].Name)
+	assert.Equal(t, []string{"libs/parser/com.example.FuzzTest_myFuzzTest/manifest.jar"}, fuzzers[0].RuntimePaths)
+}
+
 func TestIntegration_BundleAllFuzzTests(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
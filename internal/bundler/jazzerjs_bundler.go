@@ -0,0 +1,351 @@
+package bundler
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/build/nodejs"
+	"code-intelligence.com/cifuzz/internal/bundler/archive"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/pkg/dependencies"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/vcs"
+	"code-intelligence.com/cifuzz/util/fileutil"
+	"code-intelligence.com/cifuzz/util/sliceutil"
+)
+
+// JazzerJSProjectDir is the directory inside the fuzzing artifact
+// archive which contains the sources of the Node.js project
+const JazzerJSProjectDir = "project"
+
+// Directories of the project which are not added to the bundle if the
+// project is not in a Git repository (otherwise, the files ignored via
+// .gitignore are excluded). The dependencies in node_modules are
+// installed when the fuzz tests are executed, unless they are vendored.
+var jazzerJSExcludedDirs = []string{"node_modules", "dist", "coverage"}
+
+// Files and directories starting with a dot are not added to the
+// bundle, because they often contain secrets (e.g. .env files or the
+// auth token in an .npmrc), apart from these ones which configure how
+// the fuzz tests are executed and how the dependencies are installed
+var jazzerJSIncludedDotfiles = []string{".jazzerjsrc", ".babelrc", ".swcrc", ".yarnrc", ".yarnrc.yml", ".pnpmfile.cjs", ".yarn"}
+
+type jazzerJSBundler struct {
+	opts          *Opts
+	archiveWriter archive.ArchiveWriter
+}
+
+func newJazzerJSBundler(opts *Opts, archiveWriter archive.ArchiveWriter) *jazzerJSBundler {
+	return &jazzerJSBundler{opts: opts, archiveWriter: archiveWriter}
+}
+
+func (b *jazzerJSBundler) bundle() ([]*archive.Fuzzer, error) {
+	err := dependencies.Check([]dependencies.Key{dependencies.Node}, b.opts.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	fuzzTests, err := b.fuzzTests()
+	if err != nil {
+		return nil, err
+	}
+
+	hasLockFile, err := nodejs.HasLockFile(b.opts.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+	if !hasLockFile && !b.opts.VendorNodeModules {
+		log.Warnf(`No lock file found in %s. The dependencies of the project are
+installed without a lock file when the fuzz tests are executed, so their
+versions might differ from the ones used locally.`, b.opts.ProjectDir)
+	}
+
+	log.Info("Creating bundle...")
+
+	err = b.writeProject()
+	if err != nil {
+		return nil, err
+	}
+
+	var archiveDict string
+	if b.opts.Dictionary != "" {
+		archiveDict = "dict"
+		err := b.archiveWriter.WriteFile(archiveDict, b.opts.Dictionary)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var fuzzers []*archive.Fuzzer
+	for _, fuzzTest := range fuzzTests {
+		archiveSeedsDir, err := b.copySeeds(fuzzTest)
+		if err != nil {
+			return nil, err
+		}
+
+		fuzzers = append(fuzzers, &archive.Fuzzer{
+			Name:       fuzzTest,
+			Path:       JazzerJSProjectDir,
+			Engine:     "JAVASCRIPT_LIBFUZZER",
+			ProjectDir: b.opts.ProjectDir,
+			Dictionary: archiveDict,
+			Seeds:      archiveSeedsDir,
+			EngineOptions: archive.EngineOptions{
+				Env:   b.opts.Env,
+				Flags: b.opts.EngineArgs,
+			},
+			MaxRunTime: uint(b.opts.Timeout.Seconds()),
+		})
+	}
+
+	return fuzzers, nil
+}
+
+// fuzzTests returns the identifiers of the fuzz tests to bundle, which
+// are all fuzz tests of the project if no fuzz tests were specified
+func (b *jazzerJSBundler) fuzzTests() ([]string, error) {
+	if len(b.opts.FuzzTests) == 0 {
+		fuzzTests, err := cmdutils.ListNodeFuzzTestsByRegex(b.opts.ProjectDir, "")
+		if err != nil {
+			return nil, err
+		}
+		if len(fuzzTests) == 0 {
+			return nil, cmdutils.WrapIncorrectUsageError(
+				errors.Errorf("No fuzz test could be found in the project directory '%s'", b.opts.ProjectDir),
+			)
+		}
+		log.Infof("Bundling fuzz tests: %s", strings.Join(fuzzTests, ", "))
+		return fuzzTests, nil
+	}
+
+	// Fuzz tests of workspace packages are executed in the directory
+	// of their package, which is determined from the prefix of the
+	// fuzz test, so we add it if it wasn't specified
+	var fuzzTests []string
+	for _, fuzzTest := range b.opts.FuzzTests {
+		fuzzTest, err := cmdutils.QualifyNodeFuzzTest(b.opts.ProjectDir, fuzzTest)
+		if err != nil {
+			return nil, err
+		}
+		testPathPattern, testNamePattern := cmdutils.SeparateTestPathAndNamePattern(fuzzTest)
+		err = cmdutils.ValidateNodeFuzzTest(b.opts.ProjectDir, testPathPattern, testNamePattern)
+		if err != nil {
+			return nil, err
+		}
+		fuzzTests = append(fuzzTests, fuzzTest)
+	}
+	return fuzzTests, nil
+}
+
+// writeProject adds the sources of the project to the archive
+func (b *jazzerJSBundler) writeProject() error {
+	// The bundle might be created inside the project directory
+	outputPath, err := filepath.Abs(b.opts.OutputPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	files, err := b.projectFiles()
+	if err != nil {
+		return errors.WithMessagef(err, "Failed to add project %s to the bundle", b.opts.ProjectDir)
+	}
+	for _, relPath := range files {
+		path := filepath.Join(b.opts.ProjectDir, relPath)
+		info, err := os.Lstat(path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if !info.Mode().IsRegular() {
+			log.Debugf("Skipping %s, which is not a regular file", path)
+			continue
+		}
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if absPath == outputPath {
+			continue
+		}
+
+		err = b.archiveWriter.WriteFile(filepath.Join(JazzerJSProjectDir, relPath), path)
+		if err != nil {
+			return errors.WithMessagef(err, "Failed to add project %s to the bundle", b.opts.ProjectDir)
+		}
+	}
+
+	if b.opts.VendorNodeModules {
+		err = b.writeNodeModules()
+		if err != nil {
+			return errors.WithMessage(err, "Failed to add node_modules to the bundle")
+		}
+	}
+	return nil
+}
+
+// projectFiles returns the paths of the files of the project which are
+// added to the bundle, relative to the project directory. Those are
+// the files which are not ignored by Git or, if the project is not in
+// a Git repository, the files which are not in one of the excluded
+// directories. Dotfiles and node_modules are always excluded.
+func (b *jazzerJSBundler) projectFiles() ([]string, error) {
+	files, err := vcs.GitListFiles(b.opts.ProjectDir)
+	if err != nil {
+		log.Debugf("Not using Git to list the project files: %v", err)
+		files = nil
+		err = filepath.WalkDir(b.opts.ProjectDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if d.IsDir() && path != b.opts.ProjectDir && sliceutil.Contains(jazzerJSExcludedDirs, d.Name()) {
+				return fs.SkipDir
+			}
+			if d.IsDir() {
+				return nil
+			}
+			relPath, err := filepath.Rel(b.opts.ProjectDir, path)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			files = append(files, relPath)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result []string
+	for _, file := range files {
+		excluded := false
+		for _, name := range strings.Split(filepath.ToSlash(file), "/") {
+			if name == "node_modules" || (strings.HasPrefix(name, ".") && !sliceutil.Contains(jazzerJSIncludedDotfiles, name)) {
+				excluded = true
+				break
+			}
+		}
+		if excluded {
+			log.Debugf("Not adding %s to the bundle", file)
+			continue
+		}
+		result = append(result, file)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// writeNodeModules adds the installed dependencies in the node_modules
+// directories of the project and its workspace packages to the archive,
+// so that the fuzz tests can be executed without installing them
+func (b *jazzerJSBundler) writeNodeModules() error {
+	realProjectDir, err := filepath.EvalSymlinks(b.opts.ProjectDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	packages, err := nodejs.WorkspacePackages(b.opts.ProjectDir)
+	if err != nil {
+		return err
+	}
+	nodeModulesDirs := []string{filepath.Join(b.opts.ProjectDir, "node_modules")}
+	for _, pkg := range packages {
+		nodeModulesDirs = append(nodeModulesDirs, filepath.Join(pkg.Dir, "node_modules"))
+	}
+
+	for _, nodeModulesDir := range nodeModulesDirs {
+		exists, err := fileutil.Exists(nodeModulesDir)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+
+		err = filepath.WalkDir(nodeModulesDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			// The caches of tools like Babel and Jest are not needed
+			if d.IsDir() && d.Name() == ".cache" && filepath.Dir(path) == nodeModulesDir {
+				return fs.SkipDir
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			relPath, err := filepath.Rel(b.opts.ProjectDir, path)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			archivePath := filepath.Join(JazzerJSProjectDir, relPath)
+
+			if d.Type()&fs.ModeSymlink == 0 {
+				if !d.Type().IsRegular() {
+					log.Debugf("Skipping %s, which is not a regular file", path)
+					return nil
+				}
+				return b.archiveWriter.WriteFile(archivePath, path)
+			}
+
+			// Symlinks to workspace packages and, with pnpm, to the
+			// virtual store in node_modules/.pnpm are added as
+			// relative symlinks. Symlinks to files outside of the
+			// project (e.g. created via npm link) are resolved.
+			target, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				log.Debugf("Skipping %s, which is a broken symlink", path)
+				return nil
+			}
+			isBelow, err := fileutil.IsBelow(target, realProjectDir)
+			if err != nil {
+				return err
+			}
+			if !isBelow {
+				if fileutil.IsDir(target) {
+					return b.archiveWriter.WriteDir(archivePath, target)
+				}
+				return b.archiveWriter.WriteFile(archivePath, target)
+			}
+			realParent, err := filepath.EvalSymlinks(filepath.Dir(path))
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			relTarget, err := filepath.Rel(realParent, target)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			return b.archiveWriter.WriteSymlink(archivePath, relTarget)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *jazzerJSBundler) copySeeds(fuzzTest string) (string, error) {
+	// Add seeds from user-specified seed corpus dirs (if any) and the
+	// corpus imported via --corpus-from (if any) to the seeds directory
+	// of the fuzz test in the archive
+	seedCorpusDirs := append([]string{}, b.opts.SeedCorpusDirs...)
+	importedCorpus, err := importedCorpusDir(b.opts, fuzzTest)
+	if err != nil {
+		return "", err
+	}
+	if importedCorpus != "" {
+		seedCorpusDirs = append(seedCorpusDirs, importedCorpus)
+	}
+	if len(seedCorpusDirs) == 0 {
+		return "", nil
+	}
+
+	archiveSeedsDir := filepath.Join("seeds", archive.CorpusKey(fuzzTest))
+	err = prepareSeeds(seedCorpusDirs, archiveSeedsDir, b.archiveWriter)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(archiveSeedsDir), nil
+}
@@ -0,0 +1,178 @@
+package bundler
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/bundler/archive"
+	"code-intelligence.com/cifuzz/internal/testutil"
+	"code-intelligence.com/cifuzz/pkg/dependencies"
+)
+
+func TestBundleJazzerJS(t *testing.T) {
+	dependencies.TestMockAllDeps(t)
+
+	projectDir := testutil.MkdirTemp(t, "", "jazzerjs-project-*")
+	files := map[string]string{
+		"package.json":                                   `{"name": "root", "workspaces": ["packages/*"]}`,
+		"package-lock.json":                              `{}`,
+		"packages/parser/package.json":                   `{"name": "parser"}`,
+		"packages/parser/parser.fuzz.js":                 `test.fuzz("parse", (data) => {});`,
+		"packages/parser/node_modules/foo/index.js":      "",
+		"node_modules/jest/index.js":                     "",
+		".git/HEAD":                                      "",
+		".cifuzz-build/cache/entry.json":                 "",
+		"packages/parser/parser.fuzz/parse/crash-1234":   "",
+		"packages/parser/.jazzerjsrc":                    `{"sync": true}`,
+		"packages/parser/src/nested/deeply/something.js": "",
+		".env":                               "SECRET=1",
+		".npmrc":                             "//registry.npmjs.org/:_authToken=secret",
+		"packages/parser/.env.local":         "SECRET=1",
+		"dist/index.js":                      "",
+		"packages/parser/coverage/lcov.info": "",
+	}
+	for path, content := range files {
+		path = filepath.Join(projectDir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	seedCorpusDir := testutil.MkdirTemp(t, "", "seeds-*")
+	require.NoError(t, os.WriteFile(filepath.Join(seedCorpusDir, "seed"), []byte("seed"), 0o644))
+
+	bundle, err := os.CreateTemp("", "bundle-archive-")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(bundle.Name()) })
+	bufWriter := bufio.NewWriter(bundle)
+	archiveWriter := archive.NewTarArchiveWriter(bufWriter, true)
+
+	b := newJazzerJSBundler(&Opts{
+		ProjectDir:     projectDir,
+		OutputPath:     bundle.Name(),
+		SeedCorpusDirs: []string{seedCorpusDir},
+		EngineArgs:     []string{"-runs=100"},
+	}, archiveWriter)
+	fuzzers, err := b.bundle()
+	require.NoError(t, err)
+
+	require.NoError(t, archiveWriter.Close())
+	require.NoError(t, bufWriter.Flush())
+	require.NoError(t, bundle.Close())
+
+	require.Len(t, fuzzers, 1)
+	assert.Equal(t, &archive.Fuzzer{
+		Name:       "packages/parser/parser",
+		Path:       "project",
+		Engine:     "JAVASCRIPT_LIBFUZZER",
+		ProjectDir: projectDir,
+		Seeds:      "seeds/packages_parser_parser",
+		EngineOptions: archive.EngineOptions{
+			Flags: []string{"-runs=100"},
+		},
+	}, fuzzers[0])
+
+	archiveDir := testutil.MkdirTemp(t, "", "bundle-extract-*")
+	err = archive.Extract(bundle.Name(), archiveDir)
+	require.NoError(t, err)
+
+	// The sources of the project are bundled, but not the installed
+	// dependencies and the files created by git and cifuzz
+	assert.FileExists(t, filepath.Join(archiveDir, "project", "package.json"))
+	assert.FileExists(t, filepath.Join(archiveDir, "project", "package-lock.json"))
+	assert.FileExists(t, filepath.Join(archiveDir, "project", "packages", "parser", "parser.fuzz.js"))
+	assert.FileExists(t, filepath.Join(archiveDir, "project", "packages", "parser", ".jazzerjsrc"))
+	assert.FileExists(t, filepath.Join(archiveDir, "project", "packages", "parser", "parser.fuzz", "parse", "crash-1234"))
+	assert.NoDirExists(t, filepath.Join(archiveDir, "project", "node_modules"))
+	assert.NoDirExists(t, filepath.Join(archiveDir, "project", "packages", "parser", "node_modules"))
+	assert.NoDirExists(t, filepath.Join(archiveDir, "project", ".git"))
+	assert.NoDirExists(t, filepath.Join(archiveDir, "project", ".cifuzz-build"))
+	// Dotfiles which might contain secrets and build outputs are not
+	// bundled either
+	assert.NoFileExists(t, filepath.Join(archiveDir, "project", ".env"))
+	assert.NoFileExists(t, filepath.Join(archiveDir, "project", ".npmrc"))
+	assert.NoFileExists(t, filepath.Join(archiveDir, "project", "packages", "parser", ".env.local"))
+	assert.NoDirExists(t, filepath.Join(archiveDir, "project", "dist"))
+	assert.NoDirExists(t, filepath.Join(archiveDir, "project", "packages", "parser", "coverage"))
+
+	assert.FileExists(t, filepath.Join(archiveDir, "seeds", "packages_parser_parser", filepath.Base(seedCorpusDir), "seed"))
+}
+
+func TestBundleJazzerJS_GitIgnoreAndVendoredNodeModules(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Creating symlinks requires privileges on Windows")
+	}
+	dependencies.TestMockAllDeps(t)
+
+	projectDir := testutil.MkdirTemp(t, "", "jazzerjs-project-*")
+	outsideDir := testutil.MkdirTemp(t, "", "linked-package-*")
+	files := map[string]string{
+		"package.json":                       `{"name": "root", "workspaces": ["packages/*"]}`,
+		".gitignore":                         "credentials.json\nout/\n",
+		"credentials.json":                   `{"token": "secret"}`,
+		"out/index.js":                       "",
+		"packages/parser/package.json":       `{"name": "parser"}`,
+		"packages/parser/parser.fuzz.js":     `test.fuzz("parse", (data) => {});`,
+		"node_modules/jest/index.js":         "jest",
+		"node_modules/.cache/jest/cache.bin": "",
+	}
+	for path, content := range files {
+		path = filepath.Join(projectDir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(outsideDir, "index.js"), []byte("linked"), 0o644))
+	// Symlinks to a workspace package and to a package outside of the
+	// project, like the ones created by npm install and npm link
+	require.NoError(t, os.Symlink(filepath.Join("..", "packages", "parser"), filepath.Join(projectDir, "node_modules", "parser")))
+	require.NoError(t, os.Symlink(outsideDir, filepath.Join(projectDir, "node_modules", "linked")))
+
+	cmd := exec.Command("git", "init")
+	cmd.Dir = projectDir
+	require.NoError(t, cmd.Run())
+
+	bundle, err := os.CreateTemp("", "bundle-archive-")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(bundle.Name()) })
+	bufWriter := bufio.NewWriter(bundle)
+	archiveWriter := archive.NewTarArchiveWriter(bufWriter, true)
+
+	b := newJazzerJSBundler(&Opts{
+		ProjectDir:        projectDir,
+		OutputPath:        bundle.Name(),
+		VendorNodeModules: true,
+	}, archiveWriter)
+	_, err = b.bundle()
+	require.NoError(t, err)
+
+	require.NoError(t, archiveWriter.Close())
+	require.NoError(t, bufWriter.Flush())
+	require.NoError(t, bundle.Close())
+
+	archiveDir := testutil.MkdirTemp(t, "", "bundle-extract-*")
+	err = archive.Extract(bundle.Name(), archiveDir)
+	require.NoError(t, err)
+
+	// Files ignored via .gitignore are not bundled
+	assert.FileExists(t, filepath.Join(archiveDir, "project", "packages", "parser", "parser.fuzz.js"))
+	assert.NoFileExists(t, filepath.Join(archiveDir, "project", "credentials.json"))
+	assert.NoDirExists(t, filepath.Join(archiveDir, "project", "out"))
+
+	// The vendored node_modules are bundled with their symlinks
+	content, err := os.ReadFile(filepath.Join(archiveDir, "project", "node_modules", "jest", "index.js"))
+	require.NoError(t, err)
+	assert.Equal(t, "jest", string(content))
+	assert.NoDirExists(t, filepath.Join(archiveDir, "project", "node_modules", ".cache"))
+	target, err := os.Readlink(filepath.Join(archiveDir, "project", "node_modules", "parser"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("..", "packages", "parser"), target)
+	assert.FileExists(t, filepath.Join(archiveDir, "project", "node_modules", "parser", "parser.fuzz.js"))
+	content, err = os.ReadFile(filepath.Join(archiveDir, "project", "node_modules", "linked", "index.js"))
+	require.NoError(t, err)
+	assert.Equal(t, "linked", string(content))
+}
@@ -1,7 +1,9 @@
 package bundler
 
 import (
+	"context"
 	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
@@ -10,17 +12,22 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/otiai10/copy"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"golang.org/x/exp/maps"
+	"golang.org/x/sync/errgroup"
 
 	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/build/bazel"
+	"code-intelligence.com/cifuzz/internal/build/cache"
 	"code-intelligence.com/cifuzz/internal/build/cmake"
+	"code-intelligence.com/cifuzz/internal/build/meson"
 	"code-intelligence.com/cifuzz/internal/build/other"
 	"code-intelligence.com/cifuzz/internal/bundler/archive"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/pkg/dependencies"
 	"code-intelligence.com/cifuzz/pkg/log"
@@ -29,8 +36,14 @@ import (
 	"code-intelligence.com/cifuzz/util/sliceutil"
 )
 
+// The maximum number of variants which are built concurrently. Each
+// build is parallelized by the build tool itself, so we only use one
+// build per two CPU cores.
+var maxParallelVariantBuilds = runtime.NumCPU() / 2
+
 type configureVariant struct {
 	Sanitizers []string
+	Engine     config.Engine
 }
 
 // System library dependencies that are so common that we shouldn't emit a warning for them - they will be contained in
@@ -70,6 +83,13 @@ func (b *libfuzzerBundler) bundle() ([]*archive.Fuzzer, error) {
 		return nil, err
 	}
 
+	if b.opts.BuildSystem == config.BuildSystemOther && len(b.opts.FuzzTests) == 0 {
+		b.opts.FuzzTests, err = b.listFuzzTestsOther()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	buildResults, err := b.buildAllVariants()
 	if err != nil {
 		return nil, err
@@ -102,10 +122,31 @@ func (b *libfuzzerBundler) bundle() ([]*archive.Fuzzer, error) {
 	return fuzzers, nil
 }
 
+// listFuzzTestsOther returns the fuzz tests from the fuzz-tests setting
+// or, if that's not set, the fuzz test executables which were built
+// before. It's used if no fuzz tests were specified for build system
+// type "other", because the build command doesn't tell us which fuzz
+// tests it builds.
+func (b *libfuzzerBundler) listFuzzTestsOther() ([]string, error) {
+	fuzzTests, err := other.ListFuzzTests(b.opts.ProjectDir, b.opts.FuzzTestPatterns)
+	if err != nil {
+		return nil, err
+	}
+	if len(fuzzTests) == 0 {
+		msg := `No fuzz tests found. At least one <fuzz test> argument must be provided
+or the fuzz tests must be listed in the "fuzz-tests" setting when using
+the build system type "other"`
+		return nil, cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	log.Infof("Bundling fuzz tests: %s", strings.Join(fuzzTests, ", "))
+	return fuzzTests, nil
+}
+
 func (b *libfuzzerBundler) buildAllVariants() ([]*build.CBuildResult, error) {
 	fuzzingVariant := configureVariant{
 		// TODO: Do not hardcode these values.
 		Sanitizers: []string{"address"},
+		Engine:     b.fuzzingEngine(),
 	}
 	// UBSan is not supported by MSVb.
 	// TODO: Not needed anymore when sanitizers are configurable,
@@ -116,19 +157,110 @@ func (b *libfuzzerBundler) buildAllVariants() ([]*build.CBuildResult, error) {
 	}
 	configureVariants := []configureVariant{fuzzingVariant}
 
+	// MSan and TSan can't be combined with ASan, so the opt-in
+	// sanitizers are built as separate fuzzing variants
+	for _, sanitizer := range b.opts.Sanitizers {
+		configureVariants = append(configureVariants, configureVariant{
+			Sanitizers: []string{sanitizer},
+			Engine:     b.fuzzingEngine(),
+		})
+	}
+
 	// Coverage builds are not supported by MSVb.
 	if runtime.GOOS != "windows" {
 		coverageVariant := configureVariant{
 			Sanitizers: []string{"coverage"},
+			Engine:     config.Libfuzzer,
 		}
 		configureVariants = append(configureVariants, coverageVariant)
 	}
 
+	// Reuse the results of the variants for which neither the sources,
+	// nor the build flags, nor the toolchain changed since they were
+	// last built
+	caches := make([]*cache.Cache, len(configureVariants))
+	cachedResults := make([][]*build.CBuildResult, len(configureVariants))
+	found := make([]bool, len(configureVariants))
+	var variantsToBuild []configureVariant
+	for i, variant := range configureVariants {
+		caches[i] = b.buildCache(variant)
+		cachedResults[i], found[i] = cache.Load[build.CBuildResult](caches[i])
+		if found[i] {
+			log.Infof("Using cached build results for %s", variantDisplayString(variant))
+			continue
+		}
+		variantsToBuild = append(variantsToBuild, variant)
+	}
+
+	var builtResults []*build.CBuildResult
+	if len(variantsToBuild) > 0 {
+		var err error
+		builtResults, err = b.buildVariants(variantsToBuild)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var allResults []*build.CBuildResult
+	for i, variant := range configureVariants {
+		if found[i] {
+			allResults = append(allResults, cachedResults[i]...)
+			continue
+		}
+		var results []*build.CBuildResult
+		for _, result := range builtResults {
+			if sliceutil.Equal(result.Sanitizers, variant.Sanitizers) {
+				results = append(results, result)
+			}
+		}
+		err := cache.Store(caches[i], results)
+		if err != nil {
+			log.Debugf("Failed to store the build results in the build cache: %+v", err)
+		}
+		allResults = append(allResults, results...)
+	}
+
+	return allResults, nil
+}
+
+// buildCache returns the cache for the build of the specified variant,
+// or nil if the build cache is not enabled or can't be used.
+func (b *libfuzzerBundler) buildCache(variant configureVariant) *cache.Cache {
+	if !b.opts.BuildCache {
+		return nil
+	}
+	c, err := cache.New(&cache.Key{
+		ProjectDir:  b.opts.ProjectDir,
+		BuildSystem: b.opts.BuildSystem,
+		Flags: []string{
+			"bundle",
+			strings.Join(variant.Sanitizers, "+"),
+			string(variant.Engine),
+			b.opts.CleanCommand,
+			fmt.Sprintf("%q", b.opts.BuildSystemArgs),
+			fmt.Sprintf("%q", b.opts.FuzzTests),
+		},
+		BuildCommand: b.opts.BuildCommand,
+		// The artifacts of builds with Bazel are extracted to a
+		// temporary directory and those of builds with build system
+		// type "other" are overwritten by the build of the next variant
+		CopyArtifacts: b.opts.BuildSystem == config.BuildSystemBazel || b.opts.BuildSystem == config.BuildSystemOther,
+	})
+	if err != nil {
+		log.Debugf("Not using the build cache: %+v", err)
+		return nil
+	}
+	return c
+}
+
+func (b *libfuzzerBundler) buildVariants(configureVariants []configureVariant) ([]*build.CBuildResult, error) {
 	switch b.opts.BuildSystem {
 	case config.BuildSystemBazel:
 		return b.buildAllVariantsBazel(configureVariants)
 	case config.BuildSystemCMake:
 		return b.buildAllVariantsCMake(configureVariants)
+	case config.BuildSystemMeson:
+		return b.buildAllVariantsMeson(configureVariants)
 	case config.BuildSystemOther:
 		return b.buildAllVariantsOther(configureVariants)
 	default:
@@ -141,99 +273,236 @@ func (b *libfuzzerBundler) buildAllVariants() ([]*build.CBuildResult, error) {
 }
 
 func (b *libfuzzerBundler) buildAllVariantsBazel(configureVariants []configureVariant) ([]*build.CBuildResult, error) {
-	var allResults []*build.CBuildResult
-	for _, variant := range configureVariants {
-		builder, err := bazel.NewBuilder(&bazel.BuilderOptions{
-			ProjectDir: b.opts.ProjectDir,
-			Args:       b.opts.BuildSystemArgs,
-			NumJobs:    b.opts.NumBuildJobs,
-			Stdout:     b.opts.BuildStdout,
-			Stderr:     b.opts.BuildStderr,
-			TempDir:    b.opts.tempDir,
-			Verbose:    viper.GetBool("verbose"),
-		})
+	if len(b.opts.FuzzTests) == 0 {
+		// We panic here instead of returning an error because it's a
+		// programming error if this function was called without any
+		// fuzz tests, that case should have been handled in the
+		// Opts.Validate function.
+		panic("No fuzz tests specified")
+	}
+	return b.buildVariantsInParallel(configureVariants, b.buildVariantBazel)
+}
+
+func (b *libfuzzerBundler) buildVariantBazel(ctx context.Context, variant configureVariant, stdout, stderr io.Writer) ([]*build.CBuildResult, error) {
+	// Bazel only executes one command per output base at a time, so
+	// all variants but the default one are built in their own output
+	// base, which allows building them concurrently
+	var outputBase string
+	if build.IsSanitizerVariant(variant.Sanitizers) || isCoverageBuild(variant.Sanitizers) {
+		var err error
+		outputBase, err = bazelVariantOutputBase(b.opts.ProjectDir, variant)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		b.printBuildingMsg(variant)
+	builder, err := bazel.NewBuilder(&bazel.BuilderOptions{
+		ProjectDir: b.opts.ProjectDir,
+		Args:       b.opts.BuildSystemArgs,
+		Engine:     variant.Engine,
+		NumJobs:    b.opts.NumBuildJobs,
+		Stdout:     stdout,
+		Stderr:     stderr,
+		TempDir:    b.opts.tempDir,
+		Verbose:    viper.GetBool("verbose"),
+		OutputBase: outputBase,
+		Context:    ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		if len(b.opts.FuzzTests) == 0 {
-			// We panic here instead of returning an error because it's a
-			// programming error if this function was called without any
-			// fuzz tests, that case should have been handled in the
-			// Opts.Validate function.
-			panic("No fuzz tests specified")
-		}
+	return builder.BuildForBundle(variant.Sanitizers, b.opts.FuzzTests)
+}
 
-		results, err := builder.BuildForBundle(variant.Sanitizers, b.opts.FuzzTests)
-		if err != nil {
-			return nil, err
-		}
-		allResults = append(allResults, results...)
+// bazelVariantOutputBase returns the output base in which the variant
+// is built. The output bases are kept in the user cache directory (and
+// not in the workspace, where bazel would consider them part of the
+// sources), so that subsequent builds of the variant are incremental.
+func bazelVariantOutputBase(projectDir string, variant configureVariant) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.WithStack(err)
 	}
-
-	return allResults, nil
+	hash := sha256.Sum256([]byte(projectDir))
+	name := strings.Join(variant.Sanitizers, "+") + "-" + string(variant.Engine)
+	return filepath.Join(cacheDir, "cifuzz", "bazel", hex.EncodeToString(hash[:8]), name), nil
 }
 
 func (b *libfuzzerBundler) buildAllVariantsCMake(configureVariants []configureVariant) ([]*build.CBuildResult, error) {
-	var allResults []*build.CBuildResult
-	for _, variant := range configureVariants {
-		builder, err := cmake.NewBuilder(&cmake.BuilderOptions{
-			ProjectDir: b.opts.ProjectDir,
-			Args:       b.opts.BuildSystemArgs,
-			Sanitizers: variant.Sanitizers,
-			Parallel: cmake.ParallelOptions{
-				Enabled: viper.IsSet("build-jobs"),
-				NumJobs: b.opts.NumBuildJobs,
-			},
-			Stdout:          b.opts.BuildStdout,
-			Stderr:          b.opts.BuildStderr,
-			FindRuntimeDeps: true,
-		})
+	// Each variant is built in its own build directory, so the variants
+	// can be built concurrently
+	return b.buildVariantsInParallel(configureVariants, b.buildVariantCMake)
+}
+
+func (b *libfuzzerBundler) buildVariantCMake(ctx context.Context, variant configureVariant, stdout, stderr io.Writer) ([]*build.CBuildResult, error) {
+	builder, err := cmake.NewBuilder(&cmake.BuilderOptions{
+		ProjectDir: b.opts.ProjectDir,
+		Args:       b.opts.BuildSystemArgs,
+		Sanitizers: variant.Sanitizers,
+		Engine:     variant.Engine,
+		Parallel: cmake.ParallelOptions{
+			Enabled: viper.IsSet("build-jobs"),
+			NumJobs: b.opts.NumBuildJobs,
+		},
+		Stdout:          stdout,
+		Stderr:          stderr,
+		Context:         ctx,
+		FindRuntimeDeps: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = builder.Configure()
+	if err != nil {
+		return nil, err
+	}
+
+	var fuzzTests []string
+	if len(b.opts.FuzzTests) == 0 {
+		fuzzTests, err = builder.ListFuzzTests()
 		if err != nil {
 			return nil, err
 		}
+	} else {
+		fuzzTests = b.opts.FuzzTests
+	}
+
+	// The fuzz tests passed to builder.Build must not contain
+	// duplicates, which is ensured by builder.ListFuzzTests()
+	// and the Opts.Validate() function.
+	return builder.Build(fuzzTests)
+}
+
+func (b *libfuzzerBundler) buildAllVariantsMeson(configureVariants []configureVariant) ([]*build.CBuildResult, error) {
+	// Each variant is built in its own build directory, so the variants
+	// can be built concurrently
+	return b.buildVariantsInParallel(configureVariants, b.buildVariantMeson)
+}
+
+func (b *libfuzzerBundler) buildVariantMeson(ctx context.Context, variant configureVariant, stdout, stderr io.Writer) ([]*build.CBuildResult, error) {
+	builder, err := meson.NewBuilder(&meson.BuilderOptions{
+		ProjectDir: b.opts.ProjectDir,
+		Args:       b.opts.BuildSystemArgs,
+		Sanitizers: variant.Sanitizers,
+		Parallel: meson.ParallelOptions{
+			Enabled: viper.IsSet("build-jobs"),
+			NumJobs: b.opts.NumBuildJobs,
+		},
+		Stdout:          stdout,
+		Stderr:          stderr,
+		Context:         ctx,
+		FindRuntimeDeps: true,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		b.printBuildingMsg(variant)
+	err = builder.Configure()
+	if err != nil {
+		return nil, err
+	}
 
-		err = builder.Configure()
+	var fuzzTests []string
+	if len(b.opts.FuzzTests) == 0 {
+		fuzzTests, err = builder.ListFuzzTests()
 		if err != nil {
 			return nil, err
 		}
+	} else {
+		fuzzTests = b.opts.FuzzTests
+	}
 
-		var fuzzTests []string
-		if len(b.opts.FuzzTests) == 0 {
-			fuzzTests, err = builder.ListFuzzTests()
+	return builder.Build(fuzzTests)
+}
+
+// buildVariantsInParallel builds the variants concurrently, with at
+// most maxParallelVariantBuilds builds running at the same time. The
+// output of each build is prefixed with the variant, so that the
+// interleaved output of the builds can be told apart. If a build
+// fails, no further builds are started, the running builds are
+// canceled and the first error is returned.
+func (b *libfuzzerBundler) buildVariantsInParallel(
+	configureVariants []configureVariant,
+	buildVariant func(ctx context.Context, variant configureVariant, stdout, stderr io.Writer) ([]*build.CBuildResult, error),
+) ([]*build.CBuildResult, error) {
+	if len(configureVariants) == 1 || maxParallelVariantBuilds <= 1 {
+		var allResults []*build.CBuildResult
+		for _, variant := range configureVariants {
+			b.printBuildingMsg(variant, "")
+			results, err := buildVariant(context.Background(), variant, b.opts.BuildStdout, b.opts.BuildStderr)
 			if err != nil {
 				return nil, err
 			}
-		} else {
-			fuzzTests = b.opts.FuzzTests
-		}
+			allResults = append(allResults, results...)
+		}
+		return allResults, nil
+	}
+
+	// All prefix writers share a mutex, so that lines written by
+	// concurrent builds don't get mixed up
+	var mutex sync.Mutex
+	variantResults := make([][]*build.CBuildResult, len(configureVariants))
+	routines, routinesCtx := errgroup.WithContext(context.Background())
+	routines.SetLimit(maxParallelVariantBuilds)
+	for i, variant := range configureVariants {
+		i, variant := i, variant
+		routines.Go(func() error {
+			// Don't start any more builds if a build failed
+			if routinesCtx.Err() != nil {
+				return nil
+			}
 
-		// The fuzz tests passed to builder.Build must not contain
-		// duplicates, which is ensured by builder.ListFuzzTests()
-		// and the Opts.Validate() function.
-		results, err := builder.Build(fuzzTests)
-		if err != nil {
-			return nil, err
-		}
-		allResults = append(allResults, results...)
+			prefix := variantPrefix(variant)
+			stdout := newPrefixWriter(b.opts.BuildStdout, prefix, &mutex)
+			stderr := newPrefixWriter(b.opts.BuildStderr, prefix, &mutex)
+			defer stdout.Flush()
+			defer stderr.Flush()
+
+			b.printBuildingMsg(variant, prefix)
+			// The build commands are killed if another build fails
+			results, err := buildVariant(routinesCtx, variant, stdout, stderr)
+			if err != nil {
+				return errors.WithMessagef(err, "Failed to build for %s", variantDisplayString(variant))
+			}
+			variantResults[i] = results
+			return nil
+		})
+	}
+	err := routines.Wait()
+	if err != nil {
+		return nil, err
 	}
 
+	var allResults []*build.CBuildResult
+	for _, results := range variantResults {
+		allResults = append(allResults, results...)
+	}
 	return allResults, nil
 }
 
-func (b *libfuzzerBundler) printBuildingMsg(variant configureVariant) {
-	var typeDisplayString string
-	if isCoverageBuild(variant.Sanitizers) {
-		typeDisplayString = "coverage"
-	} else {
-		typeDisplayString = "fuzzing"
+// printBuildingMsg prints which variant is being built. If the build
+// output of the variant is prefixed, the prefix is printed as well.
+func (b *libfuzzerBundler) printBuildingMsg(variant configureVariant, prefix string) {
+	if prefix != "" {
+		log.Infof("Building for %s (output prefixed with %q)...", variantDisplayString(variant), strings.TrimSpace(prefix))
+		return
 	}
+	log.Infof("Building for %s...", variantDisplayString(variant))
+}
 
-	log.Infof("Building for %s...", typeDisplayString)
+func variantPrefix(variant configureVariant) string {
+	return fmt.Sprintf("[%s] ", strings.Join(variant.Sanitizers, "+"))
+}
+
+func variantDisplayString(variant configureVariant) string {
+	if isCoverageBuild(variant.Sanitizers) {
+		return "coverage"
+	} else if build.IsSanitizerVariant(variant.Sanitizers) {
+		return fmt.Sprintf("fuzzing with %s sanitizer", variant.Sanitizers[0])
+	}
+	return "fuzzing"
 }
 
 func (b *libfuzzerBundler) buildAllVariantsOther(configureVariants []configureVariant) ([]*build.CBuildResult, error) {
@@ -242,56 +511,125 @@ func (b *libfuzzerBundler) buildAllVariantsOther(configureVariants []configureVa
 			"These arguments are ignored: %s", strings.Join(b.opts.BuildSystemArgs, " "))
 	}
 
-	var results []*build.CBuildResult
-	for _, variant := range configureVariants {
-		builder, err := other.NewBuilder(&other.BuilderOptions{
-			ProjectDir:   b.opts.ProjectDir,
-			BuildCommand: b.opts.BuildCommand,
-			CleanCommand: b.opts.CleanCommand,
-			Sanitizers:   variant.Sanitizers,
-			Stdout:       b.opts.BuildStdout,
-			Stderr:       b.opts.BuildStderr,
-		})
+	if len(b.opts.FuzzTests) == 0 {
+		// We panic here instead of returning an error because it's a
+		// programming error if this function was called without any
+		// fuzz tests, that case should have been handled in the
+		// bundle function.
+		panic("No fuzz tests specified")
+	}
+
+	// The build command produces the artifacts of all variants in the
+	// same location, so in order to build the variants concurrently,
+	// all variants but the first one are built in copies of the
+	// project directory. The copies are created before any build
+	// starts, so that they don't contain artifacts of the first one.
+	workDirs := map[string]string{}
+	if len(configureVariants) > 1 && maxParallelVariantBuilds > 1 {
+		// The build command is executed in the current working
+		// directory, so we execute it in the same directory relative
+		// to the copy of the project directory
+		wd, err := os.Getwd()
 		if err != nil {
-			return nil, err
-		}
-
-		b.printBuildingMsg(variant)
-
-		if len(b.opts.FuzzTests) == 0 {
-			// We panic here instead of returning an error because it's a
-			// programming error if this function was called without any
-			// fuzz tests, that case should have been handled in the
-			// Opts.Validate function.
-			panic("No fuzz tests specified")
+			return nil, errors.WithStack(err)
 		}
-
-		if err := builder.Clean(); err != nil {
-			return nil, err
-		}
-
-		for _, fuzzTest := range b.opts.FuzzTests {
-			result, err := builder.Build(fuzzTest)
+		relWorkDir := "."
+		if isBelow, _ := fileutil.IsBelow(wd, b.opts.ProjectDir); isBelow {
+			relWorkDir, err = filepath.Rel(b.opts.ProjectDir, wd)
 			if err != nil {
-				return nil, err
+				return nil, errors.WithStack(err)
 			}
-
-			// To avoid that subsequent builds overwrite the artifacts
-			// from this build, we copy them to a temporary directory
-			// and adjust the paths in the build.CBuildResult struct
-			tempDir := filepath.Join(b.opts.tempDir, fuzzTestPrefix(result))
-			err = b.copyArtifactsToTempdir(result, tempDir)
+		}
+		for _, variant := range configureVariants[1:] {
+			copyDir := b.projectCopyDir(variant)
+			err := copyProjectDir(b.opts.ProjectDir, copyDir)
 			if err != nil {
 				return nil, err
 			}
+			workDirs[strings.Join(variant.Sanitizers, "+")] = filepath.Join(copyDir, relWorkDir)
+		}
+	}
+
+	return b.buildVariantsInParallel(configureVariants, func(ctx context.Context, variant configureVariant, stdout, stderr io.Writer) ([]*build.CBuildResult, error) {
+		return b.buildVariantOther(ctx, variant, workDirs[strings.Join(variant.Sanitizers, "+")], stdout, stderr)
+	})
+}
+
+// buildVariantOther builds all fuzz tests for the variant in the
+// specified copy of the project directory or, if workDir is empty, in
+// the current working directory.
+func (b *libfuzzerBundler) buildVariantOther(ctx context.Context, variant configureVariant, workDir string, stdout, stderr io.Writer) ([]*build.CBuildResult, error) {
+	builder, err := other.NewBuilder(&other.BuilderOptions{
+		ProjectDir:   b.opts.ProjectDir,
+		BuildCommand: b.opts.BuildCommand,
+		CleanCommand: b.opts.CleanCommand,
+		Sanitizers:   variant.Sanitizers,
+		Stdout:       stdout,
+		Stderr:       stderr,
+		WorkDir:      workDir,
+		Context:      ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := builder.Clean(); err != nil {
+		return nil, err
+	}
 
-			results = append(results, result)
+	var results []*build.CBuildResult
+	for _, fuzzTest := range b.opts.FuzzTests {
+		result, err := builder.Build(fuzzTest)
+		if err != nil {
+			return nil, err
+		}
+		if workDir != "" {
+			// The source paths in the debug info are relative to the
+			// copy of the project directory
+			result.ProjectDir = b.projectCopyDir(variant)
+		}
+
+		// To avoid that subsequent builds overwrite the artifacts
+		// from this build, we copy them to a temporary directory
+		// and adjust the paths in the build.CBuildResult struct
+		tempDir := filepath.Join(b.opts.tempDir, b.fuzzTestPrefix(result))
+		err = b.copyArtifactsToTempdir(result, tempDir)
+		if err != nil {
+			return nil, err
 		}
+
+		results = append(results, result)
 	}
 
 	return results, nil
 }
 
+// projectCopyDir returns the directory to which the project directory
+// is copied to build the variant concurrently with other variants.
+func (b *libfuzzerBundler) projectCopyDir(variant configureVariant) string {
+	return filepath.Join(b.opts.tempDir, "project-"+strings.Join(variant.Sanitizers, "+"))
+}
+
+// copyProjectDir copies the project directory, apart from the
+// directories created by cifuzz, to the specified directory. The
+// modification times are preserved, so that the build system of the
+// project can tell which files changed.
+func copyProjectDir(projectDir string, dest string) error {
+	err := copy.Copy(projectDir, dest, copy.Options{
+		OnSymlink: func(string) copy.SymlinkAction {
+			return copy.Shallow
+		},
+		Skip: func(info os.FileInfo, src, _ string) (bool, error) {
+			return info.IsDir() && strings.HasPrefix(info.Name(), ".cifuzz"), nil
+		},
+		PreserveTimes: true,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to copy project directory to %s", dest)
+	}
+	return nil
+}
+
 func (b *libfuzzerBundler) copyArtifactsToTempdir(buildResult *build.CBuildResult, tempDir string) error {
 	fuzzTestExecutableAbsPath := buildResult.Executable
 	isBelow, err := fileutil.IsBelow(fuzzTestExecutableAbsPath, buildResult.BuildDir)
@@ -360,6 +698,11 @@ func (b *libfuzzerBundler) checkDependencies() error {
 	switch b.opts.BuildSystem {
 	case config.BuildSystemCMake:
 		deps = []dependencies.Key{dependencies.Clang, dependencies.CMake}
+		if b.fuzzingEngine() == config.AFLPlusPlus {
+			deps = append(deps, dependencies.AFLFuzz)
+		}
+	case config.BuildSystemMeson:
+		deps = []dependencies.Key{dependencies.Clang, dependencies.Meson}
 	case config.BuildSystemOther:
 		deps = []dependencies.Key{dependencies.Clang}
 	}
@@ -382,7 +725,7 @@ func (b *libfuzzerBundler) assembleArtifacts(buildResult *build.CBuildResult) (
 
 	// Add all build artifacts under a subdirectory of the fuzz test base path so that these files don't clash with
 	// seeds and dictionaries.
-	buildArtifactsPrefix := filepath.Join(fuzzTestPrefix(buildResult), "bin")
+	buildArtifactsPrefix := filepath.Join(b.fuzzTestPrefix(buildResult), "bin")
 
 	// Add the fuzz test executable.
 	ok, err := fileutil.IsBelow(fuzzTestExecutableAbsPath, buildResult.BuildDir)
@@ -494,7 +837,7 @@ depsLoop:
 		// to the library search path in the run environment.
 		// Note: Since all libraries are placed in a single directory, we have to ensure that basenames of external
 		// libraries are unique. If they aren't, we report a conflict.
-		externalLibrariesPrefix = filepath.Join(fuzzTestPrefix(buildResult), "external_libs")
+		externalLibrariesPrefix = filepath.Join(b.fuzzTestPrefix(buildResult), "external_libs")
 		archivePath := filepath.Join(externalLibrariesPrefix, filepath.Base(dep))
 		if b.archiveWriter.HasFileEntry(archivePath) {
 			err = errors.Errorf(
@@ -525,7 +868,7 @@ depsLoop:
 	var archiveDict string
 	if b.opts.Dictionary != "" {
 		log.Debugf("Adding dictionary %s", b.opts.Dictionary)
-		archiveDict = filepath.Join(fuzzTestPrefix(buildResult), "dict")
+		archiveDict = filepath.Join(b.fuzzTestPrefix(buildResult), "dict")
 		err = b.archiveWriter.WriteFile(archiveDict, b.opts.Dictionary)
 		if err != nil {
 			return
@@ -535,7 +878,7 @@ depsLoop:
 	// Add seeds from user-specified seed corpus dirs (if any) and the
 	// default seed corpus (if it exists) to the seeds directory in the
 	// archive
-	seedCorpusDirs := b.opts.SeedCorpusDirs
+	seedCorpusDirs := append([]string{}, b.opts.SeedCorpusDirs...)
 	exists, err := fileutil.Exists(buildResult.SeedCorpus)
 	if err != nil {
 		return
@@ -544,9 +887,18 @@ depsLoop:
 		log.Debugf("Adding user-provided seeds to seed corpus from %s", seedCorpusDirs)
 		seedCorpusDirs = append([]string{buildResult.SeedCorpus}, seedCorpusDirs...)
 	}
+	// Add the corpus imported via --corpus-from (if any)
+	importedCorpus, err := importedCorpusDir(b.opts, buildResult.Name)
+	if err != nil {
+		return
+	}
+	if importedCorpus != "" {
+		log.Debugf("Adding imported corpus %s", importedCorpus)
+		seedCorpusDirs = append(seedCorpusDirs, importedCorpus)
+	}
 	var archiveSeedsDir string
 	if len(seedCorpusDirs) > 0 {
-		archiveSeedsDir = filepath.Join(fuzzTestPrefix(buildResult), "seeds")
+		archiveSeedsDir = filepath.Join(b.fuzzTestPrefix(buildResult), "seeds")
 
 		err = prepareSeeds(seedCorpusDirs, archiveSeedsDir, b.archiveWriter)
 		if err != nil {
@@ -599,7 +951,11 @@ depsLoop:
 			continue
 		}
 		fuzzer := baseFuzzerInfo
-		fuzzer.Engine = "LIBFUZZER"
+		if b.fuzzingEngine() == config.AFLPlusPlus {
+			fuzzer.Engine = "AFLPLUSPLUS"
+		} else {
+			fuzzer.Engine = "LIBFUZZER"
+		}
 		fuzzer.Sanitizer = strings.ToUpper(sanitizer)
 		fuzzers = append(fuzzers, &fuzzer)
 	}
@@ -609,12 +965,12 @@ depsLoop:
 
 // fuzzTestPrefix returns the path in the resulting artifact archive under which fuzz test specific files should be
 // added.
-func fuzzTestPrefix(buildResult *build.CBuildResult) string {
+func (b *libfuzzerBundler) fuzzTestPrefix(buildResult *build.CBuildResult) string {
 	sanitizerSegment := strings.Join(buildResult.Sanitizers, "+")
 	if sanitizerSegment == "" {
 		sanitizerSegment = "none"
 	}
-	engine := "libfuzzer"
+	engine := string(b.fuzzingEngine())
 	if isCoverageBuild(buildResult.Sanitizers) {
 		// The backend currently only passes the corpus directory (rather than the files contained in it) as
 		// an argument to the coverage binary if it finds the substring "replayer/coverage" in the fuzz test archive
@@ -626,6 +982,16 @@ func fuzzTestPrefix(buildResult *build.CBuildResult) string {
 	return filepath.Join(engine, sanitizerSegment, buildResult.Name)
 }
 
+// fuzzingEngine returns the engine the fuzzing variants are built for.
+// Coverage variants are always built with libFuzzer, because the
+// coverage runs use libFuzzer's merge mode.
+func (b *libfuzzerBundler) fuzzingEngine() config.Engine {
+	if b.opts.Engine == "" {
+		return config.Libfuzzer
+	}
+	return b.opts.Engine
+}
+
 func isCoverageBuild(sanitizers []string) bool {
 	return len(sanitizers) == 1 && sanitizers[0] == "coverage"
 }
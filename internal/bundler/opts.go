@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"runtime"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 
+	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/util/sliceutil"
@@ -23,13 +25,24 @@ type Opts struct {
 	Commit          string        `mapstructure:"commit"`
 	Dictionary      string        `mapstructure:"dict"`
 	DockerImage     string        `mapstructure:"docker-image"`
+	Engine          config.Engine `mapstructure:"engine"`
 	EngineArgs      []string      `mapstructure:"engine-args"`
 	Env             []string      `mapstructure:"env"`
+	Sanitizers      []string      `mapstructure:"sanitizers"`
 	SeedCorpusDirs  []string      `mapstructure:"seed-corpus-dirs"`
 	Timeout         time.Duration `mapstructure:"timeout"`
 	ProjectDir      string        `mapstructure:"project-dir"`
 	ConfigDir       string        `mapstructure:"config-dir"`
 	AdditionalFiles []string      `mapstructure:"add"`
+	CorpusFrom      string        `mapstructure:"corpus-from"`
+	BuildCache      bool          `mapstructure:"build-cache"`
+	// Add the node_modules of Node.js projects to the bundle, so that
+	// the dependencies don't have to be installed when the fuzz tests
+	// are executed
+	VendorNodeModules bool `mapstructure:"vendor-node-modules"`
+	// The fuzz tests of projects with build system type "other", which
+	// are bundled if no fuzz tests are specified
+	FuzzTestPatterns []string `mapstructure:"fuzz-tests"`
 
 	// Fields which are not configurable via viper (i.e. via cifuzz.yaml
 	// and CIFUZZ_* environment variables), by setting
@@ -43,7 +56,8 @@ type Opts struct {
 	BuildStdout     io.Writer `mapstructure:"-"`
 	BuildStderr     io.Writer `mapstructure:"-"`
 
-	tempDir string `mapstructure:"-"`
+	tempDir           string `mapstructure:"-"`
+	importedCorpusDir string `mapstructure:"-"`
 
 	ResolveSourceFilePath bool
 	BundleBuildLogFile    string
@@ -60,6 +74,19 @@ func (opts *Opts) Validate() error {
 		return err
 	}
 
+	if opts.CorpusFrom != "" {
+		// Check if the corpus archive (or the directory containing the
+		// corpora) exists and can be accessed
+		_, err = os.Stat(opts.CorpusFrom)
+		if err != nil {
+			if os.IsNotExist(err) {
+				msg := fmt.Sprintf("The corpus '%s' does not exist", opts.CorpusFrom)
+				return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+			}
+			return errors.Wrapf(err, "Failed to access corpus %s", opts.CorpusFrom)
+		}
+	}
+
 	if opts.Dictionary != "" {
 		// Check if the dictionary exists and can be accessed
 		_, err = os.Stat(opts.Dictionary)
@@ -94,14 +121,16 @@ func (opts *Opts) Validate() error {
 			msg := "Flag \"build-command\" must be set when using build system type \"other\""
 			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
 		}
-		// To build with other build systems, the fuzz tests need to be
-		// specified (because there is no way for us to figure out which
-		// fuzz tests exist).
-		if len(opts.FuzzTests) == 0 {
-			msg := `At least one <fuzz test> argument must be provided when using the build
-system type "other"`
-			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
-		}
+	}
+
+	err = config.ValidateEngine(opts.Engine, opts.BuildSystem)
+	if err != nil {
+		return err
+	}
+
+	err = opts.validateSanitizers()
+	if err != nil {
+		return err
 	}
 
 	if opts.Timeout != 0 && opts.Timeout < time.Second {
@@ -130,3 +159,37 @@ system type "other"`
 
 	return nil
 }
+
+// validateSanitizers checks that the sanitizers for which additional
+// fuzzing variants are built are supported in this configuration.
+func (opts *Opts) validateSanitizers() error {
+	opts.Sanitizers = sliceutil.RemoveDuplicates(opts.Sanitizers)
+	if len(opts.Sanitizers) == 0 {
+		return nil
+	}
+
+	if !sliceutil.Contains([]string{config.BuildSystemCMake, config.BuildSystemMeson, config.BuildSystemBazel, config.BuildSystemOther}, opts.BuildSystem) {
+		msg := fmt.Sprintf("Flag \"sanitizer\" is not supported for build system type %q", opts.BuildSystem)
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	if opts.Engine == config.AFLPlusPlus {
+		msg := "Flag \"sanitizer\" is only supported with the libFuzzer engine"
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	for _, sanitizer := range opts.Sanitizers {
+		if !sliceutil.Contains(build.SanitizerVariants, sanitizer) {
+			msg := fmt.Sprintf("Invalid sanitizer %q, valid values are: %s",
+				sanitizer, strings.Join(build.SanitizerVariants, ", "))
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if sanitizer == "memory" && runtime.GOOS != "linux" {
+			return cmdutils.WrapIncorrectUsageError(errors.New("MemorySanitizer is only supported on Linux"))
+		}
+		if sanitizer == "thread" && runtime.GOOS == "windows" {
+			return cmdutils.WrapIncorrectUsageError(errors.New("ThreadSanitizer is not supported on Windows"))
+		}
+	}
+
+	return nil
+}
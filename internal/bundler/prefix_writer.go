@@ -0,0 +1,63 @@
+package bundler
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// prefixWriter prefixes each line written to it before writing it to
+// the underlying writer. Only complete lines are written to the
+// underlying writer, so that lines written by multiple prefixWriters
+// sharing the same mutex are not mixed up.
+type prefixWriter struct {
+	w      io.Writer
+	prefix []byte
+	mutex  *sync.Mutex
+	buf    []byte
+}
+
+func newPrefixWriter(w io.Writer, prefix string, mutex *sync.Mutex) *prefixWriter {
+	return &prefixWriter{w: w, prefix: []byte(prefix), mutex: mutex}
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	i := bytes.LastIndexByte(w.buf, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+	err := w.writeLines(w.buf[:i+1])
+	w.buf = w.buf[i+1:]
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes the last line, if it's not terminated by a newline
+func (w *prefixWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	err := w.writeLines(append(w.buf, '\n'))
+	w.buf = nil
+	return err
+}
+
+func (w *prefixWriter) writeLines(lines []byte) error {
+	var out []byte
+	for _, line := range bytes.SplitAfter(lines, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		out = append(out, w.prefix...)
+		out = append(out, line...)
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	_, err := w.w.Write(out)
+	return err
+}
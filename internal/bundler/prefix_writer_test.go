@@ -0,0 +1,32 @@
+package bundler
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	var mutex sync.Mutex
+	w1 := newPrefixWriter(&out, "[address+undefined] ", &mutex)
+	w2 := newPrefixWriter(&out, "[coverage] ", &mutex)
+
+	_, err := w1.Write([]byte("foo\nba"))
+	require.NoError(t, err)
+	_, err = w2.Write([]byte("baz\n"))
+	require.NoError(t, err)
+	_, err = w1.Write([]byte("r\nqux"))
+	require.NoError(t, err)
+	require.NoError(t, w1.Flush())
+	require.NoError(t, w2.Flush())
+
+	assert.Equal(t, `[address+undefined] foo
+[coverage] baz
+[address+undefined] bar
+[address+undefined] qux
+`, out.String())
+}
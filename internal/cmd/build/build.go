@@ -0,0 +1,655 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/build/bazel"
+	"code-intelligence.com/cifuzz/internal/build/cmake"
+	"code-intelligence.com/cifuzz/internal/build/java"
+	"code-intelligence.com/cifuzz/internal/build/java/gradle"
+	"code-intelligence.com/cifuzz/internal/build/java/maven"
+	"code-intelligence.com/cifuzz/internal/build/meson"
+	"code-intelligence.com/cifuzz/internal/build/other"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/completion"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/dependencies"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/util/fileutil"
+	"code-intelligence.com/cifuzz/util/sliceutil"
+)
+
+// The aliases which can be used for the sanitizers in the --sanitizers
+// flag
+var sanitizerAliases = map[string]string{
+	"asan":  "address",
+	"ubsan": "undefined",
+	"msan":  "memory",
+	"tsan":  "thread",
+}
+
+type options struct {
+	BuildSystem  string        `mapstructure:"build-system"`
+	BuildCommand string        `mapstructure:"build-command"`
+	CleanCommand string        `mapstructure:"clean-command"`
+	NumBuildJobs uint          `mapstructure:"build-jobs"`
+	Engine       config.Engine `mapstructure:"engine"`
+	ProjectDir   string        `mapstructure:"project-dir"`
+	ConfigDir    string        `mapstructure:"config-dir"`
+	// Write a compilation database for projects with build system
+	// type "other"
+	CompileCommands bool `mapstructure:"compile-commands"`
+	// The fuzz tests of projects with build system type "other", which
+	// are built if no fuzz tests are specified
+	FuzzTestPatterns []string `mapstructure:"fuzz-tests"`
+
+	// The sanitizers are not configurable via cifuzz.yaml, because the
+	// "sanitizers" setting specifies the additional sanitizer variants
+	// which are bundled
+	Sanitizers []string `mapstructure:"-"`
+	FuzzTests  []string `mapstructure:"-"`
+	ArgsToPass []string `mapstructure:"-"`
+}
+
+func (opts *options) Validate() error {
+	err := config.ValidateBuildSystem(opts.BuildSystem)
+	if err != nil {
+		return err
+	}
+
+	switch opts.BuildSystem {
+	case config.BuildSystemCMake, config.BuildSystemMeson, config.BuildSystemBazel, config.BuildSystemOther,
+		config.BuildSystemMaven, config.BuildSystemGradle:
+	default:
+		return errors.Errorf(config.NotSupportedErrorMessage("build", opts.BuildSystem))
+	}
+
+	err = config.ValidateEngine(opts.Engine, opts.BuildSystem)
+	if err != nil {
+		return err
+	}
+
+	err = opts.validateSanitizers()
+	if err != nil {
+		return err
+	}
+
+	if opts.BuildSystem == config.BuildSystemBazel {
+		if len(opts.FuzzTests) == 0 {
+			msg := `At least one <fuzz test> argument must be provided`
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		patterns := opts.FuzzTests
+		opts.FuzzTests, err = cmdutils.EvaluateBazelTargetPatterns(patterns)
+		if err != nil {
+			return err
+		}
+		if len(opts.FuzzTests) == 0 {
+			return errors.Errorf("No valid targets found for patterns: %s", strings.Join(patterns, " "))
+		}
+	}
+
+	if opts.BuildSystem == config.BuildSystemOther {
+		if opts.BuildCommand == "" {
+			msg := "Flag \"build-command\" must be set when using build system type \"other\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if len(opts.FuzzTests) == 0 {
+			opts.FuzzTests, err = other.ListFuzzTests(opts.ProjectDir, opts.FuzzTestPatterns)
+			if err != nil {
+				return err
+			}
+			if len(opts.FuzzTests) == 0 {
+				msg := `No fuzz tests found. At least one <fuzz test> argument must be provided
+or the fuzz tests must be listed in the "fuzz-tests" setting when using
+the build system type "other"`
+				return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateSanitizers resolves the aliases of the sanitizers and checks
+// that they can be used together with the build system.
+func (opts *options) validateSanitizers() error {
+	var sanitizers []string
+	for _, sanitizer := range opts.Sanitizers {
+		if alias, ok := sanitizerAliases[sanitizer]; ok {
+			sanitizer = alias
+		}
+		sanitizers = append(sanitizers, sanitizer)
+	}
+	sanitizers = sliceutil.RemoveDuplicates(sanitizers)
+
+	isJava := opts.BuildSystem == config.BuildSystemMaven || opts.BuildSystem == config.BuildSystemGradle
+	if isJava {
+		// The sanitizers of Jazzer are enabled at runtime
+		opts.Sanitizers = nil
+		return nil
+	}
+
+	switch {
+	case len(sanitizers) == 1 && sanitizers[0] == "none":
+		// Only CMake and Meson support building without sanitizers
+		if opts.BuildSystem != config.BuildSystemCMake && opts.BuildSystem != config.BuildSystemMeson {
+			msg := fmt.Sprintf("Building without sanitizers is not supported for build system type %q", opts.BuildSystem)
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		opts.Sanitizers = []string{}
+	case sliceutil.Equal(sanitizers, []string{"address", "undefined"}),
+		sliceutil.Equal(sanitizers, []string{"undefined", "address"}):
+		// MSVC doesn't support UBSan
+		if runtime.GOOS == "windows" {
+			opts.Sanitizers = []string{"address"}
+		} else {
+			opts.Sanitizers = []string{"address", "undefined"}
+		}
+	case len(sanitizers) == 1 && sanitizers[0] == "coverage":
+		if runtime.GOOS == "windows" {
+			return cmdutils.WrapIncorrectUsageError(errors.New("Coverage builds are not supported on Windows"))
+		}
+		opts.Sanitizers = sanitizers
+	case len(sanitizers) == 1 && sliceutil.Contains(build.SanitizerVariants, sanitizers[0]):
+		if sanitizers[0] == "memory" && runtime.GOOS != "linux" {
+			return cmdutils.WrapIncorrectUsageError(errors.New("MemorySanitizer is only supported on Linux"))
+		}
+		if sanitizers[0] == "thread" && runtime.GOOS == "windows" {
+			return cmdutils.WrapIncorrectUsageError(errors.New("ThreadSanitizer is not supported on Windows"))
+		}
+		opts.Sanitizers = sanitizers
+	default:
+		msg := fmt.Sprintf(`Invalid sanitizers %q, valid values are "address,undefined", "memory", "thread",
+"coverage" and "none"`, strings.Join(opts.Sanitizers, ","))
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	if opts.Engine == config.AFLPlusPlus && !sliceutil.Equal(opts.Sanitizers, []string{"address", "undefined"}) {
+		msg := "Flag \"sanitizers\" is only supported with the libFuzzer engine"
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	return nil
+}
+
+// buildResult is the JSON description of a built fuzz test
+type buildResult struct {
+	Name            string   `json:"name,omitempty"`
+	Sanitizers      []string `json:"sanitizers,omitempty"`
+	Executable      string   `json:"executable,omitempty"`
+	TargetClass     string   `json:"target_class,omitempty"`
+	BuildDir        string   `json:"build_dir,omitempty"`
+	SeedCorpus      string   `json:"seed_corpus,omitempty"`
+	GeneratedCorpus string   `json:"generated_corpus,omitempty"`
+	Dictionary      string   `json:"dictionary,omitempty"`
+	RuntimeDeps     []string `json:"runtime_deps,omitempty"`
+}
+
+type buildCmd struct {
+	*cobra.Command
+	opts *options
+
+	// The build output is printed to stderr, so that stdout only
+	// contains the JSON output
+	buildStdout io.Writer
+	buildStderr io.Writer
+}
+
+func New() *cobra.Command {
+	return newWithOptions(&options{})
+}
+
+func newWithOptions(opts *options) *cobra.Command {
+	var bindFlags func()
+	cmd := &cobra.Command{
+		Use:   "build [flags] [<fuzz test>]... [--] [<build system arg>...]",
+		Short: "Build fuzz tests and print a JSON description of the results",
+		Long: `This command builds fuzz tests with the same configuration as
+'cifuzz run' and 'cifuzz bundle' and prints a JSON array to stdout which
+describes the build result of each fuzz test, including the path of the
+executable, the seed corpus, the dictionary and the runtime dependencies.
+The output of the build itself is printed to stderr.
+
+The --sanitizers flag selects the build configuration of C/C++ fuzz
+tests, which is one of:
+
+  address,undefined  (the default, aliases "asan" and "ubsan")
+  memory             (alias "msan", only on Linux)
+  thread             (alias "tsan")
+  coverage
+  none               (only for CMake and Meson)
+
+For CMake and Meson projects, all fuzz tests of the project are built
+if no fuzz tests are specified. For build system type "other", the fuzz
+tests listed in the "fuzz-tests" setting in cifuzz.yaml are built in
+that case.
+
+For Maven and Gradle projects, the project is built once and the class
+path is printed as the runtime dependencies. If fuzz tests are
+specified, their classes are printed as the target classes.
+
+Additional arguments for the build system can be passed after a "--",
+for example:
+
+    cifuzz build my_fuzz_test -- -G Ninja
+
+`,
+		ValidArgsFunction: completion.ValidFuzzTests,
+		Args:              cobra.ArbitraryArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Bind viper keys to flags. We can't do this in the New
+			// function, because that would re-bind viper keys which
+			// were bound to the flags of other commands before.
+			bindFlags()
+
+			if cmd.ArgsLenAtDash() != -1 {
+				opts.ArgsToPass = args[cmd.ArgsLenAtDash():]
+				args = args[:cmd.ArgsLenAtDash()]
+			}
+
+			err := config.FindAndParseProjectConfig(opts)
+			if err != nil {
+				return err
+			}
+
+			opts.FuzzTests = args
+			return opts.Validate()
+		},
+		RunE: func(c *cobra.Command, args []string) error {
+			cmd := buildCmd{
+				Command:     c,
+				opts:        opts,
+				buildStdout: c.ErrOrStderr(),
+				buildStderr: c.ErrOrStderr(),
+			}
+			return cmd.run()
+		},
+	}
+
+	// Note: If a flag should be configurable via viper as well (i.e.
+	//       via cifuzz.yaml and CIFUZZ_* environment variables), bind
+	//       it to viper in the PreRun function.
+	bindFlags = cmdutils.AddFlags(cmd,
+		cmdutils.AddBuildCommandFlag,
+		cmdutils.AddCleanCommandFlag,
+		cmdutils.AddBuildJobsFlag,
+		cmdutils.AddCompileCommandsFlag,
+		cmdutils.AddEngineFlag,
+		cmdutils.AddProjectDirFlag,
+	)
+	cmd.Flags().StringSliceVar(&opts.Sanitizers, "sanitizers", []string{"address", "undefined"},
+		"Comma-separated list of `sanitizers` to build the C/C++ fuzz tests with,\n"+
+			"see the description above for the supported values.")
+
+	return cmd
+}
+
+func (c *buildCmd) run() error {
+	err := c.checkDependencies()
+	if err != nil {
+		return err
+	}
+
+	var results []*buildResult
+	switch c.opts.BuildSystem {
+	case config.BuildSystemCMake:
+		results, err = c.buildCMake()
+	case config.BuildSystemMeson:
+		results, err = c.buildMeson()
+	case config.BuildSystemBazel:
+		results, err = c.buildBazel()
+	case config.BuildSystemOther:
+		results, err = c.buildOther()
+	case config.BuildSystemMaven, config.BuildSystemGradle:
+		results, err = c.buildJava()
+	}
+	if err != nil {
+		return err
+	}
+
+	// Always print an array, even if no fuzz tests were built
+	if results == nil {
+		results = []*buildResult{}
+	}
+	out, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = fmt.Fprintln(c.OutOrStdout(), string(out))
+	return errors.WithStack(err)
+}
+
+func (c *buildCmd) buildCMake() ([]*buildResult, error) {
+	builder, err := cmake.NewBuilder(&cmake.BuilderOptions{
+		ProjectDir: c.opts.ProjectDir,
+		Args:       c.opts.ArgsToPass,
+		Sanitizers: c.opts.Sanitizers,
+		Engine:     c.opts.Engine,
+		Parallel: cmake.ParallelOptions{
+			Enabled: viper.IsSet("build-jobs"),
+			NumJobs: c.opts.NumBuildJobs,
+		},
+		Stdout:          c.buildStdout,
+		Stderr:          c.buildStderr,
+		FindRuntimeDeps: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = builder.Configure()
+	if err != nil {
+		return nil, err
+	}
+
+	fuzzTests := c.opts.FuzzTests
+	if len(fuzzTests) == 0 {
+		fuzzTests, err = builder.ListFuzzTests()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cBuildResults, err := builder.Build(sliceutil.RemoveDuplicates(fuzzTests))
+	if err != nil {
+		return nil, err
+	}
+	return cResults(cBuildResults), nil
+}
+
+func (c *buildCmd) buildMeson() ([]*buildResult, error) {
+	builder, err := meson.NewBuilder(&meson.BuilderOptions{
+		ProjectDir: c.opts.ProjectDir,
+		Args:       c.opts.ArgsToPass,
+		Sanitizers: c.opts.Sanitizers,
+		Parallel: meson.ParallelOptions{
+			Enabled: viper.IsSet("build-jobs"),
+			NumJobs: c.opts.NumBuildJobs,
+		},
+		Stdout:          c.buildStdout,
+		Stderr:          c.buildStderr,
+		FindRuntimeDeps: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = builder.Configure()
+	if err != nil {
+		return nil, err
+	}
+
+	fuzzTests := c.opts.FuzzTests
+	if len(fuzzTests) == 0 {
+		fuzzTests, err = builder.ListFuzzTests()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cBuildResults, err := builder.Build(sliceutil.RemoveDuplicates(fuzzTests))
+	if err != nil {
+		return nil, err
+	}
+	return cResults(cBuildResults), nil
+}
+
+func (c *buildCmd) buildBazel() ([]*buildResult, error) {
+	// The artifacts of the cc_fuzz_test targets are extracted from
+	// the archives built by Bazel into this directory, which must
+	// persist after the command exits, so that the printed paths are
+	// valid
+	dirName, err := build.BuildDirName(c.opts.Sanitizers, c.opts.ArgsToPass)
+	if err != nil {
+		return nil, err
+	}
+	artifactsDir := filepath.Join(c.opts.ProjectDir, ".cifuzz-build", "bazel", dirName)
+	err = os.RemoveAll(artifactsDir)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = os.MkdirAll(artifactsDir, 0o755)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	builder, err := bazel.NewBuilder(&bazel.BuilderOptions{
+		ProjectDir: c.opts.ProjectDir,
+		Args:       c.opts.ArgsToPass,
+		Engine:     c.opts.Engine,
+		NumJobs:    c.opts.NumBuildJobs,
+		Stdout:     c.buildStdout,
+		Stderr:     c.buildStderr,
+		TempDir:    artifactsDir,
+		Verbose:    viper.GetBool("verbose"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ccFuzzTests, javaFuzzTests []string
+	for _, fuzzTest := range c.opts.FuzzTests {
+		isJava, err := bazel.IsJavaFuzzTest(fuzzTest)
+		if err != nil {
+			return nil, err
+		}
+		if isJava {
+			javaFuzzTests = append(javaFuzzTests, fuzzTest)
+		} else {
+			ccFuzzTests = append(ccFuzzTests, fuzzTest)
+		}
+	}
+
+	var results []*buildResult
+	if len(ccFuzzTests) > 0 {
+		cBuildResults, err := builder.BuildForBundle(c.opts.Sanitizers, ccFuzzTests)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, cResults(cBuildResults)...)
+	}
+	if len(javaFuzzTests) > 0 {
+		javaBuildResults, err := builder.BuildJava(javaFuzzTests)
+		if err != nil {
+			return nil, err
+		}
+		for i, javaBuildResult := range javaBuildResults {
+			result := newBuildResult(javaBuildResult.BuildResult)
+			result.Name = javaFuzzTests[i]
+			result.TargetClass = javaBuildResult.TargetClass
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+func (c *buildCmd) buildOther() ([]*buildResult, error) {
+	if len(c.opts.ArgsToPass) > 0 {
+		log.Warnf("Passing additional arguments is not supported for build system type \"other\".\n"+
+			"These arguments are ignored: %s", strings.Join(c.opts.ArgsToPass, " "))
+	}
+
+	builder, err := other.NewBuilder(&other.BuilderOptions{
+		ProjectDir:   c.opts.ProjectDir,
+		BuildCommand: c.opts.BuildCommand,
+		CleanCommand: c.opts.CleanCommand,
+		Sanitizers:   c.opts.Sanitizers,
+		Stdout:       c.buildStdout,
+		Stderr:       c.buildStderr,
+
+		CompilationDatabase: c.opts.CompileCommands,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = builder.Clean()
+	if err != nil {
+		return nil, err
+	}
+
+	// The build command might overwrite the artifacts of the previously
+	// built fuzz test, so only a single fuzz test can be built reliably
+	// per invocation if they share build artifacts. We still support
+	// building multiple fuzz tests, because that works for most
+	// projects.
+	var cBuildResults []*build.CBuildResult
+	for _, fuzzTest := range sliceutil.RemoveDuplicates(c.opts.FuzzTests) {
+		cBuildResult, err := builder.Build(fuzzTest)
+		if err != nil {
+			return nil, err
+		}
+		cBuildResults = append(cBuildResults, cBuildResult)
+	}
+	return cResults(cBuildResults), nil
+}
+
+func (c *buildCmd) buildJava() ([]*buildResult, error) {
+	if len(c.opts.ArgsToPass) > 0 {
+		log.Warnf("Passing additional arguments is not supported for %s.\n"+
+			"These arguments are ignored: %s", c.opts.BuildSystem, strings.Join(c.opts.ArgsToPass, " "))
+	}
+
+	if len(c.opts.FuzzTests) == 0 {
+		javaBuildResult, err := c.buildJavaModule("")
+		if err != nil {
+			return nil, err
+		}
+		return []*buildResult{newBuildResult(javaBuildResult)}, nil
+	}
+
+	// All fuzz tests of a module share the class path of the module,
+	// so each module is only built once
+	javaBuildResults := make(map[string]*build.BuildResult)
+	var results []*buildResult
+	for _, fuzzTest := range sliceutil.RemoveDuplicates(c.opts.FuzzTests) {
+		fuzzTest, err := java.ResolveFuzzTest(c.opts.ProjectDir, c.opts.BuildSystem, fuzzTest)
+		if err != nil {
+			return nil, err
+		}
+		module, targetClass := cmdutils.SeparateModuleAndTargetClass(fuzzTest)
+
+		javaBuildResult, found := javaBuildResults[module]
+		if !found {
+			javaBuildResult, err = c.buildJavaModule(module)
+			if err != nil {
+				return nil, err
+			}
+			javaBuildResults[module] = javaBuildResult
+		}
+
+		result := newBuildResult(javaBuildResult)
+		result.Name = fuzzTest
+		result.TargetClass, _, _ = strings.Cut(targetClass, "::")
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// buildJavaModule builds the specified Maven module or Gradle
+// subproject, or the whole project if module is empty
+func (c *buildCmd) buildJavaModule(module string) (*build.BuildResult, error) {
+	if c.opts.BuildSystem == config.BuildSystemMaven {
+		builder, err := maven.NewBuilder(&maven.BuilderOptions{
+			ProjectDir: c.opts.ProjectDir,
+			Module:     module,
+			Parallel: maven.ParallelOptions{
+				Enabled: viper.IsSet("build-jobs"),
+				NumJobs: c.opts.NumBuildJobs,
+			},
+			Stdout: c.buildStdout,
+			Stderr: c.buildStderr,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return builder.Build()
+	}
+
+	builder, err := gradle.NewBuilder(&gradle.BuilderOptions{
+		ProjectDir: c.opts.ProjectDir,
+		Module:     module,
+		Parallel: gradle.ParallelOptions{
+			Enabled: viper.IsSet("build-jobs"),
+			NumJobs: c.opts.NumBuildJobs,
+		},
+		Stdout: c.buildStdout,
+		Stderr: c.buildStderr,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return builder.Build()
+}
+
+func (c *buildCmd) checkDependencies() error {
+	var deps []dependencies.Key
+	compiler := dependencies.Clang
+	if runtime.GOOS == "windows" {
+		compiler = dependencies.VisualStudio
+	}
+
+	switch c.opts.BuildSystem {
+	case config.BuildSystemCMake:
+		deps = []dependencies.Key{dependencies.CMake, compiler}
+	case config.BuildSystemMeson:
+		deps = []dependencies.Key{dependencies.Meson, dependencies.Clang}
+	case config.BuildSystemBazel:
+		deps = []dependencies.Key{dependencies.Bazel}
+	case config.BuildSystemOther:
+		deps = []dependencies.Key{compiler}
+	case config.BuildSystemMaven:
+		deps = []dependencies.Key{dependencies.Java, dependencies.Maven, dependencies.MavenExtension}
+	case config.BuildSystemGradle:
+		deps = []dependencies.Key{dependencies.Java, dependencies.Gradle, dependencies.GradlePlugin}
+	}
+	if c.opts.Engine == config.AFLPlusPlus {
+		deps = append(deps, dependencies.AFLFuzz)
+	}
+	if sliceutil.Equal(c.opts.Sanitizers, []string{"coverage"}) &&
+		(c.opts.BuildSystem == config.BuildSystemBazel || c.opts.BuildSystem == config.BuildSystemOther) {
+		deps = append(deps, dependencies.LLVMCov, dependencies.LLVMProfData)
+	}
+	return dependencies.Check(deps, c.opts.ProjectDir)
+}
+
+func cResults(cBuildResults []*build.CBuildResult) []*buildResult {
+	var results []*buildResult
+	for _, cBuildResult := range cBuildResults {
+		result := newBuildResult(cBuildResult.BuildResult)
+		result.Name = cBuildResult.Name
+		result.Sanitizers = cBuildResult.Sanitizers
+		results = append(results, result)
+	}
+	return results
+}
+
+func newBuildResult(b *build.BuildResult) *buildResult {
+	result := &buildResult{
+		Executable:      b.Executable,
+		BuildDir:        b.BuildDir,
+		GeneratedCorpus: b.GeneratedCorpus,
+		RuntimeDeps:     b.RuntimeDeps,
+	}
+	// The builders return the default locations of the seed corpus
+	// and the dictionary, which only exist if the user created them
+	if exists, _ := fileutil.Exists(b.SeedCorpus); exists {
+		result.SeedCorpus = b.SeedCorpus
+	}
+	if exists, _ := fileutil.Exists(b.Dictionary); exists {
+		result.Dictionary = b.Dictionary
+	}
+	return result
+}
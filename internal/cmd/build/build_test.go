@@ -0,0 +1,96 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/internal/testutil"
+	"code-intelligence.com/cifuzz/pkg/dependencies"
+)
+
+func TestMain(m *testing.M) {
+	viper.Set("verbose", true)
+	m.Run()
+}
+
+func TestBuildCmd_FailsIfNoCIFuzzProject(t *testing.T) {
+	// Create an empty directory
+	projectDir := testutil.MkdirTemp(t, "", "test-build-cmd-fails-")
+
+	opts := &options{
+		ProjectDir: projectDir,
+		ConfigDir:  projectDir,
+	}
+
+	// Check that the command produces the expected error when not
+	// called below a cifuzz project directory.
+	_, stdErr, err := cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin)
+	require.Error(t, err)
+	assert.Contains(t, stdErr, "Failed to parse cifuzz.yaml")
+}
+
+func TestClangMissing(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+	projectDir := testutil.BootstrapEmptyProject(t, "test-build-")
+	opts := &options{
+		ProjectDir:  projectDir,
+		ConfigDir:   projectDir,
+		BuildSystem: config.BuildSystemCMake,
+	}
+
+	dependencies.TestMockAllDeps(t)
+	dependencies.OverwriteUninstalled(dependencies.GetDep(dependencies.Clang))
+
+	_, stdErr, err := cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin)
+	require.Error(t, err)
+	assert.Contains(t, stdErr, fmt.Sprintf(dependencies.MessageMissing, "clang"))
+}
+
+func TestValidateSanitizers(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip()
+	}
+
+	tests := []struct {
+		buildSystem string
+		sanitizers  []string
+		expected    []string
+		valid       bool
+	}{
+		{config.BuildSystemCMake, []string{"asan", "ubsan"}, []string{"address", "undefined"}, true},
+		{config.BuildSystemCMake, []string{"undefined", "address"}, []string{"address", "undefined"}, true},
+		{config.BuildSystemMeson, []string{"msan"}, []string{"memory"}, true},
+		{config.BuildSystemBazel, []string{"tsan"}, []string{"thread"}, true},
+		{config.BuildSystemOther, []string{"coverage"}, []string{"coverage"}, true},
+		{config.BuildSystemCMake, []string{"none"}, []string{}, true},
+		{config.BuildSystemMaven, []string{"address"}, nil, true},
+		{config.BuildSystemBazel, []string{"none"}, nil, false},
+		{config.BuildSystemCMake, []string{"address", "memory"}, nil, false},
+		{config.BuildSystemCMake, []string{"foo"}, nil, false},
+	}
+
+	for _, tc := range tests {
+		opts := &options{
+			BuildSystem: tc.buildSystem,
+			Engine:      config.Libfuzzer,
+			Sanitizers:  tc.sanitizers,
+		}
+		err := opts.validateSanitizers()
+		if !tc.valid {
+			assert.Error(t, err, "%s: %v", tc.buildSystem, tc.sanitizers)
+			continue
+		}
+		require.NoError(t, err, "%s: %v", tc.buildSystem, tc.sanitizers)
+		assert.Equal(t, tc.expected, opts.Sanitizers, "%s: %v", tc.buildSystem, tc.sanitizers)
+	}
+}
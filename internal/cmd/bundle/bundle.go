@@ -31,7 +31,10 @@ func (opts *options) Validate() error {
 		return err
 	}
 
-	if opts.BuildSystem == config.BuildSystemNodeJS && !config.AllowUnsupportedPlatforms() {
+	// Bundles can't contain native Go fuzz tests, Rust fuzz targets or
+	// Python fuzz tests yet
+	if opts.BuildSystem == config.BuildSystemGo || opts.BuildSystem == config.BuildSystemCargo ||
+		opts.BuildSystem == config.BuildSystemPython {
 		return errors.Errorf(config.NotSupportedErrorMessage("bundle", opts.BuildSystem))
 	}
 
@@ -95,9 +98,26 @@ on the build system. This can be overridden with a docker-image flag.
 
   If no fuzz tests are specified, all fuzz tests are added to the bundle.
 
+` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("Meson") + `
+  <fuzz test> is the name of an executable in your meson.build files
+  which uses the cifuzz_fuzz_test_args and cifuzz_fuzz_test_link_args
+  options, see 'cifuzz init meson'.
+
+  The --build-command flag is ignored.
+
+  Additional arguments for 'meson setup' can be passed after a "--".
+
+  The inputs found in the directory <fuzz test>_inputs and the default
+  dictionary <fuzz test>.dict next to the meson.build file defining the
+  fuzz test are added to the bundle automatically.
+
+  If no fuzz tests are specified, all fuzz tests are added to the bundle.
+
 ` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("Bazel") + `
-  <fuzz test> is the name of the cc_fuzz_test target as defined in your
-  BUILD file, either as a relative or absolute Bazel label.
+  <fuzz test> is the name of the cc_fuzz_test or java_fuzz_test target
+  as defined in your BUILD file, either as a relative or absolute Bazel
+  label. For java_fuzz_test targets, all fuzz tests in the target class
+  are added to the bundle.
 
   Command completion for the <fuzz test> argument is supported.
 
@@ -109,11 +129,35 @@ on the build system. This can be overridden with a docker-image flag.
 
 ` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("Maven/Gradle") + `
   <fuzz test> is the name of the class containing the fuzz test.
+  Fuzz tests of Maven modules and Gradle subprojects can be prefixed
+  with the directory of the module, e.g.
+  libs/parser/com.example.ParserFuzzTest.
 
   Command completion for the <fuzz test> argument is supported.
 
   The --build-command flag is ignored.
 
+  If no fuzz tests are specified, all fuzz tests of the project and its
+  modules are added to the bundle.
+
+` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("Node.js") + `
+  <fuzz test> is a test path pattern matching the fuzz test file,
+  optionally followed by a test name pattern, e.g. FuzzTestCase:"My fuzz test".
+  Fuzz tests of workspace packages are prefixed with the directory of
+  the package, e.g. packages/parser/ParserFuzz.
+
+  Command completion for the <fuzz test> argument is supported.
+
+  The sources of the project are added to the bundle, without the files
+  ignored via .gitignore and without dotfiles, apart from configuration
+  files like .jazzerjsrc and .yarnrc.yml. Other files (e.g. an .npmrc)
+  can be added via the --add flag.
+
+  The node_modules directories are only added to the bundle if the
+  --vendor-node-modules flag is used. Otherwise, the dependencies are
+  installed with the package manager of the project (npm, yarn or pnpm)
+  from the lock file when the fuzz tests are executed.
+
   If no fuzz tests are specified, all fuzz tests are added to the bundle.
 
 ` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("Other build systems") + `
@@ -148,6 +192,12 @@ on the build system. This can be overridden with a docker-image flag.
   are added to the bundle automatically if no other dictionary is
   specified by using the --dict flag.
 
+  If no fuzz tests are specified, the fuzz tests listed in the
+  "fuzz-tests" setting in cifuzz.yaml are added to the bundle. If that
+  setting is empty, the project directory is searched for fuzz test
+  executables which were built before, i.e. executables which define
+  LLVMFuzzerTestOneInput.
+
 `,
 		ValidArgsFunction: completion.ValidFuzzTests,
 		Args:              cobra.ArbitraryArgs,
@@ -222,13 +272,18 @@ on the build system. This can be overridden with a docker-image flag.
 		cmdutils.AddCleanCommandFlag,
 		cmdutils.AddBuildJobsFlag,
 		cmdutils.AddCommitFlag,
+		cmdutils.AddCorpusFromFlag,
 		cmdutils.AddDictFlag,
 		cmdutils.AddDockerImageFlagForBundleCommand,
+		cmdutils.AddEngineFlag,
 		cmdutils.AddEngineArgFlag,
 		cmdutils.AddEnvFlag,
+		cmdutils.AddBuildCacheFlag,
 		cmdutils.AddProjectDirFlag,
+		cmdutils.AddSanitizerFlag,
 		cmdutils.AddSeedCorpusFlag,
 		cmdutils.AddTimeoutFlag,
+		cmdutils.AddVendorNodeModulesFlag,
 		cmdutils.AddResolveSourceFileFlag,
 	)
 	cmd.Flags().StringVarP(&opts.OutputPath, "output", "o", "", "Output path of the bundle (.tar.gz)")
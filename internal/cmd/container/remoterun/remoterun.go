@@ -103,6 +103,7 @@ func newWithOptions(opts *containerRemoteRunOpts) *cobra.Command {
 		cmdutils.AddCleanCommandFlag,
 		cmdutils.AddBuildJobsFlag,
 		cmdutils.AddCommitFlag,
+		cmdutils.AddCorpusFromFlag,
 		cmdutils.AddDictFlag,
 		cmdutils.AddDockerImageFlagForContainerCommand,
 		cmdutils.AddEngineArgFlag,
@@ -118,7 +119,9 @@ func newWithOptions(opts *containerRemoteRunOpts) *cobra.Command {
 		cmdutils.AddRegistryFlag,
 		cmdutils.AddSeedCorpusFlag,
 		cmdutils.AddServerFlag,
+		cmdutils.AddTransportFlags,
 		cmdutils.AddTimeoutFlag,
+		cmdutils.AddVendorNodeModulesFlag,
 		cmdutils.AddResolveSourceFileFlag,
 	)
 
@@ -108,6 +108,7 @@ container is built and run locally instead of being pushed to a CI Sense server.
 		cmdutils.AddCleanCommandFlag,
 		cmdutils.AddBuildJobsFlag,
 		cmdutils.AddCommitFlag,
+		cmdutils.AddCorpusFromFlag,
 		cmdutils.AddDictFlag,
 		cmdutils.AddDockerImageFlagForContainerCommand,
 		cmdutils.AddEngineArgFlag,
@@ -118,7 +119,9 @@ container is built and run locally instead of being pushed to a CI Sense server.
 		cmdutils.AddProjectFlag,
 		cmdutils.AddSeedCorpusFlag,
 		cmdutils.AddServerFlag,
+		cmdutils.AddTransportFlags,
 		cmdutils.AddTimeoutFlag,
+		cmdutils.AddVendorNodeModulesFlag,
 		cmdutils.AddResolveSourceFileFlag,
 	)
 	cmd.Flags().StringVar(&opts.ContainerPath, "container", "", "Path of an existing container to start a run with.")
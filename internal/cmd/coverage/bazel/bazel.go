@@ -13,7 +13,9 @@ import (
 
 	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/build/bazel"
+	javaCoverage "code-intelligence.com/cifuzz/internal/cmd/coverage/java"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/pkg/log"
 	"code-intelligence.com/cifuzz/pkg/parser/coverage"
 	"code-intelligence.com/cifuzz/pkg/runfiles"
@@ -23,6 +25,7 @@ import (
 
 type CoverageGenerator struct {
 	FuzzTest        string
+	TargetMethod    string
 	OutputFormat    string
 	OutputPath      string
 	BuildSystemArgs []string
@@ -30,11 +33,16 @@ type CoverageGenerator struct {
 	Engine          string
 	NumJobs         uint
 	CorpusDirs      []string
+	EngineArgs      []string
 	Stdout          io.Writer
 	Stderr          io.Writer
 	BuildStdout     io.Writer
 	BuildStderr     io.Writer
 	Verbose         bool
+
+	// Coverage of java_fuzz_test targets is produced with JaCoCo
+	// like for Maven and Gradle projects
+	javaGenerator *javaCoverage.CoverageGenerator
 }
 
 // symlinkUserInputsToGeneratedCorpus handles user defined inputs set via
@@ -125,6 +133,14 @@ func (cov *CoverageGenerator) symlinkUserInputsToGeneratedCorpus(commonFlags []s
 }
 
 func (cov *CoverageGenerator) BuildFuzzTestForCoverage() error {
+	isJava, err := bazel.IsJavaFuzzTest(cov.FuzzTest)
+	if err != nil {
+		return err
+	}
+	if isJava {
+		return cov.buildJavaFuzzTestForCoverage()
+	}
+
 	commonFlags, err := cov.getBazelCommandFlags()
 	if err != nil {
 		return err
@@ -195,7 +211,77 @@ func (cov *CoverageGenerator) BuildFuzzTestForCoverage() error {
 	return nil
 }
 
+// buildJavaFuzzTestForCoverage builds the deploy JAR of the
+// java_fuzz_test and runs the inputs with Jazzer and the JaCoCo agent.
+func (cov *CoverageGenerator) buildJavaFuzzTestForCoverage() error {
+	tempDir, err := os.MkdirTemp("", "cifuzz-coverage-")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(tempDir)
+
+	builder, err := bazel.NewBuilder(&bazel.BuilderOptions{
+		ProjectDir: cov.ProjectDir,
+		Args:       cov.BuildSystemArgs,
+		NumJobs:    cov.NumJobs,
+		Stdout:     cov.BuildStdout,
+		Stderr:     cov.BuildStderr,
+		TempDir:    tempDir,
+		Verbose:    cov.Verbose,
+	})
+	if err != nil {
+		return err
+	}
+	buildResults, err := builder.BuildJava([]string{cov.FuzzTest})
+	if err != nil {
+		return err
+	}
+	buildResult := buildResults[0]
+
+	err = cmdutils.ValidateJVMFuzzTest(buildResult.TargetClass, &cov.TargetMethod, buildResult.RuntimeDeps)
+	if err != nil {
+		return err
+	}
+
+	// The corpus directories of bazel fuzz tests are not in the
+	// locations which Jazzer uses by default, so we pass them explicitly
+	var corpusDirs []string
+	for _, dir := range []string{buildResult.GeneratedCorpus, buildResult.SeedCorpus} {
+		exists, err := fileutil.Exists(dir)
+		if err != nil {
+			return err
+		}
+		if exists {
+			corpusDirs = append(corpusDirs, dir)
+		}
+	}
+	corpusDirs = append(corpusDirs, cov.CorpusDirs...)
+
+	cov.javaGenerator = &javaCoverage.CoverageGenerator{
+		BuildSystem:  config.BuildSystemBazel,
+		OutputFormat: cov.OutputFormat,
+		OutputPath:   cov.OutputPath,
+		FuzzTest:     buildResult.TargetClass,
+		TargetMethod: cov.TargetMethod,
+		ProjectDir:   cov.ProjectDir,
+		Deps:         buildResult.RuntimeDeps,
+		// The deploy JAR contains the classes of the fuzz test and
+		// all its dependencies
+		ClassFiles:  buildResult.RuntimeDeps,
+		CorpusDirs:  corpusDirs,
+		EngineArgs:  cov.EngineArgs,
+		BuildStdout: cov.BuildStdout,
+		BuildStderr: cov.BuildStderr,
+		Stderr:      cov.Stderr,
+	}
+	return cov.javaGenerator.BuildFuzzTestForCoverage()
+}
+
 func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
+	if cov.javaGenerator != nil {
+		return cov.javaGenerator.GenerateCoverageReport()
+	}
+
 	// Get the path of the created lcov report
 	cmd := exec.Command("bazel", "info", "output_path")
 	out, err := cmd.Output()
@@ -14,12 +14,15 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	javaBuild "code-intelligence.com/cifuzz/internal/build/java"
 	"code-intelligence.com/cifuzz/internal/build/java/gradle"
 	"code-intelligence.com/cifuzz/internal/build/java/maven"
 	bazelCoverage "code-intelligence.com/cifuzz/internal/cmd/coverage/bazel"
+	golangCoverage "code-intelligence.com/cifuzz/internal/cmd/coverage/golang"
 	javaCoverage "code-intelligence.com/cifuzz/internal/cmd/coverage/java"
 	llvmCoverage "code-intelligence.com/cifuzz/internal/cmd/coverage/llvm"
 	nodeCoverage "code-intelligence.com/cifuzz/internal/cmd/coverage/node"
+	pythonCoverage "code-intelligence.com/cifuzz/internal/cmd/coverage/python"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/cmdutils/logging"
 	"code-intelligence.com/cifuzz/internal/cmdutils/resolve"
@@ -80,6 +83,12 @@ func (opts *coverageOptions) validate() error {
 		return err
 	}
 
+	// cargo-fuzz doesn't produce coverage reports in a format we can
+	// process yet
+	if opts.BuildSystem == config.BuildSystemCargo {
+		return errors.Errorf(config.NotSupportedErrorMessage("coverage", opts.BuildSystem))
+	}
+
 	validFormats := coverage.ValidOutputFormats[opts.BuildSystem]
 	if !stringutil.Contains(validFormats, opts.OutputFormat) {
 		msg := fmt.Sprintf("Flag \"format\" must be %s", strings.Join(validFormats, " or "))
@@ -95,8 +104,9 @@ func (opts *coverageOptions) validate() error {
 	if opts.NumBuildJobs > 0 &&
 		opts.BuildSystem != config.BuildSystemBazel &&
 		opts.BuildSystem != config.BuildSystemCMake &&
+		opts.BuildSystem != config.BuildSystemMeson &&
 		opts.BuildSystem != config.BuildSystemOther {
-		msg := `Flag 'build-jobs' is only applicable for build system types 'Bazel', 'CMake' and 'other'`
+		msg := `Flag 'build-jobs' is only applicable for build system types 'Bazel', 'CMake', 'Meson' and 'other'`
 		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
 	}
 
@@ -122,9 +132,9 @@ addition to optional input directories specified by using the add-corpus flag.
 More details about the build system specific inputs directory location
 can be found in the help message of the run command.
 
-Additional arguments for CMake and Bazel can be passed after a "--".
+Additional arguments for CMake, Meson and Bazel can be passed after a "--".
 
-The flag 'build-jobs' is only applicable for CMake, Bazel and 'other'.
+The flag 'build-jobs' is only applicable for CMake, Meson, Bazel and 'other'.
 
 The output can be displayed in the browser or written as a HTML
 or a lcov trace file.
@@ -170,7 +180,7 @@ or a lcov trace file.
 			}
 
 			if sliceutil.Contains(
-				[]string{config.BuildSystemMaven, config.BuildSystemGradle},
+				[]string{config.BuildSystemMaven, config.BuildSystemGradle, config.BuildSystemBazel},
 				opts.BuildSystem,
 			) {
 				// Check if the fuzz test is a method of a class
@@ -250,14 +260,14 @@ func (c *coverageCmd) run() error {
 		var format string
 		var output string
 		switch c.opts.BuildSystem {
-		case config.BuildSystemCMake, config.BuildSystemBazel:
+		case config.BuildSystemCMake, config.BuildSystemMeson, config.BuildSystemBazel:
 			format = coverage.FormatLCOV
 			output = "lcov.info"
 		case config.BuildSystemMaven, config.BuildSystemGradle:
 			format = coverage.FormatJacocoXML
 			output = "coverage.xml"
 		default:
-			log.Info("The --vscode flag only supports the following build systems: CMake, Bazel, Maven, Gradle")
+			log.Info("The --vscode flag only supports the following build systems: CMake, Meson, Bazel, Maven, Gradle")
 			return nil
 		}
 
@@ -270,6 +280,7 @@ func (c *coverageCmd) run() error {
 	case config.BuildSystemBazel:
 		gen = &bazelCoverage.CoverageGenerator{
 			FuzzTest:        c.opts.fuzzTest,
+			TargetMethod:    c.opts.targetMethod,
 			OutputFormat:    c.opts.OutputFormat,
 			OutputPath:      c.opts.OutputPath,
 			BuildSystemArgs: c.opts.argsToPass,
@@ -277,13 +288,14 @@ func (c *coverageCmd) run() error {
 			Engine:          "libfuzzer",
 			NumJobs:         c.opts.NumBuildJobs,
 			CorpusDirs:      c.opts.CorpusDirs,
+			EngineArgs:      c.opts.EngineArgs,
 			Stdout:          c.OutOrStdout(),
 			Stderr:          c.ErrOrStderr(),
 			BuildStdout:     c.opts.buildStdout,
 			BuildStderr:     c.opts.buildStderr,
 			Verbose:         viper.GetBool("verbose"),
 		}
-	case config.BuildSystemCMake, config.BuildSystemOther:
+	case config.BuildSystemCMake, config.BuildSystemMeson, config.BuildSystemOther:
 		if c.opts.BuildSystem == config.BuildSystemOther {
 			if len(c.opts.argsToPass) > 0 {
 				log.Warnf("Passing additional arguments is not supported for build system type \"other\".\n"+
@@ -313,11 +325,19 @@ func (c *coverageCmd) run() error {
 				"These arguments are ignored: %s", strings.Join(c.opts.argsToPass, " "))
 		}
 
+		// Fuzz tests of modules are executed with the class path of
+		// the module and the report is created for the module
+		fuzzTest, err := javaBuild.ResolveFuzzTest(c.opts.ProjectDir, c.opts.BuildSystem, c.opts.fuzzTest)
+		if err != nil {
+			return err
+		}
+		module, targetClass := cmdutils.SeparateModuleAndTargetClass(fuzzTest)
+
 		var deps []string
 		if c.opts.BuildSystem == config.BuildSystemGradle {
-			deps, err = gradle.GetDependencies(c.opts.ProjectDir)
+			deps, err = gradle.GetDependencies(c.opts.ProjectDir, module)
 		} else {
-			deps, err = maven.GetDependencies(c.opts.ProjectDir, maven.ParallelOptions{
+			deps, err = maven.GetDependencies(c.opts.ProjectDir, module, maven.ParallelOptions{
 				Enabled: viper.IsSet("build-jobs"),
 				NumJobs: c.opts.NumBuildJobs,
 			})
@@ -326,18 +346,18 @@ func (c *coverageCmd) run() error {
 			return err
 		}
 
-		err = cmdutils.ValidateJVMFuzzTest(c.opts.fuzzTest, &c.opts.targetMethod, deps)
+		err = cmdutils.ValidateJVMFuzzTest(targetClass, &c.opts.targetMethod, deps)
 		if err != nil {
 			return err
 		}
 
-		gen = &javaCoverage.CoverageGenerator{
+		javaGen := &javaCoverage.CoverageGenerator{
 			BuildSystem:  c.opts.BuildSystem,
 			OutputFormat: c.opts.OutputFormat,
 			OutputPath:   c.opts.OutputPath,
-			FuzzTest:     c.opts.fuzzTest,
+			FuzzTest:     targetClass,
 			TargetMethod: c.opts.targetMethod,
-			ProjectDir:   c.opts.ProjectDir,
+			ProjectDir:   filepath.Join(c.opts.ProjectDir, filepath.FromSlash(module)),
 			Deps:         deps,
 			CorpusDirs:   c.opts.CorpusDirs,
 			EngineArgs:   c.opts.EngineArgs,
@@ -345,6 +365,25 @@ func (c *coverageCmd) run() error {
 			BuildStderr:  c.opts.buildStderr,
 			Stderr:       c.OutOrStderr(),
 		}
+
+		// In multi-module projects, fuzz tests usually exercise the
+		// code of other modules too, so the report includes the
+		// classes and sources of all modules
+		modules, err := javaBuild.Modules(c.opts.ProjectDir, c.opts.BuildSystem)
+		if err != nil {
+			return err
+		}
+		if len(modules) > 0 {
+			javaGen.ClassFiles, err = javaBuild.ClassFilesDirs(c.opts.ProjectDir, c.opts.BuildSystem)
+			if err != nil {
+				return err
+			}
+			javaGen.SourceDirs, err = javaBuild.SourceDirs(c.opts.ProjectDir, c.opts.BuildSystem)
+			if err != nil {
+				return err
+			}
+		}
+		gen = javaGen
 	case config.BuildSystemNodeJS:
 		if len(c.opts.argsToPass) > 0 {
 			log.Warnf("Passing additional arguments is not supported for Node.js.\n"+
@@ -366,11 +405,44 @@ func (c *coverageCmd) run() error {
 			BuildStdout:     c.opts.buildStdout,
 			BuildStderr:     c.opts.buildStderr,
 		}
+	case config.BuildSystemGo:
+		if len(c.opts.argsToPass) > 0 {
+			log.Warnf("Passing additional arguments is not supported for Go.\n"+
+				"These arguments are ignored: %s", strings.Join(c.opts.argsToPass, " "))
+		}
+
+		gen = &golangCoverage.CoverageGenerator{
+			OutputFormat: c.opts.OutputFormat,
+			OutputPath:   c.opts.OutputPath,
+			FuzzTest:     c.opts.fuzzTest,
+			CorpusDirs:   c.opts.CorpusDirs,
+			ProjectDir:   c.opts.ProjectDir,
+			Stderr:       c.OutOrStderr(),
+			BuildStdout:  c.opts.buildStdout,
+			BuildStderr:  c.opts.buildStderr,
+		}
+	case config.BuildSystemPython:
+		if len(c.opts.argsToPass) > 0 {
+			log.Warnf("Passing additional arguments is not supported for Python.\n"+
+				"These arguments are ignored: %s", strings.Join(c.opts.argsToPass, " "))
+		}
+
+		gen = &pythonCoverage.CoverageGenerator{
+			OutputFormat: c.opts.OutputFormat,
+			OutputPath:   c.opts.OutputPath,
+			FuzzTest:     c.opts.fuzzTest,
+			CorpusDirs:   c.opts.CorpusDirs,
+			ProjectDir:   c.opts.ProjectDir,
+			Stderr:       c.OutOrStderr(),
+			BuildStdout:  c.opts.buildStdout,
+			BuildStderr:  c.opts.buildStderr,
+		}
 	default:
 		return errors.Errorf("Unsupported build system \"%s\"", c.opts.BuildSystem)
 	}
 
-	if c.opts.BuildSystem != config.BuildSystemNodeJS {
+	// Node.js and Python fuzz tests don't have to be built
+	if c.opts.BuildSystem != config.BuildSystemNodeJS && c.opts.BuildSystem != config.BuildSystemPython {
 		buildPrinter := logging.NewBuildPrinter(os.Stdout, log.BuildInProgressMsg)
 		log.Infof("Building %s", pterm.Style{pterm.Reset, pterm.FgLightBlue}.Sprint(c.opts.fuzzTest))
 
@@ -467,12 +539,25 @@ func (c *coverageCmd) checkDependencies() error {
 		case "windows":
 			deps = append(deps, dependencies.VisualStudio, dependencies.Perl)
 		}
+	case config.BuildSystemMeson:
+		deps = []dependencies.Key{
+			dependencies.Meson,
+			dependencies.Clang,
+			dependencies.LLVMSymbolizer,
+			dependencies.LLVMCov,
+			dependencies.LLVMProfData,
+			dependencies.GenHTML,
+		}
 	case config.BuildSystemMaven:
 		deps = []dependencies.Key{dependencies.Maven}
 	case config.BuildSystemGradle:
 		deps = []dependencies.Key{dependencies.Gradle}
 	case config.BuildSystemNodeJS:
 		deps = []dependencies.Key{dependencies.Node}
+	case config.BuildSystemGo:
+		deps = []dependencies.Key{dependencies.Go}
+	case config.BuildSystemPython:
+		deps = []dependencies.Key{dependencies.Python, dependencies.Atheris, dependencies.CoveragePy}
 	case config.BuildSystemOther:
 		deps = []dependencies.Key{
 			dependencies.Clang,
@@ -0,0 +1,380 @@
+package golang
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/otiai10/copy"
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/build/golang"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/coverage"
+	"code-intelligence.com/cifuzz/pkg/log"
+	parser "code-intelligence.com/cifuzz/pkg/parser/coverage"
+	"code-intelligence.com/cifuzz/pkg/runner/gofuzz"
+	"code-intelligence.com/cifuzz/util/envutil"
+	"code-intelligence.com/cifuzz/util/fileutil"
+	"code-intelligence.com/cifuzz/util/regexutil"
+	"code-intelligence.com/cifuzz/util/stringutil"
+)
+
+// Prefix of the corpus entries which are added to the copy of the seed
+// corpus of the fuzz test
+const corpusEntryPrefix = "cifuzz-coverage-"
+
+type CoverageGenerator struct {
+	OutputFormat string
+	OutputPath   string
+	FuzzTest     string
+	CorpusDirs   []string
+	ProjectDir   string
+
+	Stderr      io.Writer
+	BuildStdout io.Writer
+	BuildStderr io.Writer
+
+	fuzzTest *golang.FuzzTest
+	// Path of the corpus in the Go build cache
+	generatedCorpus string
+}
+
+func (cov *CoverageGenerator) BuildFuzzTestForCoverage() error {
+	var err error
+	cov.fuzzTest, err = golang.ResolveFuzzTest(cov.ProjectDir, cov.FuzzTest)
+	if err != nil {
+		return err
+	}
+
+	buildResult, err := golang.Build(cov.ProjectDir, cov.fuzzTest, cov.BuildStdout, cov.BuildStderr)
+	if err != nil {
+		return err
+	}
+	cov.generatedCorpus = buildResult.GeneratedCorpus
+	return nil
+}
+
+func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
+	tempDir, err := os.MkdirTemp("", "cifuzz-go-coverage-")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(tempDir)
+
+	// Without -fuzz, the fuzz test is only run on the inputs of its
+	// seed corpus in testdata/fuzz, which is read from the working
+	// directory of the test binary. To get the coverage of the
+	// generated corpus and the user-specified corpus dirs without
+	// modifying the source tree, we run the test binary in a copy of
+	// the package directory and add the inputs to the seed corpus of
+	// that copy.
+	workDir := filepath.Join(tempDir, "package")
+	err = copyPackageDir(cov.fuzzTest.Dir, workDir)
+	if err != nil {
+		return "", err
+	}
+	seedCorpus := filepath.Join(workDir, "testdata", "fuzz", cov.fuzzTest.Name)
+	err = cov.addCorpusToSeedCorpus(seedCorpus, filepath.Join(tempDir, "corpus"))
+	if err != nil {
+		return "", err
+	}
+
+	testBinary := filepath.Join(tempDir, "fuzz.test")
+	if runtime.GOOS == "windows" {
+		testBinary += ".exe"
+	}
+	err = cov.buildTestBinary(testBinary)
+	if err != nil {
+		return "", err
+	}
+
+	profile := filepath.Join(tempDir, "cover.out")
+	err = cov.runFuzzTest(testBinary, workDir, seedCorpus, profile)
+	if err != nil {
+		return "", err
+	}
+
+	lcovReportPath, err := cov.writeLCOVReport(profile, tempDir)
+	if err != nil {
+		return "", err
+	}
+
+	// Print the summary table
+	lcovReport, err := os.Open(lcovReportPath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer lcovReport.Close()
+	summary, err := parser.ParseLCOVReportIntoSummary(lcovReport)
+	if err != nil {
+		return "", err
+	}
+	summary.PrintTable(cov.Stderr)
+
+	switch cov.OutputFormat {
+	case coverage.FormatHTML:
+		return cov.generateHTMLReport(profile)
+	case coverage.FormatLCOV:
+		outputPath := cov.OutputPath
+		if outputPath == "" {
+			// Like for the other build systems, the lcov report is
+			// created in the current working directory by default
+			outputPath = cov.fuzzTest.Name + ".coverage.lcov"
+		}
+		err = copy.Copy(lcovReportPath, outputPath)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		return outputPath, nil
+	default:
+		return "", errors.Errorf("Unsupported output format %q", cov.OutputFormat)
+	}
+}
+
+// buildTestBinary builds the test binary of the package containing the
+// fuzz test with coverage instrumentation of all packages of the module.
+func (cov *CoverageGenerator) buildTestBinary(testBinary string) error {
+	// Set CIFUZZ=1 to allow build constraints or tests to figure out
+	// that they are run by cifuzz
+	env, err := envutil.Setenv(os.Environ(), "CIFUZZ", "1")
+	if err != nil {
+		return err
+	}
+
+	args := []string{
+		"test", "-c",
+		"-o", testBinary,
+		"-cover",
+		// Measure the coverage of all packages of the module, not
+		// only of the package containing the fuzz test
+		"-coverpkg", "./...",
+		cov.fuzzTest.Package,
+	}
+	cmd := exec.Command("go", args...)
+	cmd.Dir = cov.ProjectDir
+	cmd.Env = env
+	cmd.Stdout = cov.BuildStdout
+	cmd.Stderr = cov.BuildStderr
+	log.Debugf("Command: %s", strings.Join(stringutil.QuotedStrings(cmd.Args), " "))
+	err = cmd.Run()
+	if err != nil {
+		return cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+	return nil
+}
+
+// runFuzzTest runs the fuzz test on the inputs of the seed corpus in
+// the working directory and writes the cover profile. If an input
+// makes the test binary exit (e.g. because of a panic), the coverage
+// data is not written, so we remove that input from the seed corpus
+// (which is a copy of the one in the source tree) and run the test
+// again.
+func (cov *CoverageGenerator) runFuzzTest(testBinary string, workDir string, seedCorpus string, profile string) error {
+	failedInputPattern := regexp.MustCompile(`(?m)^\s*--- FAIL: ` + regexp.QuoteMeta(cov.fuzzTest.Name) + `/(?P<input>\S+)`)
+	excluded := map[string]bool{}
+	for {
+		args := []string{
+			"-test.run", "^" + cov.fuzzTest.Name + "$",
+			"-test.coverprofile", profile,
+		}
+		cmd := exec.Command(testBinary, args...)
+		// The test binary reads the seed corpus from the working
+		// directory
+		cmd.Dir = workDir
+		var output bytes.Buffer
+		cmd.Stdout = io.MultiWriter(cov.BuildStdout, &output)
+		cmd.Stderr = cov.BuildStderr
+		log.Debugf("Command: %s", strings.Join(stringutil.QuotedStrings(cmd.Args), " "))
+		err := cmd.Run()
+		if err == nil {
+			return nil
+		}
+
+		// The test binary exits with a non-zero exit code if the fuzz
+		// test fails on one of the inputs, which is expected if the
+		// corpus contains crashing inputs
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return cmdutils.WrapExecError(errors.WithStack(err), cmd)
+		}
+		hasCoverage, err := hasCoverageData(profile)
+		if err != nil {
+			return err
+		}
+		if hasCoverage {
+			return nil
+		}
+
+		result, found := regexutil.FindNamedGroupsMatch(failedInputPattern, output.String())
+		if !found || excluded[result["input"]] {
+			return errors.New("Failed to measure the coverage of the fuzz test")
+		}
+		input := filepath.Join(seedCorpus, result["input"])
+		exists, err := fileutil.Exists(input)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.New("Failed to measure the coverage of the fuzz test")
+		}
+
+		log.Warnf("Excluding input %s from the coverage report because it makes the fuzz test crash",
+			strings.TrimPrefix(result["input"], corpusEntryPrefix))
+		err = os.Remove(input)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		excluded[result["input"]] = true
+	}
+}
+
+// hasCoverageData returns true if the given cover profile contains
+// coverage data in addition to the "mode:" line.
+func hasCoverageData(profile string) (bool, error) {
+	content, err := os.ReadFile(profile)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return len(strings.Split(strings.TrimSpace(string(content)), "\n")) > 1, nil
+}
+
+func (cov *CoverageGenerator) writeLCOVReport(profile string, dir string) (string, error) {
+	packageDirs, err := golang.PackageDirs(cov.ProjectDir)
+	if err != nil {
+		return "", err
+	}
+
+	profileFile, err := os.Open(profile)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer profileFile.Close()
+	report, err := parser.ParseGoCoverProfileIntoLCOVReport(profileFile, packageDirs)
+	if err != nil {
+		return "", err
+	}
+
+	reportPath := filepath.Join(dir, "coverage.lcov")
+	err = report.WriteLCOVReportToFile(reportPath)
+	if err != nil {
+		return "", err
+	}
+	// No file is written for empty reports
+	exists, err := fileutil.Exists(reportPath)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		err = os.WriteFile(reportPath, nil, 0o644)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+	return reportPath, nil
+}
+
+func (cov *CoverageGenerator) generateHTMLReport(profile string) (string, error) {
+	outputPath := cov.OutputPath
+	if outputPath == "" {
+		// If no output path is specified, we create the output in a
+		// temporary directory.
+		var err error
+		outputPath, err = os.MkdirTemp("", "coverage-")
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+	err := os.MkdirAll(outputPath, 0o755)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	cmd := exec.Command("go", "tool", "cover", "-html", profile, "-o", filepath.Join(outputPath, "index.html"))
+	cmd.Dir = cov.ProjectDir
+	cmd.Stdout = cov.BuildStdout
+	cmd.Stderr = cov.BuildStderr
+	log.Debugf("Command: %s", strings.Join(stringutil.QuotedStrings(cmd.Args), " "))
+	err = cmd.Run()
+	if err != nil {
+		return "", cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+	return outputPath, nil
+}
+
+// copyPackageDir copies the files of the package directory and its
+// testdata directory, which contains the seed corpus and might contain
+// other files used by the fuzz test, to the given directory. The other
+// subdirectories are other packages, which are not needed to run the
+// test binary.
+func copyPackageDir(packageDir string, dest string) error {
+	err := copy.Copy(packageDir, dest, copy.Options{
+		Skip: func(info os.FileInfo, src, dest string) (bool, error) {
+			if !info.IsDir() || src == packageDir {
+				return false, nil
+			}
+			relPath, err := filepath.Rel(packageDir, src)
+			if err != nil {
+				return false, errors.WithStack(err)
+			}
+			parts := strings.Split(relPath, string(filepath.Separator))
+			return parts[0] != "testdata", nil
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to copy package directory %s", packageDir)
+	}
+	return nil
+}
+
+// addCorpusToSeedCorpus copies the inputs of the generated corpus and
+// the user-specified corpus dirs to the given seed corpus directory.
+func (cov *CoverageGenerator) addCorpusToSeedCorpus(seedCorpus string, tempCorpusDir string) error {
+	corpusDirs := append([]string{}, cov.CorpusDirs...)
+	exists, err := fileutil.Exists(cov.generatedCorpus)
+	if err != nil {
+		return err
+	}
+	if exists {
+		corpusDirs = append(corpusDirs, cov.generatedCorpus)
+	}
+	if len(corpusDirs) == 0 {
+		return nil
+	}
+
+	// Collect the inputs in a temporary directory first, which removes
+	// duplicates and inputs in other formats
+	err = gofuzz.CopyToCorpus(tempCorpusDir, corpusDirs...)
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(tempCorpusDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = os.MkdirAll(seedCorpus, 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, entry := range entries {
+		dest := filepath.Join(seedCorpus, corpusEntryPrefix+entry.Name())
+		err = copy.Copy(filepath.Join(tempCorpusDir, entry.Name()), dest)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	log.Debugf("Added %d inputs to %s", len(entries), seedCorpus)
+	return nil
+}
@@ -0,0 +1,60 @@
+package golang
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/otiai10/copy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/coverage"
+	"code-intelligence.com/cifuzz/internal/testutil"
+)
+
+func TestGenerateCoverageReport(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+
+	tempDir := testutil.MkdirTemp(t, "", "go-coverage-test-")
+	projectDir := filepath.Join(tempDir, "project")
+	err := copy.Copy(filepath.Join("testdata", "project"), projectDir)
+	require.NoError(t, err)
+	seedCorpus := filepath.Join(projectDir, "parser", "testdata", "fuzz", "FuzzParse")
+
+	// The corpus contains an input which makes the fuzz test crash,
+	// which is excluded from the coverage report
+	corpusDir := filepath.Join(tempDir, "corpus")
+	require.NoError(t, os.MkdirAll(corpusDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(corpusDir, "input"), []byte("go test fuzz v1\n[]byte(\"x\")\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(corpusDir, "crash"), []byte("go test fuzz v1\n[]byte(\"crash\")\n"), 0o644))
+
+	cov := &CoverageGenerator{
+		OutputFormat: coverage.FormatLCOV,
+		OutputPath:   filepath.Join(tempDir, "coverage.lcov"),
+		FuzzTest:     "FuzzParse",
+		CorpusDirs:   []string{corpusDir},
+		ProjectDir:   projectDir,
+		Stderr:       io.Discard,
+		BuildStdout:  io.Discard,
+		BuildStderr:  io.Discard,
+	}
+	err = cov.BuildFuzzTestForCoverage()
+	require.NoError(t, err)
+	reportPath, err := cov.GenerateCoverageReport()
+	require.NoError(t, err)
+
+	report, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(report), "parser.go")
+
+	// The seed corpus in the source tree is not modified
+	entries, err := os.ReadDir(seedCorpus)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "seed", entries[0].Name())
+}
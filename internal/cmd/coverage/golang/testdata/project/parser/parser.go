@@ -0,0 +1,11 @@
+package parser
+
+func Parse(data []byte) int {
+	if len(data) > 0 && data[0] == 'x' {
+		return 1
+	}
+	if string(data) == "crash" {
+		panic("crash")
+	}
+	return 0
+}
@@ -36,6 +36,12 @@ type CoverageGenerator struct {
 	Deps       []string
 	CorpusDirs []string
 	EngineArgs []string
+	// The class files (directories or JARs) to include in the report.
+	// If not set, the class files directory of the build system is used.
+	ClassFiles []string
+	// The source directories to include in the report. If not set, the
+	// first main source directory of the project is used.
+	SourceDirs []string
 
 	BuildStdout io.Writer
 	BuildStderr io.Writer
@@ -74,24 +80,31 @@ func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
 	}
 
 	// Class files are stored differently dependent on build system
-	classFilesDir := filepath.Join(cov.ProjectDir, "target", "classes")
-	if cov.BuildSystem == config.BuildSystemGradle {
-		classFilesDir = filepath.Join(cov.ProjectDir, "build", "classes")
+	classFiles := cov.ClassFiles
+	if len(classFiles) == 0 {
+		classFiles = []string{java.ClassFilesDir(cov.ProjectDir, cov.BuildSystem)}
 	}
 
-	sourceFilesDirs, err := java.SourceDirs(cov.ProjectDir, cov.BuildSystem)
-	if err != nil {
-		return "", err
-	}
+	sourceFilesDirs := cov.SourceDirs
 	if len(sourceFilesDirs) == 0 {
-		return "", errors.Errorf("Failed to find source file directory in %s", cov.ProjectDir)
+		sourceFilesDirs, err = java.SourceDirs(cov.ProjectDir, cov.BuildSystem)
+		if err != nil {
+			return "", err
+		}
+		if len(sourceFilesDirs) == 0 {
+			return "", errors.Errorf("Failed to find source file directory in %s", cov.ProjectDir)
+		}
+		if cov.BuildSystem != config.BuildSystemBazel {
+			// For Maven and Gradle projects, we assume that the first source
+			// file directory has all the sources. In bazel workspaces, the
+			// sources are usually spread across multiple source roots, which
+			// are all passed to JaCoCo.
+			sourceFilesDirs = sourceFilesDirs[:1]
+		}
 	}
-	// JaCoCo does not seem to support multiple source file directories, so we assume that the first
-	// one has all the sources.
-	sourceFilesDir := sourceFilesDirs[0]
 
 	htmlPath := filepath.Join(cov.OutputPath, "html")
-	jacocoXMLPath, err := cov.runJacocoCommand(cliJar, cov.jacocoExecFilePath(), htmlPath, classFilesDir, sourceFilesDir)
+	jacocoXMLPath, err := cov.runJacocoCommand(cliJar, cov.jacocoExecFilePath(), htmlPath, classFiles, sourceFilesDirs)
 	if err != nil {
 		return "", err
 	}
@@ -119,10 +132,16 @@ func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
 			return "", errors.WithStack(err)
 		}
 
-		lcovReport, err := parser.ParseJacocoXMLIntoLCOVReport(reportFile, sourceFilesDir)
+		lcovReport, err := parser.ParseJacocoXMLIntoLCOVReport(reportFile, sourceFilesDirs[0])
 		if err != nil {
 			return "", err
 		}
+		if len(sourceFilesDirs) > 1 {
+			err = resolveSourceFiles(lcovReport, sourceFilesDirs)
+			if err != nil {
+				return "", err
+			}
+		}
 
 		lcovFilePath := filepath.Join(cov.OutputPath, "report.lcov")
 		err = lcovReport.WriteLCOVReportToFile(lcovFilePath)
@@ -178,7 +197,7 @@ func (cov *CoverageGenerator) GenerateCoverageReportInFuzzContainer(jacocoExecFi
 	// Here and in the call to parser.ParseJacocoXMLIntoLCOVReport below, we do not pass in a
 	// non-empty sourceFilesDir as source files aren't available in fuzz containers anyway. We are
 	// only interested in coverage statistics, not actual source file contents.
-	jacocoXMLFile, err := cov.runJacocoCommand(cliJar, jacocoExecFilePath, "", classFilesDir, "")
+	jacocoXMLFile, err := cov.runJacocoCommand(cliJar, jacocoExecFilePath, "", []string{classFilesDir}, nil)
 	if err != nil {
 		return "", err
 	}
@@ -246,16 +265,18 @@ func (cov *CoverageGenerator) jacocoExecFilePath() string {
 	return filepath.Join(cov.OutputPath, fmt.Sprintf("jacoco_%s_%s.exec", cov.FuzzTest, cov.TargetMethod))
 }
 
-func (cov *CoverageGenerator) runJacocoCommand(cliJar, jacocoExecPath, htmlPath, classFilesDir, sourceFilesDir string) (string, error) {
+func (cov *CoverageGenerator) runJacocoCommand(cliJar, jacocoExecPath, htmlPath string, classFiles, sourceFilesDirs []string) (string, error) {
 	jacocoXMLPath := filepath.Join(cov.OutputPath, "jacoco.xml")
 
 	args := []string{
 		"-jar", cliJar,
 		"report", jacocoExecPath,
 		"--xml", jacocoXMLPath,
-		"--classfiles", classFilesDir,
 	}
-	if sourceFilesDir != "" {
+	for _, classFile := range classFiles {
+		args = append(args, "--classfiles", classFile)
+	}
+	for _, sourceFilesDir := range sourceFilesDirs {
 		args = append(args, "--sourcefiles", sourceFilesDir)
 	}
 	// Set html output path if needed
@@ -276,6 +297,29 @@ func (cov *CoverageGenerator) runJacocoCommand(cliJar, jacocoExecPath, htmlPath,
 	return jacocoXMLPath, nil
 }
 
+// resolveSourceFiles changes the paths of the source files in the
+// report, which were made relative to the first source files directory,
+// to the path in the source files directory which contains the file.
+func resolveSourceFiles(report *parser.LCOVReport, sourceFilesDirs []string) error {
+	for _, sf := range report.SourceFiles {
+		packagePath, err := filepath.Rel(sourceFilesDirs[0], sf.Name)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for _, dir := range sourceFilesDirs {
+			exists, err := fileutil.Exists(filepath.Join(dir, packagePath))
+			if err != nil {
+				return err
+			}
+			if exists {
+				sf.Name = filepath.Join(dir, packagePath)
+				break
+			}
+		}
+	}
+	return nil
+}
+
 func (cov *CoverageGenerator) produceJacocoExec(agentJarPath, jacocoExecFilePath string) error {
 	javaBin, err := runfiles.Finder.JavaPath()
 	if err != nil {
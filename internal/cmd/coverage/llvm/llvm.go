@@ -19,6 +19,7 @@ import (
 
 	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/build/cmake"
+	"code-intelligence.com/cifuzz/internal/build/meson"
 	"code-intelligence.com/cifuzz/internal/build/other"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/config"
@@ -211,6 +212,32 @@ func (cov *CoverageGenerator) build() error {
 			return err
 		}
 		buildResult = buildResults[0]
+	case config.BuildSystemMeson:
+		builder, err := meson.NewBuilder(&meson.BuilderOptions{
+			ProjectDir: cov.ProjectDir,
+			Args:       cov.BuildSystemArgs,
+			Sanitizers: []string{"coverage"},
+			Parallel: meson.ParallelOptions{
+				Enabled: viper.IsSet("build-jobs"),
+				NumJobs: uint(cov.NumBuildJobs),
+			},
+			Stdout:          cov.BuildStdout,
+			Stderr:          cov.BuildStderr,
+			FindRuntimeDeps: true,
+			RunfilesFinder:  cov.runfilesFinder,
+		})
+		if err != nil {
+			return err
+		}
+		err = builder.Configure()
+		if err != nil {
+			return err
+		}
+		buildResults, err := builder.Build([]string{cov.FuzzTest})
+		if err != nil {
+			return err
+		}
+		buildResult = buildResults[0]
 	case config.BuildSystemOther:
 		if runtime.GOOS == "windows" {
 			return errors.New("CMake is the only supported build system on Windows")
@@ -2,6 +2,7 @@ package node
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"os"
 	"os/exec"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"code-intelligence.com/cifuzz/internal/build/nodejs"
 	"code-intelligence.com/cifuzz/internal/coverage"
 	"code-intelligence.com/cifuzz/pkg/log"
 	"code-intelligence.com/cifuzz/pkg/options"
@@ -26,10 +28,18 @@ type CoverageGenerator struct {
 	TestPathPattern string
 	TestNamePattern string
 	ProjectDir      string
+	// Additional corpus dirs whose inputs are executed to produce the
+	// coverage report, e.g. the generated corpus of a fuzzing run in a
+	// container
+	CorpusDirs []string
 
 	Stderr      io.Writer
 	BuildStdout io.Writer
 	BuildStderr io.Writer
+
+	packageDir      string
+	packageManager  string
+	testPathPattern string
 }
 
 func (cov *CoverageGenerator) BuildFuzzTestForCoverage() error {
@@ -37,8 +47,20 @@ func (cov *CoverageGenerator) BuildFuzzTestForCoverage() error {
 }
 
 func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
+	// Fuzz tests of workspace packages are executed in the directory
+	// of the package
+	var err error
+	cov.packageDir, cov.testPathPattern, err = nodejs.ResolveFuzzTest(cov.ProjectDir, cov.TestPathPattern)
+	if err != nil {
+		return "", err
+	}
+	cov.packageManager, err = nodejs.DetectPackageManager(cov.ProjectDir)
+	if err != nil {
+		return "", err
+	}
+
 	// check if the specified path and name patterns have at least one match
-	err := cov.validateFuzzTest()
+	err = cov.validateFuzzTest()
 	if err != nil {
 		return "", err
 	}
@@ -51,16 +73,34 @@ func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
 		if err != nil {
 			return "", errors.WithStack(err)
 		}
+		// Jest is executed in the package directory, so a relative
+		// output path would be resolved relative to that directory
+		cov.OutputPath, err = filepath.Abs(cov.OutputPath)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
 	}
 
-	args := []string{"jest", "--coverage"}
-	args = append(args, options.JazzerJSTestPathPatternFlag(cov.TestPathPattern))
+	args := []string{"--coverage"}
+	args = append(args, options.JazzerJSTestPathPatternFlag(cov.testPathPattern))
 	args = append(args, options.JazzerJSTestNamePatternFlag(cov.TestNamePattern))
 	args = append(args, options.JazzerJSCoverageDirectoryFlag(cov.OutputPath))
 	// the lcov coverage reporter generates both the lcov.info and an html report
 	args = append(args, options.JazzerJSCoverageReportersFlag(coverage.FormatLCOV))
 
-	err = cov.runNPXCommand(args, cov.BuildStdout, cov.BuildStderr)
+	// In regression mode, Jest only executes the inputs in the corpus
+	// directories of Jazzer.js. To include the inputs of additional
+	// corpus dirs, the fuzz test is run in fuzzing mode with -runs=0,
+	// which makes libfuzzer execute all corpus inputs and then exit.
+	var env []string
+	if len(cov.CorpusDirs) > 0 {
+		env, err = cov.corpusEnv()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = cov.runJestCommand(args, env, cov.BuildStdout, cov.BuildStderr)
 	if err != nil {
 		return "", err
 	}
@@ -88,12 +128,12 @@ func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
 
 func (cov *CoverageGenerator) validateFuzzTest() error {
 	// list all fuzz tests with the specified path and name patterns
-	args := []string{"jest", "--listTests"}
-	args = append(args, options.JazzerJSTestPathPatternFlag(cov.TestPathPattern))
+	args := []string{"--listTests"}
+	args = append(args, options.JazzerJSTestPathPatternFlag(cov.testPathPattern))
 	args = append(args, options.JazzerJSTestNamePatternFlag(cov.TestNamePattern))
 
 	stdout := new(bytes.Buffer)
-	err := cov.runNPXCommand(args, stdout, stdout)
+	err := cov.runJestCommand(args, nil, stdout, stdout)
 	if err != nil {
 		return err
 	}
@@ -110,9 +150,33 @@ func (cov *CoverageGenerator) validateFuzzTest() error {
 	return nil
 }
 
-func (cov *CoverageGenerator) runNPXCommand(args []string, stdout, stderr io.Writer) error {
-	cmd := executil.Command("npx", args...)
-	cmd.Dir = cov.ProjectDir
+// corpusEnv returns the environment variables which make Jazzer.js
+// execute the inputs of the corpus dirs.
+func (cov *CoverageGenerator) corpusEnv() ([]string, error) {
+	// Jest is executed in the package directory, so relative corpus
+	// dirs would be resolved relative to that directory
+	fuzzerOptions := []string{"-runs=0"}
+	for _, dir := range cov.CorpusDirs {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		fuzzerOptions = append(fuzzerOptions, absDir)
+	}
+	fuzzerOptionsJSON, err := json.Marshal(fuzzerOptions)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return []string{"JAZZER_FUZZ=1", "JAZZER_FUZZER_OPTIONS=" + string(fuzzerOptionsJSON)}, nil
+}
+
+func (cov *CoverageGenerator) runJestCommand(args, env []string, stdout, stderr io.Writer) error {
+	args = append(nodejs.JestCommand(cov.packageManager), args...)
+	cmd := executil.Command(args[0], args[1:]...)
+	cmd.Dir = cov.packageDir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
@@ -128,7 +192,7 @@ func (cov *CoverageGenerator) runNPXCommand(args []string, stdout, stderr io.Wri
 		}
 	}()
 
-	log.Debugf("Running npx command: %s", strings.Join(stringutil.QuotedStrings(cmd.Args), " "))
+	log.Debugf("Running jest command: %s", strings.Join(stringutil.QuotedStrings(cmd.Args), " "))
 	err := cmd.Run()
 	if err != nil {
 		// The jest test runner returns exit code 1 if not all tests
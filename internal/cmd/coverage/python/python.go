@@ -0,0 +1,267 @@
+package python
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/otiai10/copy"
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/build/python"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/coverage"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/options"
+	parser "code-intelligence.com/cifuzz/pkg/parser/coverage"
+	"code-intelligence.com/cifuzz/pkg/runfiles"
+	"code-intelligence.com/cifuzz/util/envutil"
+	"code-intelligence.com/cifuzz/util/fileutil"
+	"code-intelligence.com/cifuzz/util/stringutil"
+)
+
+// Installed packages are excluded from the report, also if they are
+// installed in a virtual environment inside the project directory
+var omittedPaths = []string{"*/site-packages/*", "*/dist-packages/*"}
+
+type CoverageGenerator struct {
+	OutputFormat string
+	OutputPath   string
+	FuzzTest     string
+	CorpusDirs   []string
+	ProjectDir   string
+
+	Stderr      io.Writer
+	BuildStdout io.Writer
+	BuildStderr io.Writer
+
+	buildResult *build.BuildResult
+	pythonBin   string
+	env         []string
+}
+
+// Python fuzz tests don't have to be built
+func (cov *CoverageGenerator) BuildFuzzTestForCoverage() error {
+	return nil
+}
+
+func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
+	err := cov.prepare()
+	if err != nil {
+		return "", err
+	}
+
+	tempDir, err := os.MkdirTemp("", "cifuzz-python-coverage-")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(tempDir)
+
+	dataFile := filepath.Join(tempDir, ".coverage")
+	err = cov.runFuzzTest(dataFile, tempDir)
+	if err != nil {
+		return "", err
+	}
+
+	lcovReportPath := filepath.Join(tempDir, "coverage.lcov")
+	err = cov.runCoverage("lcov", "--data-file", dataFile, "-o", lcovReportPath)
+	if err != nil {
+		return "", err
+	}
+
+	// Print the summary table
+	lcovReport, err := os.Open(lcovReportPath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer lcovReport.Close()
+	summary, err := parser.ParseLCOVReportIntoSummary(lcovReport)
+	if err != nil {
+		return "", err
+	}
+	summary.PrintTable(cov.Stderr)
+
+	switch cov.OutputFormat {
+	case coverage.FormatHTML:
+		return cov.generateHTMLReport(dataFile)
+	case coverage.FormatLCOV:
+		outputPath := cov.OutputPath
+		if outputPath == "" {
+			// Like for the other build systems, the lcov report is
+			// created in the current working directory by default
+			name, err := python.Name(cov.ProjectDir, cov.FuzzTest)
+			if err != nil {
+				return "", err
+			}
+			outputPath = filepath.Base(name) + ".coverage.lcov"
+		}
+		err = copy.Copy(lcovReportPath, outputPath)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		return outputPath, nil
+	default:
+		return "", errors.Errorf("Unsupported output format %q", cov.OutputFormat)
+	}
+}
+
+// prepare checks that the fuzz test exists and sets up the environment
+// to run it in
+func (cov *CoverageGenerator) prepare() error {
+	var err error
+	cov.buildResult, err = python.BuildResult(cov.ProjectDir, cov.FuzzTest)
+	if err != nil {
+		return err
+	}
+
+	cov.pythonBin, err = runfiles.Finder.PythonPath()
+	if err != nil {
+		return err
+	}
+
+	// Like when running the fuzz test, the modules of the project can
+	// be imported relative to the project directory
+	cov.env = os.Environ()
+	pythonPath := envutil.AppendToPathList(envutil.Getenv(cov.env, "PYTHONPATH"), cov.ProjectDir)
+	cov.env, err = envutil.Setenv(cov.env, "PYTHONPATH", pythonPath)
+	return err
+}
+
+// runFuzzTest runs the fuzz test on all inputs of the corpus under
+// coverage.py. If an input makes the fuzz test crash, the process exits
+// without coverage.py writing its data, so in that case we run the
+// inputs one by one and exclude the crashing ones.
+func (cov *CoverageGenerator) runFuzzTest(dataFile string, tempDir string) error {
+	corpusDirs, err := cov.corpusDirs()
+	if err != nil {
+		return err
+	}
+
+	// Crashing inputs are written to the current working directory by
+	// default
+	artifactPrefix := options.LibFuzzerArtifactPrefixFlag(tempDir + string(filepath.Separator))
+
+	// With -runs=0, libFuzzer only runs the fuzz test on the inputs of
+	// the corpus dirs (or on the empty input if there are none)
+	args := append([]string{"-runs=0", artifactPrefix}, corpusDirs...)
+	err = cov.runUnderCoverage(dataFile, false, args...)
+	var exitErr *exec.ExitError
+	if err == nil || !errors.As(err, &exitErr) || len(corpusDirs) == 0 {
+		return err
+	}
+
+	inputs, err := corpusInputs(corpusDirs)
+	if err != nil {
+		return err
+	}
+	fileutil.Cleanup(dataFile)
+	for _, input := range inputs {
+		// If files are passed instead of directories, libFuzzer runs
+		// the fuzz test on each of them once
+		err = cov.runUnderCoverage(dataFile, true, artifactPrefix, input)
+		if errors.As(err, &exitErr) {
+			log.Warnf("Excluding input %s from the coverage report because it makes the fuzz test crash",
+				fileutil.PrettifyPath(input))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runUnderCoverage runs the fuzz test with the given arguments via
+// `coverage run`, optionally appending to the existing coverage data.
+func (cov *CoverageGenerator) runUnderCoverage(dataFile string, appendData bool, args ...string) error {
+	coverageArgs := []string{
+		"run",
+		"--data-file", dataFile,
+		"--source", cov.ProjectDir,
+		"--omit", strings.Join(omittedPaths, ","),
+	}
+	if appendData {
+		coverageArgs = append(coverageArgs, "--append")
+	}
+	coverageArgs = append(coverageArgs, cov.buildResult.Executable)
+	coverageArgs = append(coverageArgs, args...)
+	return cov.runCoverage(coverageArgs...)
+}
+
+func (cov *CoverageGenerator) runCoverage(args ...string) error {
+	cmd := exec.Command(cov.pythonBin, append([]string{"-m", "coverage"}, args...)...)
+	// Run in the project directory, so that coverage.py stores the
+	// source file paths relative to it
+	cmd.Dir = cov.ProjectDir
+	cmd.Env = cov.env
+	cmd.Stdout = cov.BuildStdout
+	cmd.Stderr = cov.BuildStderr
+	log.Debugf("Command: %s", strings.Join(stringutil.QuotedStrings(cmd.Args), " "))
+	err := cmd.Run()
+	if err != nil {
+		return cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+	return nil
+}
+
+// corpusDirs returns the existing directories of the seed corpus, the
+// generated corpus and the user-specified corpus dirs.
+func (cov *CoverageGenerator) corpusDirs() ([]string, error) {
+	var corpusDirs []string
+	for _, dir := range append([]string{cov.buildResult.SeedCorpus, cov.buildResult.GeneratedCorpus}, cov.CorpusDirs...) {
+		exists, err := fileutil.Exists(dir)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			corpusDirs = append(corpusDirs, dir)
+		}
+	}
+	return corpusDirs, nil
+}
+
+func corpusInputs(corpusDirs []string) ([]string, error) {
+	var inputs []string
+	for _, dir := range corpusDirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type().IsRegular() {
+				inputs = append(inputs, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return inputs, nil
+}
+
+func (cov *CoverageGenerator) generateHTMLReport(dataFile string) (string, error) {
+	outputPath := cov.OutputPath
+	if outputPath == "" {
+		// If no output path is specified, we create the output in a
+		// temporary directory.
+		var err error
+		outputPath, err = os.MkdirTemp("", "coverage-")
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+	outputPath, err := filepath.Abs(outputPath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	err = cov.runCoverage("html", "--data-file", dataFile, "-d", outputPath)
+	if err != nil {
+		return "", err
+	}
+	return outputPath, nil
+}
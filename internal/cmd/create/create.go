@@ -154,6 +154,29 @@ func (c *createCmd) printBuildSystemInstructions() {
 	// Printing build system instructions is best-effort: Do not fail on errors.
 	switch c.opts.BuildSystem {
 	case config.BuildSystemBazel:
+		if c.opts.testType == config.Java {
+			log.Printf(`
+Define a bazel target for the fuzz test by adding the following to the
+BUILD.bazel file, with target_class set to the fully qualified name of
+the fuzz test class:
+
+    load("@rules_fuzzing//fuzzing:java_defs.bzl", "java_fuzz_test")
+
+    java_fuzz_test(
+        name = "%[1]s",
+        srcs = ["%[2]s"],
+        target_class = "%[1]s",
+        corpus = glob(["%[1]s_inputs/**"], allow_empty = True),
+        deps = [
+            "@maven//:com_code_intelligence_jazzer_junit",
+            "@maven//:org_junit_jupiter_junit_jupiter_api",
+        ],
+        runtime_deps = ["@maven//:com_code_intelligence_jazzer"],
+    )
+
+`, strings.TrimSuffix(filename, filepath.Ext(filename)), filename)
+			return
+		}
 		log.Printf(`
 Define a bazel target for the fuzz test by adding the following to the
 BUILD.bazel file:
@@ -181,6 +204,17 @@ a regular add_executable(...):
 
     add_fuzz_test(%s %s)
 
+`, strings.TrimSuffix(filename, filepath.Ext(filename)), filename)
+
+	case config.BuildSystemMeson:
+		log.Printf(`
+Create an executable for the fuzz test in the meson.build file of its
+directory, which uses the options declared for cifuzz:
+
+    executable('%[1]s', '%[2]s',
+      cpp_args: get_option('cifuzz_fuzz_test_args'),
+      link_args: get_option('cifuzz_fuzz_test_link_args'))
+
 `, strings.TrimSuffix(filename, filepath.Ext(filename)), filename)
 
 	case config.BuildSystemOther:
@@ -196,6 +230,15 @@ The FUZZ_TEST_CFLAGS and FUZZ_TEST_LDFLAGS environment variables are
 set by cifuzz when building the fuzz test, please make sure that
 $FUZZ_TEST_CFLAGS is passed as a command-line argument to the compiler
 and $FUZZ_TEST_LDFLAGS to the linker.`)
+	case config.BuildSystemPython:
+		log.Printf(`
+Python fuzz tests don't have to be built. Import the modules you want
+to fuzz in the 'atheris.instrument_imports()' block of the fuzz test
+and run it via:
+
+    cifuzz run %s
+
+`, c.opts.outputPath)
 	}
 }
 
@@ -212,8 +255,12 @@ func (c *createCmd) checkDependencies() {
 		case "windows":
 			deps = append(deps, dependencies.VisualStudio)
 		}
+	case config.BuildSystemMeson:
+		deps = []dependencies.Key{dependencies.Meson, dependencies.Clang}
 	case config.BuildSystemOther:
 		deps = []dependencies.Key{dependencies.Clang}
+	case config.BuildSystemPython:
+		deps = []dependencies.Key{dependencies.Python, dependencies.Atheris}
 	}
 	err := dependencies.Check(deps, "")
 	if err != nil {
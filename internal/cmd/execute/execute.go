@@ -7,27 +7,36 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/otiai10/copy"
 	"github.com/pkg/errors"
 	"github.com/pterm/pterm"
 	"github.com/pterm/pterm/putils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"code-intelligence.com/cifuzz/internal/build/nodejs"
 	"code-intelligence.com/cifuzz/internal/bundler/archive"
 	javaCoverage "code-intelligence.com/cifuzz/internal/cmd/coverage/java"
 	llvmCoverage "code-intelligence.com/cifuzz/internal/cmd/coverage/llvm"
+	nodeCoverage "code-intelligence.com/cifuzz/internal/cmd/coverage/node"
 	"code-intelligence.com/cifuzz/internal/cmd/run/adapter"
 	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/internal/container"
 	"code-intelligence.com/cifuzz/internal/coverage"
 	"code-intelligence.com/cifuzz/pkg/java/sourcemap"
 	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/runner/aflpp"
 	"code-intelligence.com/cifuzz/pkg/runner/jazzer"
+	"code-intelligence.com/cifuzz/pkg/runner/jazzerjs"
 	"code-intelligence.com/cifuzz/pkg/runner/libfuzzer"
+	"code-intelligence.com/cifuzz/pkg/symbolizer"
 	"code-intelligence.com/cifuzz/util/fileutil"
 	"code-intelligence.com/cifuzz/util/stringutil"
 )
@@ -38,7 +47,10 @@ type executeOpts struct {
 	PrintBundleMetadata bool   `mapstructure:"print-bundle-metadata"`
 	JSONOutputFilePath  string `mapstructure:"json-output-file"`
 	GeneratedCorpusDir  string `mapstructure:"generated-corpus-dir"`
+	ExportCorpusDir     string `mapstructure:"export-corpus-dir"`
 	CoverageOutputPath  string `mapstructure:"coverage-output-path"`
+	Engine              string `mapstructure:"engine"`
+	Sanitizer           string `mapstructure:"sanitizer"`
 
 	name string
 }
@@ -73,12 +85,18 @@ It is currently only intended for use with the 'cifuzz container' subcommand.
 			cmdutils.ViperMustBindPFlag("stop-signal-file", cmd.Flags().Lookup("stop-signal-file"))
 			cmdutils.ViperMustBindPFlag("json-output-file", cmd.Flags().Lookup("json-output-file"))
 			cmdutils.ViperMustBindPFlag("generated-corpus-dir", cmd.Flags().Lookup("generated-corpus-dir"))
+			cmdutils.ViperMustBindPFlag("export-corpus-dir", cmd.Flags().Lookup("export-corpus-dir"))
+			cmdutils.ViperMustBindPFlag("engine", cmd.Flags().Lookup("engine"))
+			cmdutils.ViperMustBindPFlag("sanitizer", cmd.Flags().Lookup("sanitizer"))
 			opts.SingleFuzzTest = viper.GetBool("single-fuzz-test")
 			opts.PrintBundleMetadata = viper.GetBool("print-bundle-metadata")
 			opts.CoverageOutputPath = viper.GetString("coverage-output-path")
 			opts.PrintJSON = viper.GetBool("print-json")
 			opts.JSONOutputFilePath = viper.GetString("json-output-file")
 			opts.GeneratedCorpusDir = viper.GetString("generated-corpus-dir")
+			opts.ExportCorpusDir = viper.GetString("export-corpus-dir")
+			opts.Engine = viper.GetString("engine")
+			opts.Sanitizer = viper.GetString("sanitizer")
 		},
 		RunE: func(c *cobra.Command, args []string) error {
 			if signalFile := viper.GetString("stop-signal-file"); signalFile != "" {
@@ -122,6 +140,13 @@ It is currently only intended for use with the 'cifuzz container' subcommand.
 	cmd.Flags().String("stop-signal-file", "", "CI Fuzz will create a file 'cifuzz-execution-finished' upon exit")
 	cmd.Flags().String("json-output-file", "", "Print output as JSON to the specified file (implies --json)")
 	cmd.Flags().String("generated-corpus-dir", "/tmp/generated-corpus", "The directory where inputs which increased the coverage are stored. The user running the container must have write access to this directory.")
+	cmd.Flags().String("export-corpus-dir", "", "Write the generated corpus as a <fuzz test>.tar.gz archive to the specified directory after running the fuzz test.\n"+
+		"The archive can be added to the seeds of future bundles via 'cifuzz bundle --corpus-from'.")
+	cmd.Flags().String("engine", "", "Only execute fuzz tests which were bundled for the specified `engine` (\"libfuzzer\" or \"aflpp\").\n"+
+		"By default, the engine specified in the bundle metadata is used.")
+	cmd.Flags().String("sanitizer", "", "Execute the variant of the fuzz test which was built with the specified `sanitizer`\n"+
+		"(e.g. \"memory\" or \"thread\"), if the bundle contains multiple variants.\n"+
+		"By default, the variant built with AddressSanitizer is executed.")
 
 	// Note: If a flag should be configurable via viper as well (i.e.
 	//       via cifuzz.yaml and CIFUZZ_* environment variables), bind
@@ -173,7 +198,12 @@ func (c *executeCmd) run(metadata *archive.Metadata) error {
 		}
 	}
 
-	fuzzer, err := findFuzzer(c.opts.name, metadata)
+	err := validateEngine(c.opts.Engine, metadata)
+	if err != nil {
+		return err
+	}
+
+	fuzzer, err := findFuzzer(c.opts.name, metadata, c.opts.Engine, c.opts.Sanitizer)
 	if err != nil {
 		return err
 	}
@@ -187,6 +217,17 @@ func (c *executeCmd) run(metadata *archive.Metadata) error {
 		return errors.WithStack(err)
 	}
 
+	// The container might not contain llvm-symbolizer, in which case
+	// the sanitizers print raw addresses instead of stack frames
+	var symbolizerOpts *symbolizer.Options
+	if fuzzer.Engine != "JAVA_LIBFUZZER" && fuzzer.Engine != "JAVASCRIPT_LIBFUZZER" {
+		symbolizerOpts = &symbolizer.Options{
+			Binary:      fuzzer.Path,
+			LibraryDirs: fuzzer.LibraryPaths,
+			ProjectDir:  fuzzer.ProjectDir,
+		}
+	}
+
 	reportHandler, err := reporthandler.NewReportHandler(
 		getFuzzerName(fuzzer),
 		&reporthandler.ReportHandlerOptions{
@@ -199,6 +240,7 @@ func (c *executeCmd) run(metadata *archive.Metadata) error {
 			SkipSavingFinding: true,
 			PrinterOutput:     printerOutput,
 			JSONOutput:        jsonOutput,
+			SymbolizerOptions: symbolizerOpts,
 		})
 	if err != nil {
 		return err
@@ -221,6 +263,9 @@ func (c *executeCmd) run(metadata *archive.Metadata) error {
 	var runner adapter.FuzzerRunner
 	var targetClass string
 	var targetMethod string
+	var projectDir string
+	var testPathPattern string
+	var testNamePattern string
 
 	switch fuzzer.Engine {
 	case "JAVA_LIBFUZZER":
@@ -262,10 +307,11 @@ func (c *executeCmd) run(metadata *archive.Metadata) error {
 			runnerOpts.SourceMap = sourceMap
 		}
 
-		targetClass = fuzzer.Name
+		// Fuzz tests of modules are prefixed with the module directory
+		_, targetClass = cmdutils.SeparateModuleAndTargetClass(fuzzer.Name)
 		targetMethod = ""
-		if strings.Contains(fuzzer.Name, "::") {
-			split := strings.Split(fuzzer.Name, "::")
+		if strings.Contains(targetClass, "::") {
+			split := strings.Split(targetClass, "::")
 			targetClass = split[0]
 			targetMethod = split[1]
 		}
@@ -276,35 +322,77 @@ func (c *executeCmd) run(metadata *archive.Metadata) error {
 			LibfuzzerOptions: runnerOpts,
 		}
 		runner = jazzer.NewRunner(runnerOpts)
-	default:
-		// Use dictionary file if the bundle includes one.
-		dictFileName := fuzzer.Dictionary
-		exists, err := fileutil.Exists(dictFileName)
+	case "JAVASCRIPT_LIBFUZZER":
+		err = addBundledSeedsAndDictionary(fuzzer, runnerOpts)
 		if err != nil {
 			return err
 		}
-		if exists {
-			runnerOpts.Dictionary = dictFileName
-		}
 
-		// Use seed corpus dirs if the bundle includes any.
-		entries, err := os.ReadDir(fuzzer.Seeds)
-		// Don't return an error if the directory doesn't exist.
-		if err != nil && !os.IsNotExist(err) {
+		projectDir, err = filepath.Abs(fuzzer.Path)
+		if err != nil {
 			return errors.WithStack(err)
 		}
-		for _, entry := range entries {
-			if !entry.IsDir() {
-				return errors.Errorf("unexpected file in user seed corpus dir %q: %s", fuzzer.Seeds, entry.Name())
-			}
-			seedCorpusDir := fmt.Sprintf("%s/%s", fuzzer.Seeds, entry.Name())
-			runnerOpts.SeedCorpusDirs = append(runnerOpts.SeedCorpusDirs, seedCorpusDir)
+		packageManager, err := nodejs.DetectPackageManager(projectDir)
+		if err != nil {
+			return err
+		}
+		err = installNodeDependencies(projectDir, packageManager, printerOutput)
+		if err != nil {
+			return err
 		}
 
+		testPathPattern, testNamePattern = cmdutils.SeparateTestPathAndNamePattern(fuzzer.Name)
+		packageDir, packageTestPathPattern, err := nodejs.ResolveFuzzTest(projectDir, testPathPattern)
+		if err != nil {
+			return err
+		}
+		runner = jazzerjs.NewRunner(&jazzerjs.RunnerOptions{
+			TestPathPattern:  packageTestPathPattern,
+			TestNamePattern:  testNamePattern,
+			PackageManager:   packageManager,
+			PackageDir:       packageDir,
+			LibfuzzerOptions: runnerOpts,
+		})
+	case "AFLPLUSPLUS":
+		err = addBundledSeedsAndDictionary(fuzzer, runnerOpts)
+		if err != nil {
+			return err
+		}
+		runner = aflpp.NewRunner(&aflpp.RunnerOptions{
+			Dictionary:         runnerOpts.Dictionary,
+			EngineArgs:         runnerOpts.EngineArgs,
+			EnvVars:            runnerOpts.EnvVars,
+			FuzzTarget:         runnerOpts.FuzzTarget,
+			GeneratedCorpusDir: runnerOpts.GeneratedCorpusDir,
+			KeepColor:          runnerOpts.KeepColor,
+			LibraryDirs:        runnerOpts.LibraryDirs,
+			ProjectDir:         runnerOpts.ProjectDir,
+			ReportHandler:      runnerOpts.ReportHandler,
+			SeedCorpusDirs:     runnerOpts.SeedCorpusDirs,
+			Timeout:            runnerOpts.Timeout,
+			Verbose:            runnerOpts.Verbose,
+		})
+	default:
+		err = addBundledSeedsAndDictionary(fuzzer, runnerOpts)
+		if err != nil {
+			return err
+		}
 		runner = libfuzzer.NewRunner(runnerOpts)
 	}
 
 	err = adapter.ExecuteFuzzerRunner(runner)
+	// Export the corpus even if the fuzzer run failed (e.g. because the
+	// container was stopped), so that the progress is not lost.
+	if c.opts.ExportCorpusDir != "" {
+		exportErr := c.exportCorpus(getFuzzerName(fuzzer))
+		if exportErr != nil {
+			if err != nil {
+				log.Error(exportErr)
+				return err
+			}
+			return exportErr
+		}
+	}
 	if err != nil {
 		return err
 	}
@@ -345,6 +433,36 @@ func (c *executeCmd) run(metadata *archive.Metadata) error {
 		}
 
 		return nil
+	case "JAVASCRIPT_LIBFUZZER":
+		// Jest runs the fuzz test on the inputs in the corpus
+		// directories of Jazzer.js and the corpus dirs of the fuzzing
+		// run to produce the report
+		corpusDirs := append(runnerOpts.SeedCorpusDirs, runnerOpts.GeneratedCorpusDir, container.ManagedSeedCorpusDir)
+		outputDir, err := os.MkdirTemp("", "jazzerjs-coverage-")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer fileutil.Cleanup(outputDir)
+		gen := &nodeCoverage.CoverageGenerator{
+			OutputFormat:    coverage.FormatLCOV,
+			OutputPath:      outputDir,
+			TestPathPattern: testPathPattern,
+			TestNamePattern: testNamePattern,
+			ProjectDir:      projectDir,
+			CorpusDirs:      corpusDirs,
+			Stderr:          os.Stderr,
+		}
+
+		if viper.GetBool("verbose") {
+			gen.BuildStdout = printerOutput
+			gen.BuildStderr = printerOutput
+		}
+
+		reportPath, err := gen.GenerateCoverageReport()
+		if err != nil {
+			return err
+		}
+		return errors.WithStack(copy.Copy(reportPath, c.opts.CoverageOutputPath))
 	default:
 		// libFuzzer fuzz tests have a separate coverage binary which
 		// is used to produce coverage data. The coverage binary is
@@ -364,6 +482,72 @@ func (c *executeCmd) run(metadata *archive.Metadata) error {
 	}
 }
 
+// addBundledSeedsAndDictionary configures the runner to use the
+// dictionary and seed corpus dirs of the fuzzer if the bundle includes
+// any.
+func addBundledSeedsAndDictionary(fuzzer *archive.Fuzzer, runnerOpts *libfuzzer.RunnerOptions) error {
+	exists, err := fileutil.Exists(fuzzer.Dictionary)
+	if err != nil {
+		return err
+	}
+	if exists {
+		runnerOpts.Dictionary = fuzzer.Dictionary
+	}
+
+	entries, err := os.ReadDir(fuzzer.Seeds)
+	// Don't return an error if the directory doesn't exist.
+	if err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			return errors.Errorf("unexpected file in user seed corpus dir %q: %s", fuzzer.Seeds, entry.Name())
+		}
+		seedCorpusDir := fmt.Sprintf("%s/%s", fuzzer.Seeds, entry.Name())
+		runnerOpts.SeedCorpusDirs = append(runnerOpts.SeedCorpusDirs, seedCorpusDir)
+	}
+	return nil
+}
+
+// installNodeDependencies installs the dependencies of the bundled
+// Node.js project and its workspace packages, unless they were vendored
+// in the bundle or already installed by a previous execution.
+func installNodeDependencies(projectDir string, packageManager string, output io.Writer) error {
+	installed, err := nodejs.DependenciesInstalled(projectDir)
+	if err != nil {
+		return err
+	}
+	if installed {
+		return nil
+	}
+
+	args, err := nodejs.InstallCommand(projectDir, packageManager)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = projectDir
+	cmd.Stdout = output
+	cmd.Stderr = output
+	log.Infof("Installing dependencies: %s", strings.Join(stringutil.QuotedStrings(cmd.Args), " "))
+	err = cmd.Run()
+	if err != nil {
+		return cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+	return nil
+}
+
+// exportCorpus writes the generated corpus to a corpus archive in the
+// export corpus directory.
+func (c *executeCmd) exportCorpus(fuzzerName string) error {
+	archivePath, err := archive.WriteCorpusArchive(c.opts.GeneratedCorpusDir, fuzzerName, c.opts.ExportCorpusDir)
+	if err != nil {
+		return errors.WithMessage(err, "Failed to export corpus")
+	}
+	log.Infof("Exported corpus to %s", archivePath)
+	return nil
+}
+
 // getMetadata returns the bundle metadata from the bundle.yaml file.
 func getMetadata() (*archive.Metadata, error) {
 	exists, err := fileutil.Exists(archive.MetadataFileName)
@@ -419,12 +603,49 @@ func printNotice(metadata *archive.Metadata) error {
 		}
 		fmt.Printf("  %s\n", fuzzerName)
 		fmt.Printf("    using: %s\n", fuzzer.Engine)
-		fmt.Printf("    run fuzz test with: cifuzz execute %s\n", fuzzerName)
+		if isDefaultSanitizer(fuzzer.Sanitizer) {
+			fmt.Printf("    run fuzz test with: cifuzz execute %s\n", fuzzerName)
+		} else {
+			fmt.Printf("    sanitizer: %s\n", fuzzer.Sanitizer)
+			fmt.Printf("    run fuzz test with: cifuzz execute --sanitizer %s %s\n", strings.ToLower(fuzzer.Sanitizer), fuzzerName)
+		}
 		fmt.Println("")
 	}
 	return nil
 }
 
+// The names of the engines in the bundle metadata
+var bundleEngineNames = map[config.Engine]string{
+	config.Libfuzzer:   "LIBFUZZER",
+	config.AFLPlusPlus: "AFLPLUSPLUS",
+}
+
+// validateEngine checks that the engine specified via --engine is
+// known and that the bundle contains fuzz tests for it
+func validateEngine(engine string, bundleMetadata *archive.Metadata) error {
+	if engine == "" {
+		return nil
+	}
+
+	bundleEngineName, ok := bundleEngineNames[config.Engine(engine)]
+	if !ok {
+		var names []string
+		for _, e := range config.Engines {
+			names = append(names, string(e))
+		}
+		return cmdutils.WrapIncorrectUsageError(errors.Errorf(
+			"Unknown engine %q, supported engines are: %s", engine, strings.Join(names, ", ")))
+	}
+
+	for _, fuzzer := range bundleMetadata.Fuzzers {
+		if fuzzer.Engine == bundleEngineName {
+			return nil
+		}
+	}
+	return cmdutils.WrapIncorrectUsageError(errors.Errorf(
+		"The bundle doesn't contain any fuzz tests for the engine %q", engine))
+}
+
 // getFuzzerName returns the fuzzer name. Some Fuzzer define Name (jazzer) and some define Target (libfuzzer).
 func getFuzzerName(fuzzer *archive.Fuzzer) string {
 	if fuzzer.Name != "" {
@@ -434,15 +655,19 @@ func getFuzzerName(fuzzer *archive.Fuzzer) string {
 }
 
 // findFuzzer returns the fuzzer with the given name in Fuzzers list in Bundle Metadata.
-func findFuzzer(nameToFind string, bundleMetadata *archive.Metadata) (*archive.Fuzzer, error) {
-	return findBinary(nameToFind, bundleMetadata, false)
+// If an engine is specified, only fuzzers bundled for that engine are
+// considered. If the bundle contains multiple variants of the fuzzer
+// built with different sanitizers, the one built with the specified
+// sanitizer or, by default, the one built with ASan is returned.
+func findFuzzer(nameToFind string, bundleMetadata *archive.Metadata, engine string, sanitizer string) (*archive.Fuzzer, error) {
+	return findBinary(nameToFind, bundleMetadata, false, engine, sanitizer)
 }
 
 func findCoverageBinary(nameToFind string, bundleMetadata *archive.Metadata) (*archive.Fuzzer, error) {
-	return findBinary(nameToFind, bundleMetadata, true)
+	return findBinary(nameToFind, bundleMetadata, true, "", "")
 }
 
-func findBinary(nameToFind string, bundleMetadata *archive.Metadata, isCoverageBinary bool) (*archive.Fuzzer, error) {
+func findBinary(nameToFind string, bundleMetadata *archive.Metadata, isCoverageBinary bool, engine string, sanitizer string) (*archive.Fuzzer, error) {
 	// libFuzzer fuzz tests contain two entries in the metadata file,
 	// one for the fuzz test and one for the coverage binary. The
 	// coverage binary has the engine set to "LLVM_COV".
@@ -458,6 +683,18 @@ func findBinary(nameToFind string, bundleMetadata *archive.Metadata, isCoverageB
 		// to the map if it has engine set to anything other than
 		// "LLVM_COV".
 		if !isCoverageBinary && fuzzer.Engine != "LLVM_COV" {
+			if engine != "" && fuzzer.Engine != bundleEngineNames[config.Engine(engine)] {
+				continue
+			}
+			if sanitizer != "" && !strings.EqualFold(fuzzer.Sanitizer, sanitizer) {
+				continue
+			}
+			// Opt-in sanitizers like MSan and TSan are bundled as
+			// additional variants of the fuzzer, which must not
+			// replace the default ASan variant
+			if existing, ok := fuzzers[name]; ok && sanitizer == "" && isDefaultSanitizer(existing.Sanitizer) {
+				continue
+			}
 			fuzzers[name] = fuzzer
 		}
 	}
@@ -479,5 +716,14 @@ func findBinary(nameToFind string, bundleMetadata *archive.Metadata, isCoverageB
 		return fuzzer, nil
 	}
 
+	if sanitizer != "" {
+		return nil, errors.Errorf("fuzzer '%s' built with sanitizer '%s' not found in a bundle metadata file", nameToFind, sanitizer)
+	}
 	return nil, errors.Errorf("fuzzer '%s' not found in a bundle metadata file", nameToFind)
 }
+
+// isDefaultSanitizer returns true if the sanitizer of a fuzzer in the
+// bundle metadata is the one of the default fuzzing variant
+func isDefaultSanitizer(sanitizer string) bool {
+	return sanitizer == "" || sanitizer == "ADDRESS"
+}
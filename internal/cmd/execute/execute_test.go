@@ -25,11 +25,11 @@ func Test_getFuzzer(t *testing.T) {
 			},
 		},
 	}
-	fuzzer, err := findFuzzer("a-fuzzer", sampleMetadata)
+	fuzzer, err := findFuzzer("a-fuzzer", sampleMetadata, "", "")
 	require.NoError(t, err)
 	require.Equal(t, "a-fuzzer", fuzzer.Name)
 
-	fuzzer, err = findFuzzer("b-fuzzer", sampleMetadata)
+	fuzzer, err = findFuzzer("b-fuzzer", sampleMetadata, "", "")
 	require.EqualErrorf(t, err, "fuzzer 'b-fuzzer' not found in a bundle metadata file", "error message mismatch")
 }
 
@@ -85,6 +85,16 @@ func Test_findFuzzer(t *testing.T) {
 	type args struct {
 		nameToFind     string
 		bundleMetadata *archive.Metadata
+		engine         string
+		sanitizer      string
+	}
+	multiVariantMetadata := &archive.Metadata{
+		Fuzzers: []*archive.Fuzzer{
+			{Target: "a-fuzzer", Engine: "LIBFUZZER", Sanitizer: "ADDRESS"},
+			{Target: "a-fuzzer", Engine: "LLVM_COV"},
+			{Target: "a-fuzzer", Engine: "LIBFUZZER", Sanitizer: "MEMORY"},
+			{Target: "a-fuzzer", Engine: "LIBFUZZER", Sanitizer: "THREAD"},
+		},
 	}
 	tests := []struct {
 		name    string
@@ -165,6 +175,62 @@ func Test_findFuzzer(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "find fuzzer by engine",
+			args: args{
+				nameToFind: "a-fuzzer",
+				bundleMetadata: &archive.Metadata{
+					Fuzzers: []*archive.Fuzzer{
+						{
+							Target: "a-fuzzer",
+							Engine: "LIBFUZZER",
+						},
+						{
+							Target: "a-fuzzer",
+							Engine: "AFLPLUSPLUS",
+						},
+					},
+				},
+				engine: "aflpp",
+			},
+			want: &archive.Fuzzer{
+				Target: "a-fuzzer",
+				Engine: "AFLPLUSPLUS",
+			},
+		},
+		{
+			name: "find address sanitizer variant by default",
+			args: args{
+				nameToFind:     "a-fuzzer",
+				bundleMetadata: multiVariantMetadata,
+			},
+			want: &archive.Fuzzer{Target: "a-fuzzer", Engine: "LIBFUZZER", Sanitizer: "ADDRESS"},
+		},
+		{
+			name: "find single fuzzer with multiple variants",
+			args: args{
+				bundleMetadata: multiVariantMetadata,
+			},
+			want: &archive.Fuzzer{Target: "a-fuzzer", Engine: "LIBFUZZER", Sanitizer: "ADDRESS"},
+		},
+		{
+			name: "find fuzzer by sanitizer",
+			args: args{
+				nameToFind:     "a-fuzzer",
+				bundleMetadata: multiVariantMetadata,
+				sanitizer:      "memory",
+			},
+			want: &archive.Fuzzer{Target: "a-fuzzer", Engine: "LIBFUZZER", Sanitizer: "MEMORY"},
+		},
+		{
+			name: "error out if sanitizer variant not found",
+			args: args{
+				nameToFind:     "a-fuzzer",
+				bundleMetadata: multiVariantMetadata,
+				sanitizer:      "undefined",
+			},
+			wantErr: true,
+		},
 		{
 			name: "error out if fuzzer not found",
 			args: args{
@@ -183,7 +249,7 @@ func Test_findFuzzer(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := findFuzzer(tt.args.nameToFind, tt.args.bundleMetadata)
+			got, err := findFuzzer(tt.args.nameToFind, tt.args.bundleMetadata, tt.args.engine, tt.args.sanitizer)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("findFuzzer() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -195,6 +261,30 @@ func Test_findFuzzer(t *testing.T) {
 	}
 }
 
+func Test_validateEngine(t *testing.T) {
+	metadata := &archive.Metadata{
+		Fuzzers: []*archive.Fuzzer{
+			{Target: "my_fuzz_test", Engine: "LIBFUZZER"},
+			{Target: "my_fuzz_test", Engine: "LLVM_COV"},
+		},
+	}
+
+	require.NoError(t, validateEngine("", metadata))
+	require.NoError(t, validateEngine("libfuzzer", metadata))
+
+	// Unknown engines are rejected
+	err := validateEngine("libfuzer", metadata)
+	require.Error(t, err)
+	var usageErr *cmdutils.IncorrectUsageError
+	require.ErrorAs(t, err, &usageErr)
+
+	// Known engines which the bundle doesn't contain fuzz tests for are
+	// rejected too
+	err = validateEngine("aflpp", metadata)
+	require.Error(t, err)
+	require.ErrorAs(t, err, &usageErr)
+}
+
 func TestStopSignalFile(t *testing.T) {
 	dir := testutil.BootstrapExampleProjectForTest(t, "execute-stop-signal-test", config.BuildSystemCMake)
 
@@ -13,6 +13,7 @@ import (
 	"golang.org/x/term"
 
 	"code-intelligence.com/cifuzz/internal/api"
+	symbolizeCmd "code-intelligence.com/cifuzz/internal/cmd/finding/symbolize"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/cmdutils/auth"
 	"code-intelligence.com/cifuzz/internal/completion"
@@ -20,9 +21,19 @@ import (
 	"code-intelligence.com/cifuzz/pkg/dialog"
 	"code-intelligence.com/cifuzz/pkg/finding"
 	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/parser/errorid"
 	"code-intelligence.com/cifuzz/util/stringutil"
 )
 
+const (
+	// The number of stack frames for which source code snippets are
+	// printed
+	maxSourceSnippets = 3
+	// The number of lines before and after the crashing line which are
+	// included in the source code snippets
+	sourceSnippetContextLines = 3
+)
+
 type options struct {
 	PrintJSON   bool   `mapstructure:"print-json"`
 	ProjectDir  string `mapstructure:"project-dir"`
@@ -30,6 +41,8 @@ type options struct {
 	Interactive bool   `mapstructure:"interactive"`
 	Server      string `mapstructure:"server"`
 	Project     string `mapstructure:"project"`
+
+	ErrorDetails []*errorid.UserDefinedError `mapstructure:"error-details"`
 }
 
 type findingCmd struct {
@@ -59,7 +72,7 @@ func newWithOptions(opts *options) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return nil
+			return errorid.AddUserDefinedErrors(opts.ErrorDetails)
 		},
 		RunE: func(c *cobra.Command, args []string) error {
 			opts.Interactive = viper.GetBool("interactive")
@@ -88,9 +101,12 @@ func newWithOptions(opts *options) *cobra.Command {
 		cmdutils.AddProjectDirFlag,
 		cmdutils.AddInteractiveFlag,
 		cmdutils.AddServerFlag,
+		cmdutils.AddTransportFlags,
 		cmdutils.AddProjectFlag,
 	)
 
+	cmd.AddCommand(symbolizeCmd.New())
+
 	return cmd
 }
 
@@ -249,8 +265,17 @@ Skipping remote findings because running in non-interactive mode.`)
 }
 
 func (cmd *findingCmd) printFinding(f *finding.Finding) error {
+	snippets, err := f.SourceSnippets(cmd.opts.ProjectDir, maxSourceSnippets, sourceSnippetContextLines)
+	if err != nil {
+		// The finding can still be printed without the snippets
+		log.Warnf("Failed to read source code snippets: %v", err)
+	}
+
 	if cmd.opts.PrintJSON {
-		s, err := stringutil.ToJSONString(f)
+		s, err := stringutil.ToJSONString(struct {
+			*finding.Finding
+			SourceSnippets []*finding.SourceSnippet `json:"source_snippets,omitempty"`
+		}{f, snippets})
 		if err != nil {
 			return err
 		}
@@ -262,6 +287,8 @@ func (cmd *findingCmd) printFinding(f *finding.Finding) error {
 		s := pterm.Style{pterm.Reset, pterm.Bold}.Sprint(f.ShortDescriptionWithName())
 		s += fmt.Sprintf("\nDate: %s\n", f.CreatedAt)
 		s += fmt.Sprintf("\n  %s\n", strings.Join(f.Logs, "\n  "))
+		s += auxiliaryStackTracesString(f)
+		s += sourceSnippetsString(snippets)
 		_, err := fmt.Fprint(cmd.OutOrStdout(), s)
 		if err != nil {
 			return errors.WithStack(err)
@@ -271,6 +298,55 @@ func (cmd *findingCmd) printFinding(f *finding.Finding) error {
 	return nil
 }
 
+// auxiliaryStackTracesString returns a summary of the auxiliary stack
+// traces of the finding, which only contains the frames from source
+// files in the project.
+func auxiliaryStackTracesString(f *finding.Finding) string {
+	var s string
+	for _, trace := range f.AuxiliaryStackTraces {
+		s += fmt.Sprintf("\n%s\n", pterm.Style{pterm.Bold}.Sprint(trace.Name+":"))
+		for _, frame := range trace.StackTrace {
+			location := fmt.Sprintf("%s:%d", frame.SourceFile, frame.Line)
+			if frame.Column != 0 {
+				location += fmt.Sprintf(":%d", frame.Column)
+			}
+			if frame.Function != "" {
+				location = frame.Function + " " + location
+			}
+			s += fmt.Sprintf("  #%d %s\n", frame.FrameNumber, location)
+		}
+	}
+	return s
+}
+
+// sourceSnippetsString returns the source code snippets with line
+// numbers, with the line of the stack frame highlighted.
+func sourceSnippetsString(snippets []*finding.SourceSnippet) string {
+	var s string
+	for _, snippet := range snippets {
+		location := fmt.Sprintf("%s:%d", snippet.SourceFile, snippet.Line)
+		if snippet.Column != 0 {
+			location += fmt.Sprintf(":%d", snippet.Column)
+		}
+		if snippet.Function != "" {
+			location += " in " + snippet.Function
+		}
+		s += fmt.Sprintf("\n%s\n", pterm.Style{pterm.Bold}.Sprint(location))
+
+		lastLine := snippet.StartLine + uint32(len(snippet.Lines)) - 1
+		width := len(fmt.Sprint(lastLine))
+		for i, line := range snippet.Lines {
+			lineNumber := snippet.StartLine + uint32(i)
+			if lineNumber == snippet.Line {
+				s += pterm.Style{pterm.FgRed, pterm.Bold}.Sprintf("> %*d | %s", width, lineNumber, line) + "\n"
+			} else {
+				s += fmt.Sprintf("  %*d | %s\n", width, lineNumber, line)
+			}
+		}
+	}
+	return s
+}
+
 func PrintMoreDetails(f *finding.Finding) {
 	if f.MoreDetails == nil {
 		return
@@ -1,9 +1,11 @@
 package finding
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -167,6 +169,101 @@ func TestPrintFinding(t *testing.T) {
 	require.NotContains(t, stdErr, "cifuzz found more extensive information about this finding:")
 }
 
+func TestPrintFinding_AuxiliaryStackTraces(t *testing.T) {
+	f := &finding.Finding{
+		Origin: "Local",
+		Name:   "test_finding",
+		Logs:   []string{"==19426==ERROR: AddressSanitizer: heap-use-after-free on address 0x602000000010"},
+		AuxiliaryStackTraces: []*stacktrace.AuxiliaryStackTrace{{
+			Name: "freed by thread T0",
+			StackTrace: []*stacktrace.StackFrame{{
+				FrameNumber: 1,
+				SourceFile:  "src/api.cpp",
+				Function:    "DoStuff",
+				Line:        20,
+				Column:      5,
+			}},
+		}},
+	}
+
+	projectDir := testutil.BootstrapEmptyProject(t, "test-print-finding-")
+	opts := &options{
+		ProjectDir: projectDir,
+		ConfigDir:  projectDir,
+	}
+	err := f.Save(projectDir)
+	require.NoError(t, err)
+
+	stdOut, _, err := cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, f.Name, "--interactive=false")
+	require.NoError(t, err)
+	assert.Contains(t, stdOut, "freed by thread T0:")
+	assert.Contains(t, stdOut, "#1 DoStuff src/api.cpp:20:5")
+
+	stdOut, _, err = cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, f.Name, "--json", "--interactive=false")
+	require.NoError(t, err)
+	assert.Contains(t, stdOut, `"auxiliary_stack_traces"`)
+}
+
+func TestPrintFinding_SourceSnippets(t *testing.T) {
+	projectDir := testutil.BootstrapEmptyProject(t, "test-print-finding-")
+	source := "void DoStuff() {\n  int a = 1;\n  int b = 2;\n  crash(a, b);\n  int c = 3;\n}\n"
+	err := os.MkdirAll(filepath.Join(projectDir, "src"), 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(projectDir, "src", "api.cpp"), []byte(source), 0o644)
+	require.NoError(t, err)
+	outsideFile := filepath.Join(testutil.MkdirTemp(t, filepath.Dir(projectDir), "outside-project-"), "secret.cpp")
+	err = os.WriteFile(outsideFile, []byte("secret\n"), 0o644)
+	require.NoError(t, err)
+
+	f := &finding.Finding{
+		Origin: "Local",
+		Name:   "test_finding",
+		Logs:   []string{"==19426==ERROR: AddressSanitizer: SEGV on unknown address 0x000000000000"},
+		StackTrace: []*stacktrace.StackFrame{
+			{SourceFile: "src/api.cpp", Function: "DoStuff", Line: 4, Column: 3},
+			// Frames of source files which don't exist are skipped
+			{SourceFile: "src/missing.cpp", Function: "Missing", Line: 1, FrameNumber: 1},
+			// Frames of source files outside of the project are skipped
+			{SourceFile: filepath.ToSlash(outsideFile), Function: "Secret", Line: 1, FrameNumber: 2},
+			{SourceFile: "../" + filepath.Base(filepath.Dir(outsideFile)) + "/secret.cpp", Function: "Secret", Line: 1, FrameNumber: 3},
+		},
+	}
+	err = f.Save(projectDir)
+	require.NoError(t, err)
+	opts := &options{
+		ProjectDir: projectDir,
+		ConfigDir:  projectDir,
+	}
+
+	stdOut, _, err := cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, f.Name, "--interactive=false")
+	require.NoError(t, err)
+	assert.Contains(t, stdOut, "src/api.cpp:4:3 in DoStuff")
+	assert.Contains(t, stdOut, "  1 | void DoStuff() {")
+	assert.Contains(t, stdOut, "> 4 |   crash(a, b);")
+	assert.Contains(t, stdOut, "  6 | }")
+	assert.NotContains(t, stdOut, "missing.cpp")
+	assert.NotContains(t, stdOut, "> 1 | secret")
+
+	stdOut, _, err = cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, f.Name, "--json", "--interactive=false")
+	require.NoError(t, err)
+	var result struct {
+		Name           string                   `json:"name"`
+		SourceSnippets []*finding.SourceSnippet `json:"source_snippets"`
+	}
+	err = json.Unmarshal([]byte(stdOut), &result)
+	require.NoError(t, err)
+	assert.Equal(t, "test_finding", result.Name)
+	require.Len(t, result.SourceSnippets, 1)
+	assert.Equal(t, &finding.SourceSnippet{
+		SourceFile: "src/api.cpp",
+		Function:   "DoStuff",
+		Line:       4,
+		Column:     3,
+		StartLine:  1,
+		Lines:      []string{"void DoStuff() {", "  int a = 1;", "  int b = 2;", "  crash(a, b);", "  int c = 3;", "}"},
+	}, result.SourceSnippets[0])
+}
+
 func TestPrintFinding_Authenticated(t *testing.T) {
 	t.Setenv("CIFUZZ_API_TOKEN", "token")
 	server := mockserver.New(t)
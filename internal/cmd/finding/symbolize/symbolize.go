@@ -0,0 +1,106 @@
+package symbolize
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/completion"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/symbolizer"
+)
+
+type options struct {
+	ProjectDir string `mapstructure:"project-dir"`
+	ConfigDir  string `mapstructure:"config-dir"`
+
+	Binary      string
+	LibraryDirs []string
+}
+
+type symbolizeCmd struct {
+	*cobra.Command
+	opts *options
+}
+
+func New() *cobra.Command {
+	return newWithOptions(&options{})
+}
+
+func newWithOptions(opts *options) *cobra.Command {
+	var bindFlags func()
+
+	cmd := &cobra.Command{
+		Use:   "symbolize [flags] <finding name>",
+		Short: "Symbolize the stack traces of a finding",
+		Long: `This command resolves the raw addresses in the stack traces of a
+finding, which are printed instead of function names and source
+locations if llvm-symbolizer was not available when the fuzz test was
+executed, e.g. because it was executed in a container.
+
+The addresses are resolved with llvm-symbolizer if it's available and
+via the DWARF debug info of the fuzz test otherwise. The fuzz test
+binary is looked up at the path from the stack trace, unless it's
+specified via --binary, which also allows symbolizing findings of fuzz
+tests which were executed on another machine.`,
+		Example:           "cifuzz finding symbolize --binary build/my_fuzz_test funky_dog",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completion.ValidFindings,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Bind viper keys to flags. We can't do this in the New
+			// function, because that would re-bind viper keys which
+			// were bound to the flags of other commands before.
+			bindFlags()
+			return config.FindAndParseProjectConfig(opts)
+		},
+		RunE: func(c *cobra.Command, args []string) error {
+			cmd := symbolizeCmd{Command: c, opts: opts}
+			return cmd.run(args[0])
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Binary, "binary", "", "The fuzz test `binary` which produced the finding.")
+	cmd.Flags().StringArrayVar(&opts.LibraryDirs, "library-dir", nil, "A `directory` containing shared libraries which appear in the stack traces.\n"+
+		"This flag can be used multiple times.")
+
+	// Note: If a flag should be configurable via viper as well (i.e.
+	//       via cifuzz.yaml and CIFUZZ_* environment variables), bind
+	//       it to viper in the PreRun function.
+	bindFlags = cmdutils.AddFlags(cmd,
+		cmdutils.AddProjectDirFlag,
+	)
+
+	return cmd
+}
+
+func (c *symbolizeCmd) run(findingName string) error {
+	f, err := finding.LoadFinding(c.opts.ProjectDir, findingName)
+	if finding.IsNotExistError(err) {
+		return errors.WithMessagef(err, "Finding %s does not exist", findingName)
+	}
+	if err != nil {
+		return err
+	}
+
+	symbolized, err := symbolizer.SymbolizeFinding(f, &symbolizer.Options{
+		Binary:      c.opts.Binary,
+		LibraryDirs: c.opts.LibraryDirs,
+		ProjectDir:  c.opts.ProjectDir,
+	})
+	if err != nil {
+		return err
+	}
+	if !symbolized {
+		log.Infof("No unsymbolized stack frames of finding %s could be resolved", findingName)
+		return nil
+	}
+
+	err = f.Save(c.opts.ProjectDir)
+	if err != nil {
+		return err
+	}
+	log.Successf("Symbolized the stack traces of finding %s", findingName)
+	return nil
+}
@@ -92,6 +92,7 @@ func New() *cobra.Command {
 		cmdutils.AddInteractiveFlag,
 		cmdutils.AddProjectFlag,
 		cmdutils.AddServerFlag,
+		cmdutils.AddTransportFlags,
 	)
 	return cmd
 }
@@ -108,6 +108,7 @@ func New() *cobra.Command {
 		cmdutils.AddInteractiveFlag,
 		cmdutils.AddProjectFlag,
 		cmdutils.AddServerFlag,
+		cmdutils.AddTransportFlags,
 	)
 
 	return cmd
@@ -175,7 +176,7 @@ func setUpAndMentionBuildSystemIntegrations(dir string, buildSystem string, test
 		} else {
 			log.Print(messaging.Instructions(buildSystem))
 		}
-	case config.BuildSystemMaven:
+	case config.BuildSystemMeson, config.BuildSystemMaven, config.BuildSystemPython:
 		log.Print(messaging.Instructions(buildSystem))
 	case config.BuildSystemGradle:
 		gradleBuildLanguage, err := config.DetermineGradleBuildLanguage(dir)
@@ -210,16 +211,24 @@ func getNodeProjectLang() (string, error) {
 // map of supported test types/build systems for init command. Used to validate input and show args in --help
 var supportedInitTestTypesMap = map[string]string{
 	"cmake":  config.BuildSystemCMake,
+	"meson":  config.BuildSystemMeson,
 	"maven":  config.BuildSystemMaven,
 	"gradle": config.BuildSystemGradle,
 	"js":     config.BuildSystemNodeJS,
 	"ts":     config.BuildSystemNodeJS,
+	"go":     config.BuildSystemGo,
+	"rust":   config.BuildSystemCargo,
+	"python": config.BuildSystemPython,
 }
 
 var supportedInitTestTypes = []string{
 	"cmake",
+	"meson",
 	"maven",
 	"gradle",
 	"js",
 	"ts",
+	"go",
+	"rust",
+	"python",
 }
@@ -35,7 +35,13 @@ func New() *cobra.Command {
 		Use:   "login",
 		Short: "Authenticate with CI Sense",
 		Long: `This command is used to authenticate with CI Sense.
-To learn more, visit https://www.code-intelligence.com.`,
+To learn more, visit https://www.code-intelligence.com.
+
+On Linux, the API access token is stored in the OS keyring via the
+Secret Service API if it's available. Otherwise, it's stored in the
+cifuzz directory of the user config directory. Set the
+CIFUZZ_TOKEN_STORAGE environment variable to "keyring" or "file" to
+enforce one of the storage backends.`,
 		Example: "$ cifuzz login",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			// Bind viper keys to flags. We can't do this in the New
@@ -64,6 +70,7 @@ To learn more, visit https://www.code-intelligence.com.`,
 	bindFlags = cmdutils.AddFlags(cmd,
 		cmdutils.AddInteractiveFlag,
 		cmdutils.AddServerFlag,
+		cmdutils.AddTransportFlags,
 	)
 
 	cmdutils.DisableConfigCheck(cmd)
@@ -117,11 +124,11 @@ in interactive mode. You can generate a token here:
 		return err
 	}
 
-	tokenFilePath, err := tokenstorage.GetTokenFilePath()
+	tokenLocation, err := tokenstorage.Location()
 	if err != nil {
 		return err
 	}
-	log.Infof("Your API access token is stored in %s", tokenFilePath)
+	log.Infof("Your API access token is stored in %s", tokenLocation)
 
 	return nil
 }
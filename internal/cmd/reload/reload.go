@@ -6,6 +6,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"code-intelligence.com/cifuzz/internal/build/cmake"
+	"code-intelligence.com/cifuzz/internal/build/meson"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/pkg/dependencies"
@@ -71,10 +72,13 @@ func (c *reloadCmd) run() error {
 		return err
 	}
 
-	if c.opts.BuildSystem == config.BuildSystemCMake {
+	switch c.opts.BuildSystem {
+	case config.BuildSystemCMake:
 		return c.reloadCMake()
-	} else {
-		// Nothing to reload for build systems other than CMake
+	case config.BuildSystemMeson:
+		return c.reloadMeson()
+	default:
+		// Nothing to reload for build systems other than CMake and Meson
 		return nil
 	}
 }
@@ -100,6 +104,22 @@ func (c *reloadCmd) reloadCMake() error {
 	return nil
 }
 
+func (c *reloadCmd) reloadMeson() error {
+	sanitizers := []string{"address", "undefined"}
+
+	builder, err := meson.NewBuilder(&meson.BuilderOptions{
+		ProjectDir: c.opts.ProjectDir,
+		Sanitizers: sanitizers,
+		Stdout:     c.OutOrStdout(),
+		Stderr:     c.ErrOrStderr(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return builder.Configure()
+}
+
 func (c *reloadCmd) checkDependencies() error {
 	deps := []dependencies.Key{}
 	if c.opts.BuildSystem == config.BuildSystemCMake {
@@ -111,6 +131,9 @@ func (c *reloadCmd) checkDependencies() error {
 			deps = append(deps, dependencies.VisualStudio)
 		}
 	}
+	if c.opts.BuildSystem == config.BuildSystemMeson {
+		deps = []dependencies.Key{dependencies.Meson, dependencies.Clang}
+	}
 	err := dependencies.Check(deps, c.opts.ProjectDir)
 	if err != nil {
 		return err
@@ -20,6 +20,43 @@ func NewReader(reader io.Reader, size int64, successMessage string) *ioprogress.
 	}
 }
 
+// ChunkedProgress displays a single progress bar for data which is read
+// in multiple consecutive chunks, e.g. for chunked uploads.
+type ChunkedProgress struct {
+	size     int64
+	drawFunc ioprogress.DrawFunc
+}
+
+func NewChunkedProgress(size int64, successMessage string) *ChunkedProgress {
+	return &ChunkedProgress{
+		size:     size,
+		drawFunc: DrawProgressBar(os.Stdout, ioprogress.DrawTextFormatBar(60), successMessage),
+	}
+}
+
+// NewReader returns a reader which updates the progress bar while
+// reading the chunk starting at the given offset.
+func (p *ChunkedProgress) NewReader(reader io.Reader, offset int64) io.Reader {
+	return &ioprogress.Reader{
+		Reader: reader,
+		Size:   p.size,
+		DrawFunc: func(progress, total int64) error {
+			// The success message is only printed by Done, after the
+			// last chunk was read
+			if progress == -1 && total == -1 {
+				return nil
+			}
+			return p.drawFunc(offset+progress, total)
+		},
+		DrawInterval: 100 * time.Millisecond,
+	}
+}
+
+// Done clears the progress bar and prints the success message.
+func (p *ChunkedProgress) Done() error {
+	return p.drawFunc(-1, -1)
+}
+
 func DrawProgressBar(w io.Writer, drawFormatBar ioprogress.DrawTextFormatFunc, successMessage string) ioprogress.DrawFunc {
 	var maxLength int
 
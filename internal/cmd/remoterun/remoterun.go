@@ -50,6 +50,13 @@ func (opts *remoteRunOpts) Validate() error {
 		return errors.Errorf(config.NotSupportedErrorMessage("remote run", opts.BuildSystem))
 	}
 
+	// Remote runs are based on bundles, which don't support Go, Rust and
+	// Python yet
+	if opts.BuildSystem == config.BuildSystemGo || opts.BuildSystem == config.BuildSystemCargo ||
+		opts.BuildSystem == config.BuildSystemPython {
+		return errors.Errorf(config.NotSupportedErrorMessage("remote run", opts.BuildSystem))
+	}
+
 	if opts.BundlePath == "" {
 		// We need to build a bundle, so we validate the bundler options
 		// as well
@@ -100,6 +107,13 @@ dictionary locations can be found in the help message of the run command.
 If the --bundle flag is used, building and bundling is skipped and the
 specified bundle is uploaded to start a remote fuzzing run instead.
 
+If CI Sense supports it, the bundle is uploaded in chunks. If the
+upload is interrupted, running the command again with the same --bundle
+resumes the upload. Requests failing with a transient error are retried
+up to 5 times, which can be changed via the --api-max-retries flag, the
+api-max-retries setting in cifuzz.yaml or the CIFUZZ_API_MAX_RETRIES
+environment variable.
+
 This command needs a token to access the API of the remote fuzzing
 server. You can specify this token via the CIFUZZ_API_TOKEN environment
 variable or by running 'cifuzz login' first.
@@ -185,17 +199,23 @@ variable or by running 'cifuzz login' first.
 		cmdutils.AddCleanCommandFlag,
 		cmdutils.AddBuildJobsFlag,
 		cmdutils.AddCommitFlag,
+		cmdutils.AddCorpusFromFlag,
 		cmdutils.AddDictFlag,
 		cmdutils.AddDockerImageFlagForContainerCommand,
+		cmdutils.AddEngineFlag,
 		cmdutils.AddEngineArgFlag,
 		cmdutils.AddEnvFlag,
 		cmdutils.AddInteractiveFlag,
+		cmdutils.AddBuildCacheFlag,
 		cmdutils.AddPrintJSONFlag,
 		cmdutils.AddProjectDirFlag,
 		cmdutils.AddProjectFlag,
+		cmdutils.AddSanitizerFlag,
 		cmdutils.AddSeedCorpusFlag,
 		cmdutils.AddServerFlag,
+		cmdutils.AddTransportFlags,
 		cmdutils.AddTimeoutFlag,
+		cmdutils.AddVendorNodeModulesFlag,
 		cmdutils.AddResolveSourceFileFlag,
 	)
 	cmd.Flags().StringVar(&opts.BundlePath, "bundle", "", "Path of an existing bundle to start a remote run with.")
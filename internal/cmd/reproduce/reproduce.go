@@ -22,19 +22,21 @@ import (
 	"code-intelligence.com/cifuzz/pkg/dialog"
 	findingPkg "code-intelligence.com/cifuzz/pkg/finding"
 	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/parser/errorid"
 	"code-intelligence.com/cifuzz/pkg/runner"
 	"code-intelligence.com/cifuzz/util/envutil"
 )
 
 type options struct {
-	ProjectDir   string `mapstructure:"project-dir"`
-	ConfigDir    string `mapstructure:"config-dir"`
-	Interactive  bool   `mapstructure:"interactive"`
-	Server       string `mapstructure:"server"`
-	Project      string `mapstructure:"project"`
-	BuildSystem  string `mapstructure:"build-system"`
-	BuildCommand string `mapstructure:"build-command"`
-	CleanCommand string `mapstructure:"clean-command"`
+	ProjectDir   string                      `mapstructure:"project-dir"`
+	ConfigDir    string                      `mapstructure:"config-dir"`
+	Interactive  bool                        `mapstructure:"interactive"`
+	Server       string                      `mapstructure:"server"`
+	Project      string                      `mapstructure:"project"`
+	BuildSystem  string                      `mapstructure:"build-system"`
+	BuildCommand string                      `mapstructure:"build-command"`
+	CleanCommand string                      `mapstructure:"clean-command"`
+	ErrorDetails []*errorid.UserDefinedError `mapstructure:"error-details"`
 
 	FindingName string
 
@@ -63,7 +65,7 @@ func (opts *options) validate() error {
 		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
 	}
 
-	return nil
+	return errorid.AddUserDefinedErrors(opts.ErrorDetails)
 }
 
 type reproduceCmd struct {
@@ -128,6 +130,7 @@ Note that only other build systems are supported for now.
 		cmdutils.AddProjectDirFlag,
 		cmdutils.AddInteractiveFlag,
 		cmdutils.AddServerFlag,
+		cmdutils.AddTransportFlags,
 		cmdutils.AddProjectFlag,
 		cmdutils.AddBuildCommandFlag,
 		cmdutils.AddCleanCommandFlag,
@@ -314,6 +317,18 @@ func setSanitizerOptions(env []string) ([]string, error) {
 			return nil, err
 		}
 	}
+	if os.Getenv("MSAN_OPTIONS") != "" {
+		env, err = envutil.Setenv(env, "MSAN_OPTIONS", os.Getenv("MSAN_OPTIONS"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if os.Getenv("TSAN_OPTIONS") != "" {
+		env, err = envutil.Setenv(env, "TSAN_OPTIONS", os.Getenv("TSAN_OPTIONS"))
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	env, err = runner.SetCommonUBSANOptions(env)
 	if err != nil {
@@ -325,5 +340,15 @@ func setSanitizerOptions(env []string) ([]string, error) {
 		return nil, err
 	}
 
+	env, err = runner.SetCommonMSANOptions(env)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err = runner.SetCommonTSANOptions(env)
+	if err != nil {
+		return nil, err
+	}
+
 	return env, nil
 }
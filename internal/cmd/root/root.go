@@ -13,6 +13,7 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
+	buildCmd "code-intelligence.com/cifuzz/internal/cmd/build"
 	bundleCmd "code-intelligence.com/cifuzz/internal/cmd/bundle"
 	containerCmd "code-intelligence.com/cifuzz/internal/cmd/container"
 	coverageCmd "code-intelligence.com/cifuzz/internal/cmd/coverage"
@@ -28,6 +29,7 @@ import (
 	remoteRunCmd "code-intelligence.com/cifuzz/internal/cmd/remoterun"
 	reproduceCmd "code-intelligence.com/cifuzz/internal/cmd/reproduce"
 	runCmd "code-intelligence.com/cifuzz/internal/cmd/run"
+	serverMockCmd "code-intelligence.com/cifuzz/internal/cmd/servermock"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/internal/version"
@@ -108,11 +110,13 @@ func New() (*cobra.Command, error) {
 	rootCmd.AddCommand(goCmd.New())
 	rootCmd.AddCommand(remoteRunCmd.New())
 	rootCmd.AddCommand(reloadCmd.New())
+	rootCmd.AddCommand(buildCmd.New())
 	rootCmd.AddCommand(bundleCmd.New())
 	rootCmd.AddCommand(coverageCmd.New())
 	rootCmd.AddCommand(findingCmd.New())
 	rootCmd.AddCommand(integrateCmd.New())
 	rootCmd.AddCommand(reproduceCmd.New())
+	rootCmd.AddCommand(serverMockCmd.New())
 
 	for _, cmd := range printflagsCmds.New() {
 		rootCmd.AddCommand(cmd)
@@ -18,6 +18,8 @@ func NewAdapter(buildSystem string) (Adapter, error) {
 	switch buildSystem {
 	case config.BuildSystemCMake:
 		adapter = &CMakeAdapter{}
+	case config.BuildSystemMeson:
+		adapter = &MesonAdapter{}
 	case config.BuildSystemMaven:
 		adapter = &MavenAdapter{}
 	case config.BuildSystemGradle:
@@ -28,6 +30,12 @@ func NewAdapter(buildSystem string) (Adapter, error) {
 		adapter = &OtherAdapter{}
 	case config.BuildSystemBazel:
 		adapter = &BazelAdapter{}
+	case config.BuildSystemGo:
+		adapter = &GoAdapter{}
+	case config.BuildSystemCargo:
+		adapter = &CargoAdapter{}
+	case config.BuildSystemPython:
+		adapter = &PythonAdapter{}
 	default:
 		return nil, errors.Errorf("Unsupported build system \"%s\"", buildSystem)
 	}
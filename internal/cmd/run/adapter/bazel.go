@@ -11,6 +11,7 @@ import (
 	"code-intelligence.com/cifuzz/internal/build/bazel"
 	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/pkg/dependencies"
 	"code-intelligence.com/cifuzz/util/fileutil"
 )
@@ -22,9 +23,13 @@ type BazelAdapter struct {
 func (r *BazelAdapter) CheckDependencies(projectDir string) error {
 	// All dependencies are managed via bazel but it should be checked
 	// that the correct bazel version is installed
-	return dependencies.Check([]dependencies.Key{
+	deps := []dependencies.Key{
 		dependencies.Bazel,
-	}, projectDir)
+	}
+	if config.Engine(viper.GetString("engine")) == config.AFLPlusPlus {
+		deps = append(deps, dependencies.AFLFuzz)
+	}
+	return dependencies.Check(deps, projectDir)
 }
 
 func (r *BazelAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, error) {
@@ -36,6 +41,14 @@ func (r *BazelAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, erro
 		return nil, errors.WithStack(err)
 	}
 
+	isJava, err := bazel.IsJavaFuzzTest(opts.FuzzTest)
+	if err != nil {
+		return nil, err
+	}
+	if isJava {
+		return r.runJava(opts)
+	}
+
 	buildResult, err := wrapBuild[build.BuildResult](opts, r.build)
 	if err != nil {
 		return nil, err
@@ -64,13 +77,77 @@ func (r *BazelAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, erro
 		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
 	}
 
-	err = runLibfuzzer(opts, buildResult, reportHandler)
+	if opts.Engine == config.AFLPlusPlus {
+		// The executable is a script generated by bazel which runs
+		// the instrumented binary
+		err = runAFLPlusPlus(opts, buildResult, reportHandler, true)
+	} else {
+		err = runLibfuzzer(opts, buildResult, reportHandler)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return reportHandler, nil
+}
+
+// runJava runs a fuzz test defined via the java_fuzz_test rule with
+// Jazzer, using the class path of the deploy JAR built by bazel.
+func (r *BazelAdapter) runJava(opts *RunOptions) (*reporthandler.ReportHandler, error) {
+	buildResult, err := wrapBuild[build.JavaBuildResult](opts, r.buildJava)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.BuildOnly {
+		return nil, nil
+	}
+
+	// From here on, the fuzz test is identified by its class, like
+	// for Maven and Gradle projects
+	opts.FuzzTest = buildResult.TargetClass
+	err = cmdutils.ValidateJVMFuzzTest(opts.FuzzTest, &opts.TargetMethod, buildResult.RuntimeDeps)
+	if err != nil {
+		return nil, err
+	}
+
+	err = prepareCorpusDir(opts, buildResult.BuildResult)
+	if err != nil {
+		return nil, err
+	}
+
+	reportHandler, err := createReportHandler(opts, buildResult.BuildResult)
+	if err != nil {
+		return nil, err
+	}
+
+	err = runJazzer(opts, buildResult.BuildResult, reportHandler)
 	if err != nil {
 		return nil, err
 	}
 	return reportHandler, nil
 }
 
+func (r *BazelAdapter) buildJava(opts *RunOptions) (*build.JavaBuildResult, error) {
+	builder, err := bazel.NewBuilder(&bazel.BuilderOptions{
+		ProjectDir: opts.ProjectDir,
+		Args:       opts.ArgsToPass,
+		NumJobs:    opts.NumBuildJobs,
+		Stdout:     opts.BuildStdout,
+		Stderr:     opts.BuildStderr,
+		TempDir:    r.tempDir,
+		Verbose:    viper.GetBool("verbose"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	buildResults, err := builder.BuildJava([]string{opts.FuzzTest})
+	if err != nil {
+		return nil, err
+	}
+	return buildResults[0], nil
+}
+
 func (r *BazelAdapter) build(opts *RunOptions) (*build.BuildResult, error) {
 
 	// The cc_fuzz_test rule defines multiple bazel targets: If the
@@ -89,6 +166,7 @@ func (r *BazelAdapter) build(opts *RunOptions) (*build.BuildResult, error) {
 	builder, err = bazel.NewBuilder(&bazel.BuilderOptions{
 		ProjectDir: opts.ProjectDir,
 		Args:       opts.ArgsToPass,
+		Engine:     opts.Engine,
 		NumJobs:    opts.NumBuildJobs,
 		Stdout:     opts.BuildStdout,
 		Stderr:     opts.BuildStderr,
@@ -0,0 +1,67 @@
+package adapter
+
+import (
+	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/build/cargo"
+	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
+	"code-intelligence.com/cifuzz/pkg/dependencies"
+)
+
+type CargoAdapter struct {
+}
+
+func (r *CargoAdapter) CheckDependencies(projectDir string) error {
+	return dependencies.Check([]dependencies.Key{
+		dependencies.Cargo,
+		dependencies.CargoFuzz,
+		dependencies.LLVMSymbolizer,
+	}, projectDir)
+}
+
+func (r *CargoAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, error) {
+	cBuildResult, err := wrapBuild[build.CBuildResult](opts, r.build)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.BuildOnly {
+		return nil, nil
+	}
+
+	err = prepareCorpusDir(opts, cBuildResult.BuildResult)
+	if err != nil {
+		return nil, err
+	}
+
+	reportHandler, err := createReportHandler(opts, cBuildResult.BuildResult)
+	if err != nil {
+		return nil, err
+	}
+
+	err = runLibfuzzer(opts, cBuildResult.BuildResult, reportHandler)
+	if err != nil {
+		return nil, err
+	}
+
+	return reportHandler, nil
+}
+
+func (r *CargoAdapter) build(opts *RunOptions) (*build.CBuildResult, error) {
+	// In contrast to clang, rustc only supports a single sanitizer per
+	// build, and UBSan is not available at all
+	builder, err := cargo.NewBuilder(&cargo.BuilderOptions{
+		ProjectDir: opts.ProjectDir,
+		Args:       opts.ArgsToPass,
+		Sanitizers: []string{"address"},
+		Stdout:     opts.BuildStdout,
+		Stderr:     opts.BuildStderr,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return builder.Build(opts.FuzzTest)
+}
+
+func (*CargoAdapter) Cleanup() {
+}
@@ -8,6 +8,7 @@ import (
 	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/build/cmake"
 	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
+	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/pkg/dependencies"
 )
 
@@ -26,12 +27,15 @@ func (r *CMakeAdapter) CheckDependencies(projectDir string) error {
 	case "windows":
 		deps = append(deps, dependencies.VisualStudio)
 	}
+	if config.Engine(viper.GetString("engine")) == config.AFLPlusPlus {
+		deps = append(deps, dependencies.AFLFuzz)
+	}
 
 	return dependencies.Check(deps, projectDir)
 }
 
 func (r *CMakeAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, error) {
-	cBuildResult, err := wrapBuild[build.CBuildResult](opts, r.build)
+	cBuildResult, err := wrapBuild[build.CBuildResult](opts, withBuildCache(r.build, false))
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +54,11 @@ func (r *CMakeAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, erro
 		return nil, err
 	}
 
-	err = runLibfuzzer(opts, cBuildResult.BuildResult, reportHandler)
+	if opts.Engine == config.AFLPlusPlus {
+		err = runAFLPlusPlus(opts, cBuildResult.BuildResult, reportHandler, false)
+	} else {
+		err = runLibfuzzer(opts, cBuildResult.BuildResult, reportHandler)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -66,6 +74,7 @@ func (r *CMakeAdapter) build(opts *RunOptions) (*build.CBuildResult, error) {
 		ProjectDir: opts.ProjectDir,
 		Args:       opts.ArgsToPass,
 		Sanitizers: sanitizers,
+		Engine:     opts.Engine,
 		Parallel: cmake.ParallelOptions{
 			Enabled: viper.IsSet("build-jobs"),
 			NumJobs: opts.NumBuildJobs,
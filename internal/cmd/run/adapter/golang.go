@@ -0,0 +1,88 @@
+package adapter
+
+import (
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+
+	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/build/golang"
+	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
+	"code-intelligence.com/cifuzz/pkg/dependencies"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/runner/gofuzz"
+)
+
+type GoAdapter struct {
+	fuzzTest *golang.FuzzTest
+}
+
+func (r *GoAdapter) CheckDependencies(projectDir string) error {
+	return dependencies.Check([]dependencies.Key{
+		dependencies.Go,
+	}, projectDir)
+}
+
+func (r *GoAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, error) {
+	buildResult, err := wrapBuild[build.BuildResult](opts, r.build)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.BuildOnly {
+		return nil, nil
+	}
+
+	reportHandler, err := createReportHandler(opts, buildResult)
+	if err != nil {
+		return nil, err
+	}
+
+	style := pterm.Style{pterm.Reset, pterm.FgLightBlue}
+	log.Infof("Running %s", style.Sprintf(r.fuzzTest.String()))
+
+	if opts.UseSandbox {
+		log.Warn("Running Go fuzz tests in the sandbox is not supported, running without sandbox")
+		opts.UseSandbox = false
+	}
+
+	runnerOpts := &gofuzz.RunnerOptions{
+		Dictionary:         opts.Dictionary,
+		EngineArgs:         opts.EngineArgs,
+		EnvVars:            []string{"NO_CIFUZZ=1"},
+		GeneratedCorpusDir: buildResult.GeneratedCorpus,
+		Package:            r.fuzzTest.Package,
+		PackageDir:         r.fuzzTest.Dir,
+		ProjectDir:         opts.ProjectDir,
+		ReportHandler:      reportHandler,
+		SeedCorpusDirs:     opts.SeedCorpusDirs,
+		TestName:           r.fuzzTest.Name,
+		Timeout:            opts.Timeout,
+		Verbose:            viper.GetBool("verbose"),
+	}
+	err = ExecuteFuzzerRunner(gofuzz.NewRunner(runnerOpts))
+	if err != nil {
+		return nil, err
+	}
+
+	return reportHandler, nil
+}
+
+func (r *GoAdapter) build(opts *RunOptions) (*build.BuildResult, error) {
+	if len(opts.ArgsToPass) > 0 {
+		log.Warnf("Passing additional arguments is not supported for Go.\n"+
+			"These arguments are ignored: %s", strings.Join(opts.ArgsToPass, " "))
+	}
+
+	var err error
+	r.fuzzTest, err = golang.ResolveFuzzTest(opts.ProjectDir, opts.FuzzTest)
+	if err != nil {
+		return nil, err
+	}
+
+	return golang.Build(opts.ProjectDir, r.fuzzTest, opts.BuildStdout, opts.BuildStderr)
+}
+
+func (*GoAdapter) Cleanup() {
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/spf13/viper"
 
 	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/build/java"
 	"code-intelligence.com/cifuzz/internal/build/java/gradle"
 	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
@@ -25,7 +26,14 @@ func (r *GradleAdapter) CheckDependencies(projectDir string) error {
 }
 
 func (r *GradleAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, error) {
-	buildResult, err := wrapBuild[build.BuildResult](opts, r.build)
+	// Fuzz tests of modules are built with the class path of the module,
+	// so the module has to be known before building
+	var err error
+	opts.FuzzTest, err = java.ResolveFuzzTest(opts.ProjectDir, opts.BuildSystem, opts.FuzzTest)
+	if err != nil {
+		return nil, err
+	}
+	buildResult, err := wrapBuild[build.BuildResult](opts, withBuildCache(r.build, false))
 	if err != nil {
 		return nil, err
 	}
@@ -34,7 +42,8 @@ func (r *GradleAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, err
 		return nil, nil
 	}
 
-	err = cmdutils.ValidateJVMFuzzTest(opts.FuzzTest, &opts.TargetMethod, buildResult.RuntimeDeps)
+	_, targetClass := cmdutils.SeparateModuleAndTargetClass(opts.FuzzTest)
+	err = cmdutils.ValidateJVMFuzzTest(targetClass, &opts.TargetMethod, buildResult.RuntimeDeps)
 	if err != nil {
 		return nil, err
 	}
@@ -63,9 +72,11 @@ func (r *GradleAdapter) build(opts *RunOptions) (*build.BuildResult, error) {
 			"These arguments are ignored: %s", strings.Join(opts.ArgsToPass, " "))
 	}
 
+	module, _ := cmdutils.SeparateModuleAndTargetClass(opts.FuzzTest)
 	var builder *gradle.Builder
 	builder, err := gradle.NewBuilder(&gradle.BuilderOptions{
 		ProjectDir: opts.ProjectDir,
+		Module:     module,
 		Parallel: gradle.ParallelOptions{
 			Enabled: viper.IsSet("build-jobs"),
 			NumJobs: opts.NumBuildJobs,
@@ -6,6 +6,7 @@ import (
 	"github.com/spf13/viper"
 
 	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/build/java"
 	"code-intelligence.com/cifuzz/internal/build/java/maven"
 	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
@@ -25,8 +26,15 @@ func (r *MavenAdapter) CheckDependencies(projectDir string) error {
 }
 
 func (r *MavenAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, error) {
+	// Fuzz tests of modules are built with the class path of the module,
+	// so the module has to be known before building
+	var err error
+	opts.FuzzTest, err = java.ResolveFuzzTest(opts.ProjectDir, opts.BuildSystem, opts.FuzzTest)
+	if err != nil {
+		return nil, err
+	}
 
-	buildResult, err := wrapBuild[build.BuildResult](opts, r.build)
+	buildResult, err := wrapBuild[build.BuildResult](opts, withBuildCache(r.build, false))
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +43,8 @@ func (r *MavenAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, erro
 		return nil, nil
 	}
 
-	err = cmdutils.ValidateJVMFuzzTest(opts.FuzzTest, &opts.TargetMethod, buildResult.RuntimeDeps)
+	_, targetClass := cmdutils.SeparateModuleAndTargetClass(opts.FuzzTest)
+	err = cmdutils.ValidateJVMFuzzTest(targetClass, &opts.TargetMethod, buildResult.RuntimeDeps)
 	if err != nil {
 		return nil, err
 	}
@@ -65,9 +74,11 @@ func (r *MavenAdapter) build(opts *RunOptions) (*build.BuildResult, error) {
 			"These arguments are ignored: %s", strings.Join(opts.ArgsToPass, " "))
 	}
 
+	module, _ := cmdutils.SeparateModuleAndTargetClass(opts.FuzzTest)
 	var builder *maven.Builder
 	builder, err := maven.NewBuilder(&maven.BuilderOptions{
 		ProjectDir: opts.ProjectDir,
+		Module:     module,
 		Parallel: maven.ParallelOptions{
 			Enabled: viper.IsSet("build-jobs"),
 			NumJobs: opts.NumBuildJobs,
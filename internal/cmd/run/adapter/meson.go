@@ -0,0 +1,87 @@
+package adapter
+
+import (
+	"github.com/spf13/viper"
+
+	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/build/meson"
+	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
+	"code-intelligence.com/cifuzz/pkg/dependencies"
+)
+
+type MesonAdapter struct {
+}
+
+func (r *MesonAdapter) CheckDependencies(projectDir string) error {
+	deps := []dependencies.Key{
+		dependencies.Meson,
+		dependencies.Clang,
+		dependencies.LLVMSymbolizer,
+	}
+	return dependencies.Check(deps, projectDir)
+}
+
+func (r *MesonAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, error) {
+	cBuildResult, err := wrapBuild[build.CBuildResult](opts, withBuildCache(r.build, false))
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.BuildOnly {
+		return nil, nil
+	}
+
+	err = prepareCorpusDir(opts, cBuildResult.BuildResult)
+	if err != nil {
+		return nil, err
+	}
+
+	reportHandler, err := createReportHandler(opts, cBuildResult.BuildResult)
+	if err != nil {
+		return nil, err
+	}
+
+	err = runLibfuzzer(opts, cBuildResult.BuildResult, reportHandler)
+	if err != nil {
+		return nil, err
+	}
+
+	return reportHandler, nil
+}
+
+func (r *MesonAdapter) build(opts *RunOptions) (*build.CBuildResult, error) {
+	sanitizers := []string{"address", "undefined"}
+
+	builder, err := meson.NewBuilder(&meson.BuilderOptions{
+		ProjectDir: opts.ProjectDir,
+		Args:       opts.ArgsToPass,
+		Sanitizers: sanitizers,
+		Parallel: meson.ParallelOptions{
+			Enabled: viper.IsSet("build-jobs"),
+			NumJobs: opts.NumBuildJobs,
+		},
+		Stdout:    opts.BuildStdout,
+		Stderr:    opts.BuildStderr,
+		BuildOnly: opts.BuildOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = builder.Configure()
+	if err != nil {
+		return nil, err
+	}
+
+	cBuildResults, err := builder.Build([]string{opts.FuzzTest})
+	if err != nil {
+		return nil, err
+	}
+	if opts.BuildOnly {
+		return nil, nil
+	}
+
+	return cBuildResults[0], nil
+}
+
+func (*MesonAdapter) Cleanup() {
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/spf13/viper"
 
 	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/build/nodejs"
 	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/pkg/dependencies"
@@ -33,12 +34,24 @@ func (r *NodeJSAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, err
 		return nil, err
 	}
 
+	// Fuzz tests of workspace packages are executed in the directory
+	// of the package
+	packageDir, testPathPattern, err := nodejs.ResolveFuzzTest(opts.ProjectDir, opts.FuzzTest)
+	if err != nil {
+		return nil, err
+	}
+	packageManager, err := nodejs.DetectPackageManager(opts.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+
 	style := pterm.Style{pterm.Reset, pterm.FgLightBlue}
 	log.Infof("Running %s", style.Sprintf(opts.FuzzTest+":"+opts.TestNamePattern))
 
 	runnerOpts := &jazzerjs.RunnerOptions{
-		PackageManager:  "npm",
-		TestPathPattern: opts.FuzzTest,
+		PackageManager:  packageManager,
+		PackageDir:      packageDir,
+		TestPathPattern: testPathPattern,
 		TestNamePattern: opts.TestNamePattern,
 		LibfuzzerOptions: &libfuzzer.RunnerOptions{
 			Dictionary:     opts.Dictionary,
@@ -10,23 +10,28 @@ import (
 
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/parser/errorid"
 )
 
 type RunOptions struct {
-	BuildSystem           string        `mapstructure:"build-system"`
-	BuildCommand          string        `mapstructure:"build-command"`
-	CleanCommand          string        `mapstructure:"clean-command"`
-	NumBuildJobs          uint          `mapstructure:"build-jobs"`
-	Dictionary            string        `mapstructure:"dict"`
-	EngineArgs            []string      `mapstructure:"engine-args"`
-	SeedCorpusDirs        []string      `mapstructure:"seed-corpus-dirs"`
-	Timeout               time.Duration `mapstructure:"timeout"`
-	Interactive           bool          `mapstructure:"interactive"`
-	Server                string        `mapstructure:"server"`
-	Project               string        `mapstructure:"project"`
-	UseSandbox            bool          `mapstructure:"use-sandbox"`
-	PrintJSON             bool          `mapstructure:"print-json"`
-	BuildOnly             bool          `mapstructure:"build-only"`
+	BuildSystem           string                      `mapstructure:"build-system"`
+	BuildCommand          string                      `mapstructure:"build-command"`
+	CleanCommand          string                      `mapstructure:"clean-command"`
+	NumBuildJobs          uint                        `mapstructure:"build-jobs"`
+	Dictionary            string                      `mapstructure:"dict"`
+	Engine                config.Engine               `mapstructure:"engine"`
+	EngineArgs            []string                    `mapstructure:"engine-args"`
+	SeedCorpusDirs        []string                    `mapstructure:"seed-corpus-dirs"`
+	Timeout               time.Duration               `mapstructure:"timeout"`
+	Interactive           bool                        `mapstructure:"interactive"`
+	Server                string                      `mapstructure:"server"`
+	Project               string                      `mapstructure:"project"`
+	UseSandbox            bool                        `mapstructure:"use-sandbox"`
+	PrintJSON             bool                        `mapstructure:"print-json"`
+	BuildOnly             bool                        `mapstructure:"build-only"`
+	BuildCache            bool                        `mapstructure:"build-cache"`
+	CompileCommands       bool                        `mapstructure:"compile-commands"`
+	ErrorDetails          []*errorid.UserDefinedError `mapstructure:"error-details"`
 	ResolveSourceFilePath bool
 
 	ProjectDir      string
@@ -70,6 +75,11 @@ func (opts *RunOptions) Validate() error {
 		return err
 	}
 
+	err = config.ValidateEngine(opts.Engine, opts.BuildSystem)
+	if err != nil {
+		return err
+	}
+
 	// To build with other build systems, a build command must be provided
 	if opts.BuildSystem == config.BuildSystemOther && opts.BuildCommand == "" {
 		msg := "Flag \"build-command\" must be set when using build system type \"other\""
@@ -81,5 +91,5 @@ func (opts *RunOptions) Validate() error {
 		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
 	}
 
-	return nil
+	return errorid.AddUserDefinedErrors(opts.ErrorDetails)
 }
@@ -32,7 +32,7 @@ func (r *OtherAdapter) CheckDependencies(projectDir string) error {
 }
 
 func (r *OtherAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, error) {
-	cBuildResult, err := wrapBuild[build.CBuildResult](opts, r.build)
+	cBuildResult, err := wrapBuild[build.CBuildResult](opts, withBuildCache(r.build, true))
 	if err != nil {
 		return nil, err
 	}
@@ -75,6 +75,8 @@ func (r *OtherAdapter) build(opts *RunOptions) (*build.CBuildResult, error) {
 		Sanitizers:   sanitizers,
 		Stdout:       opts.BuildStdout,
 		Stderr:       opts.BuildStderr,
+
+		CompilationDatabase: opts.CompileCommands,
 	})
 	if err != nil {
 		return nil, err
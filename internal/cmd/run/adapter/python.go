@@ -0,0 +1,85 @@
+package adapter
+
+import (
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+
+	"code-intelligence.com/cifuzz/internal/build/python"
+	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
+	"code-intelligence.com/cifuzz/pkg/dependencies"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/runner/atheris"
+	"code-intelligence.com/cifuzz/pkg/runner/libfuzzer"
+)
+
+type PythonAdapter struct {
+}
+
+func (r *PythonAdapter) CheckDependencies(projectDir string) error {
+	return dependencies.Check([]dependencies.Key{
+		dependencies.Python,
+		dependencies.Atheris,
+	}, projectDir)
+}
+
+func (r *PythonAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, error) {
+	// Python fuzz tests don't have to be built, so we only check that
+	// the fuzz test exists
+	buildResult, err := python.BuildResult(opts.ProjectDir, opts.FuzzTest)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.BuildOnly {
+		return nil, nil
+	}
+
+	err = prepareCorpusDir(opts, buildResult)
+	if err != nil {
+		return nil, err
+	}
+
+	reportHandler, err := createReportHandler(opts, buildResult)
+	if err != nil {
+		return nil, err
+	}
+
+	style := pterm.Style{pterm.Reset, pterm.FgLightBlue}
+	log.Infof("Running %s", style.Sprintf(opts.FuzzTest))
+
+	if opts.UseSandbox {
+		log.Warn("Running Python fuzz tests in the sandbox is not supported, running without sandbox")
+		opts.UseSandbox = false
+	}
+
+	err = addDefaultSeedCorpusAndDictionary(opts, buildResult)
+	if err != nil {
+		return nil, err
+	}
+
+	runnerOpts := &atheris.RunnerOptions{
+		FuzzTestScript: buildResult.Executable,
+		LibfuzzerOptions: &libfuzzer.RunnerOptions{
+			Dictionary:         opts.Dictionary,
+			EngineArgs:         opts.EngineArgs,
+			EnvVars:            []string{"NO_CIFUZZ=1"},
+			FuzzTarget:         buildResult.Executable,
+			GeneratedCorpusDir: buildResult.GeneratedCorpus,
+			KeepColor:          !opts.PrintJSON && !log.PlainStyle(),
+			ProjectDir:         opts.ProjectDir,
+			ReportHandler:      reportHandler,
+			SeedCorpusDirs:     opts.SeedCorpusDirs,
+			Timeout:            opts.Timeout,
+			Verbose:            viper.GetBool("verbose"),
+		},
+	}
+	err = ExecuteFuzzerRunner(atheris.NewRunner(runnerOpts))
+	if err != nil {
+		return nil, err
+	}
+
+	return reportHandler, nil
+}
+
+func (*PythonAdapter) Cleanup() {
+}
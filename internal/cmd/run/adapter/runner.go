@@ -19,6 +19,7 @@ import (
 	"code-intelligence.com/cifuzz/internal/ldd"
 	"code-intelligence.com/cifuzz/pkg/java/sourcemap"
 	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/runner/aflpp"
 	"code-intelligence.com/cifuzz/pkg/runner/jazzer"
 	"code-intelligence.com/cifuzz/pkg/runner/libfuzzer"
 	"code-intelligence.com/cifuzz/util/fileutil"
@@ -95,27 +96,10 @@ func runLibfuzzer(opts *RunOptions, buildResult *build.BuildResult, reportHandle
 		}
 	}
 
-	// Use user-specified seed corpus dirs (if any) and the default seed
-	// corpus (if it exists).
-	exists, err := fileutil.Exists(buildResult.SeedCorpus)
+	err = addDefaultSeedCorpusAndDictionary(opts, buildResult)
 	if err != nil {
 		return err
 	}
-	if exists {
-		opts.SeedCorpusDirs = append(opts.SeedCorpusDirs, buildResult.SeedCorpus)
-	}
-
-	// If user-specified dictionary is not set, use
-	// implicit dictionary from buildResult (if it exists).
-	if opts.Dictionary == "" {
-		exists, err := fileutil.Exists(buildResult.Dictionary)
-		if err != nil {
-			return err
-		}
-		if exists {
-			opts.Dictionary = buildResult.Dictionary
-		}
-	}
 
 	runnerOpts := &libfuzzer.RunnerOptions{
 		Dictionary:         opts.Dictionary,
@@ -138,6 +122,71 @@ func runLibfuzzer(opts *RunOptions, buildResult *build.BuildResult, reportHandle
 	return ExecuteFuzzerRunner(libfuzzer.NewRunner(runnerOpts))
 }
 
+func runAFLPlusPlus(opts *RunOptions, buildResult *build.BuildResult, reportHandler *reporthandler.ReportHandler, skipBinaryCheck bool) error {
+	var err error
+
+	style := pterm.Style{pterm.Reset, pterm.FgLightBlue}
+	log.Infof("Running %s with AFL++", style.Sprintf(opts.FuzzTest))
+	log.Debugf("Executable: %s", buildResult.Executable)
+
+	if opts.UseSandbox {
+		log.Warn("Running AFL++ in the sandbox is not supported, running without sandbox")
+		opts.UseSandbox = false
+	}
+
+	libraryPaths, err := ldd.LibraryPaths(buildResult.Executable)
+	if err != nil {
+		return err
+	}
+
+	err = addDefaultSeedCorpusAndDictionary(opts, buildResult)
+	if err != nil {
+		return err
+	}
+
+	runnerOpts := &aflpp.RunnerOptions{
+		Dictionary:         opts.Dictionary,
+		EngineArgs:         opts.EngineArgs,
+		EnvVars:            []string{"NO_CIFUZZ=1"},
+		FuzzTarget:         buildResult.Executable,
+		GeneratedCorpusDir: buildResult.GeneratedCorpus,
+		KeepColor:          !opts.PrintJSON && !log.PlainStyle(),
+		LibraryDirs:        libraryPaths,
+		ProjectDir:         opts.ProjectDir,
+		ReportHandler:      reportHandler,
+		SeedCorpusDirs:     opts.SeedCorpusDirs,
+		SkipBinaryCheck:    skipBinaryCheck,
+		Timeout:            opts.Timeout,
+		Verbose:            viper.GetBool("verbose"),
+	}
+
+	return ExecuteFuzzerRunner(aflpp.NewRunner(runnerOpts))
+}
+
+// addDefaultSeedCorpusAndDictionary adds the default seed corpus of the
+// fuzz test to the seed corpus dirs and uses its default dictionary if
+// the user didn't specify one (if they exist).
+func addDefaultSeedCorpusAndDictionary(opts *RunOptions, buildResult *build.BuildResult) error {
+	exists, err := fileutil.Exists(buildResult.SeedCorpus)
+	if err != nil {
+		return err
+	}
+	if exists {
+		opts.SeedCorpusDirs = append(opts.SeedCorpusDirs, buildResult.SeedCorpus)
+	}
+
+	if opts.Dictionary == "" {
+		exists, err := fileutil.Exists(buildResult.Dictionary)
+		if err != nil {
+			return err
+		}
+		if exists {
+			opts.Dictionary = buildResult.Dictionary
+		}
+	}
+	return nil
+}
+
 func runJazzer(opts *RunOptions, buildResult *build.BuildResult, reportHandler *reporthandler.ReportHandler) error {
 	style := pterm.Style{pterm.Reset, pterm.FgLightBlue}
 	log.Infof("Running %s", style.Sprintf(opts.FuzzTest+"::"+opts.TargetMethod))
@@ -176,8 +225,9 @@ func runJazzer(opts *RunOptions, buildResult *build.BuildResult, reportHandler *
 
 	var fuzzerRunner FuzzerRunner
 
+	_, targetClass := cmdutils.SeparateModuleAndTargetClass(opts.FuzzTest)
 	runnerOpts := &jazzer.RunnerOptions{
-		TargetClass:  opts.FuzzTest,
+		TargetClass:  targetClass,
 		TargetMethod: opts.TargetMethod,
 		ClassPaths:   buildResult.RuntimeDeps,
 		LibfuzzerOptions: &libfuzzer.RunnerOptions{
@@ -1,6 +1,7 @@
 package adapter
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -8,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 
 	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/build/cache"
 	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/cmdutils/logging"
@@ -42,9 +44,55 @@ func wrapBuild[BR BuildResultType](opts *RunOptions, build func(*RunOptions) (*B
 	return cBuildResult, err
 }
 
+// withBuildCache wraps the build function so that, if the build cache
+// is enabled, the result of the last build of the fuzz test is reused
+// if neither the sources, nor the build flags, nor the toolchain
+// changed since then. If copyArtifacts
+// is true, the artifacts are copied to the cache, which is required if
+// they are overwritten by builds of other fuzz tests.
+func withBuildCache[BR cache.Result](build func(*RunOptions) (*BR, error), copyArtifacts bool) func(*RunOptions) (*BR, error) {
+	return func(opts *RunOptions) (*BR, error) {
+		// Builds with --build-only don't produce a build result
+		if !opts.BuildCache || opts.BuildOnly {
+			return build(opts)
+		}
+
+		c, err := cache.New(&cache.Key{
+			ProjectDir:  opts.ProjectDir,
+			BuildSystem: opts.BuildSystem,
+			Flags: []string{
+				"run",
+				opts.FuzzTest,
+				string(opts.Engine),
+				opts.CleanCommand,
+				fmt.Sprintf("%q", opts.ArgsToPass),
+			},
+			BuildCommand:  opts.BuildCommand,
+			CopyArtifacts: copyArtifacts,
+		})
+		if err != nil {
+			log.Debugf("Not using the build cache: %+v", err)
+		}
+		if cachedResults, found := cache.Load[BR](c); found && len(cachedResults) == 1 {
+			log.Info("Using cached build results")
+			return cachedResults[0], nil
+		}
+
+		result, err := build(opts)
+		if err != nil {
+			return nil, err
+		}
+		err = cache.Store(c, []*BR{result})
+		if err != nil {
+			log.Debugf("Failed to store the build results in the build cache: %+v", err)
+		}
+		return result, nil
+	}
+}
+
 func prepareCorpusDir(opts *RunOptions, buildResult *build.BuildResult) error {
 	switch opts.BuildSystem {
-	case config.BuildSystemCMake, config.BuildSystemBazel, config.BuildSystemOther:
+	case config.BuildSystemCMake, config.BuildSystemMeson, config.BuildSystemBazel, config.BuildSystemCargo, config.BuildSystemPython, config.BuildSystemOther:
 		// The generated corpus dir has to be created before starting the fuzzing run.
 		err := os.MkdirAll(buildResult.GeneratedCorpus, 0o755)
 		if err != nil {
@@ -80,7 +128,11 @@ func prepareCorpusDir(opts *RunOptions, buildResult *build.BuildResult) error {
 		// The seed corpus dir has to be created before starting the fuzzing run.
 		// Otherwise jazzer will store the findings in the project dir.
 		// It is not necessary to create the corpus dir. Jazzer will do that for us.
-		err := os.MkdirAll(cmdutils.JazzerSeedCorpus(opts.FuzzTest, opts.ProjectDir), 0o755)
+		// The seed corpus of fuzz tests of modules is located in the
+		// module directory.
+		module, targetClass := cmdutils.SeparateModuleAndTargetClass(opts.FuzzTest)
+		moduleDir := filepath.Join(opts.ProjectDir, filepath.FromSlash(module))
+		err := os.MkdirAll(cmdutils.JazzerSeedCorpus(targetClass, moduleDir), 0o755)
 		if err != nil {
 			return errors.WithStack(err)
 		}
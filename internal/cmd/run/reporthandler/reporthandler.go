@@ -22,6 +22,7 @@ import (
 	"code-intelligence.com/cifuzz/pkg/log"
 	"code-intelligence.com/cifuzz/pkg/parser/libfuzzer/stacktrace"
 	"code-intelligence.com/cifuzz/pkg/report"
+	"code-intelligence.com/cifuzz/pkg/symbolizer"
 	"code-intelligence.com/cifuzz/util/fileutil"
 	"code-intelligence.com/cifuzz/util/stringutil"
 )
@@ -34,6 +35,9 @@ type ReportHandlerOptions struct {
 	JSONOutput           io.Writer
 	PrinterOutput        io.Writer
 	SkipSavingFinding    bool
+	// If set, unsymbolized stack frames of findings are symbolized
+	// before the finding is named and saved
+	SymbolizerOptions *symbolizer.Options
 }
 
 type ReportHandler struct {
@@ -188,6 +192,15 @@ func (h *ReportHandler) handleFinding(f *finding.Finding) error {
 
 	f.CreatedAt = time.Now()
 
+	if h.SymbolizerOptions != nil {
+		_, err = symbolizer.SymbolizeFinding(f, h.SymbolizerOptions)
+		if err != nil {
+			// The finding is still useful without symbolized stack
+			// traces, so we don't return the error
+			log.Warnf("Failed to symbolize stack traces of finding: %v", err)
+		}
+	}
+
 	// Generate a name for the finding. The name is chosen deterministically,
 	// based on:
 	// * Parts of the stack trace: The function name, source file name,
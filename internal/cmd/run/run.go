@@ -72,9 +72,37 @@ depends on the build system configured for the project.
   is used automatically if no other dictionary is specified
   by using the --dict flag.
 
+` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("Meson") + `
+  <fuzz test> is the name of an executable in your meson.build files
+  which uses the cifuzz_fuzz_test_args and cifuzz_fuzz_test_link_args
+  options, see 'cifuzz init meson'.
+
+  Command completion for the <fuzz test> argument is supported when the
+  fuzz test was built before or after running 'cifuzz reload'.
+
+  The --build-command flag is ignored.
+
+  Additional arguments for 'meson setup' can be passed after a "--".
+  For example:
+
+    cifuzz run my_fuzz_test -- -Dfoo=bar
+
+  The inputs found in the directory
+
+    <fuzz test>_inputs
+
+  next to the meson.build file defining the fuzz test are used as a
+  starting point for the fuzzing run, as is the default dictionary
+
+    <fuzz test>.dict
+
+  if no other dictionary is specified by using the --dict flag.
+
 ` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("Bazel") + `
-  <fuzz test> is the name of the cc_fuzz_test target as defined in your
-  BUILD file, either as a relative or absolute Bazel label.
+  <fuzz test> is the name of the cc_fuzz_test or java_fuzz_test target
+  as defined in your BUILD file, either as a relative or absolute Bazel
+  label. If the class of a java_fuzz_test contains multiple fuzz tests,
+  you can use <fuzz test>::<method name> to specify a single fuzz test.
 
   Command completion for the <fuzz test> argument is supported.
 
@@ -94,7 +122,9 @@ depends on the build system configured for the project.
   <fuzz test> is the name of the class containing the fuzz test(s).
   If the fuzz test class contains multiple fuzz tests,
   you can use <fuzz test>::<method name> to specify a single fuzz
-  test.
+  test. Fuzz tests of Maven modules and Gradle subprojects can be
+  prefixed with the directory of the module, e.g.
+  libs/parser/com.example.ParserFuzzTest.
 
   Command completion for the <fuzz test> argument is supported.
 
@@ -184,7 +214,7 @@ depends on the build system configured for the project.
 			}
 
 			if sliceutil.Contains(
-				[]string{config.BuildSystemMaven, config.BuildSystemGradle},
+				[]string{config.BuildSystemMaven, config.BuildSystemGradle, config.BuildSystemBazel},
 				opts.BuildSystem,
 			) {
 				// Check if the fuzz test is a method of a class
@@ -251,14 +281,18 @@ depends on the build system configured for the project.
 		cmdutils.AddCleanCommandFlag,
 		cmdutils.AddBuildJobsFlag,
 		cmdutils.AddBuildOnlyFlag,
+		cmdutils.AddCompileCommandsFlag,
 		cmdutils.AddDictFlag,
+		cmdutils.AddEngineFlag,
 		cmdutils.AddEngineArgFlag,
 		cmdutils.AddInteractiveFlag,
+		cmdutils.AddBuildCacheFlag,
 		cmdutils.AddPrintJSONFlag,
 		cmdutils.AddProjectFlag,
 		cmdutils.AddProjectDirFlag,
 		cmdutils.AddSeedCorpusFlag,
 		cmdutils.AddServerFlag,
+		cmdutils.AddTransportFlags,
 		cmdutils.AddTimeoutFlag,
 		cmdutils.AddUseSandboxFlag,
 		cmdutils.AddResolveSourceFileFlag,
@@ -311,6 +345,22 @@ func (c *runCmd) run() error {
 		return err
 	}
 
+	// CI Sense doesn't know about the native Go fuzzing engine yet
+	if c.opts.BuildSystem == config.BuildSystemGo {
+		if len(c.reportHandler.Findings) > 0 {
+			log.Info("Skipping upload of findings because uploading findings of Go fuzz tests is not supported yet.")
+		}
+		return nil
+	}
+
+	// Neither does it know about Atheris
+	if c.opts.BuildSystem == config.BuildSystemPython {
+		if len(c.reportHandler.Findings) > 0 {
+			log.Info("Skipping upload of findings because uploading findings of Python fuzz tests is not supported yet.")
+		}
+		return nil
+	}
+
 	// We need this check, otherwise we might hang forever in CI
 	if c.opts.Project == "" && !c.opts.Interactive {
 		log.Info("Skipping upload of findings because no project was specified and running in non-interactive mode.")
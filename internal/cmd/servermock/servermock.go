@@ -0,0 +1,102 @@
+package servermock
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"code-intelligence.com/cifuzz/internal/api/localserver"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/pkg/log"
+)
+
+type options struct {
+	Address string
+	DataDir string
+	Token   string
+}
+
+type serverMockCmd struct {
+	*cobra.Command
+	opts *options
+}
+
+func New() *cobra.Command {
+	opts := &options{}
+	cmd := &cobra.Command{
+		Use:   "server-mock",
+		Short: "Serve a local stand-in for CI Sense",
+		Long: `This command starts a local server which implements the parts of the
+CI Sense API used by cifuzz to upload bundles, campaign runs and
+findings and to download findings. All data is stored in the data
+directory, so that the workflow can be used in air-gapped environments
+without access to CI Sense.
+
+To use the server, pass its URL via the --server flag (or the server
+option in cifuzz.yaml) and provide the token via the CIFUZZ_API_TOKEN
+environment variable. If no token is specified via --token, the server
+accepts any token. Projects are created on first use.
+
+Note that the server doesn't execute remote fuzzing runs, it only
+stores the uploaded bundles.`,
+		Example: `$ cifuzz server-mock --data-dir /srv/cifuzz
+$ CIFUZZ_API_TOKEN=local cifuzz run my_fuzz_test --server http://127.0.0.1:8080 --project my-project`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			cmd := serverMockCmd{Command: c, opts: opts}
+			return cmd.run()
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Address, "address", "127.0.0.1:8080", "The address the server listens on")
+	cmd.Flags().StringVar(&opts.DataDir, "data-dir", ".cifuzz-server-mock", "The directory in which the uploaded data is stored")
+	cmd.Flags().StringVar(&opts.Token, "token", "", "The API access token which clients have to provide")
+
+	cmdutils.DisableConfigCheck(cmd)
+
+	return cmd
+}
+
+func (c *serverMockCmd) run() error {
+	server, err := localserver.New(c.opts.DataDir, c.opts.Token)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", c.opts.Address)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	httpServer := &http.Server{
+		Handler:           server,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	go func() {
+		s := <-sigs
+		log.Infof("Received %s, shutting down", s.String())
+		err := httpServer.Shutdown(context.Background())
+		if err != nil {
+			log.Warnf("Failed to shut down server: %v", err)
+		}
+	}()
+
+	log.Successf("Serving a local stand-in for CI Sense on http://%s", listener.Addr().String())
+	log.Infof("Storing data in %s", c.opts.DataDir)
+
+	err = httpServer.Serve(listener)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
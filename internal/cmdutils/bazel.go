@@ -24,7 +24,7 @@ func EvaluateBazelTargetPatterns(patterns []string) ([]string, error) {
 
 	args := []string{
 		"query",
-		fmt.Sprintf("kind(fuzzing_regression_test, attr(generator_function, cc_fuzz_test, %s))", multiPattern),
+		fmt.Sprintf(`kind(fuzzing_regression_test, attr(generator_function, "^(cc|java)_fuzz_test$", %s))`, multiPattern),
 	}
 	cmd := exec.Command("bazel", args...)
 	log.Debugf("Command: %s", cmd.String())
@@ -151,6 +151,20 @@ func SeparateTargetClassAndMethod(fuzzTest string) (string, string) {
 	return split[0], split[1]
 }
 
+// SeparateModuleAndTargetClass splits up the given fuzz test into the
+// module and the target class if it follows the pattern <module>/<class>,
+// which is used for fuzz tests of Maven modules and Gradle subprojects.
+// The module is the path of the module directory relative to the root
+// project. If the fuzz test doesn't follow the pattern, it will return
+// an empty string and the given string.
+func SeparateModuleAndTargetClass(fuzzTest string) (string, string) {
+	i := strings.LastIndex(fuzzTest, "/")
+	if i == -1 {
+		return "", fuzzTest
+	}
+	return fuzzTest[:i], fuzzTest[i+1:]
+}
+
 // ListJVMFuzzTests gathers all fuzz tests using the list-fuzz-tests tool.
 func ListJVMFuzzTests(classNames []string, runtimeDeps []string) ([]string, error) {
 	listFuzzTestsJar, err := runfiles.Finder.ListFuzzTestsJarPath()
@@ -167,3 +167,13 @@ func TestGetJazzerSeedCorpus(t *testing.T) {
 	)
 	assert.Equal(t, expectedSeedCorpusDir, seedCorpusDir)
 }
+
+func TestSeparateModuleAndTargetClass(t *testing.T) {
+	module, class := SeparateModuleAndTargetClass("com.example.FuzzTest::fuzz")
+	assert.Equal(t, "", module)
+	assert.Equal(t, "com.example.FuzzTest::fuzz", class)
+
+	module, class = SeparateModuleAndTargetClass("libs/parser/com.example.FuzzTest::fuzz")
+	assert.Equal(t, "libs/parser", module)
+	assert.Equal(t, "com.example.FuzzTest::fuzz", class)
+}
@@ -6,17 +6,24 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/mattn/go-zglob"
 	"github.com/pkg/errors"
 
+	"code-intelligence.com/cifuzz/internal/build/nodejs"
 	"code-intelligence.com/cifuzz/pkg/log"
 	"code-intelligence.com/cifuzz/pkg/options"
 	"code-intelligence.com/cifuzz/util/envutil"
 	"code-intelligence.com/cifuzz/util/regexutil"
+	"code-intelligence.com/cifuzz/util/sliceutil"
 )
 
+// ListNodeFuzzTestsByRegex lists the fuzz tests of the project which
+// start with the prefix filter. Fuzz tests of workspace packages are
+// prefixed with the directory of the package relative to the project
+// directory, e.g. "packages/parser/ParserFuzz".
 func ListNodeFuzzTestsByRegex(projectDir string, prefixFilter string) ([]string, error) {
 	// use zglob to support globbing in windows
 	fuzzTestFiles, err := zglob.Glob(filepath.Join(projectDir, "**", "*.fuzz.*"))
@@ -24,8 +31,19 @@ func ListNodeFuzzTestsByRegex(projectDir string, prefixFilter string) ([]string,
 		return nil, errors.WithStack(err)
 	}
 
+	packages, err := nodejs.WorkspacePackages(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
 	var fuzzTests []string
 	for _, testFile := range fuzzTestFiles {
+		// Skip the fuzz tests of dependencies and the symlinks to the
+		// workspace packages
+		if sliceutil.Contains(strings.Split(filepath.ToSlash(testFile), "/"), "node_modules") {
+			continue
+		}
+
 		methods, err := getTargetMethodsFromNodeTestFile(testFile)
 		if err != nil {
 			return nil, err
@@ -37,8 +55,15 @@ func ListNodeFuzzTestsByRegex(projectDir string, prefixFilter string) ([]string,
 		} else {
 			fuzzTest = strings.TrimSuffix(fuzzTest, ".fuzz.js")
 		}
+		pkg, err := nodejs.PackageForFile(packages, testFile)
+		if err != nil {
+			return nil, err
+		}
+		if pkg != nil {
+			fuzzTest = pkg.RelDir + "/" + fuzzTest
+		}
 		if len(methods) == 1 {
-			if prefixFilter == "" || strings.HasPrefix(testFile, prefixFilter) {
+			if prefixFilter == "" || strings.HasPrefix(fuzzTest, prefixFilter) {
 				fuzzTests = append(fuzzTests, fuzzTest)
 			}
 			continue
@@ -55,10 +80,105 @@ func ListNodeFuzzTestsByRegex(projectDir string, prefixFilter string) ([]string,
 	return fuzzTests, nil
 }
 
-func ValidateNodeFuzzTest(projectDir string, testPathPattern string, testNamePattern string) error {
+// QualifyNodeFuzzTest returns the fuzz test prefixed with the directory
+// of the workspace package which contains it. If the given fuzz test is
+// not prefixed yet, the package is determined by matching the test path
+// pattern against the fuzz test files of the workspace packages. Fuzz
+// tests of the root package are returned unchanged.
+func QualifyNodeFuzzTest(projectDir string, fuzzTest string) (string, error) {
+	testPathPattern, _ := SeparateTestPathAndNamePattern(fuzzTest)
+	packageDir, _, err := nodejs.ResolveFuzzTest(projectDir, testPathPattern)
+	if err != nil {
+		return "", err
+	}
+	if packageDir != projectDir {
+		return fuzzTest, nil
+	}
+
+	packages, err := nodejs.WorkspacePackages(projectDir)
+	if err != nil {
+		return "", err
+	}
+	if len(packages) == 0 {
+		return fuzzTest, nil
+	}
+
+	// Jest matches the test path pattern as a regex against the path of
+	// the test file
+	testPathRegex, err := regexp.Compile(testPathPattern)
+	if err != nil {
+		testPathRegex = regexp.MustCompile(regexp.QuoteMeta(testPathPattern))
+	}
+
+	// use zglob to support globbing in windows
+	fuzzTestFiles, err := zglob.Glob(filepath.Join(projectDir, "**", "*.fuzz.*"))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	var matchingPackages []*nodejs.Package
+	for _, testFile := range fuzzTestFiles {
+		if sliceutil.Contains(strings.Split(filepath.ToSlash(testFile), "/"), "node_modules") {
+			continue
+		}
+		pkg, err := nodejs.PackageForFile(packages, testFile)
+		if err != nil {
+			return "", err
+		}
+		dir := projectDir
+		if pkg != nil {
+			dir = pkg.Dir
+		}
+		relPath, err := filepath.Rel(dir, testFile)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		if !testPathRegex.MatchString(filepath.ToSlash(relPath)) {
+			continue
+		}
+		if pkg == nil {
+			// The fuzz test belongs to the root package
+			return fuzzTest, nil
+		}
+		if !slices.Contains(matchingPackages, pkg) {
+			matchingPackages = append(matchingPackages, pkg)
+		}
+	}
+
+	switch len(matchingPackages) {
+	case 0:
+		return fuzzTest, nil
+	case 1:
+		return matchingPackages[0].RelDir + "/" + fuzzTest, nil
+	default:
+		var relDirs []string
+		for _, pkg := range matchingPackages {
+			relDirs = append(relDirs, pkg.RelDir)
+		}
+		return "", WrapIncorrectUsageError(errors.Errorf(
+			"Fuzz test '%s' matches fuzz tests of multiple workspace packages: %s\n"+
+				"Prefix the fuzz test with the directory of its package, e.g. '%s/%s'",
+			fuzzTest, strings.Join(relDirs, ", "), relDirs[0], fuzzTest,
+		))
+	}
+}
+
+// ValidateNodeFuzzTest checks that exactly one fuzz test matches the
+// fuzz test identifier and the test name pattern. The fuzz test
+// identifier is the test path pattern, prefixed with the directory of
+// the workspace package if the fuzz test belongs to one.
+func ValidateNodeFuzzTest(projectDir string, fuzzTest string, testNamePattern string) error {
+	packageDir, testPathPattern, err := nodejs.ResolveFuzzTest(projectDir, fuzzTest)
+	if err != nil {
+		return err
+	}
+	packageManager, err := nodejs.DetectPackageManager(projectDir)
+	if err != nil {
+		return err
+	}
+
 	var env []string
 	// enable "list fuzz tests" mode for jazzer.js
-	env, err := envutil.Setenv(env, "JAZZER_LIST_FUZZTEST_NAMES", "1")
+	env, err = envutil.Setenv(env, "JAZZER_LIST_FUZZTEST_NAMES", "1")
 	if err != nil {
 		return err
 	}
@@ -68,7 +188,7 @@ func ValidateNodeFuzzTest(projectDir string, testPathPattern string, testNamePat
 		return err
 	}
 
-	args := []string{"jest"}
+	args := nodejs.JestCommand(packageManager)
 	// pass test path pattern to jest
 	args = append(args, options.JazzerJSTestPathPatternFlag(testPathPattern))
 	// use a test name pattern, which is not matched by any fuzz test
@@ -76,8 +196,8 @@ func ValidateNodeFuzzTest(projectDir string, testPathPattern string, testNamePat
 	// disable jest reporters to prevent unnecessary output
 	args = append(args, options.JazzerJSReportersFlag(""))
 
-	cmd := exec.Command("npx", args...)
-	cmd.Dir = projectDir
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = packageDir
 	cmd.Env, err = envutil.Copy(os.Environ(), env)
 	if err != nil {
 		return err
@@ -113,6 +233,17 @@ func ValidateNodeFuzzTest(projectDir string, testPathPattern string, testNamePat
 	return nil
 }
 
+// SeparateTestPathAndNamePattern splits up the given Node.js fuzz test
+// into the test path pattern and the test name pattern if it follows
+// the pattern <test path pattern>:<test name pattern>, e.g.
+// `FuzzTestCase:"My fuzz test"`. The quotes around the test name
+// pattern are removed. If it doesn't follow the pattern, it will return
+// the given string and an empty string.
+func SeparateTestPathAndNamePattern(fuzzTest string) (string, string) {
+	testPathPattern, testNamePattern, _ := strings.Cut(fuzzTest, ":")
+	return testPathPattern, strings.ReplaceAll(testNamePattern, "\"", "")
+}
+
 func getTargetMethodsFromNodeTestFile(path string) ([]string, error) {
 	bytes, err := os.ReadFile(path)
 	if err != nil {
@@ -1,6 +1,7 @@
 package cmdutils
 
 import (
+	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
@@ -49,3 +50,43 @@ func TestIntegration_ValidateNodeFuzzTest(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "Multiple fuzz tests found")
 }
+
+func TestQualifyNodeFuzzTest(t *testing.T) {
+	projectDir := t.TempDir()
+	files := map[string]string{
+		"package.json":                           `{"name": "root", "workspaces": ["packages/*"]}`,
+		"root.fuzz.js":                           "",
+		"packages/parser/package.json":           `{"name": "parser"}`,
+		"packages/parser/ParserFuzz.fuzz.js":     "",
+		"packages/parser/shared.fuzz.js":         "",
+		"packages/lexer/package.json":            `{"name": "lexer"}`,
+		"packages/lexer/test/LexerFuzz.fuzz.ts":  "",
+		"packages/lexer/shared.fuzz.js":          "",
+		"node_modules/parser/ParserFuzz.fuzz.js": "",
+	}
+	for path, content := range files {
+		path = filepath.Join(projectDir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	for fuzzTest, expected := range map[string]string{
+		"ParserFuzz":                 "packages/parser/ParserFuzz",
+		`ParserFuzz:"My fuzz test"`:  `packages/parser/ParserFuzz:"My fuzz test"`,
+		"test/LexerFuzz":             "packages/lexer/test/LexerFuzz",
+		"packages/parser/ParserFuzz": "packages/parser/ParserFuzz",
+		"packages/parser/shared":     "packages/parser/shared",
+		"root":                       "root",
+		"NonExistingFuzz":            "NonExistingFuzz",
+	} {
+		qualified, err := QualifyNodeFuzzTest(projectDir, fuzzTest)
+		require.NoError(t, err)
+		assert.Equal(t, expected, qualified)
+	}
+
+	// The fuzz test is ambiguous if it matches fuzz tests of multiple
+	// workspace packages
+	_, err := QualifyNodeFuzzTest(projectDir, "shared")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "multiple workspace packages")
+}
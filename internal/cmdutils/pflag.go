@@ -7,6 +7,9 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/internal/transport"
 )
 
 var BundleFlags = []string{
@@ -14,8 +17,10 @@ var BundleFlags = []string{
 	"build-command",
 	"build-jobs",
 	"commit",
+	"corpus-from",
 	"dict",
 	"docker-image",
+	"engine",
 	"engine-arg",
 	"env",
 	"seed-corpus",
@@ -116,12 +121,32 @@ func AddCommitFlag(cmd *cobra.Command) func() {
 	}
 }
 
+func AddCompileCommandsFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("compile-commands", false,
+		"Write a compilation database of the fuzzing build for editor tooling.\n"+
+			"Only affects the build system type \"other\", for which it's\n"+
+			"created by recording the compiler invocations of the build command.")
+	return func() {
+		ViperMustBindPFlag("compile-commands", cmd.Flags().Lookup("compile-commands"))
+	}
+}
+
+func AddCorpusFromFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("corpus-from", "",
+		"A corpus archive created by 'cifuzz execute --export-corpus-dir' or a `directory`\n"+
+			"containing such archives and/or corpus directories named after the fuzz tests.\n"+
+			"The inputs are added to the seeds of the fuzz tests with the matching names.")
+	return func() {
+		ViperMustBindPFlag("corpus-from", cmd.Flags().Lookup("corpus-from"))
+	}
+}
+
 func AddDictFlag(cmd *cobra.Command) func() {
-	// TODO(afl): Also link to https://github.com/AFLplusplus/AFLplusplus/blob/stable/dictionaries/README.md
 	cmd.Flags().String("dict", "",
 		"A `file` containing input language keywords or other interesting byte sequences.\n"+
 			"This flag is only used if no default dictionary is found for the fuzz test.\n"+
-			"See https://llvm.org/docs/LibFuzzer.html#dictionaries.")
+			"See https://llvm.org/docs/LibFuzzer.html#dictionaries and\n"+
+			"https://github.com/AFLplusplus/AFLplusplus/blob/stable/dictionaries/README.md.")
 	return func() {
 		ViperMustBindPFlag("dict", cmd.Flags().Lookup("dict"))
 	}
@@ -153,11 +178,21 @@ By default, the image is chosen automatically based on the build system
 	}
 }
 
+func AddEngineFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("engine", string(config.Libfuzzer),
+		"The fuzzing `engine` used to run C/C++ fuzz tests, either \"libfuzzer\" or \"aflpp\" (AFL++).\n"+
+			"AFL++ is only supported for CMake and Bazel projects and requires\n"+
+			"afl-fuzz and afl-clang-fast to be installed.")
+	return func() {
+		ViperMustBindPFlag("engine", cmd.Flags().Lookup("engine"))
+	}
+}
+
 func AddEngineArgFlag(cmd *cobra.Command) func() {
-	// TODO(afl): Also link to https://www.mankier.com/8/afl-fuzz
 	cmd.Flags().StringArray("engine-arg", nil,
 		"Command-line `argument` to pass to the fuzzing engine.\n"+
-			"See https://llvm.org/docs/LibFuzzer.html#options.\n"+
+			"See https://llvm.org/docs/LibFuzzer.html#options\n"+
+			"and https://www.mankier.com/8/afl-fuzz (with --engine aflpp).\n"+
 			"This flag can be used multiple times.\n"+
 			"Not supported for Node.js projects.")
 	return func() {
@@ -219,6 +254,15 @@ func AddMonitorIntervalFlag(cmd *cobra.Command) func() {
 	}
 }
 
+func AddBuildCacheFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("build-cache", false,
+		"Reuse the results of the last build if neither the sources, nor the\n"+
+			"build flags, nor the toolchain changed since then.")
+	return func() {
+		ViperMustBindPFlag("build-cache", cmd.Flags().Lookup("build-cache"))
+	}
+}
+
 func AddPresetFlag(cmd *cobra.Command) func() {
 	cmd.Flags().String("preset", "", "Preset for a given environment to execute coverage with necessary flags.\n"+
 		"We recommend not using this flag with '--format' or '--output' because the preset will set these accordingly.\n"+
@@ -272,8 +316,18 @@ e.g. "my-project-c170bc17".`)
 	}
 }
 
+func AddSanitizerFlag(cmd *cobra.Command) func() {
+	cmd.Flags().StringArray("sanitizer", nil,
+		"Additionally build the C/C++ fuzz tests with the given `sanitizer`, either \"memory\"\n"+
+			"(MemorySanitizer, only on Linux) or \"thread\" (ThreadSanitizer). These sanitizers\n"+
+			"can't be combined with AddressSanitizer, so each of them is built as a separate variant.\n"+
+			"This flag can be used multiple times.")
+	return func() {
+		ViperMustBindPFlag("sanitizers", cmd.Flags().Lookup("sanitizer"))
+	}
+}
+
 func AddSeedCorpusFlag(cmd *cobra.Command) func() {
-	// TODO(afl): Also link to https://aflplus.plus/docs/fuzzing_in_depth/#a-collecting-inputs
 	cmd.Flags().StringArrayP("seed-corpus", "s", nil,
 		"A `directory` containing sample inputs used as seeds for fuzzing the code under test.\n"+
 			"This is used in addition to inputs found in the inputs directory of the fuzz test.\n"+
@@ -299,6 +353,27 @@ func AddTimeoutFlag(cmd *cobra.Command) func() {
 	}
 }
 
+// AddTransportFlags adds the flags to configure a proxy, certificates
+// and retries for the connection to CI Sense
+func AddTransportFlags(cmd *cobra.Command) func() {
+	cmd.Flags().String(transport.ProxyKey, "",
+		"URL of an HTTP(S) or SOCKS5 proxy used to connect to CI Sense, e.g. \"http://proxy.example.com:3128\"")
+	cmd.Flags().String(transport.CACertKey, "",
+		"Path of a PEM file with additional CA certificates trusted when connecting to CI Sense")
+	cmd.Flags().String(transport.ClientCertKey, "",
+		"Path of a PEM encoded client certificate for mutual TLS authentication with CI Sense")
+	cmd.Flags().String(transport.ClientKeyKey, "",
+		"Path of the PEM encoded private key of the client certificate")
+	cmd.Flags().Int("api-max-retries", 5,
+		"Number of times requests to CI Sense which failed with a transient error are retried")
+	return func() {
+		for _, key := range []string{transport.ProxyKey, transport.CACertKey, transport.ClientCertKey, transport.ClientKeyKey} {
+			ViperMustBindPFlag(key, cmd.Flags().Lookup(key))
+		}
+		ViperMustBindPFlag("api-max-retries", cmd.Flags().Lookup("api-max-retries"))
+	}
+}
+
 func AddUseSandboxFlag(cmd *cobra.Command) func() {
 	cmd.Flags().Bool("use-sandbox", false,
 		"By default, fuzz tests are executed in a sandbox to prevent accidental damage to the system.\n"+
@@ -309,3 +384,13 @@ func AddUseSandboxFlag(cmd *cobra.Command) func() {
 		ViperMustBindPFlag("use-sandbox", cmd.Flags().Lookup("use-sandbox"))
 	}
 }
+
+func AddVendorNodeModulesFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("vendor-node-modules", false,
+		"Add the installed dependencies in the node_modules directories of\n"+
+			"Node.js projects to the bundle, so that they don't have to be\n"+
+			"installed when the fuzz tests are executed, e.g. by offline runners.")
+	return func() {
+		ViperMustBindPFlag("vendor-node-modules", cmd.Flags().Lookup("vendor-node-modules"))
+	}
+}
@@ -13,8 +13,11 @@ import (
 	"github.com/mattn/go-zglob"
 	"github.com/pkg/errors"
 
-	"code-intelligence.com/cifuzz/internal/build/java/gradle"
-	"code-intelligence.com/cifuzz/internal/build/java/maven"
+	"code-intelligence.com/cifuzz/internal/build/cargo"
+	"code-intelligence.com/cifuzz/internal/build/java"
+	"code-intelligence.com/cifuzz/internal/build/meson"
+	"code-intelligence.com/cifuzz/internal/build/nodejs"
+	"code-intelligence.com/cifuzz/internal/build/python"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/util/fileutil"
@@ -24,6 +27,8 @@ import (
 // TODO: use file info of cmake instead of this regex
 var cmakeFuzzTestFileNamePattern = regexp.MustCompile(`add_fuzz_test\((?P<fuzzTest>[a-zA-Z0-9_.+=,@~-]+)\s(?P<file>[a-zA-Z0-9/\_.+=,@~-]+)\)`)
 
+var goFuzzTestFuncPattern = regexp.MustCompile(`(?m)^func (?P<fuzzTest>Fuzz\w*)\(\w+ \*testing\.F\)`)
+
 // resolve determines the corresponding fuzz test name to a given source file.
 // The path has to be relative to the project directory.
 func resolve(path, buildSystem, projectDir string) (string, error) {
@@ -71,7 +76,7 @@ func resolve(path, buildSystem, projectDir string) (string, error) {
 			// backslashes and replace them internally
 			path = strings.ReplaceAll(path, "\\", "/")
 		}
-		arg := fmt.Sprintf(`attr(generator_function, cc_fuzz_test, same_pkg_direct_rdeps(%q))`, path)
+		arg := fmt.Sprintf(`attr(generator_function, "^(cc|java)_fuzz_test$", same_pkg_direct_rdeps(%q))`, path)
 		cmd := exec.Command("bazel", "query", arg)
 		cmd.Stderr = os.Stderr
 		out, err := cmd.Output()
@@ -83,24 +88,18 @@ func resolve(path, buildSystem, projectDir string) (string, error) {
 		}
 
 		fuzzTest := strings.TrimSpace(string(out))
+		// The source files are direct dependencies of the "_raw_" target
+		// of cc_fuzz_test and of the "_target" java_binary of
+		// java_fuzz_test
 		fuzzTest = strings.TrimSuffix(fuzzTest, "_raw_")
+		fuzzTest = strings.TrimSuffix(fuzzTest, "_target")
 
 		return fuzzTest, nil
 
 	case config.BuildSystemMaven, config.BuildSystemGradle:
-		var testDirs []string
-		var err error
-		if buildSystem == config.BuildSystemMaven {
-			testDir, err := maven.GetTestDir(projectDir)
-			if err != nil {
-				return "", err
-			}
-			testDirs = append(testDirs, testDir)
-		} else if buildSystem == config.BuildSystemGradle {
-			testDirs, err = gradle.GetTestSourceSets(projectDir)
-			if err != nil {
-				return "", err
-			}
+		testDirs, err := java.TestDirs(projectDir, buildSystem)
+		if err != nil {
+			return "", err
 		}
 
 		var fuzzTest string
@@ -147,6 +146,20 @@ func resolve(path, buildSystem, projectDir string) (string, error) {
 			if err != nil {
 				return "", err
 			}
+
+			// Fuzz tests of modules are prefixed with the directory of
+			// the module
+			modules, err := java.Modules(projectDir, buildSystem)
+			if err != nil {
+				return "", err
+			}
+			module, err := java.ModuleForDir(modules, testDir)
+			if err != nil {
+				return "", err
+			}
+			if module != nil {
+				fuzzTest = module.RelDir + "/" + fuzzTest
+			}
 			break
 		}
 		if !found {
@@ -158,10 +171,74 @@ func resolve(path, buildSystem, projectDir string) (string, error) {
 		testFile := filepath.Base(path)
 		fuzzTest := strings.TrimSuffix(testFile, ".fuzz"+filepath.Ext(testFile))
 
+		// Fuzz tests of workspace packages are prefixed with the
+		// directory of the package
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectDir, path)
+		}
+		packages, err := nodejs.WorkspacePackages(projectDir)
+		if err != nil {
+			return "", err
+		}
+		pkg, err := nodejs.PackageForFile(packages, path)
+		if err != nil {
+			return "", err
+		}
+		if pkg != nil {
+			fuzzTest = pkg.RelDir + "/" + fuzzTest
+		}
+
 		return fuzzTest, nil
 
+	case config.BuildSystemGo:
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectDir, path)
+		}
+		bs, err := os.ReadFile(path)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		matches, _ := regexutil.FindAllNamedGroupsMatches(goFuzzTestFuncPattern, string(bs))
+		if len(matches) == 0 {
+			return "", errors.New("no fuzz test found")
+		}
+		if len(matches) > 1 {
+			return "", errors.Errorf("%s contains more than one fuzz test", path)
+		}
+		// Go resolves relative package paths only if they start with "./"
+		pkgDir, err := filepath.Rel(projectDir, filepath.Dir(path))
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		return "./" + filepath.ToSlash(pkgDir) + ":" + matches[0]["fuzzTest"], nil
+
+	case config.BuildSystemCargo:
+		return cargo.FuzzTargetForSourceFile(projectDir, path)
+
+	case config.BuildSystemMeson:
+		return meson.FuzzTestForSourceFile(projectDir, path)
+
+	case config.BuildSystemPython:
+		// Python fuzz tests are identified by the path of the script
+		// relative to the project directory
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectDir, path)
+		}
+		isFuzzTest, err := python.IsFuzzTest(path)
+		if err != nil {
+			return "", err
+		}
+		if !isFuzzTest {
+			return "", errors.New("no fuzz test found")
+		}
+		fuzzTest, err := filepath.Rel(projectDir, path)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		return filepath.ToSlash(fuzzTest), nil
+
 	default:
-		return "", errors.New("The flag '--resolve' only supports the following build systems: CMake, Bazel, Maven, Gradle.")
+		return "", errors.New("The flag '--resolve' only supports the following build systems: CMake, Meson, Bazel, Maven, Gradle, Go, Cargo, Python.")
 	}
 }
 
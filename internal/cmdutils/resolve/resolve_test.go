@@ -63,6 +63,21 @@ func TestResolve(t *testing.T) {
 		pwd := changeWdToTestData("nodejs")
 		testResolveNodeJS(t, pwd)
 	})
+
+	t.Run("testResolveGo", func(t *testing.T) {
+		defer revertToTestDataDir()
+		testResolveGo(t, changeWdToTestData("go"))
+	})
+
+	t.Run("testResolveCargo", func(t *testing.T) {
+		defer revertToTestDataDir()
+		testResolveCargo(t, changeWdToTestData("cargo"))
+	})
+
+	t.Run("testResolvePython", func(t *testing.T) {
+		defer revertToTestDataDir()
+		testResolvePython(t, changeWdToTestData("python"))
+	})
 }
 
 func testResolveBazel(t *testing.T, pwd string) {
@@ -224,3 +239,51 @@ func testResolveNodeJS(t *testing.T, pwd string) {
 	require.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 }
+
+func testResolveGo(t *testing.T, pwd string) {
+	fuzzTestName := "./parser:FuzzParse"
+
+	// relative path
+	srcFile := filepath.Join("parser", "parser_test.go")
+	resolved, err := resolve(srcFile, config.BuildSystemGo, pwd)
+	require.NoError(t, err)
+	require.Equal(t, fuzzTestName, resolved)
+
+	// absolute path
+	srcFile = filepath.Join(pwd, srcFile)
+	resolved, err = resolve(srcFile, config.BuildSystemGo, pwd)
+	require.NoError(t, err)
+	require.Equal(t, fuzzTestName, resolved)
+}
+
+func testResolveCargo(t *testing.T, pwd string) {
+	fuzzTestName := "parse"
+
+	// relative path
+	srcFile := filepath.Join("fuzz", "fuzz_targets", "parse.rs")
+	resolved, err := resolve(srcFile, config.BuildSystemCargo, pwd)
+	require.NoError(t, err)
+	require.Equal(t, fuzzTestName, resolved)
+
+	// absolute path
+	srcFile = filepath.Join(pwd, srcFile)
+	resolved, err = resolve(srcFile, config.BuildSystemCargo, pwd)
+	require.NoError(t, err)
+	require.Equal(t, fuzzTestName, resolved)
+}
+
+func testResolvePython(t *testing.T, pwd string) {
+	fuzzTestName := "tests/fuzz_parse.py"
+
+	// relative path
+	srcFile := filepath.Join("tests", "fuzz_parse.py")
+	resolved, err := resolve(srcFile, config.BuildSystemPython, pwd)
+	require.NoError(t, err)
+	require.Equal(t, fuzzTestName, resolved)
+
+	// absolute path
+	srcFile = filepath.Join(pwd, srcFile)
+	resolved, err = resolve(srcFile, config.BuildSystemPython, pwd)
+	require.NoError(t, err)
+	require.Equal(t, fuzzTestName, resolved)
+}
@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func FuzzParse(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_ = data
+	})
+}
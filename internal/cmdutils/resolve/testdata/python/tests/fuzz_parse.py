@@ -0,0 +1,12 @@
+import sys
+
+import atheris
+
+
+def TestOneInput(data):
+    pass
+
+
+if __name__ == "__main__":
+    atheris.Setup(sys.argv, TestOneInput)
+    atheris.Fuzz()
@@ -12,6 +12,12 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"code-intelligence.com/cifuzz/internal/build/cargo"
+	"code-intelligence.com/cifuzz/internal/build/golang"
+	"code-intelligence.com/cifuzz/internal/build/java"
+	"code-intelligence.com/cifuzz/internal/build/meson"
+	"code-intelligence.com/cifuzz/internal/build/other"
+	"code-intelligence.com/cifuzz/internal/build/python"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/pkg/log"
@@ -21,7 +27,7 @@ import (
 
 // This regex is based on the bazel bash completion script, see:
 // https://github.com/bazelbuild/bazel/blob/021c2a053780d697899cbcbd76a032c72cd5cbbb/scripts/bazel-complete-template.bash#L173
-var bazelFuzzTestTargetPattern = regexp.MustCompile(`cc_fuzz_test *\([^)]* {0,1}name *= *['"](?P<name>[a-zA-Z0-9_.+=,@~-]*)['"][^)]*\)`)
+var bazelFuzzTestTargetPattern = regexp.MustCompile(`(?:cc|java)_fuzz_test *\([^)]* {0,1}name *= *['"](?P<name>[a-zA-Z0-9_.+=,@~-]*)['"][^)]*\)`)
 
 // ValidFuzzTests can be used as a cobra ValidArgsFunction that completes fuzz test names.
 func ValidFuzzTests(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -34,8 +40,9 @@ func ValidFuzzTests(cmd *cobra.Command, args []string, toComplete string) ([]str
 
 	// Read the project config to figure out the build system
 	conf := struct {
-		BuildSystem string `mapstructure:"build-system"`
-		ProjectDir  string `mapstructure:"project-dir"`
+		BuildSystem      string   `mapstructure:"build-system"`
+		ProjectDir       string   `mapstructure:"project-dir"`
+		FuzzTestPatterns []string `mapstructure:"fuzz-tests"`
 	}{}
 	err = config.FindAndParseProjectConfig(&conf)
 	if err != nil {
@@ -48,18 +55,21 @@ func ValidFuzzTests(cmd *cobra.Command, args []string, toComplete string) ([]str
 		return validBazelFuzzTests(toComplete)
 	case config.BuildSystemCMake:
 		return validCMakeFuzzTests(conf.ProjectDir)
+	case config.BuildSystemMeson:
+		return validMesonFuzzTests(conf.ProjectDir)
 	case config.BuildSystemMaven, config.BuildSystemGradle:
-		return validJVMFuzzTests(conf.ProjectDir, toComplete)
+		return validJVMFuzzTests(conf.ProjectDir, conf.BuildSystem, toComplete)
 	case config.BuildSystemNodeJS:
 		return validNodeFuzzTests(conf.ProjectDir, toComplete)
+	case config.BuildSystemGo:
+		return validGoFuzzTests(conf.ProjectDir)
+	case config.BuildSystemCargo:
+		return validCargoFuzzTests(conf.ProjectDir)
+	case config.BuildSystemPython:
+		return validPythonFuzzTests(conf.ProjectDir)
 
 	case config.BuildSystemOther:
-		// For other build systems, the <fuzz test> argument must be
-		// the path to the fuzz test executable, so we use file
-		// completion here (which is only useful if the executable has
-		// been built before, but that's still better than no completion
-		// support)
-		return nil, cobra.ShellCompDirectiveDefault
+		return validOtherFuzzTests(conf.ProjectDir, conf.FuzzTestPatterns)
 	default:
 		err := errors.Errorf("Unsupported build system \"%s\"", conf.BuildSystem)
 		log.Error(err)
@@ -166,13 +176,38 @@ func validCMakeFuzzTests(projectDir string) ([]string, cobra.ShellCompDirective)
 	return res, cobra.ShellCompDirectiveNoFileComp
 }
 
-// validJVMFuzzTests returns a list of valid JVM fuzz test identifiers
-// (i.e. the fully qualified class name of the fuzz test)
-func validJVMFuzzTests(projectDir string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	testDirs := []string{
-		filepath.Join(projectDir, "src", "test"),
+// validMesonFuzzTests returns the names of the fuzz tests found in the
+// build directories which were configured by cifuzz before
+func validMesonFuzzTests(projectDir string) ([]string, cobra.ShellCompDirective) {
+	fuzzTests, err := meson.ListConfiguredFuzzTests(projectDir)
+	if err != nil {
+		log.Error(err)
+		return nil, cobra.ShellCompDirectiveError
 	}
-	fuzzTests, err := cmdutils.ListJVMFuzzTestsByRegex(testDirs, toComplete)
+	return fuzzTests, cobra.ShellCompDirectiveNoFileComp
+}
+
+// validOtherFuzzTests returns the fuzz tests from the fuzz-tests setting
+// or the fuzz test executables which were built before
+func validOtherFuzzTests(projectDir string, patterns []string) ([]string, cobra.ShellCompDirective) {
+	fuzzTests, err := other.ListFuzzTests(projectDir, patterns)
+	if err != nil {
+		log.Error(err)
+		return nil, cobra.ShellCompDirectiveError
+	}
+	if len(fuzzTests) == 0 {
+		// The <fuzz test> argument can also be the path to the fuzz
+		// test executable, so we fall back to file completion
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return fuzzTests, cobra.ShellCompDirectiveNoFileComp
+}
+
+// validJVMFuzzTests returns a list of valid JVM fuzz test identifiers
+// (i.e. the fully qualified class name of the fuzz test, prefixed with
+// the module directory for fuzz tests of modules)
+func validJVMFuzzTests(projectDir string, buildSystem string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	fuzzTests, err := java.ListFuzzTestsByRegex(projectDir, buildSystem, toComplete)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
@@ -189,6 +224,41 @@ func validNodeFuzzTests(projectDir string, toComplete string) ([]string, cobra.S
 	return fuzzTests, cobra.ShellCompDirectiveNoFileComp
 }
 
+// validGoFuzzTests returns a list of valid Go fuzz test identifiers
+// (i.e. the import path of the package and the name of the fuzz test)
+func validGoFuzzTests(projectDir string) ([]string, cobra.ShellCompDirective) {
+	fuzzTests, err := golang.ListFuzzTests(projectDir)
+	if err != nil {
+		log.Error(err)
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var res []string
+	for _, fuzzTest := range fuzzTests {
+		res = append(res, fuzzTest.String())
+	}
+	return res, cobra.ShellCompDirectiveNoFileComp
+}
+
+// validCargoFuzzTests returns the names of the fuzz targets declared in
+// the fuzz/Cargo.toml of the project
+func validCargoFuzzTests(projectDir string) ([]string, cobra.ShellCompDirective) {
+	fuzzTargets, err := cargo.ListFuzzTargets(projectDir)
+	if err != nil {
+		log.Error(err)
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return fuzzTargets, cobra.ShellCompDirectiveNoFileComp
+}
+
+func validPythonFuzzTests(projectDir string) ([]string, cobra.ShellCompDirective) {
+	fuzzTests, err := python.ListFuzzTests(projectDir)
+	if err != nil {
+		log.Error(err)
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return fuzzTests, cobra.ShellCompDirectiveNoFileComp
+}
+
 // findBazelBuildFiles returns the paths to all BUILD.bazel and BUILD files
 // found in the given directory.
 func findBazelBuildFiles(toComplete string, dir string) ([]string, error) {
@@ -221,7 +291,8 @@ func findBazelBuildFiles(toComplete string, dir string) ([]string, error) {
 	return buildFiles, errors.WithStack(err)
 }
 
-// findTargetsInBuildFile returns all "cc_fuzz_test" targets in a given build file.
+// findTargetsInBuildFile returns all "cc_fuzz_test" and "java_fuzz_test"
+// targets in a given build file.
 func findTargetsInBuildFile(filePath string) (map[string]string, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -240,7 +311,7 @@ func findTargetsInBuildFile(filePath string) (map[string]string, error) {
 		}
 	}
 
-	if !strings.Contains(text, "cc_fuzz_test") {
+	if !strings.Contains(text, "cc_fuzz_test") && !strings.Contains(text, "java_fuzz_test") {
 		return nil, nil
 	}
 
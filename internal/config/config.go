@@ -25,33 +25,46 @@ import (
 
 const (
 	BuildSystemBazel  string = "bazel"
+	BuildSystemCargo  string = "cargo"
 	BuildSystemCMake  string = "cmake"
+	BuildSystemMeson  string = "meson"
+	BuildSystemGo     string = "go"
 	BuildSystemNodeJS string = "nodejs"
 	BuildSystemMaven  string = "maven"
 	BuildSystemGradle string = "gradle"
+	BuildSystemPython string = "python"
 	BuildSystemOther  string = "other"
 )
 
 var buildSystemTypes = []string{
 	BuildSystemBazel,
+	BuildSystemCargo,
 	BuildSystemCMake,
+	BuildSystemMeson,
+	BuildSystemGo,
 	BuildSystemNodeJS,
 	BuildSystemMaven,
 	BuildSystemGradle,
+	BuildSystemPython,
 	BuildSystemOther,
 }
 
 var supportedBuildSystems = map[string][]string{
 	"linux": buildSystemTypes,
 	"darwin": {
+		BuildSystemCargo,
 		BuildSystemCMake,
+		BuildSystemMeson,
+		BuildSystemGo,
 		BuildSystemNodeJS,
 		BuildSystemMaven,
 		BuildSystemGradle,
+		BuildSystemPython,
 		BuildSystemOther,
 	},
 	"windows": {
 		BuildSystemCMake,
+		BuildSystemGo,
 		BuildSystemNodeJS,
 		BuildSystemMaven,
 		BuildSystemGradle,
@@ -62,7 +75,7 @@ const ProjectConfigFile = "cifuzz.yaml"
 
 const AllowUnsupportedPlatformsEnv = "CIFUZZ_ALLOW_UNSUPPORTED_PLATFORMS"
 
-const GradleMultiProjectWarningMsg = "If this project has subprojects, CI Fuzz should be setup in the subprojects containing the fuzz tests."
+const GradleMultiProjectWarningMsg = "If this project has subprojects, the CI Fuzz Gradle plugin has to be added to the subprojects containing the fuzz tests."
 
 //go:embed cifuzz.yaml.tmpl
 var projectConfigTemplate string
@@ -197,24 +210,72 @@ func ValidateBuildSystem(buildSystem string) error {
 	return nil
 }
 
-func DetermineBuildSystem(projectDir string) (string, error) {
-	buildSystemIdentifier := map[string][]string{
-		BuildSystemBazel:  {"WORKSPACE", "WORKSPACE.bazel"},
-		BuildSystemCMake:  {"CMakeLists.txt"},
-		BuildSystemNodeJS: {"package.json", "package-lock.json", "yarn.lock", "node_modules/"},
-		BuildSystemMaven:  {"pom.xml"},
-		BuildSystemGradle: {"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"},
+// ValidateEngine checks that the engine is supported in combination
+// with the build system and the current platform. An empty engine
+// means that the default engine of the build system is used.
+func ValidateEngine(engine Engine, buildSystem string) error {
+	if engine == "" || engine == Libfuzzer {
+		return nil
+	}
+
+	if engine != AFLPlusPlus {
+		return errors.Errorf("Unsupported engine %q, supported engines are: %s", engine, strings.Join(engineNames(), ", "))
+	}
+
+	if buildSystem != BuildSystemCMake && buildSystem != BuildSystemBazel {
+		return errors.Errorf("The %s engine is only supported for CMake and Bazel projects", engine)
+	}
+	if runtime.GOOS == "windows" {
+		return errors.Errorf("The %s engine is not supported on Windows", engine)
+	}
+
+	return nil
+}
+
+func engineNames() []string {
+	var names []string
+	for _, engine := range Engines {
+		names = append(names, string(engine))
 	}
+	return names
+}
+
+// buildSystemIdentifiers are the files which identify the build
+// systems, in the order in which the build systems are detected.
+// Projects often contain the files of multiple build systems (e.g. a
+// package.json for tooling or a go.mod for bindings), so the build
+// systems which are the most specific for fuzzing come first: Bazel,
+// which is often used alongside other build systems, then the C/C++,
+// Rust and JVM build systems, then Go and Python and finally Node.js,
+// whose files exist in many projects for tooling only.
+var buildSystemIdentifiers = []struct {
+	buildSystem string
+	files       []string
+}{
+	{BuildSystemBazel, []string{"WORKSPACE", "WORKSPACE.bazel"}},
+	{BuildSystemCMake, []string{"CMakeLists.txt"}},
+	{BuildSystemMeson, []string{"meson.build"}},
+	{BuildSystemCargo, []string{"Cargo.toml", "fuzz/Cargo.toml"}},
+	{BuildSystemMaven, []string{"pom.xml"}},
+	{BuildSystemGradle, []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"}},
+	{BuildSystemGo, []string{"go.mod"}},
+	{BuildSystemPython, []string{"pyproject.toml", "setup.py"}},
+	{BuildSystemNodeJS, []string{"package.json", "package-lock.json", "yarn.lock", "node_modules/"}},
+}
 
-	for buildSystem, files := range buildSystemIdentifier {
-		for _, f := range files {
+// DetermineBuildSystem detects the build system of the project by the
+// files in the project directory. If the files of multiple build
+// systems exist, the first one in buildSystemIdentifiers is returned.
+func DetermineBuildSystem(projectDir string) (string, error) {
+	for _, identifier := range buildSystemIdentifiers {
+		for _, f := range identifier.files {
 			isBuildSystem, err := fileutil.Exists(filepath.Join(projectDir, f))
 			if err != nil {
 				return "", err
 			}
 
 			if isBuildSystem {
-				return buildSystem, nil
+				return identifier.buildSystem, nil
 			}
 		}
 	}
@@ -318,6 +379,12 @@ func NotSupportedErrorMessage(tool string, platform string) string {
 			return "CMake"
 		case "nodejs":
 			return "NodeJS"
+		case "go":
+			return "Go"
+		case "cargo":
+			return "Cargo"
+		case "python":
+			return "Python"
 		case "nodets":
 			return "NodeTS"
 		case "darwin":
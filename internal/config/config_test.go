@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hectane/go-acl"
@@ -185,6 +186,91 @@ func TestDetermineBuildSystem_CMake(t *testing.T) {
 	assert.Equal(t, BuildSystemCMake, buildSystem)
 }
 
+func TestDetermineBuildSystem_Meson(t *testing.T) {
+	projectDir, err := os.MkdirTemp(baseTempDir, "project-")
+	require.NoError(t, err)
+	defer fileutil.Cleanup(projectDir)
+
+	err = os.WriteFile(filepath.Join(projectDir, "meson.build"), []byte("project('project', 'c')\n"), 0o644)
+	require.NoError(t, err, "Failed to create meson.build")
+	buildSystem, err := DetermineBuildSystem(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, BuildSystemMeson, buildSystem)
+}
+
+func TestDetermineBuildSystem_Go(t *testing.T) {
+	projectDir, err := os.MkdirTemp(baseTempDir, "project-")
+	require.NoError(t, err)
+	defer fileutil.Cleanup(projectDir)
+
+	err = os.WriteFile(filepath.Join(projectDir, "go.mod"), []byte("module example.com/project\n"), 0o644)
+	require.NoError(t, err, "Failed to create go.mod")
+	buildSystem, err := DetermineBuildSystem(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, BuildSystemGo, buildSystem)
+}
+
+func TestDetermineBuildSystem_Cargo(t *testing.T) {
+	projectDir, err := os.MkdirTemp(baseTempDir, "project-")
+	require.NoError(t, err)
+	defer fileutil.Cleanup(projectDir)
+
+	err = os.WriteFile(filepath.Join(projectDir, "Cargo.toml"), []byte("[package]\nname = \"project\"\n"), 0o644)
+	require.NoError(t, err, "Failed to create Cargo.toml")
+	buildSystem, err := DetermineBuildSystem(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, BuildSystemCargo, buildSystem)
+}
+
+func TestDetermineBuildSystem_Python(t *testing.T) {
+	projectDir, err := os.MkdirTemp(baseTempDir, "project-")
+	require.NoError(t, err)
+	defer fileutil.Cleanup(projectDir)
+
+	err = os.WriteFile(filepath.Join(projectDir, "pyproject.toml"), []byte("[project]\nname = \"project\"\n"), 0o644)
+	require.NoError(t, err, "Failed to create pyproject.toml")
+	buildSystem, err := DetermineBuildSystem(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, BuildSystemPython, buildSystem)
+}
+
+func TestDetermineBuildSystem_Overlapping(t *testing.T) {
+	testCases := []struct {
+		files    []string
+		expected string
+	}{
+		{[]string{"go.mod", "package.json"}, BuildSystemGo},
+		{[]string{"go.mod", "CMakeLists.txt"}, BuildSystemCMake},
+		{[]string{"meson.build", "CMakeLists.txt"}, BuildSystemCMake},
+		{[]string{"pyproject.toml", "package.json"}, BuildSystemPython},
+		{[]string{"Cargo.toml", "package.json"}, BuildSystemCargo},
+		{[]string{"WORKSPACE", "CMakeLists.txt", "go.mod"}, BuildSystemBazel},
+		{[]string{"pom.xml", "build.gradle"}, BuildSystemMaven},
+		{[]string{"setup.py", "go.mod"}, BuildSystemGo},
+	}
+
+	for _, tc := range testCases {
+		t.Run(strings.Join(tc.files, "+"), func(t *testing.T) {
+			projectDir, err := os.MkdirTemp(baseTempDir, "project-")
+			require.NoError(t, err)
+			defer fileutil.Cleanup(projectDir)
+
+			for _, f := range tc.files {
+				err = os.WriteFile(filepath.Join(projectDir, f), []byte{}, 0o644)
+				require.NoError(t, err)
+			}
+
+			// The result must not depend on any randomness, so we
+			// determine the build system multiple times
+			for i := 0; i < 10; i++ {
+				buildSystem, err := DetermineBuildSystem(projectDir)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, buildSystem)
+			}
+		})
+	}
+}
+
 func TestDetermineBuildSystem_Maven(t *testing.T) {
 	projectDir, err := os.MkdirTemp(baseTempDir, "project-")
 	require.NoError(t, err)
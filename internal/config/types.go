@@ -8,6 +8,7 @@ const (
 	Kotlin     FuzzTestType = "kotlin"
 	JavaScript FuzzTestType = "js"
 	TypeScript FuzzTestType = "ts"
+	Python     FuzzTestType = "python"
 )
 
 // map of supported test types -> label:value
@@ -17,6 +18,7 @@ var SupportedTestTypes = map[string]string{
 	"Kotlin":     string(Kotlin),
 	"JavaScript": string(JavaScript),
 	"TypeScript": string(TypeScript),
+	"Python":     string(Python),
 }
 
 type GradleBuildLanguage string
@@ -29,5 +31,8 @@ const (
 type Engine string
 
 const (
-	Libfuzzer Engine = "libfuzzer"
+	Libfuzzer   Engine = "libfuzzer"
+	AFLPlusPlus Engine = "aflpp"
 )
+
+var Engines = []Engine{Libfuzzer, AFLPlusPlus}
@@ -16,6 +16,7 @@ import (
 
 	"code-intelligence.com/cifuzz/internal/api"
 	"code-intelligence.com/cifuzz/internal/bundler/archive"
+	"code-intelligence.com/cifuzz/internal/transport"
 	"code-intelligence.com/cifuzz/pkg/log"
 	"code-intelligence.com/cifuzz/pkg/runfiles"
 	"code-intelligence.com/cifuzz/util/fileutil"
@@ -41,6 +42,17 @@ func BuildImageFromBundle(bundlePath string) (string, error) {
 func UploadImage(imageID string, regConf *api.RegistryConfig, imageName string) error {
 	log.Debugf("Start uploading image %s to %s", imageID, regConf.URL)
 
+	// The image is pushed by the Docker daemon, so the proxy and
+	// certificates configured for cifuzz can't be applied here. This
+	// limitation is documented in docs/Configuration.md.
+	if transport.ConfigFromViper().IsCustomized() {
+		log.Warn(`The image is pushed to the registry by the Docker daemon, which doesn't use
+the proxy and certificates configured for cifuzz. If the registry requires them,
+configure the Docker daemon accordingly, see
+https://docs.docker.com/config/daemon/systemd/#httphttps-proxy and
+https://docs.docker.com/engine/security/certificates/`)
+	}
+
 	dockerClient, err := GetDockerClient()
 	if err != nil {
 		return err
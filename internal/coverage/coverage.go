@@ -8,9 +8,12 @@ const FormatJacocoXML = "jacocoxml"
 
 var ValidOutputFormats = map[string][]string{
 	config.BuildSystemCMake:  {FormatHTML, FormatLCOV},
+	config.BuildSystemMeson:  {FormatHTML, FormatLCOV},
 	config.BuildSystemBazel:  {FormatHTML, FormatLCOV},
 	config.BuildSystemOther:  {FormatHTML, FormatLCOV},
 	config.BuildSystemMaven:  {FormatHTML, FormatLCOV, FormatJacocoXML},
 	config.BuildSystemGradle: {FormatHTML, FormatLCOV, FormatJacocoXML},
 	config.BuildSystemNodeJS: {FormatHTML, FormatLCOV},
+	config.BuildSystemGo:     {FormatHTML, FormatLCOV},
+	config.BuildSystemPython: {FormatHTML, FormatLCOV},
 }
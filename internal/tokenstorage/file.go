@@ -0,0 +1,118 @@
+package tokenstorage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+// fileBackend stores the access tokens in plaintext in a JSON file.
+type fileBackend struct {
+	path string
+}
+
+// newDefaultFileBackend returns a fileBackend which uses the
+// access_tokens.json file in the user config directory.
+func newDefaultFileBackend() (*fileBackend, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error determining access tokens file path")
+	}
+	path := filepath.Join(configDir, "cifuzz", "access_tokens.json")
+
+	migrateOldTokens(path)
+
+	return &fileBackend{path: path}, nil
+}
+
+func (b *fileBackend) Load() (map[string]string, error) {
+	bytes, err := os.ReadFile(b.path)
+	if err != nil && os.IsNotExist(err) {
+		// The access tokens file doesn't exist, so we initialize the
+		// access tokens with an empty map
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var tokens map[string]string
+	err = json.Unmarshal(bytes, &tokens)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error parsing access tokens file %s", b.path)
+	}
+	if tokens == nil {
+		tokens = map[string]string{}
+	}
+	return tokens, nil
+}
+
+func (b *fileBackend) Store(target, token string) error {
+	tokens, err := b.Load()
+	if err != nil {
+		return err
+	}
+	tokens[target] = token
+
+	// Ensure that the parent directory exists
+	err = os.MkdirAll(filepath.Dir(b.path), 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Convert the access tokens to JSON
+	bytes, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Write the JSON to file
+	err = os.WriteFile(b.path, bytes, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func (b *fileBackend) Location() string {
+	return b.path
+}
+
+func (b *fileBackend) exists() (bool, error) {
+	return fileutil.Exists(b.path)
+}
+
+// migrateOldTokens migrates the old access tokens file to the new location
+func migrateOldTokens(accessTokensFilePath string) {
+	oldTokensFilePath := os.ExpandEnv("$HOME/.config/cifuzz/access_tokens.json")
+	if oldTokensFilePath == accessTokensFilePath {
+		return
+	}
+
+	exists, err := fileutil.Exists(oldTokensFilePath)
+	if err != nil {
+		log.Errorf(err, "Error checking if old tokens file exists: %v", err.Error())
+		return
+	}
+	if !exists {
+		return
+	}
+
+	// make sure that new tokens file directory exists
+	err = os.MkdirAll(filepath.Dir(accessTokensFilePath), 0o755)
+	if err != nil {
+		log.Errorf(err, "Error creating config directory: %v", err.Error())
+		return
+	}
+
+	log.Infof("Migrating old tokens file to new location: %s", accessTokensFilePath)
+	err = os.Rename(oldTokensFilePath, accessTokensFilePath)
+	if err != nil {
+		log.Errorf(err, "Error migrating old tokens file: %v", err.Error())
+	}
+}
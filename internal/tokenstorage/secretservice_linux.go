@@ -0,0 +1,308 @@
+package tokenstorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/pkg/errors"
+)
+
+// See https://specifications.freedesktop.org/secret-service/latest/
+const (
+	secretServiceName            = "org.freedesktop.secrets"
+	secretServicePath            = dbus.ObjectPath("/org/freedesktop/secrets")
+	secretServiceInterface       = "org.freedesktop.Secret.Service"
+	secretCollectionInterface    = "org.freedesktop.Secret.Collection"
+	secretPromptInterface        = "org.freedesktop.Secret.Prompt"
+	secretItemLabelProperty      = "org.freedesktop.Secret.Item.Label"
+	secretItemAttributesProperty = "org.freedesktop.Secret.Item.Attributes"
+
+	// The path returned by the Secret Service API if no object
+	// exists or no prompt is required
+	noObject = dbus.ObjectPath("/")
+	noPrompt = noObject
+
+	// Attributes used to identify the items created by cifuzz
+	applicationAttribute = "application"
+	applicationName      = "cifuzz"
+	targetAttribute      = "target"
+)
+
+// Calls which don't require user interaction shouldn't take long.
+// Using a timeout avoids hanging if the Secret Service is broken.
+const secretServiceCallTimeout = 10 * time.Second
+
+// Prompts require user interaction, so they get more time. The timeout
+// avoids waiting forever if the prompt is never shown or its
+// notification is dropped.
+const secretServicePromptTimeout = 2 * time.Minute
+
+// secret is the D-Bus representation of a secret, as defined by the
+// Secret Service API.
+type secret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// secretServiceBackend stores the access tokens in the OS keyring via
+// the freedesktop.org Secret Service API (e.g. GNOME Keyring or
+// KWallet) on the D-Bus session bus. Each operation uses its own
+// connection, which is closed afterwards.
+type secretServiceBackend struct{}
+
+// secretServiceConn is a connection to the Secret Service with an open
+// session
+type secretServiceConn struct {
+	conn    *dbus.Conn
+	session dbus.ObjectPath
+}
+
+func newSecretServiceBackend() (Backend, error) {
+	// Check that the Secret Service is available
+	c, err := openSecretService()
+	if err != nil {
+		return nil, err
+	}
+	c.conn.Close()
+	return &secretServiceBackend{}, nil
+}
+
+func (b *secretServiceBackend) Load() (map[string]string, error) {
+	c, err := openSecretService()
+	if err != nil {
+		return nil, err
+	}
+	defer c.conn.Close()
+	return c.load()
+}
+
+func (b *secretServiceBackend) Store(target, token string) error {
+	c, err := openSecretService()
+	if err != nil {
+		return err
+	}
+	defer c.conn.Close()
+	return c.store(target, token)
+}
+
+func (b *secretServiceBackend) Location() string {
+	return "the OS keyring (Secret Service)"
+}
+
+// openSecretService connects to the session bus and opens a Secret
+// Service session. The caller must close the connection.
+func openSecretService() (*secretServiceConn, error) {
+	// Don't use dbus.SessionBus() here, because it tries to launch a
+	// new session bus via dbus-launch if none is running, which we
+	// don't want (e.g. in CI environments).
+	conn, err := dbus.SessionBusPrivateNoAutoStartup()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = conn.Auth(nil)
+	if err != nil {
+		conn.Close()
+		return nil, errors.WithStack(err)
+	}
+	err = conn.Hello()
+	if err != nil {
+		conn.Close()
+		return nil, errors.WithStack(err)
+	}
+
+	b := &secretServiceConn{conn: conn}
+
+	// The secrets are transferred unencrypted over the session bus,
+	// which is only accessible by the current user.
+	var output dbus.Variant
+	err = b.call(b.service(), secretServiceInterface+".OpenSession", "plain", dbus.MakeVariant("")).
+		Store(&output, &b.session)
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "Failed to open Secret Service session")
+	}
+
+	return b, nil
+}
+
+func (b *secretServiceConn) load() (map[string]string, error) {
+	var unlocked, locked []dbus.ObjectPath
+	attributes := map[string]string{applicationAttribute: applicationName}
+	err := b.call(b.service(), secretServiceInterface+".SearchItems", attributes).Store(&unlocked, &locked)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to search access tokens in keyring")
+	}
+
+	if len(locked) > 0 {
+		newlyUnlocked, err := b.unlock(locked)
+		if err != nil {
+			return nil, err
+		}
+		unlocked = append(unlocked, newlyUnlocked...)
+	}
+
+	tokens := map[string]string{}
+	if len(unlocked) == 0 {
+		return tokens, nil
+	}
+
+	var secrets map[dbus.ObjectPath]secret
+	err = b.call(b.service(), secretServiceInterface+".GetSecrets", unlocked, b.session).Store(&secrets)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read access tokens from keyring")
+	}
+
+	for item, s := range secrets {
+		attributesVariant, err := b.conn.Object(secretServiceName, item).GetProperty(secretItemAttributesProperty)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to read access token attributes from keyring")
+		}
+		itemAttributes, ok := attributesVariant.Value().(map[string]string)
+		if !ok {
+			return nil, errors.Errorf("Unexpected type of keyring item attributes: %s", attributesVariant.Signature())
+		}
+		tokens[itemAttributes[targetAttribute]] = string(s.Value)
+	}
+
+	return tokens, nil
+}
+
+func (b *secretServiceConn) store(target, token string) error {
+	var collectionPath dbus.ObjectPath
+	err := b.call(b.service(), secretServiceInterface+".ReadAlias", "default").Store(&collectionPath)
+	if err != nil {
+		return errors.Wrap(err, "Failed to find the default keyring")
+	}
+	if collectionPath == noObject {
+		return errors.New("No default keyring found")
+	}
+	collection := b.conn.Object(secretServiceName, collectionPath)
+
+	// Make sure that the default collection is unlocked, which might
+	// require user interaction
+	_, err = b.unlock([]dbus.ObjectPath{collectionPath})
+	if err != nil {
+		return err
+	}
+
+	properties := map[string]dbus.Variant{
+		secretItemLabelProperty: dbus.MakeVariant("cifuzz access token for " + target),
+		secretItemAttributesProperty: dbus.MakeVariant(map[string]string{
+			applicationAttribute: applicationName,
+			targetAttribute:      target,
+		}),
+	}
+	s := secret{
+		Session:     b.session,
+		Value:       []byte(token),
+		ContentType: "text/plain",
+	}
+
+	var item, prompt dbus.ObjectPath
+	// Replace an existing item with the same attributes
+	err = b.call(collection, secretCollectionInterface+".CreateItem", properties, s, true).Store(&item, &prompt)
+	if err != nil {
+		return errors.Wrap(err, "Failed to store access token in keyring")
+	}
+	if prompt != noPrompt {
+		_, err = b.prompt(prompt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unlock unlocks the given objects and returns the unlocked objects.
+// If unlocking requires user interaction, the user is prompted by the
+// Secret Service.
+func (b *secretServiceConn) unlock(objects []dbus.ObjectPath) ([]dbus.ObjectPath, error) {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	err := b.call(b.service(), secretServiceInterface+".Unlock", objects).Store(&unlocked, &prompt)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to unlock keyring")
+	}
+	if prompt == noPrompt {
+		return unlocked, nil
+	}
+
+	result, err := b.prompt(prompt)
+	if err != nil {
+		return nil, err
+	}
+	promptUnlocked, ok := result.Value().([]dbus.ObjectPath)
+	if !ok {
+		return nil, errors.Errorf("Unexpected result of keyring unlock prompt: %s", result.Signature())
+	}
+	return append(unlocked, promptUnlocked...), nil
+}
+
+// prompt shows the given prompt and waits until the user completed or
+// dismissed it. The prompt is dismissed if the user doesn't respond
+// within secretServicePromptTimeout.
+func (b *secretServiceConn) prompt(prompt dbus.ObjectPath) (dbus.Variant, error) {
+	matchOptions := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(prompt),
+		dbus.WithMatchInterface(secretPromptInterface),
+		dbus.WithMatchMember("Completed"),
+	}
+	err := b.conn.AddMatchSignal(matchOptions...)
+	if err != nil {
+		return dbus.Variant{}, errors.WithStack(err)
+	}
+	defer func() {
+		_ = b.conn.RemoveMatchSignal(matchOptions...)
+	}()
+	signals := make(chan *dbus.Signal, 1)
+	b.conn.Signal(signals)
+	defer b.conn.RemoveSignal(signals)
+
+	promptObject := b.conn.Object(secretServiceName, prompt)
+	err = b.call(promptObject, secretPromptInterface+".Prompt", "").Err
+	if err != nil {
+		return dbus.Variant{}, errors.Wrap(err, "Failed to prompt for keyring access")
+	}
+
+	timeout := time.After(secretServicePromptTimeout)
+	for {
+		select {
+		case signal, ok := <-signals:
+			if !ok {
+				return dbus.Variant{}, errors.New("Connection to the Secret Service was closed")
+			}
+			if signal.Path != prompt || signal.Name != secretPromptInterface+".Completed" {
+				continue
+			}
+			var dismissed bool
+			var result dbus.Variant
+			err = dbus.Store(signal.Body, &dismissed, &result)
+			if err != nil {
+				return dbus.Variant{}, errors.WithStack(err)
+			}
+			if dismissed {
+				return dbus.Variant{}, errors.New("Keyring access prompt was dismissed")
+			}
+			return result, nil
+		case <-timeout:
+			// Close the prompt, if it's still shown
+			_ = b.call(promptObject, secretPromptInterface+".Dismiss").Err
+			return dbus.Variant{}, errors.Errorf("Timed out after %s waiting for the keyring access prompt",
+				secretServicePromptTimeout)
+		}
+	}
+}
+
+func (b *secretServiceConn) service() dbus.BusObject {
+	return b.conn.Object(secretServiceName, secretServicePath)
+}
+
+func (b *secretServiceConn) call(obj dbus.BusObject, method string, args ...any) *dbus.Call {
+	ctx, cancel := context.WithTimeout(context.Background(), secretServiceCallTimeout)
+	defer cancel()
+	return obj.CallWithContext(ctx, method, 0, args...)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package tokenstorage
+
+import (
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+func newSecretServiceBackend() (Backend, error) {
+	return nil, errors.Errorf("storing access tokens in the OS keyring is not supported on %s", runtime.GOOS)
+}
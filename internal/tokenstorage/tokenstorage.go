@@ -1,83 +1,129 @@
 package tokenstorage
 
 import (
-	"encoding/json"
 	"os"
-	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 
 	"code-intelligence.com/cifuzz/pkg/log"
-	"code-intelligence.com/cifuzz/util/fileutil"
 )
 
+// StorageEnv is the environment variable which can be used to select
+// the backend used to store access tokens.
+const StorageEnv = "CIFUZZ_TOKEN_STORAGE"
+
+const (
+	// StorageAuto uses the OS keyring if it's available and falls back
+	// to the access tokens file otherwise.
+	StorageAuto = "auto"
+	// StorageKeyring uses the OS keyring and fails if it's not
+	// available.
+	StorageKeyring = "keyring"
+	// StorageFile uses the access tokens file in the user config
+	// directory.
+	StorageFile = "file"
+)
+
+// Backend stores access tokens by target (i.e. server URL).
+type Backend interface {
+	// Load returns all access tokens stored in the backend.
+	Load() (map[string]string, error)
+	// Store stores the access token for the given target.
+	Store(target, token string) error
+	// Location returns a human-readable description of where the
+	// access tokens are stored.
+	Location() string
+}
+
 var accessTokens map[string]string
 
-var accessTokensFilePath string
+var backend Backend
+var initOnce sync.Once
 var readErr error
-var filePathErr error
+var backendErr error
+
+// load selects the backend and reads the access tokens from it. It's
+// called lazily, because some backends (like the keyring) require
+// connecting to other services, which we only want to do when access
+// tokens are actually needed.
+func load() {
+	initOnce.Do(func() {
+		backend, backendErr = newBackend(os.Getenv(StorageEnv))
+		if backendErr != nil {
+			readErr = backendErr
+			log.Debug(backendErr.Error())
+			return
+		}
+
+		backend, accessTokens, readErr = loadAccessTokens(backend, os.Getenv(StorageEnv))
+		if readErr != nil {
+			log.Errorf(readErr, "Error reading access tokens from %s: %v", backend.Location(), readErr.Error())
+		}
+	})
+}
 
-func init() {
-	configDir, err := os.UserConfigDir()
-	if err != nil {
-		// If we can't get the user config directory, we can't read the access
-		// tokens file. We only log the error on debug level here because it's
-		// not clear yet if the access tokens are needed. We return the error
-		// in the Get/Set functions.
-		filePathErr = errors.Wrap(err, "Error determining access tokens file path")
-		readErr = filePathErr
-		log.Debug(filePathErr.Error())
-		return
-	}
-	accessTokensFilePath = filepath.Join(configDir, "cifuzz", "access_tokens.json")
-
-	migrateOldTokens()
-
-	bytes, err := os.ReadFile(accessTokensFilePath)
-	if err != nil && os.IsNotExist(err) {
-		// The access tokens file doesn't exist, so we initialize the
-		// access tokens with an empty map
-		accessTokens = map[string]string{}
-		return
+// loadAccessTokens reads the access tokens from the backend and returns
+// the backend which is used from now on. In auto mode, the access
+// tokens file is used instead of the keyring if the keyring can't be
+// read, like when the keyring is not available at all.
+func loadAccessTokens(b Backend, storage string) (Backend, map[string]string, error) {
+	tokens, err := b.Load()
+	if err == nil || (storage != "" && storage != StorageAuto) {
+		return b, tokens, err
 	}
-	if err != nil {
-		readErr = errors.WithStack(err)
-		log.Errorf(err, "Error reading access tokens file: %v", err.Error())
-		return
+	if _, isFile := b.(*fileBackend); isFile {
+		return b, tokens, err
 	}
-	err = json.Unmarshal(bytes, &accessTokens)
-	if err != nil {
-		readErr = errors.WithStack(err)
-		log.Errorf(err, "Error parsing access tokens file %s: %v", accessTokensFilePath, err.Error())
-		return
+
+	file, fileErr := newDefaultFileBackend()
+	if fileErr != nil {
+		return b, tokens, err
 	}
+	log.Debugf("Error reading access tokens from %s, storing access tokens in a file: %v", b.Location(), err)
+	tokens, err = file.Load()
+	return file, tokens, err
 }
 
-func Set(target, token string) error {
-	if filePathErr != nil {
-		return errors.WithMessage(filePathErr, "Can't set access token")
+func newBackend(storage string) (Backend, error) {
+	switch storage {
+	case "", StorageAuto:
+		keyring, err := newSecretServiceBackend()
+		if err != nil {
+			log.Debugf("OS keyring is not available, storing access tokens in a file: %v", err)
+			return newDefaultFileBackend()
+		}
+		return withMigratedTokens(keyring), nil
+	case StorageKeyring:
+		keyring, err := newSecretServiceBackend()
+		if err != nil {
+			return nil, errors.WithMessagef(err, "%s=%s is set but the OS keyring is not available", StorageEnv, storage)
+		}
+		return withMigratedTokens(keyring), nil
+	case StorageFile:
+		return newDefaultFileBackend()
+	default:
+		return nil, errors.Errorf("Invalid value for %s: %q (valid values are %q, %q and %q)",
+			StorageEnv, storage, StorageAuto, StorageKeyring, StorageFile)
 	}
+}
 
-	// Ensure that the parent directory exists
-	err := os.MkdirAll(filepath.Dir(accessTokensFilePath), 0o755)
-	if err != nil {
-		return errors.WithStack(err)
+func Set(target, token string) error {
+	load()
+	if backendErr != nil {
+		return errors.WithMessage(backendErr, "Can't set access token")
 	}
 
-	accessTokens[target] = token
-
-	// Convert the access tokens to JSON
-	bytes, err := json.MarshalIndent(accessTokens, "", "  ")
+	err := backend.Store(target, token)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
 
-	// Write the JSON to file
-	err = os.WriteFile(accessTokensFilePath, bytes, 0o600)
-	if err != nil {
-		return errors.WithStack(err)
+	if accessTokens == nil {
+		accessTokens = map[string]string{}
 	}
+	accessTokens[target] = token
 	return nil
 }
 
@@ -85,6 +131,7 @@ func Set(target, token string) error {
 // If the given target doesn't exist, try to add or remove a trailing slash
 // and return the access token for that target
 func Get(target string) (string, error) {
+	load()
 	if readErr != nil {
 		return "", errors.WithMessage(readErr, "Can't get access token")
 	}
@@ -101,36 +148,61 @@ func Get(target string) (string, error) {
 	return "", nil
 }
 
-func GetTokenFilePath() (string, error) {
-	return accessTokensFilePath, filePathErr
+// Location returns a human-readable description of where the access
+// tokens are stored
+func Location() (string, error) {
+	load()
+	if backendErr != nil {
+		return "", backendErr
+	}
+	return backend.Location(), nil
 }
 
-// migrateOldTokens migrates the old access tokens file to the new location
-func migrateOldTokens() {
-	oldTokensFilePath := os.ExpandEnv("$HOME/.config/cifuzz/access_tokens.json")
-	if oldTokensFilePath == accessTokensFilePath {
-		return
+// withMigratedTokens moves the access tokens from the access tokens
+// file into the given backend. If that fails, the access tokens file
+// is kept and used instead.
+func withMigratedTokens(b Backend) Backend {
+	fileBackend, err := newDefaultFileBackend()
+	if err != nil {
+		// There is no access tokens file we could migrate
+		return b
+	}
+
+	err = migrateTokens(fileBackend, b)
+	if err != nil {
+		log.Errorf(err, "Error migrating access tokens to %s: %v", b.Location(), err.Error())
+		return fileBackend
 	}
+	return b
+}
 
-	exists, err := fileutil.Exists(oldTokensFilePath)
+// migrateTokens stores all access tokens from the access tokens file in
+// the given backend and removes the file afterwards.
+func migrateTokens(from *fileBackend, to Backend) error {
+	exists, err := from.exists()
 	if err != nil {
-		log.Errorf(err, "Error checking if old tokens file exists: %v", err.Error())
-		return
+		return err
 	}
 	if !exists {
-		return
+		return nil
 	}
 
-	// make sure that new tokens file directory exists
-	err = os.MkdirAll(filepath.Dir(accessTokensFilePath), 0o755)
+	tokens, err := from.Load()
 	if err != nil {
-		log.Errorf(err, "Error creating config directory: %v", err.Error())
-		return
+		return err
 	}
 
-	log.Infof("Migrating old tokens file to new location: %s", accessTokensFilePath)
-	err = os.Rename(oldTokensFilePath, accessTokensFilePath)
+	log.Infof("Migrating access tokens from %s to %s", from.Location(), to.Location())
+	for target, token := range tokens {
+		err = to.Store(target, token)
+		if err != nil {
+			return errors.WithMessagef(err, "Failed to store access token for %s", target)
+		}
+	}
+
+	err = os.Remove(from.path)
 	if err != nil {
-		log.Errorf(err, "Error migrating old tokens file: %v", err.Error())
+		return errors.WithStack(err)
 	}
+	return nil
 }
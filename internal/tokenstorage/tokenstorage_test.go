@@ -2,7 +2,9 @@ package tokenstorage
 
 import (
 	"errors"
+	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -10,12 +12,42 @@ import (
 	"code-intelligence.com/cifuzz/internal/testutil"
 )
 
+// useBackend makes the package use the given backend instead of the
+// one selected via CIFUZZ_TOKEN_STORAGE.
+func useBackend(b Backend) {
+	// Mark the backend as initialized
+	initOnce.Do(func() {})
+	backend = b
+	backendErr = nil
+	accessTokens, readErr = b.Load()
+}
+
+// mapBackend stores access tokens in memory
+type mapBackend struct {
+	tokens map[string]string
+}
+
+func (b *mapBackend) Load() (map[string]string, error) {
+	tokens := map[string]string{}
+	for target, token := range b.tokens {
+		tokens[target] = token
+	}
+	return tokens, nil
+}
+
+func (b *mapBackend) Store(target, token string) error {
+	b.tokens[target] = token
+	return nil
+}
+
+func (b *mapBackend) Location() string {
+	return "memory"
+}
+
 func TestGetAndSet(t *testing.T) {
 	tempDir := testutil.MkdirTemp(t, "", "access-tokens-test-")
-	accessTokensFilePath = filepath.Join(tempDir, "access_tokens.json")
-	accessTokens = map[string]string{}
-	readErr = nil
-	filePathErr = nil
+	accessTokensFilePath := filepath.Join(tempDir, "access_tokens.json")
+	useBackend(&fileBackend{path: accessTokensFilePath})
 
 	token, err := Get("http://localhost:8000")
 	require.NoError(t, err)
@@ -35,24 +67,28 @@ func TestGetAndSet(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "token2", token)
 
+	// The token should have been written to the file
+	tokens, err := (&fileBackend{path: accessTokensFilePath}).Load()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"http://localhost:8000": "token2"}, tokens)
+
 	// Test readErr being returned
 	readErr = errors.New("read error")
 	_, err = Get("app.example.com")
 	require.EqualError(t, errors.Unwrap(err), "read error")
 
-	// Test filePathErr being returned
-	filePathErr = errors.New("file path error")
+	// Test backendErr being returned
+	backendErr = errors.New("file path error")
 	err = Set("app.example.com", "123")
 	require.EqualError(t, errors.Unwrap(err), "file path error")
 }
 
 func TestGet(t *testing.T) {
-	accessTokens = map[string]string{
+	useBackend(&mapBackend{tokens: map[string]string{
 		"app.example.com":                    "123",
 		"app.code-intelligence.com":          "456",
 		"app.staging.code-intelligence.com/": "789",
-	}
-	readErr = nil
+	}})
 
 	// Test exact match
 	token, err := Get("app.example.com")
@@ -74,3 +110,65 @@ func TestGet(t *testing.T) {
 	require.NoError(t, err)
 	require.Empty(t, token)
 }
+
+func TestMigrateTokens(t *testing.T) {
+	tempDir := testutil.MkdirTemp(t, "", "access-tokens-test-")
+	from := &fileBackend{path: filepath.Join(tempDir, "access_tokens.json")}
+	to := &mapBackend{tokens: map[string]string{"app.example.com": "123"}}
+
+	// Nothing to migrate if the file doesn't exist
+	err := migrateTokens(from, to)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"app.example.com": "123"}, to.tokens)
+
+	err = from.Store("app.code-intelligence.com", "456")
+	require.NoError(t, err)
+
+	err = migrateTokens(from, to)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"app.example.com":           "123",
+		"app.code-intelligence.com": "456",
+	}, to.tokens)
+
+	// The plaintext file should be removed after the migration
+	_, err = os.Stat(from.path)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestInvalidStorage(t *testing.T) {
+	_, err := newBackend("foo")
+	require.Error(t, err)
+}
+
+// failingBackend fails to load the access tokens
+type failingBackend struct {
+	mapBackend
+}
+
+func (b *failingBackend) Load() (map[string]string, error) {
+	return nil, errors.New("keyring is locked")
+}
+
+func TestLoadAccessTokens_FallbackToFile(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("os.UserConfigDir only uses XDG_CONFIG_HOME on Linux")
+	}
+	configDir := testutil.MkdirTemp(t, "", "config-dir-")
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	fileBackend, err := newDefaultFileBackend()
+	require.NoError(t, err)
+	err = fileBackend.Store("app.code-intelligence.com", "123")
+	require.NoError(t, err)
+
+	// In auto mode, the access tokens file is used if the keyring
+	// can't be read
+	b, tokens, err := loadAccessTokens(&failingBackend{}, StorageAuto)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"app.code-intelligence.com": "123"}, tokens)
+	require.Equal(t, fileBackend.Location(), b.Location())
+
+	// If the keyring was explicitly selected, the error is returned
+	_, _, err = loadAccessTokens(&failingBackend{}, StorageKeyring)
+	require.Error(t, err)
+}
@@ -0,0 +1,160 @@
+// Package transport builds the HTTP transports used by cifuzz to
+// access CI Sense and other remote services, taking the configured
+// proxy, CA certificates and client certificates into account.
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"golang.org/x/net/proxy"
+)
+
+// The viper keys of the transport settings, which can be set via
+// cifuzz.yaml, command-line flags or environment variables.
+const (
+	ProxyKey      = "proxy"
+	CACertKey     = "ca-cert"
+	ClientCertKey = "client-cert"
+	ClientKeyKey  = "client-key"
+)
+
+var envVars = map[string]string{
+	ProxyKey:      "CIFUZZ_PROXY",
+	CACertKey:     "CIFUZZ_CA_CERT",
+	ClientCertKey: "CIFUZZ_CLIENT_CERT",
+	ClientKeyKey:  "CIFUZZ_CLIENT_KEY",
+}
+
+type Config struct {
+	// Proxy is the URL of the proxy used for all requests. Supported
+	// schemes are http, https and socks5. If empty, the ALL_PROXY and
+	// NO_PROXY environment variables are respected.
+	Proxy string
+	// CACert is the path of a PEM file with CA certificates which are
+	// trusted in addition to the system's CA certificates.
+	CACert string
+	// ClientCert and ClientKey are the paths of the PEM encoded client
+	// certificate and key used for mutual TLS authentication.
+	ClientCert string
+	ClientKey  string
+}
+
+// ConfigFromViper returns the transport settings configured via
+// viper.
+func ConfigFromViper() *Config {
+	for key, env := range envVars {
+		// BindEnv only returns an error if no key is specified
+		_ = viper.BindEnv(key, env)
+	}
+	return &Config{
+		Proxy:      viper.GetString(ProxyKey),
+		CACert:     viper.GetString(CACertKey),
+		ClientCert: viper.GetString(ClientCertKey),
+		ClientKey:  viper.GetString(ClientKeyKey),
+	}
+}
+
+// IsCustomized returns true if any of the settings is set.
+func (c *Config) IsCustomized() bool {
+	return *c != Config{}
+}
+
+// Default returns a transport using the settings configured via viper.
+func Default() (*http.Transport, error) {
+	return New(ConfigFromViper())
+}
+
+func New(conf *Config) (*http.Transport, error) {
+	t := &http.Transport{}
+
+	if conf.Proxy == "" {
+		// it is not possible to use the default Proxy Environment because
+		// of https://github.com/golang/go/issues/24135
+		t.DialContext = dialContext(proxy.FromEnvironment())
+	} else {
+		proxyURL, err := url.Parse(conf.Proxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid proxy URL %q", conf.Proxy)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https":
+			t.Proxy = http.ProxyURL(proxyURL)
+		case "socks5", "socks5h":
+			dialer, err := proxy.FromURL(proxyURL, proxy.Direct)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Invalid proxy URL %q", conf.Proxy)
+			}
+			t.DialContext = dialContext(dialer)
+		default:
+			return nil, errors.Errorf("Unsupported proxy scheme %q, supported are http, https and socks5", proxyURL.Scheme)
+		}
+	}
+
+	tlsConfig, err := tlsConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+	t.TLSClientConfig = tlsConfig
+
+	return t, nil
+}
+
+func tlsConfig(conf *Config) (*tls.Config, error) {
+	if conf.CACert == "" && conf.ClientCert == "" && conf.ClientKey == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if conf.CACert != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			// The system cert pool is not available on all platforms,
+			// in which case we only trust the specified CA certificates
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(conf.CACert)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to read CA certificates")
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("No valid PEM encoded certificates found in %s", conf.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if conf.ClientCert != "" || conf.ClientKey != "" {
+		if conf.ClientCert == "" || conf.ClientKey == "" {
+			return nil, errors.New("Both a client certificate and a client key must be specified")
+		}
+		cert, err := tls.LoadX509KeyPair(conf.ClientCert, conf.ClientKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to load client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func dialContext(dialer proxy.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if contextDialer, ok := dialer.(proxy.ContextDialer); ok {
+			// This error is being returned to the http package and we
+			// don't know if it could have side effects with an added stack
+			// trace
+			// nolint: wrapcheck
+			return contextDialer.DialContext(ctx, network, address)
+		}
+		// nolint: wrapcheck
+		return dialer.Dial(network, address)
+	}
+}
@@ -0,0 +1,112 @@
+package transport
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/testutil"
+)
+
+func TestProxy(t *testing.T) {
+	tr, err := New(&Config{Proxy: "http://proxy.example.com:3128"})
+	require.NoError(t, err)
+	proxyURL, err := tr.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "app.code-intelligence.com"}})
+	require.NoError(t, err)
+	require.Equal(t, "proxy.example.com:3128", proxyURL.Host)
+
+	tr, err = New(&Config{Proxy: "socks5://proxy.example.com:1080"})
+	require.NoError(t, err)
+	require.Nil(t, tr.Proxy)
+	require.NotNil(t, tr.DialContext)
+
+	_, err = New(&Config{Proxy: "ftp://proxy.example.com"})
+	require.Error(t, err)
+}
+
+func TestCertificates(t *testing.T) {
+	dir := testutil.MkdirTemp(t, "", "transport-test-")
+
+	// Start a TLS server which requires a client certificate
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert, MinVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	caCertPath := filepath.Join(dir, "ca.pem")
+	writePEM(t, caCertPath, "CERTIFICATE", server.Certificate().Raw)
+	clientCertPath, clientKeyPath := writeClientCertificate(t, dir)
+
+	// Without the CA certificate, the server certificate is not trusted
+	err := get(t, &Config{ClientCert: clientCertPath, ClientKey: clientKeyPath}, server.URL)
+	require.Error(t, err)
+
+	// Without the client certificate, the server rejects the connection
+	err = get(t, &Config{CACert: caCertPath}, server.URL)
+	require.Error(t, err)
+
+	err = get(t, &Config{CACert: caCertPath, ClientCert: clientCertPath, ClientKey: clientKeyPath}, server.URL)
+	require.NoError(t, err)
+
+	// A client certificate without key is invalid
+	_, err = New(&Config{ClientCert: clientCertPath})
+	require.Error(t, err)
+
+	// A CA file without certificates is invalid
+	err = os.WriteFile(filepath.Join(dir, "empty.pem"), nil, 0o644)
+	require.NoError(t, err)
+	_, err = New(&Config{CACert: filepath.Join(dir, "empty.pem")})
+	require.Error(t, err)
+}
+
+func get(t *testing.T, conf *Config, url string) error {
+	tr, err := New(conf)
+	require.NoError(t, err)
+	client := &http.Client{Transport: tr, Timeout: 10 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func writeClientCertificate(t *testing.T, dir string) (certPath string, keyPath string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	certPath = filepath.Join(dir, "client.pem")
+	keyPath = filepath.Join(dir, "client-key.pem")
+	writePEM(t, certPath, "CERTIFICATE", cert)
+	writePEM(t, keyPath, "PRIVATE KEY", keyBytes)
+	return certPath, keyPath
+}
+
+func writePEM(t *testing.T, path string, blockType string, bytes []byte) {
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: bytes}), 0o600)
+	require.NoError(t, err)
+}
@@ -2,8 +2,12 @@ package binary
 
 import (
 	"debug/elf"
+	"debug/macho"
+	"io"
 	"runtime"
 
+	"github.com/pkg/errors"
+
 	"code-intelligence.com/cifuzz/pkg/log"
 )
 
@@ -44,3 +48,72 @@ func SupportsLlvmProfileContinuousMode(binary string) bool {
 	// https://github.com/llvm/llvm-project/blob/846709b287abe541fcad42e5a54d37a41dae3f67/compiler-rt/lib/profile/InstrProfilingFile.c#L574
 	return biasVarAddress != 0 && biasDefaultVarAddress != 0 && biasVarAddress != biasDefaultVarAddress
 }
+
+// The entry point of libFuzzer fuzz tests
+const fuzzTestEntryPoint = "LLVMFuzzerTestOneInput"
+
+// IsFuzzTest returns true if the ELF or Mach-O binary at the specified
+// path defines the LLVMFuzzerTestOneInput function, i.e. if it's a
+// libFuzzer fuzz test. Files which are not ELF or Mach-O binaries are
+// not fuzz tests.
+func IsFuzzTest(path string) (bool, error) {
+	elfFile, err := elf.Open(path)
+	if err == nil {
+		defer elfFile.Close()
+		return elfDefinesSymbol(elfFile, fuzzTestEntryPoint)
+	}
+	if !isFormatError(err) {
+		return false, errors.WithStack(err)
+	}
+
+	machoFile, err := macho.Open(path)
+	if isFormatError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	defer machoFile.Close()
+	if machoFile.Symtab == nil {
+		return false, nil
+	}
+	for _, symbol := range machoFile.Symtab.Syms {
+		// Symbols of Mach-O binaries are prefixed with an underscore.
+		// Undefined symbols don't belong to a section.
+		if symbol.Name == "_"+fuzzTestEntryPoint && symbol.Sect != 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func elfDefinesSymbol(file *elf.File, name string) (bool, error) {
+	// Stripped binaries only have a dynamic symbol table, which
+	// contains the symbol if the fuzz test was linked with
+	// -rdynamic or the symbol is exported otherwise
+	for _, readSymbols := range []func() ([]elf.Symbol, error){file.Symbols, file.DynamicSymbols} {
+		symbols, err := readSymbols()
+		if errors.Is(err, elf.ErrNoSymbols) {
+			continue
+		}
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		for _, symbol := range symbols {
+			if symbol.Name == name && symbol.Section != elf.SHN_UNDEF {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// isFormatError returns true if the error returned when opening a file
+// as an ELF or Mach-O binary means that the file has a different format
+func isFormatError(err error) bool {
+	var elfFormatErr *elf.FormatError
+	var machoFormatErr *macho.FormatError
+	// Files which are shorter than the header can't be read completely
+	return errors.As(err, &elfFormatErr) || errors.As(err, &machoFormatErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,59 @@
+package binary
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compile(t *testing.T, dir string, name string, source string) string {
+	t.Helper()
+	sourceFile := filepath.Join(dir, name+".c")
+	err := os.WriteFile(sourceFile, []byte(source), 0o644)
+	require.NoError(t, err)
+	executable := filepath.Join(dir, name)
+	out, err := exec.Command("cc", "-o", executable, sourceFile).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return executable
+}
+
+func TestIsFuzzTest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Only ELF and Mach-O binaries are supported")
+	}
+	if _, err := exec.LookPath("cc"); err != nil {
+		t.Skip("No C compiler found")
+	}
+	dir := t.TempDir()
+
+	// A fuzz test which is not linked with libFuzzer, which doesn't
+	// make a difference for the check
+	fuzzTest := compile(t, dir, "fuzz_test", `
+#include <stddef.h>
+#include <stdint.h>
+int LLVMFuzzerTestOneInput(const uint8_t *data, size_t size) { return 0; }
+int main() { return LLVMFuzzerTestOneInput(NULL, 0); }
+`)
+	isFuzzTest, err := IsFuzzTest(fuzzTest)
+	require.NoError(t, err)
+	assert.True(t, isFuzzTest)
+
+	program := compile(t, dir, "program", "int main() { return 0; }\n")
+	isFuzzTest, err = IsFuzzTest(program)
+	require.NoError(t, err)
+	assert.False(t, isFuzzTest)
+
+	for _, content := range []string{"#!/bin/sh\necho foo\n", ""} {
+		script := filepath.Join(dir, "script")
+		err = os.WriteFile(script, []byte(content), 0o755)
+		require.NoError(t, err)
+		isFuzzTest, err = IsFuzzTest(script)
+		require.NoError(t, err)
+		assert.False(t, isFuzzTest)
+	}
+}
@@ -20,6 +20,18 @@ type Dependencies map[Key]*Dependency
 
 // List of all known dependencies
 var deps = Dependencies{
+	AFLFuzz: {
+		Key: AFLFuzz,
+		// AFL++ versions (e.g. "4.08c") are not semantic versions, so
+		// we only check that afl-fuzz is installed
+		MinVersion: *semver.MustParse("0.0.0"),
+		GetVersion: func(dep *Dependency, projectDir string) (*semver.Version, error) {
+			return semver.MustParse("0.0.0"), nil
+		},
+		Installed: func(dep *Dependency, projectDir string) bool {
+			return dep.checkFinder(dep.finder.AFLFuzzPath)
+		},
+	},
 	Bazel: {
 		Key:        Bazel,
 		MinVersion: getMinVersionBazel(),
@@ -66,6 +78,15 @@ var deps = Dependencies{
 			return dep.checkFinder(dep.finder.CMakePath)
 		},
 	},
+	Meson: {
+		Key: Meson,
+		// "meson compile" was added in Meson 0.54
+		MinVersion: *semver.MustParse("0.54.0"),
+		GetVersion: mesonVersion,
+		Installed: func(dep *Dependency, projectDir string) bool {
+			return dep.checkFinder(dep.finder.MesonPath)
+		},
+	},
 	GenHTML: {
 		Key:        GenHTML,
 		MinVersion: *semver.MustParse("0.0.0"),
@@ -221,6 +242,57 @@ var deps = Dependencies{
 			return dep.checkFinder(dep.finder.NodePath)
 		},
 	},
+	Go: {
+		Key: Go,
+		// Native fuzzing was added in Go 1.18
+		MinVersion: *semver.MustParse("1.18"),
+		GetVersion: goVersion,
+		Installed: func(dep *Dependency, projectDir string) bool {
+			return dep.checkFinder(dep.finder.GoPath)
+		},
+	},
+	Cargo: {
+		Key:        Cargo,
+		MinVersion: *semver.MustParse("1.58"),
+		GetVersion: cargoVersion,
+		Installed: func(dep *Dependency, projectDir string) bool {
+			return dep.checkFinder(dep.finder.CargoPath)
+		},
+	},
+	CargoFuzz: {
+		Key:        CargoFuzz,
+		MinVersion: *semver.MustParse("0.11.0"),
+		GetVersion: cargoFuzzVersion,
+		Installed: func(dep *Dependency, projectDir string) bool {
+			return dep.checkFinder(dep.finder.CargoFuzzPath)
+		},
+	},
+	Python: {
+		Key: Python,
+		// Atheris supports Python 3.8 and newer
+		MinVersion: *semver.MustParse("3.8"),
+		GetVersion: pythonVersion,
+		Installed: func(dep *Dependency, projectDir string) bool {
+			return dep.checkFinder(dep.finder.PythonPath)
+		},
+	},
+	Atheris: {
+		Key:        Atheris,
+		MinVersion: *semver.MustParse("2.0.0"),
+		GetVersion: atherisVersion,
+		Installed: func(dep *Dependency, projectDir string) bool {
+			return pythonModuleInstalled(dep, "atheris")
+		},
+	},
+	CoveragePy: {
+		Key: CoveragePy,
+		// LCOV reports were added in coverage.py 6.3
+		MinVersion: *semver.MustParse("6.3"),
+		GetVersion: coveragePyVersion,
+		Installed: func(dep *Dependency, projectDir string) bool {
+			return pythonModuleInstalled(dep, "coverage")
+		},
+	},
 	Perl: {
 		Key:        Perl,
 		MinVersion: *semver.MustParse("0.0.0"),
@@ -2,6 +2,7 @@ package dependencies
 
 import (
 	"fmt"
+	"os/exec"
 
 	"github.com/Masterminds/semver"
 	"github.com/pkg/errors"
@@ -18,9 +19,11 @@ For installation instruction see:
 type Key string
 
 const (
+	AFLFuzz        Key = "afl-fuzz"
 	Bazel          Key = "bazel"
 	Clang          Key = "clang"
 	CMake          Key = "cmake"
+	Meson          Key = "meson"
 	LLVMCov        Key = "llvm-cov"
 	LLVMSymbolizer Key = "llvm-symbolizer"
 	LLVMProfData   Key = "llvm-profdata"
@@ -36,6 +39,15 @@ const (
 
 	Node Key = "node"
 
+	Go Key = "go"
+
+	Cargo     Key = "cargo"
+	CargoFuzz Key = "cargo-fuzz"
+
+	Python     Key = "python"
+	Atheris    Key = "atheris"
+	CoveragePy Key = "coverage.py"
+
 	VisualStudio Key = "Visual Studio"
 
 	MessageVersion = "cifuzz requires %s %s or higher, found %s"
@@ -80,6 +92,23 @@ func (dep *Dependency) checkFinder(finderFunc func() (string, error)) bool {
 	return true
 }
 
+// pythonModuleInstalled checks if the given module can be imported by
+// the Python interpreter in the PATH
+func pythonModuleInstalled(dep *Dependency, module string) bool {
+	path, err := dep.finder.PythonPath()
+	if err != nil {
+		log.Debug(err)
+		return false
+	}
+	cmd := exec.Command(path, "-c", "import "+module)
+	err = cmd.Run()
+	if err != nil {
+		log.Debugf("Failed to import Python module %s: %v", module, err)
+		return false
+	}
+	return true
+}
+
 // Check iterates of a list of dependencies and checks if they are fulfilled
 func Check(keys []Key, projectDir string) error {
 	err := check(keys, deps, runfiles.Finder, projectDir)
@@ -23,17 +23,24 @@ Note: we made the "patch" part of the semver (when parsing the output with regex
 be more lenient when a command returns something like 1.2 instead of 1.2.0
 */
 var (
-	bazelRegex   = regexp.MustCompile(`(?m)bazel (?P<version>\d+(\.\d+\.\d+)?)`)
-	clangRegex   = regexp.MustCompile(`(?m)clang version (?P<version>\d+\.\d+(\.\d+)?)`)
-	cmakeRegex   = regexp.MustCompile(`(?m)cmake version (?P<version>\d+\.\d+(\.\d+)?)`)
-	genHTMLRegex = regexp.MustCompile(`.*LCOV version (?P<version>\d+\.\d+(\.\d+)?)`)
-	gradleRegex  = regexp.MustCompile(`(?m)Gradle (?P<version>\d+(\.\d+){0,2})`)
-	javaRegex    = regexp.MustCompile(`(?m)version "(?P<version>\d+(\.\d+\.\d+)*)([_\.]\d+)?"`)
-	junitRegex   = regexp.MustCompile(`junit-jupiter-engine-(?P<version>\d+\.\d+\.\d+).jar`)
-	jazzerRegex  = regexp.MustCompile(`jazzer-(?P<version>\d+\.\d+\.\d+).jar`)
-	llvmRegex    = regexp.MustCompile(`(?m)LLVM version (?P<version>\d+\.\d+(\.\d+)?)`)
-	mavenRegex   = regexp.MustCompile(`(?m)Apache Maven (?P<version>\d+(\.\d+){0,2})`)
-	nodeRegex    = regexp.MustCompile(`(?m)(?P<version>\d+(\.\d+\.\d+)?)`)
+	bazelRegex      = regexp.MustCompile(`(?m)bazel (?P<version>\d+(\.\d+\.\d+)?)`)
+	cargoRegex      = regexp.MustCompile(`(?m)cargo (?P<version>\d+\.\d+(\.\d+)?)`)
+	cargoFuzzRegex  = regexp.MustCompile(`(?m)cargo-fuzz (?P<version>\d+\.\d+(\.\d+)?)`)
+	atherisRegex    = regexp.MustCompile(`(?m)atheris (?P<version>\d+\.\d+(\.\d+)?)`)
+	coveragePyRegex = regexp.MustCompile(`(?m)Coverage.py, version (?P<version>\d+\.\d+(\.\d+)?)`)
+	clangRegex      = regexp.MustCompile(`(?m)clang version (?P<version>\d+\.\d+(\.\d+)?)`)
+	cmakeRegex      = regexp.MustCompile(`(?m)cmake version (?P<version>\d+\.\d+(\.\d+)?)`)
+	genHTMLRegex    = regexp.MustCompile(`.*LCOV version (?P<version>\d+\.\d+(\.\d+)?)`)
+	goRegex         = regexp.MustCompile(`(?m)go version go(?P<version>\d+\.\d+(\.\d+)?)`)
+	gradleRegex     = regexp.MustCompile(`(?m)Gradle (?P<version>\d+(\.\d+){0,2})`)
+	javaRegex       = regexp.MustCompile(`(?m)version "(?P<version>\d+(\.\d+\.\d+)*)([_\.]\d+)?"`)
+	junitRegex      = regexp.MustCompile(`junit-jupiter-engine-(?P<version>\d+\.\d+\.\d+).jar`)
+	jazzerRegex     = regexp.MustCompile(`jazzer-(?P<version>\d+\.\d+\.\d+).jar`)
+	llvmRegex       = regexp.MustCompile(`(?m)LLVM version (?P<version>\d+\.\d+(\.\d+)?)`)
+	mavenRegex      = regexp.MustCompile(`(?m)Apache Maven (?P<version>\d+(\.\d+){0,2})`)
+	mesonRegex      = regexp.MustCompile(`(?m)^(?P<version>\d+\.\d+(\.\d+)?)`)
+	nodeRegex       = regexp.MustCompile(`(?m)(?P<version>\d+(\.\d+\.\d+)?)`)
+	pythonRegex     = regexp.MustCompile(`(?m)Python (?P<version>\d+\.\d+(\.\d+)?)`)
 )
 
 type execCheck func(string, Key) (*semver.Version, error)
@@ -165,6 +172,20 @@ func cmakeVersion(dep *Dependency, projectDir string) (*semver.Version, error) {
 	return version, nil
 }
 
+func mesonVersion(dep *Dependency, projectDir string) (*semver.Version, error) {
+	path, err := dep.finder.MesonPath()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := getVersionFromCommand(path, []string{"--version"}, mesonRegex, dep.Key)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Found Meson version %s in PATH: %s", version, path)
+	return version, nil
+}
+
 func genHTMLVersion(path string, dep *Dependency) (*semver.Version, error) {
 	version, err := getVersionFromCommand(path, []string{"--version"}, genHTMLRegex, dep.Key)
 	if err != nil {
@@ -300,6 +321,93 @@ func nodeVersion(dep *Dependency, projectDir string) (*semver.Version, error) {
 	return version, nil
 }
 
+func goVersion(dep *Dependency, projectDir string) (*semver.Version, error) {
+	path, err := dep.finder.GoPath()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := getVersionFromCommand(path, []string{"version"}, goRegex, dep.Key)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Found Go version %s in PATH: %s", version, path)
+	return version, nil
+}
+
+func cargoVersion(dep *Dependency, projectDir string) (*semver.Version, error) {
+	path, err := dep.finder.CargoPath()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := getVersionFromCommand(path, []string{"--version"}, cargoRegex, dep.Key)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Found cargo version %s in PATH: %s", version, path)
+	return version, nil
+}
+
+func cargoFuzzVersion(dep *Dependency, projectDir string) (*semver.Version, error) {
+	path, err := dep.finder.CargoFuzzPath()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := getVersionFromCommand(path, []string{"--version"}, cargoFuzzRegex, dep.Key)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Found cargo-fuzz version %s in PATH: %s", version, path)
+	return version, nil
+}
+
+func pythonVersion(dep *Dependency, projectDir string) (*semver.Version, error) {
+	path, err := dep.finder.PythonPath()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := getVersionFromCommand(path, []string{"--version"}, pythonRegex, dep.Key)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Found Python version %s in PATH: %s", version, path)
+	return version, nil
+}
+
+func atherisVersion(dep *Dependency, projectDir string) (*semver.Version, error) {
+	path, err := dep.finder.PythonPath()
+	if err != nil {
+		return nil, err
+	}
+
+	// The atheris module doesn't provide a version attribute, so we
+	// read the version from the package metadata
+	args := []string{"-c", "from importlib.metadata import version; print('atheris', version('atheris'))"}
+	version, err := getVersionFromCommand(path, args, atherisRegex, dep.Key)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Found Atheris version %s for %s", version, path)
+	return version, nil
+}
+
+func coveragePyVersion(dep *Dependency, projectDir string) (*semver.Version, error) {
+	path, err := dep.finder.PythonPath()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := getVersionFromCommand(path, []string{"-m", "coverage", "--version"}, coveragePyRegex, dep.Key)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Found coverage.py version %s for %s", version, path)
+	return version, nil
+}
+
 func visualStudioVersion() (*semver.Version, error) {
 	var vsVersion *semver.Version
 	versionFromEnv := os.Getenv("VisualStudioVersion")
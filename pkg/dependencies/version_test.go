@@ -101,6 +101,47 @@ OpenJDK 64-Bit Server VM (build 18+36-2087, mixed mode, sharing)`,
 		Regex:  nodeRegex,
 		Output: `v16.16.0`,
 	},
+	{
+		Want:   semver.MustParse("1.21.5"),
+		Regex:  goRegex,
+		Output: `go version go1.21.5 linux/amd64`,
+	},
+	{
+		Want:   semver.MustParse("1.18.0"),
+		Regex:  goRegex,
+		Output: `go version go1.18 darwin/arm64`,
+	},
+	{
+		Want:   semver.MustParse("1.74.1"),
+		Regex:  cargoRegex,
+		Output: `cargo 1.74.1 (ecb9851af 2023-10-18)`,
+	},
+	{
+		Want:   semver.MustParse("0.11.2"),
+		Regex:  cargoFuzzRegex,
+		Output: `cargo-fuzz 0.11.2`,
+	},
+	{
+		Want:   semver.MustParse("1.3.2"),
+		Regex:  mesonRegex,
+		Output: "1.3.2\n",
+	},
+	{
+		Want:   semver.MustParse("3.11.4"),
+		Regex:  pythonRegex,
+		Output: `Python 3.11.4`,
+	},
+	{
+		Want:   semver.MustParse("2.3.0"),
+		Regex:  atherisRegex,
+		Output: `atheris 2.3.0`,
+	},
+	{
+		Want:  semver.MustParse("7.3.2"),
+		Regex: coveragePyRegex,
+		Output: `Coverage.py, version 7.3.2 with C extension
+Full documentation is at https://coverage.readthedocs.io/en/7.3.2`,
+	},
 	{
 		Want:   semver.MustParse("0.19.0"),
 		Regex:  jazzerRegex,
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -43,6 +44,12 @@ type Finding struct {
 	CreatedAt  time.Time                `json:"created_at,omitempty"`
 	InputFile  string                   `json:"input_file,omitempty"`
 	StackTrace []*stacktrace.StackFrame `json:"stack_trace,omitempty"`
+	// Further stack traces from the error report, like the stack traces
+	// of the allocation and free sites of a use-after-free
+	AuxiliaryStackTraces []*stacktrace.AuxiliaryStackTrace `json:"auxiliary_stack_traces,omitempty"`
+	// The error report of the sanitizer (or of Jazzer) in structured
+	// form, so that it doesn't have to be parsed from the logs again
+	SanitizerReport *SanitizerReport `json:"sanitizer_report,omitempty"`
 
 	seedPath string
 
@@ -51,6 +58,33 @@ type Finding struct {
 	FuzzTest string `json:"fuzz_test,omitempty"`
 }
 
+// SanitizerReport contains the information from the error report of a
+// sanitizer or of Jazzer which is relevant for triaging the finding
+type SanitizerReport struct {
+	// The tool which reported the error, e.g. "AddressSanitizer" or
+	// "Jazzer"
+	Sanitizer string `json:"sanitizer,omitempty"`
+	// The type of the bug as reported by the sanitizer, e.g.
+	// "heap-buffer-overflow", or the class of the exception thrown in
+	// the fuzz test
+	BugType string `json:"bug_type,omitempty"`
+	// The description of the error in the first line of the report
+	Message string `json:"message,omitempty"`
+	// Either "READ" or "WRITE" for invalid memory accesses
+	AccessType string `json:"access_type,omitempty"`
+	// The number of bytes which were accessed
+	AccessSize uint64 `json:"access_size,omitempty"`
+	// The address which was accessed
+	Address string `json:"address,omitempty"`
+	// The thread in which the error occurred, e.g. "T0"
+	Thread string `json:"thread,omitempty"`
+	// The rows of the shadow memory around the accessed address, as
+	// printed by ASan
+	ShadowMemory []string `json:"shadow_memory,omitempty"`
+	// The SUMMARY line printed at the end of the report
+	Summary string `json:"summary,omitempty"`
+}
+
 type ErrorType string
 
 // These constants must have this exact value (in uppercase) to be able
@@ -89,6 +123,21 @@ const (
 	SeverityLevelLow      SeverityLevel = "LOW"
 )
 
+// SeverityLevelForScore returns the severity level of the specified
+// score, using the same ranges as CVSS v3.
+func SeverityLevelForScore(score float32) SeverityLevel {
+	switch {
+	case score >= 9.0:
+		return SeverityLevelCritical
+	case score >= 7.0:
+		return SeverityLevelHigh
+	case score >= 4.0:
+		return SeverityLevelMedium
+	default:
+		return SeverityLevelLow
+	}
+}
+
 type Severity struct {
 	Level SeverityLevel `json:"description,omitempty"`
 	Score float32       `json:"score,omitempty"`
@@ -309,6 +358,14 @@ func (f *Finding) ShortDescriptionColumns() []string {
 		case f.Details == "fuzz target exited":
 			// Jazzer.js findings
 			errorType = f.Details
+		case strings.HasPrefix(f.Details, "panic: "):
+			// Go panics
+			errorType = "panic"
+		case strings.HasPrefix(f.Details, "Rust panic"):
+			errorType = "Rust panic"
+		case strings.HasPrefix(f.Details, "fuzzing process hung"):
+			// Go fuzz tests which hung or were killed
+			errorType = strings.Split(f.Details, ":")[0]
 		default:
 			errorType = strings.ReplaceAll(strings.Split(f.Details, " ")[0], "-", " ")
 		}
@@ -399,8 +456,10 @@ func (f *Finding) EnhanceWithErrorDetails() error {
 		return err
 	}
 	for _, d := range errorDetails {
+		// Error details without a name (e.g. user-defined errors which
+		// only override the severity) would match every finding
 		if (f.MoreDetails != nil && f.MoreDetails.ID == d.ID) ||
-			strings.Contains(
+			d.Name != "" && strings.Contains(
 				strings.ToLower(f.ShortDescriptionColumns()[0]),
 				strings.ToLower(d.Name)) {
 
@@ -424,8 +483,32 @@ func (f *Finding) EnhanceWithErrorDetails() error {
 	return nil
 }
 
+// Error details declared by the user in the cifuzz.yaml, which take
+// precedence over the ones from the error details file
+var additionalErrorDetails []*ErrorDetails
+
+// AddErrorDetails adds the specified error details to the ones returned
+// by ErrorDetailsCollection. Error details with the ID of previously
+// added ones replace those.
+func AddErrorDetails(details ...*ErrorDetails) {
+	for _, d := range details {
+		i := slices.IndexFunc(additionalErrorDetails, func(e *ErrorDetails) bool { return e.ID == d.ID })
+		if i >= 0 {
+			additionalErrorDetails[i] = d
+		} else {
+			additionalErrorDetails = append(additionalErrorDetails, d)
+		}
+	}
+}
+
+// ResetErrorDetails removes all error details added via AddErrorDetails.
+func ResetErrorDetails() {
+	additionalErrorDetails = nil
+}
+
 // ErrorDetailsCollection returns all error details from the error details
-// file.
+// file and the ones added via AddErrorDetails. Added error details with
+// the ID of a built-in error are merged into the built-in error details.
 func ErrorDetailsCollection() ([]*ErrorDetails, error) {
 	// Read error details from error-details.json
 	errorDetailsPath, err := runfiles.Finder.ErrorDetailsPath()
@@ -441,7 +524,52 @@ func ErrorDetailsCollection() ([]*ErrorDetails, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return errorDetailsFromJSON.ErrorDetails, nil
+
+	// Return copies of the additional error details, because callers
+	// like EnhanceWithErrorDetails modify the returned error details.
+	// The additional error details come first so that they take
+	// precedence when matching by name.
+	var res []*ErrorDetails
+	builtinDetails := errorDetailsFromJSON.ErrorDetails
+	for _, d := range additionalErrorDetails {
+		i := slices.IndexFunc(builtinDetails, func(e *ErrorDetails) bool { return e.ID == d.ID })
+		if i < 0 {
+			detailsCopy := *d
+			res = append(res, &detailsCopy)
+			continue
+		}
+		res = append(res, mergeErrorDetails(builtinDetails[i], d))
+		builtinDetails = slices.Delete(slices.Clone(builtinDetails), i, i+1)
+	}
+	return append(res, builtinDetails...), nil
+}
+
+// mergeErrorDetails returns a copy of base with all fields which are set
+// in override replaced by the ones from override.
+func mergeErrorDetails(base, override *ErrorDetails) *ErrorDetails {
+	merged := *base
+	if override.Name != "" {
+		merged.Name = override.Name
+	}
+	if override.Description != "" {
+		merged.Description = override.Description
+	}
+	if override.Severity != nil {
+		merged.Severity = override.Severity
+	}
+	if override.Mitigation != "" {
+		merged.Mitigation = override.Mitigation
+	}
+	if len(override.Links) > 0 {
+		merged.Links = override.Links
+	}
+	if override.OwaspDetails != nil {
+		merged.OwaspDetails = override.OwaspDetails
+	}
+	if override.CweDetails != nil {
+		merged.CweDetails = override.CweDetails
+	}
+	return &merged
 }
 
 // SeverityForErrorID returns the severity for the specified error ID.
@@ -0,0 +1,182 @@
+package finding
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/pkg/java/sourcemap"
+	"code-intelligence.com/cifuzz/pkg/parser/libfuzzer/stacktrace"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+// A SourceSnippet contains the lines of source code around the location
+// of a stack frame
+type SourceSnippet struct {
+	SourceFile string `json:"source_file"`
+	Function   string `json:"function,omitempty"`
+	Line       uint32 `json:"line"`
+	Column     uint32 `json:"column,omitempty"`
+	// The line number of the first line in Lines
+	StartLine uint32   `json:"start_line"`
+	Lines     []string `json:"lines"`
+}
+
+// SourceSnippets returns snippets of the source code around the
+// locations of the first maxFrames frames of the stack trace whose
+// source files exist in the project directory. Source files outside of
+// the project directory are skipped. Each snippet contains up
+// to contextLines lines before and after the location.
+func (f *Finding) SourceSnippets(projectDir string, maxFrames int, contextLines int) ([]*SourceSnippet, error) {
+	var snippets []*SourceSnippet
+	var sourceMap *sourcemap.SourceMap
+	seen := map[string]bool{}
+
+	for _, frame := range f.StackTrace {
+		if len(snippets) >= maxFrames {
+			break
+		}
+		if frame.Line == 0 {
+			continue
+		}
+
+		sourceFile := frame.SourceFile
+		path, err := resolveSourceFile(projectDir, sourceFile)
+		if err != nil {
+			return nil, err
+		}
+		if path == "" && isJavaSourceFile(frame.SourceFile) {
+			// Stack traces of Java findings only contain the base name
+			// of the source file if no source map was available when
+			// the finding was parsed, e.g. for remote findings
+			if sourceMap == nil {
+				sourceMap, err = sourcemap.CreateSourceMap(projectDir, []string{projectDir})
+				if err != nil {
+					return nil, err
+				}
+			}
+			relPath := sourceMap.FindSourceFile(filepath.Base(frame.SourceFile), frame.Function)
+			if relPath != "" {
+				sourceFile = relPath
+				path, err = resolveSourceFile(projectDir, relPath)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		if path == "" {
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%d", path, frame.Line)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		snippet, err := readSourceSnippet(path, sourceFile, frame, contextLines)
+		if err != nil {
+			return nil, err
+		}
+		if snippet == nil {
+			continue
+		}
+		snippets = append(snippets, snippet)
+	}
+
+	return snippets, nil
+}
+
+// resolveSourceFile returns the absolute path of the specified source
+// file, which is either absolute or relative to the project directory,
+// or an empty string if the source file doesn't exist or is not located
+// in the project directory. Source files outside of the project
+// directory (e.g. system headers or files referenced via symlinks) are
+// never shown, because the stack trace of a finding can originate from
+// a remote run and must not be used to read arbitrary local files.
+func resolveSourceFile(projectDir string, sourceFile string) (string, error) {
+	path := filepath.FromSlash(sourceFile)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectDir, path)
+	}
+	exists, err := fileutil.Exists(path)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", nil
+	}
+
+	// Resolve symlinks to make sure that the file is actually located
+	// in the project directory
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	realPath, err = filepath.Abs(realPath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	realProjectDir, err := filepath.EvalSymlinks(projectDir)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	realProjectDir, err = filepath.Abs(realProjectDir)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	isBelow, err := fileutil.IsBelow(realPath, realProjectDir)
+	if err != nil {
+		return "", err
+	}
+	if !isBelow {
+		return "", nil
+	}
+	return path, nil
+}
+
+func isJavaSourceFile(sourceFile string) bool {
+	return strings.HasSuffix(sourceFile, ".java") || strings.HasSuffix(sourceFile, ".kt")
+}
+
+func readSourceSnippet(path string, sourceFile string, frame *stacktrace.StackFrame, contextLines int) (*SourceSnippet, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer file.Close()
+
+	startLine := uint32(1)
+	if frame.Line > uint32(contextLines) {
+		startLine = frame.Line - uint32(contextLines)
+	}
+	endLine := frame.Line + uint32(contextLines)
+
+	snippet := &SourceSnippet{
+		SourceFile: sourceFile,
+		Function:   frame.Function,
+		Line:       frame.Line,
+		Column:     frame.Column,
+		StartLine:  startLine,
+	}
+	scanner := bufio.NewScanner(file)
+	for lineNumber := uint32(1); lineNumber <= endLine && scanner.Scan(); lineNumber++ {
+		if lineNumber >= startLine {
+			snippet.Lines = append(snippet.Lines, strings.TrimRight(scanner.Text(), "\r"))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// The source file changed since the finding was found if it
+	// doesn't contain the line anymore
+	if uint32(len(snippet.Lines)) <= frame.Line-startLine {
+		return nil, nil
+	}
+	return snippet, nil
+}
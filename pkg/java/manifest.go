@@ -2,6 +2,7 @@ package java
 
 import (
 	"archive/zip"
+	"bufio"
 	"fmt"
 	"io"
 	"os"
@@ -97,3 +98,59 @@ func entriesToString(entries map[string]string) (string, error) {
 	}
 	return content.String(), nil
 }
+
+// ReadManifest returns the main attributes of the META-INF/MANIFEST.MF
+// file of the specified JAR. If the JAR doesn't contain a manifest, an
+// empty map is returned.
+func ReadManifest(jarPath string) (map[string]string, error) {
+	zipReader, err := zip.OpenReader(jarPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer zipReader.Close()
+
+	manifestFile, err := zipReader.Open("META-INF/MANIFEST.MF")
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer manifestFile.Close()
+
+	return parseManifest(manifestFile)
+}
+
+func parseManifest(r io.Reader) (map[string]string, error) {
+	entries := map[string]string{}
+	var lastKey string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// The main attributes end with the first empty line, the
+		// following sections contain per-entry attributes
+		if line == "" {
+			break
+		}
+		// Lines starting with a space continue the value of the
+		// previous line
+		if strings.HasPrefix(line, " ") {
+			if lastKey != "" {
+				entries[lastKey] += line[1:]
+			}
+			continue
+		}
+		key, value, found := strings.Cut(line, ": ")
+		if !found {
+			return nil, errors.Errorf("invalid manifest line: %q", line)
+		}
+		entries[key] = value
+		lastKey = key
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return entries, nil
+}
@@ -135,3 +135,18 @@ func TestEntriesToString_LongHeaderAndLine(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 4, strings.Count(result, "\n"))
 }
+
+func TestReadManifest(t *testing.T) {
+	tempDir := testutil.MkdirTemp(t, "", "manifest-*")
+
+	entries := map[string]string{
+		"Jazzer-Fuzz-Target-Class": "com.example." + strings.Repeat("VeryLongPackageName.", 5) + "FuzzTest",
+		"Foo":                      "Bar",
+	}
+	jarPath, err := CreateManifestJar(entries, tempDir)
+	require.NoError(t, err)
+
+	manifest, err := ReadManifest(jarPath)
+	require.NoError(t, err)
+	assert.Equal(t, entries, manifest)
+}
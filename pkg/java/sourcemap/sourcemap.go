@@ -76,6 +76,42 @@ func ReadSourceMapFromFile(filepath string) (*SourceMap, error) {
 	return &sourceMap, nil
 }
 
+// FindSourceFile returns the path relative to the project directory of
+// the source file with the specified base name which defines the
+// specified function, e.g. "src/main/java/com/example/Example.java" for
+// "Example.java" and "com.example.Example.fuzz". If the source file is
+// not found in the source map, an empty string is returned.
+func (sm *SourceMap) FindSourceFile(sourceFile string, function string) string {
+	if sm == nil {
+		return ""
+	}
+
+	// remove function and class name
+	possiblePackageName := removeLastPart(removeLastPart(function))
+	// In the case of nested classes we are not at the true package
+	// name yet. Remove possible class suffixes until we find the
+	// Java package that matches.
+
+	for possiblePackageName != "" {
+		for _, relFile := range sm.JavaPackages[possiblePackageName] {
+			if filepath.Base(relFile) == sourceFile {
+				return relFile
+			}
+		}
+		possiblePackageName = removeLastPart(possiblePackageName)
+	}
+
+	return ""
+}
+
+func removeLastPart(packageName string) string {
+	sepIndex := strings.LastIndex(packageName, ".")
+	if sepIndex > 0 {
+		return packageName[0:sepIndex]
+	}
+	return ""
+}
+
 func getPackageFromSourceFile(sourceFile string) (string, error) {
 	fd, err := os.Open(sourceFile)
 	if err != nil {
@@ -23,3 +23,26 @@ func TestCreateSourceMap(t *testing.T) {
 	assert.Contains(t, sourceMap.JavaPackages["com.example"], "src/main/java/com/example/Example.java")
 	assert.Contains(t, sourceMap.JavaPackages["com.other"], "src/main/java/com/other/Other.java")
 }
+
+func TestFindSourceFile(t *testing.T) {
+	sourceMap := &SourceMap{
+		JavaPackages: map[string][]string{
+			"com.example": {"src/main/java/com/example/Example.java"},
+		},
+	}
+	assert.Equal(t, "src/main/java/com/example/Example.java", sourceMap.FindSourceFile("Example.java", "com.example.Example.fuzz"))
+	// Nested classes
+	assert.Equal(t, "src/main/java/com/example/Example.java", sourceMap.FindSourceFile("Example.java", "com.example.Example$Inner.fuzz"))
+	assert.Equal(t, "", sourceMap.FindSourceFile("Other.java", "com.example.Other.fuzz"))
+
+	var nilSourceMap *SourceMap
+	assert.Equal(t, "", nilSourceMap.FindSourceFile("Example.java", "com.example.Example.fuzz"))
+}
+
+func TestRemoveLastPart(t *testing.T) {
+	result := removeLastPart("com.example")
+	assert.Equal(t, "com", result)
+
+	result = removeLastPart(result)
+	assert.Equal(t, "", result)
+}
@@ -12,6 +12,9 @@ var bazelSetup string
 //go:embed instructions/cmake
 var cmakeSetup string
 
+//go:embed instructions/meson
+var mesonSetup string
+
 //go:embed instructions/maven
 var mavenSetup string
 
@@ -27,12 +30,17 @@ var nodejsSetup string
 //go:embed instructions/nodets
 var nodetsSetup string
 
+//go:embed instructions/python
+var pythonSetup string
+
 func Instructions(buildSystem string) string {
 	switch buildSystem {
 	case config.BuildSystemBazel:
 		return bazelSetup
 	case config.BuildSystemCMake:
 		return cmakeSetup
+	case config.BuildSystemMeson:
+		return mesonSetup
 	case config.BuildSystemNodeJS:
 		return nodejsSetup
 	case "nodets":
@@ -43,6 +51,8 @@ func Instructions(buildSystem string) string {
 		return gradleGroovySetup
 	case string(config.GradleKotlin):
 		return gradleKotlinSetup
+	case config.BuildSystemPython:
+		return pythonSetup
 	default:
 		return ""
 	}
@@ -37,6 +37,11 @@ func (m *RunfilesFinderMock) CMakePath() (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *RunfilesFinderMock) MesonPath() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
 func (m *RunfilesFinderMock) CMakePresetsPath() (string, error) {
 	args := m.Called()
 	return args.String(0), args.Error(1)
@@ -72,6 +77,11 @@ func (m *RunfilesFinderMock) GenHTMLPath() (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *RunfilesFinderMock) AFLFuzzPath() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
 func (m *RunfilesFinderMock) PerlPath() (string, error) {
 	args := m.Called()
 	return args.String(0), args.Error(1)
@@ -142,6 +152,26 @@ func (m *RunfilesFinderMock) NodePath() (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *RunfilesFinderMock) GoPath() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *RunfilesFinderMock) CargoPath() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *RunfilesFinderMock) CargoFuzzPath() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *RunfilesFinderMock) PythonPath() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
 func (m *RunfilesFinderMock) ErrorDetailsPath() (string, error) {
 	args := m.Called()
 	return args.String(0), args.Error(1)
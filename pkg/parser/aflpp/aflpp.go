@@ -0,0 +1,179 @@
+// Package aflpp parses the output directory of afl-fuzz. In contrast to
+// libFuzzer, AFL++ doesn't print its progress and findings in a
+// parsable way, but writes its statistics to the fuzzer_stats file and
+// the inputs which caused crashes and timeouts to the crashes and hangs
+// directories.
+package aflpp
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/pkg/report"
+)
+
+// The name of the fuzzer instance directory which afl-fuzz creates in
+// the output directory if it's not run in parallel mode.
+const DefaultFuzzerName = "default"
+
+// Matches the signal number in the names of crashing inputs, e.g.
+// "id:000000,sig:06,src:000001,time:1234,execs:5678,op:havoc,rep:2"
+var crashSignalPattern = regexp.MustCompile(`(?:^|,)sig:(\d+)(?:,|$)`)
+
+// FuzzerStats contains the statistics written by afl-fuzz to the
+// fuzzer_stats file.
+type FuzzerStats map[string]string
+
+// ParseFuzzerStats parses the content of a fuzzer_stats file, which
+// consists of lines of the form "key   : value".
+func ParseFuzzerStats(r io.Reader) (FuzzerStats, error) {
+	stats := FuzzerStats{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		stats[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return stats, nil
+}
+
+func (s FuzzerStats) uint(key string) uint64 {
+	// AFL++ prints some counters as floating point numbers (e.g.
+	// execs_per_sec), so we parse all values as floats
+	value, err := strconv.ParseFloat(strings.TrimSuffix(s[key], "%"), 64)
+	if err != nil || value < 0 {
+		return 0
+	}
+	return uint64(value)
+}
+
+// Metric converts the statistics into a fuzzing metric. AFL++ doesn't
+// differentiate between features and edges, so both are set to the
+// number of edges found.
+func (s FuzzerStats) Metric() *report.FuzzingMetric {
+	lastUpdate := s.uint("last_update")
+	// last_find is 0 if no new input was found yet, in which case we
+	// report the time since the start of the fuzzing run
+	lastFind := s.uint("last_find")
+	if lastFind == 0 {
+		lastFind = s.uint("start_time")
+	}
+	var secondsSinceLastFind uint64
+	if lastUpdate > lastFind {
+		secondsSinceLastFind = lastUpdate - lastFind
+	}
+
+	edges := int32(s.uint("edges_found"))
+	return &report.FuzzingMetric{
+		Timestamp:               time.Unix(int64(lastUpdate), 0),
+		ExecutionsPerSecond:     int32(s.uint("execs_per_sec")),
+		Features:                edges,
+		CorpusSize:              int32(s.uint("corpus_count")),
+		SecondsSinceLastFeature: secondsSinceLastFind,
+		TotalExecutions:         s.uint("execs_done"),
+		Edges:                   edges,
+		SecondsSinceLastEdge:    secondsSinceLastFind,
+	}
+}
+
+// CrashSignal returns the number of the signal with which the fuzz
+// target crashed on the given crashing input, as encoded by afl-fuzz in
+// the file name, or 0 if the file name doesn't contain a signal.
+func CrashSignal(crashFile string) int {
+	match := crashSignalPattern.FindStringSubmatch(filepath.Base(crashFile))
+	if match == nil {
+		return 0
+	}
+	signal, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0
+	}
+	return signal
+}
+
+// OutputParser parses the output directory of an afl-fuzz instance.
+// It keeps track of the files it already returned, so that each
+// crashing input and corpus entry is only returned once.
+type OutputParser struct {
+	// The directory of the fuzzer instance, i.e. <output dir>/default
+	fuzzerDir string
+
+	seenCrashes map[string]bool
+	seenQueue   map[string]bool
+}
+
+func NewOutputParser(outputDir string) *OutputParser {
+	return &OutputParser{
+		fuzzerDir:   filepath.Join(outputDir, DefaultFuzzerName),
+		seenCrashes: map[string]bool{},
+		seenQueue:   map[string]bool{},
+	}
+}
+
+// Metric returns the current fuzzing metric or nil if afl-fuzz didn't
+// write any statistics yet.
+func (p *OutputParser) Metric() (*report.FuzzingMetric, error) {
+	f, err := os.Open(filepath.Join(p.fuzzerDir, "fuzzer_stats"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+
+	stats, err := ParseFuzzerStats(f)
+	if err != nil {
+		return nil, err
+	}
+	return stats.Metric(), nil
+}
+
+// NewCrashes returns the paths of the crashing inputs which were
+// written since the last call.
+func (p *OutputParser) NewCrashes() ([]string, error) {
+	return newFiles(filepath.Join(p.fuzzerDir, "crashes"), p.seenCrashes)
+}
+
+// NewQueueEntries returns the paths of the inputs which were added to
+// the fuzzer's queue (i.e. the corpus) since the last call.
+func (p *OutputParser) NewQueueEntries() ([]string, error) {
+	return newFiles(filepath.Join(p.fuzzerDir, "queue"), p.seenQueue)
+}
+
+func newFiles(dir string, seen map[string]bool) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		// Only files starting with "id:" are inputs, the directories
+		// also contain a README.txt and state directories
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), "id:") || seen[entry.Name()] {
+			continue
+		}
+		seen[entry.Name()] = true
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
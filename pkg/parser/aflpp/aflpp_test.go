@@ -0,0 +1,101 @@
+package aflpp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/testutil"
+	"code-intelligence.com/cifuzz/pkg/report"
+)
+
+const fuzzerStats = `start_time        : 1700000000
+last_update       : 1700000120
+run_time          : 120
+fuzzer_pid        : 4242
+cycles_done       : 3
+execs_done        : 1234567
+execs_per_sec     : 10288.06
+corpus_count      : 42
+bitmap_cvg        : 1.23%
+saved_crashes     : 1
+saved_hangs       : 0
+last_find         : 1700000100
+edges_found       : 321
+total_edges       : 65536
+command_line      : afl-fuzz -i in -o out -- ./fuzz_test
+`
+
+func TestParseFuzzerStats(t *testing.T) {
+	stats, err := ParseFuzzerStats(strings.NewReader(fuzzerStats))
+	require.NoError(t, err)
+	require.Equal(t, "afl-fuzz -i in -o out -- ./fuzz_test", stats["command_line"])
+
+	require.Equal(t, &report.FuzzingMetric{
+		Timestamp:               time.Unix(1700000120, 0),
+		ExecutionsPerSecond:     10288,
+		Features:                321,
+		CorpusSize:              42,
+		SecondsSinceLastFeature: 20,
+		TotalExecutions:         1234567,
+		Edges:                   321,
+		SecondsSinceLastEdge:    20,
+	}, stats.Metric())
+
+	// Without any finds, the time since the start is reported
+	stats["last_find"] = "0"
+	require.EqualValues(t, 120, stats.Metric().SecondsSinceLastEdge)
+}
+
+func TestCrashSignal(t *testing.T) {
+	require.Equal(t, 6, CrashSignal("/out/default/crashes/id:000000,sig:06,src:000001,time:1234,execs:5678,op:havoc,rep:2"))
+	require.Equal(t, 11, CrashSignal("id:000001,sig:11,src:000000,op:flip1,pos:0"))
+	require.Equal(t, 0, CrashSignal("README.txt"))
+}
+
+func TestOutputParser(t *testing.T) {
+	outputDir := testutil.MkdirTemp(t, "", "aflpp-test-")
+	parser := NewOutputParser(outputDir)
+
+	// Nothing is reported before afl-fuzz created the directories
+	metric, err := parser.Metric()
+	require.NoError(t, err)
+	require.Nil(t, metric)
+	crashes, err := parser.NewCrashes()
+	require.NoError(t, err)
+	require.Empty(t, crashes)
+
+	fuzzerDir := filepath.Join(outputDir, DefaultFuzzerName)
+	crashesDir := filepath.Join(fuzzerDir, "crashes")
+	queueDir := filepath.Join(fuzzerDir, "queue")
+	require.NoError(t, os.MkdirAll(crashesDir, 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(queueDir, ".state"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(fuzzerDir, "fuzzer_stats"), []byte(fuzzerStats), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(crashesDir, "README.txt"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(crashesDir, "id:000000,sig:06"), []byte("crash"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(queueDir, "id:000000,time:0,execs:0,orig:seed"), []byte("seed"), 0o644))
+
+	metric, err = parser.Metric()
+	require.NoError(t, err)
+	require.EqualValues(t, 42, metric.CorpusSize)
+
+	crashes, err = parser.NewCrashes()
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(crashesDir, "id:000000,sig:06")}, crashes)
+	queue, err := parser.NewQueueEntries()
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(queueDir, "id:000000,time:0,execs:0,orig:seed")}, queue)
+
+	// Files are only returned once
+	require.NoError(t, os.WriteFile(filepath.Join(crashesDir, "id:000001,sig:11"), []byte("crash"), 0o644))
+	crashes, err = parser.NewCrashes()
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(crashesDir, "id:000001,sig:11")}, crashes)
+	queue, err = parser.NewQueueEntries()
+	require.NoError(t, err)
+	require.Empty(t, queue)
+}
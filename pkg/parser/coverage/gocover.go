@@ -0,0 +1,110 @@
+package coverage
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/util/regexutil"
+)
+
+// Each line of a Go cover profile (after the "mode:" line) describes a
+// block of statements, e.g.
+// example.com/project/parser/parser.go:3.26,5.2 1 17
+// which means that the block starting at line 3 column 26 and ending at
+// line 5 column 2 contains one statement and was executed 17 times.
+var goCoverBlockPattern = regexp.MustCompile(
+	`^(?P<file>.+):(?P<start_line>\d+)\.\d+,(?P<end_line>\d+)\.\d+ (?P<statements>\d+) (?P<count>\d+)$`)
+
+// ParseGoCoverProfileIntoLCOVReport converts a cover profile as created
+// by `go test -coverprofile` into an LCOV report. The file names in the
+// cover profile are of the form <import path>/<file>, they are converted
+// to paths by looking up the directory of the package in packageDirs.
+// Files of unknown packages keep their name.
+func ParseGoCoverProfileIntoLCOVReport(in io.Reader, packageDirs map[string]string) (*LCOVReport, error) {
+	lcovReport := &LCOVReport{}
+
+	// The execution counts of the lines by file name. A line can be
+	// part of multiple blocks, so we use the maximum count of all
+	// blocks. We do the same for blocks which are listed more than
+	// once, which is the case if multiple packages were tested.
+	executions := map[string]map[int]int{}
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+
+		block, found := regexutil.FindNamedGroupsMatch(goCoverBlockPattern, line)
+		if !found {
+			return nil, errors.Errorf("Invalid line in Go cover profile: %s", line)
+		}
+		startLine, err := strconv.Atoi(block["start_line"])
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		endLine, err := strconv.Atoi(block["end_line"])
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		count, err := strconv.Atoi(block["count"])
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		fileName := goSourceFilePath(block["file"], packageDirs)
+		if executions[fileName] == nil {
+			executions[fileName] = map[int]int{}
+		}
+		for l := startLine; l <= endLine; l++ {
+			if c, ok := executions[fileName][l]; !ok || count > c {
+				executions[fileName][l] = count
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "Unable to read Go cover profile")
+	}
+
+	for fileName, lines := range executions {
+		sourceFile := &SourceFile{Name: fileName}
+		for number, count := range lines {
+			sourceFile.LineInformation = append(sourceFile.LineInformation, Line{
+				Number:     number,
+				Executions: count,
+			})
+			sourceFile.LinesFound++
+			if count > 0 {
+				sourceFile.LinesHit++
+			}
+		}
+		sort.Slice(sourceFile.LineInformation, func(i, j int) bool {
+			return sourceFile.LineInformation[i].Number < sourceFile.LineInformation[j].Number
+		})
+		lcovReport.SourceFiles = append(lcovReport.SourceFiles, sourceFile)
+	}
+	sort.Slice(lcovReport.SourceFiles, func(i, j int) bool {
+		return lcovReport.SourceFiles[i].Name < lcovReport.SourceFiles[j].Name
+	})
+
+	return lcovReport, nil
+}
+
+func goSourceFilePath(name string, packageDirs map[string]string) string {
+	// Import paths always use forward slashes
+	pkg, file := path.Split(name)
+	dir, ok := packageDirs[strings.TrimSuffix(pkg, "/")]
+	if !ok {
+		return name
+	}
+	return filepath.Join(dir, file)
+}
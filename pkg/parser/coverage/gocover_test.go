@@ -0,0 +1,55 @@
+package coverage
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoCoverProfileIntoLCOVReport(t *testing.T) {
+	profile := `mode: set
+example.com/project/parser/parser.go:3.26,4.18 1 1
+example.com/project/parser/parser.go:4.18,6.3 1 0
+example.com/project/parser/parser.go:7.2,7.15 1 1
+example.com/project/parser/parser.go:3.26,4.18 1 0
+example.com/other/util.go:5.10,6.2 1 0
+`
+	packageDirs := map[string]string{
+		"example.com/project/parser": filepath.Join("project", "parser"),
+	}
+
+	report, err := ParseGoCoverProfileIntoLCOVReport(strings.NewReader(profile), packageDirs)
+	require.NoError(t, err)
+	require.Len(t, report.SourceFiles, 2)
+
+	// Files of unknown packages keep their name
+	assert.Equal(t, "example.com/other/util.go", report.SourceFiles[0].Name)
+	assert.Equal(t, 2, report.SourceFiles[0].LinesFound)
+	assert.Equal(t, 0, report.SourceFiles[0].LinesHit)
+
+	parserFile := report.SourceFiles[1]
+	assert.Equal(t, filepath.Join("project", "parser", "parser.go"), parserFile.Name)
+	assert.Equal(t, []Line{
+		{Number: 3, Executions: 1},
+		{Number: 4, Executions: 1},
+		{Number: 5, Executions: 0},
+		{Number: 6, Executions: 0},
+		{Number: 7, Executions: 1},
+	}, parserFile.LineInformation)
+	assert.Equal(t, 5, parserFile.LinesFound)
+	assert.Equal(t, 3, parserFile.LinesHit)
+}
+
+func TestParseGoCoverProfileIntoLCOVReport_Empty(t *testing.T) {
+	report, err := ParseGoCoverProfileIntoLCOVReport(strings.NewReader("mode: set\n"), nil)
+	require.NoError(t, err)
+	assert.Empty(t, report.SourceFiles)
+}
+
+func TestParseGoCoverProfileIntoLCOVReport_InvalidFormat(t *testing.T) {
+	_, err := ParseGoCoverProfileIntoLCOVReport(strings.NewReader("mode: set\nfoo bar\n"), nil)
+	require.Error(t, err)
+}
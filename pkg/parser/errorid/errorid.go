@@ -4,6 +4,8 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/pkg/errors"
+
 	"code-intelligence.com/cifuzz/pkg/finding"
 	"code-intelligence.com/cifuzz/pkg/log"
 )
@@ -31,36 +33,53 @@ func (m *matcher) Match(input string) bool {
 
 var matchers = []matcher{
 	{id: "alloc_dealloc_mismatch", substrings: []string{"attempting free on address which was not malloc"}},
+	{id: "data_race", substrings: []string{"data race"}},
 	{id: "deadly_signal", substrings: []string{"deadly signal"}},
 	{id: "double_free", substrings: []string{"attempting double-free on"}},
 	{id: "heap_buffer_overflow", substrings: []string{"heap-buffer-overflow on address"}},
 	{id: "heap_use_after_free", substrings: []string{"heap-use-after-free on address"}},
 	{id: "global_buffer_overflow", substrings: []string{"global-buffer-overflow on address"}},
 	{id: "java_assertion_error", substrings: []string{"Java Assertion Error"}},
-	{id: "out_of_bounds", regexs: []*regexp.Regexp{regexp.MustCompile(`undefined behavior: index \d+ out of bounds`)}},
+	{id: "out_of_bounds", regexs: []*regexp.Regexp{
+		regexp.MustCompile(`undefined behavior: index \d+ out of bounds`),
+		// Go panics
+		regexp.MustCompile(`runtime error: (index|slice bounds) out of range`),
+		// Rust panics
+		regexp.MustCompile(`index out of bounds: the len is \d+ but the index is \d+`),
+		regexp.MustCompile(`range (start|end) index \d+ out of range for slice of length \d+`),
+		regexp.MustCompile(`byte index \d+ is out of bounds`),
+		// Python exceptions
+		regexp.MustCompile(`^IndexError: `),
+	}},
 	{id: "java_out_of_bounds", substrings: []string{"java.lang.ArrayIndexOutOfBoundsException"}},
 	{id: "ldap_injection", substrings: []string{"Security Issue: LDAP Injection"}},
 	{id: "load_arbitrary_library", substrings: []string{"Security Issue: load arbitrary library"}},
+	{id: "lock_order_inversion", substrings: []string{"lock-order-inversion"}},
 	{id: "memory_leak", substrings: []string{"detected memory leaks"}},
 	{id: "negative_array_size", substrings: []string{"java.lang.NegativeArraySizeException"}},
 	{id: "null_pointer", substrings: []string{"java.lang.NullPointerException"}},
 	{id: "number_format", substrings: []string{"java.lang.NumberFormatException"}},
 	{id: "os_command_injection", substrings: []string{"Command Injection"}},
-	{id: "out_of_memory", substrings: []string{"out-of-memory"}},
+	{id: "out_of_memory", substrings: []string{"out-of-memory", "fatal error: runtime: out of memory", "MemoryError"}},
 	{id: "regex_injection", substrings: []string{"Security Issue: Regular Expression Injection"}},
 	{id: "remote_code_execution", substrings: []string{"Security Issue: Remote Code Execution"}},
-	{id: "segmentation_fault", substrings: []string{"SEGV on unknown address"}},
+	{id: "segmentation_fault", substrings: []string{"SEGV on unknown address", "invalid memory address or nil pointer dereference"}},
 	{id: "signed_integer_overflow", substrings: []string{"undefined behavior: signed integer overflow"}},
+	{id: "integer_overflow", regexs: []*regexp.Regexp{regexp.MustCompile(`attempt to (add|subtract|multiply|negate) with overflow`)}},
+	{id: "division_by_zero", substrings: []string{"attempt to divide by zero", "attempt to calculate the remainder with a divisor of zero", "ZeroDivisionError"}},
 	{id: "slow_input", substrings: []string{"Slow input detected. Processing time:"}},
 	{id: "stack_buffer_overflow", substrings: []string{"stack-buffer-overflow on address"}},
-	{id: "stack_exhaustion", substrings: []string{"stack-overflow on address"}},
+	{id: "stack_exhaustion", substrings: []string{"stack-overflow on address", "fatal error: stack overflow", "RecursionError: maximum recursion depth exceeded"}},
 	{id: "sql_injection", substrings: []string{"Security Issue: SQL Injection"}},
 	{
 		id:         "timeout",
 		substrings: []string{"timeout"},
 		regexs:     []*regexp.Regexp{regexp.MustCompile(`timeout after \d+ \w+`)},
 	},
-	{id: "shift_exponent", regexs: []*regexp.Regexp{regexp.MustCompile(`undefined behaviou?r: shift exponent.+`)}},
+	{id: "shift_exponent", regexs: []*regexp.Regexp{
+		regexp.MustCompile(`undefined behaviou?r: shift exponent.+`),
+		regexp.MustCompile(`attempt to shift (left|right) with overflow`),
+	}},
 	{id: "use_after_return", substrings: []string{"stack-use-after-return on address"}},
 	{id: "use_after_scope", substrings: []string{"stack-use-after-scope on address"}},
 	{id: "use_of_uninitialized_value", substrings: []string{"use-of-uninitialized-value"}},
@@ -70,12 +89,111 @@ var matchers = []matcher{
 	{id: "server_side_request_forgery", substrings: []string{"Security Issue: Server Side Request Forgery"}},
 
 	// more global issues, should be at the end so they do not overwrite more explicit ones
+	{id: "rust_panic", substrings: []string{"Rust panic"}},
 	{id: "java_exception", regexs: []*regexp.Regexp{regexp.MustCompile(`java\.lang.+|Exception`)}},
 	{id: "jazzer_security_issue", substrings: []string{"Security Issue:"}},
 	{id: "Crash", regexs: []*regexp.Regexp{regexp.MustCompile(`Error|Crash`)}},
 }
 
+// UserDefinedError is an error declared in the "error-details" section
+// of the cifuzz.yaml. It allows projects to classify findings reported
+// by their own bug oracles, like custom assertion macros or Jazzer
+// sanitizers, which would otherwise only be reported as "Crash".
+type UserDefinedError struct {
+	ID string `mapstructure:"id"`
+	// Findings whose details or output contain one of the substrings or
+	// match one of the regular expressions get the ID of this error
+	Substrings []string `mapstructure:"substrings"`
+	Regexes    []string `mapstructure:"regexes"`
+
+	Name        string `mapstructure:"name"`
+	Description string `mapstructure:"description"`
+	// The severity score between 0 and 10, as in CVSS
+	Severity   float32 `mapstructure:"severity"`
+	CWE        int64   `mapstructure:"cwe"`
+	Mitigation string  `mapstructure:"mitigation"`
+}
+
+// The matchers of the user-defined errors, which are checked before
+// the built-in matchers
+var userDefinedMatchers []matcher
+
+// AddUserDefinedErrors registers the matchers of the specified errors,
+// so that they are used by ForFinding, and adds their error details to
+// the finding.ErrorDetailsCollection. Previously added user-defined
+// errors are replaced.
+func AddUserDefinedErrors(userErrors []*UserDefinedError) error {
+	var newMatchers []matcher
+	var newErrorDetails []*finding.ErrorDetails
+	for _, e := range userErrors {
+		if e.ID == "" {
+			return errors.New("Error details in cifuzz.yaml must have an \"id\"")
+		}
+		if e.Severity < 0 || e.Severity > 10 {
+			return errors.Errorf("Severity of error %q must be between 0 and 10, got %v", e.ID, e.Severity)
+		}
+
+		m := matcher{id: e.ID, substrings: e.Substrings}
+		for _, r := range e.Regexes {
+			regex, err := regexp.Compile(r)
+			if err != nil {
+				return errors.Wrapf(err, "Invalid regex %q for error %q", r, e.ID)
+			}
+			m.regexs = append(m.regexs, regex)
+		}
+		// Errors without any matchers can be used to override the error
+		// details of built-in errors
+		if len(m.substrings) > 0 || len(m.regexs) > 0 {
+			newMatchers = append(newMatchers, m)
+		}
+
+		details := &finding.ErrorDetails{
+			ID:          e.ID,
+			Name:        e.Name,
+			Description: e.Description,
+			Mitigation:  e.Mitigation,
+		}
+		if e.Severity != 0 {
+			details.Severity = &finding.Severity{
+				Level: finding.SeverityLevelForScore(e.Severity),
+				Score: e.Severity,
+			}
+		}
+		if e.CWE != 0 {
+			details.CweDetails = &finding.ExternalDetail{ID: e.CWE}
+		}
+		newErrorDetails = append(newErrorDetails, details)
+	}
+
+	ResetUserDefinedErrors()
+	userDefinedMatchers = newMatchers
+	finding.AddErrorDetails(newErrorDetails...)
+	return nil
+}
+
+// ResetUserDefinedErrors removes all errors added via
+// AddUserDefinedErrors.
+func ResetUserDefinedErrors() {
+	userDefinedMatchers = nil
+	finding.ResetErrorDetails()
+}
+
 func ForFinding(f *finding.Finding) string {
+	// The messages of custom bug oracles (e.g. assertion macros) are
+	// often only part of the output and not of the details, so the
+	// user-defined errors are also matched against the logs
+	for _, m := range userDefinedMatchers {
+		if m.Match(f.Details) {
+			return m.id
+		}
+	}
+	for _, m := range userDefinedMatchers {
+		for _, line := range f.Logs {
+			if m.Match(line) {
+				return m.id
+			}
+		}
+	}
 	for _, m := range matchers {
 		if m.Match(f.Details) {
 			return m.id
@@ -4,8 +4,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"code-intelligence.com/cifuzz/internal/builder"
 	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/runfiles"
 )
 
 func TestForFinding(t *testing.T) {
@@ -15,6 +18,7 @@ func TestForFinding(t *testing.T) {
 	}{
 		{id: "alloc_dealloc_mismatch", f: &finding.Finding{Details: "attempting free on address which was not malloc()-ed: 0x7ffebd8d4e10 in thread T0"}},
 		{id: "double_free", f: &finding.Finding{Details: "attempting double-free on 0x6020000422b0 in thread T0:"}},
+		{id: "data_race", f: &finding.Finding{Details: "data race"}},
 		{id: "deadly_signal", f: &finding.Finding{Details: "deadly signal"}},
 		{id: "heap_buffer_overflow", f: &finding.Finding{Details: "heap-buffer-overflow on address 0x602000000e31 at pc 0x55657aa63e9f bp 0x7ffdae3791b0 sp 0x7ffdae378970"}},
 		{id: "heap_use_after_free", f: &finding.Finding{Details: "heap-use-after-free on address 0x602000000e31 at pc 0x55657aa63e9f bp 0x7ffdae3791b0 sp 0x7ffdae378970"}},
@@ -22,9 +26,23 @@ func TestForFinding(t *testing.T) {
 		{id: "java_assertion_error", f: &finding.Finding{Details: "Java Assertion Error"}},
 		{id: "java_out_of_bounds", f: &finding.Finding{Details: "java.lang.ArrayIndexOutOfBoundsException"}},
 		{id: "out_of_bounds", f: &finding.Finding{Details: "undefined behavior: index 12 out of bounds for type 'int[4]'"}},
+		{id: "out_of_bounds", f: &finding.Finding{Details: "panic: runtime error: index out of range [3] with length 3"}},
+		{id: "out_of_bounds", f: &finding.Finding{Details: "panic: runtime error: slice bounds out of range [:5] with capacity 4"}},
+		{id: "out_of_bounds", f: &finding.Finding{Details: "Rust panic: index out of bounds: the len is 3 but the index is 5"}},
+		{id: "out_of_bounds", f: &finding.Finding{Details: "Rust panic: range end index 8 out of range for slice of length 4"}},
+		{id: "integer_overflow", f: &finding.Finding{Details: "Rust panic: attempt to multiply with overflow"}},
+		{id: "division_by_zero", f: &finding.Finding{Details: "Rust panic: attempt to divide by zero"}},
+		{id: "shift_exponent", f: &finding.Finding{Details: "Rust panic: attempt to shift left with overflow"}},
+		{id: "rust_panic", f: &finding.Finding{Details: "Rust panic: called `Option::unwrap()` on a `None` value"}},
+		{id: "out_of_bounds", f: &finding.Finding{Details: "IndexError: list index out of range"}},
+		{id: "division_by_zero", f: &finding.Finding{Details: "ZeroDivisionError: division by zero"}},
+		{id: "lock_order_inversion", f: &finding.Finding{Details: "lock-order-inversion (potential deadlock)"}},
+		{id: "use_of_uninitialized_value", f: &finding.Finding{Details: "use-of-uninitialized-value"}},
+		{id: "stack_exhaustion", f: &finding.Finding{Details: "RecursionError: maximum recursion depth exceeded while calling a Python object"}},
 		{id: "out_of_memory", f: &finding.Finding{Details: "out-of-memory"}},
 		{id: "remote_code_execution", f: &finding.Finding{Details: "Security Issue: Remote Code Execution"}},
 		{id: "segmentation_fault", f: &finding.Finding{Details: "SEGV on unknown address"}},
+		{id: "segmentation_fault", f: &finding.Finding{Details: "panic: runtime error: invalid memory address or nil pointer dereference"}},
 		{id: "shift_exponent", f: &finding.Finding{Details: "undefined behavior: shift exponent 32 is too large for 32-bit type 'int'"}},
 		{id: "signed_integer_overflow", f: &finding.Finding{Details: "undefined behavior: signed integer overflow"}},
 		{id: "slow_input", f: &finding.Finding{Details: "Slow input detected. Processing time: 10s"}},
@@ -59,3 +77,104 @@ func TestForFinding(t *testing.T) {
 		})
 	}
 }
+
+func TestUserDefinedErrors(t *testing.T) {
+	t.Cleanup(ResetUserDefinedErrors)
+
+	err := AddUserDefinedErrors([]*UserDefinedError{
+		{
+			ID:         "invariant_violation",
+			Substrings: []string{"INVARIANT VIOLATED"},
+			Regexes:    []string{`check_\w+ failed`},
+			Name:       "Invariant Violation",
+			Severity:   7.5,
+			CWE:        617,
+		},
+		// Overrides the built-in error which contains "Security Issue:"
+		{ID: "custom_sanitizer", Substrings: []string{"Security Issue: Custom"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "invariant_violation", ForFinding(&finding.Finding{Details: "INVARIANT VIOLATED: x > 0"}))
+	assert.Equal(t, "invariant_violation", ForFinding(&finding.Finding{Details: "check_bounds failed"}))
+	assert.Equal(t, "custom_sanitizer", ForFinding(&finding.Finding{Details: "Security Issue: Custom sink reached"}))
+	assert.Equal(t, "heap_buffer_overflow", ForFinding(&finding.Finding{Details: "heap-buffer-overflow on address 0x602000000e31"}))
+	// Custom abort messages are only part of the logs
+	assert.Equal(t, "invariant_violation", ForFinding(&finding.Finding{
+		Details: "deadly signal",
+		Logs:    []string{"INVARIANT VIOLATED: x > 0", "==1234== ERROR: libFuzzer: deadly signal"},
+	}))
+	// Matches in the details take precedence over matches in the logs
+	assert.Equal(t, "custom_sanitizer", ForFinding(&finding.Finding{
+		Details: "Security Issue: Custom sink reached",
+		Logs:    []string{"check_bounds failed"},
+	}))
+
+	err = AddUserDefinedErrors([]*UserDefinedError{{ID: "invalid", Regexes: []string{"("}}})
+	require.Error(t, err)
+	err = AddUserDefinedErrors([]*UserDefinedError{{Substrings: []string{"foo"}}})
+	require.Error(t, err)
+	err = AddUserDefinedErrors([]*UserDefinedError{{ID: "too_severe", Severity: 11}})
+	require.Error(t, err)
+}
+
+func TestUserDefinedErrors_WithoutName(t *testing.T) {
+	sourceDir, err := builder.FindProjectDir()
+	require.NoError(t, err)
+	runfiles.Finder = runfiles.RunfilesFinderImpl{InstallDir: sourceDir}
+	t.Cleanup(ResetUserDefinedErrors)
+
+	// An error without a name which only overrides the severity of a
+	// built-in error must not be attached to other findings
+	err = AddUserDefinedErrors([]*UserDefinedError{{ID: "custom_severity", Severity: 9}})
+	require.NoError(t, err)
+
+	f := &finding.Finding{
+		Type:    finding.ErrorTypeCrash,
+		Details: "heap-buffer-overflow on address 0x602000000e31",
+	}
+	err = f.EnhanceWithErrorDetails()
+	require.NoError(t, err)
+	require.NotNil(t, f.MoreDetails)
+	assert.NotEqual(t, "custom_severity", f.MoreDetails.ID)
+}
+
+func TestUserDefinedErrors_OverrideBuiltinSeverity(t *testing.T) {
+	sourceDir, err := builder.FindProjectDir()
+	require.NoError(t, err)
+	runfiles.Finder = runfiles.RunfilesFinderImpl{InstallDir: sourceDir}
+	t.Cleanup(ResetUserDefinedErrors)
+
+	// Adding the errors again must replace the previously added ones
+	// instead of accumulating them
+	for i := 0; i < 2; i++ {
+		err = AddUserDefinedErrors([]*UserDefinedError{{ID: "heap_buffer_overflow", Severity: 2}})
+		require.NoError(t, err)
+	}
+	errorDetails, err := finding.ErrorDetailsCollection()
+	require.NoError(t, err)
+	var count int
+	for _, d := range errorDetails {
+		if d.ID == "heap_buffer_overflow" {
+			count++
+		}
+	}
+	assert.Equal(t, 1, count)
+
+	f := &finding.Finding{
+		Type:    finding.ErrorTypeCrash,
+		Details: "heap-buffer-overflow on address 0x602000000e31",
+	}
+	err = f.EnhanceWithErrorDetails()
+	require.NoError(t, err)
+	require.NotNil(t, f.MoreDetails)
+	assert.Equal(t, "heap_buffer_overflow", f.MoreDetails.ID)
+	require.NotNil(t, f.MoreDetails.Severity)
+	assert.Equal(t, float32(2), f.MoreDetails.Severity.Score)
+	// The fields which are not overridden are kept from the built-in
+	// error details
+	assert.Equal(t, "Heap Buffer Overflow", f.MoreDetails.Name)
+	assert.NotEmpty(t, f.MoreDetails.Description)
+	assert.NotEmpty(t, f.MoreDetails.Mitigation)
+	assert.NotEmpty(t, f.MoreDetails.Links)
+}
@@ -0,0 +1,305 @@
+// Package gofuzz parses the output of native Go fuzz tests, i.e. the
+// output of `go test -fuzz`.
+package gofuzz
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/parser/errorid"
+	"code-intelligence.com/cifuzz/pkg/parser/libfuzzer/stacktrace"
+	"code-intelligence.com/cifuzz/pkg/report"
+	"code-intelligence.com/cifuzz/util/regexutil"
+)
+
+var (
+	// Example:
+	// fuzz: elapsed: 0s, gathering baseline coverage: 1/1 completed, now fuzzing with 8 workers
+	baselineCoveragePattern = regexp.MustCompile(
+		`^fuzz: elapsed: \S+, gathering baseline coverage: (?P<done>\d+)/(?P<total>\d+) completed`)
+	emptyCorpusPattern = regexp.MustCompile(`^warning: starting with empty corpus`)
+	// Example:
+	// fuzz: elapsed: 3s, execs: 203768 (67920/sec), new interesting: 2 (total: 3)
+	statsPattern = regexp.MustCompile(
+		`^fuzz: elapsed: (?P<elapsed>\S+), execs: (?P<execs>\d+) \((?P<execs_per_second>\d+)/sec\), new interesting: (?P<new_interesting>\d+) \(total: (?P<total>\d+)\)`)
+	failPattern         = regexp.MustCompile(`^--- FAIL: (?P<name>\S+)`)
+	endOfFailurePattern = regexp.MustCompile(`^(FAIL|exit status \d+)`)
+	// The message of a failed test is prefixed with the location of
+	// the t.Error / t.Fatal call, e.g. "parser_test.go:15: bad input"
+	messagePattern      = regexp.MustCompile(`^\s*\S+\.go:\d+: (?P<message>.+)`)
+	panicPattern        = regexp.MustCompile(`^\s*(?:\S+\.go:\d+: )?(?P<message>panic: .+)`)
+	hungPattern         = regexp.MustCompile(`^\s*(?P<message>fuzzing process hung or terminated unexpectedly.*)`)
+	failingInputPattern = regexp.MustCompile(`^\s*Failing input written to (?P<path>\S+)`)
+	// Example:
+	// example.com/project/parser.Parse(...)
+	//     /home/user/project/parser/parser.go:6 +0x13d
+	stackFrameFunctionPattern = regexp.MustCompile(`^\s*(?P<function>[^\s(]\S*)\(.*\)$`)
+	stackFrameLocationPattern = regexp.MustCompile(`^\s*(?P<file>\S+\.go):(?P<line>\d+)(?: \+0x[0-9a-f]+)?$`)
+)
+
+// Functions which are part of every panic stack trace and therefore
+// not useful to identify a finding
+var ignoredStackFramePrefixes = []string{
+	"panic",
+	"runtime.",
+	"runtime/debug.",
+	"testing.",
+	"reflect.",
+}
+
+type Options struct {
+	// The directory of the package containing the fuzz test. Go writes
+	// failing inputs to a path relative to this directory.
+	PackageDir string
+	// The directory to which paths in the stack trace are made relative to
+	ProjectDir string
+	// The parser writes all parsed lines to OutputWriter (if set)
+	OutputWriter io.Writer
+}
+
+type parser struct {
+	*Options
+
+	// FindingReported is set when the parser sent a report which
+	// contains a finding
+	FindingReported bool
+
+	reportsCh chan *report.Report
+
+	initFinished bool
+
+	// The finding which is currently parsed. Go prints the whole
+	// failure report before the "FAIL" line, so we send the finding
+	// once that line was parsed.
+	pendingFinding *finding.Finding
+	// The function of the stack frame which is currently parsed
+	pendingStackFrameFunction string
+
+	lastInteresting    int
+	lastInterestingAge time.Duration
+}
+
+func NewOutputParser(options *Options) *parser {
+	if options == nil {
+		options = &Options{}
+	}
+	return &parser{Options: options}
+}
+
+// Parse parses the output of `go test -fuzz` and sends reports to the
+// reports channel, which is closed when the input was read completely.
+func (p *parser) Parse(ctx context.Context, input io.Reader, reportsCh chan *report.Report) error {
+	p.reportsCh = reportsCh
+	defer close(p.reportsCh)
+
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if p.OutputWriter != nil {
+			_, err := io.WriteString(p.OutputWriter, line+"\n")
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		err := p.parseLine(ctx, line)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The output was closed, which means that the fuzz test exited. If
+	// there is still a pending finding, send it now.
+	if p.pendingFinding != nil {
+		err := p.sendFinding(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return errors.WithStack(scanner.Err())
+}
+
+func (p *parser) parseLine(ctx context.Context, line string) error {
+	if p.pendingFinding != nil {
+		if _, found := regexutil.FindNamedGroupsMatch(endOfFailurePattern, line); found {
+			return p.sendFinding(ctx)
+		}
+		p.parseFindingLine(line)
+		return nil
+	}
+
+	if result, found := regexutil.FindNamedGroupsMatch(baselineCoveragePattern, line); found {
+		if p.initFinished {
+			return nil
+		}
+		p.initFinished = true
+		numSeeds, err := strconv.ParseUint(result["total"], 10, 32)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return p.send(ctx, &report.Report{
+			Status:   report.RunStatusInitializing,
+			NumSeeds: uint(numSeeds),
+		})
+	}
+
+	if _, found := regexutil.FindNamedGroupsMatch(emptyCorpusPattern, line); found {
+		p.initFinished = true
+		return p.send(ctx, &report.Report{Status: report.RunStatusInitializing})
+	}
+
+	if result, found := regexutil.FindNamedGroupsMatch(statsPattern, line); found {
+		return p.send(ctx, &report.Report{
+			Status: report.RunStatusRunning,
+			Metric: p.parseMetric(result),
+		})
+	}
+
+	if _, found := regexutil.FindNamedGroupsMatch(failPattern, line); found {
+		p.pendingFinding = &finding.Finding{
+			Type: finding.ErrorTypeRuntimeError,
+			Logs: []string{line},
+		}
+		return nil
+	}
+
+	return nil
+}
+
+func (p *parser) parseMetric(result map[string]string) *report.FuzzingMetric {
+	elapsed, _ := time.ParseDuration(result["elapsed"])
+	execs, _ := strconv.ParseUint(result["execs"], 10, 64)
+	execsPerSecond, _ := strconv.ParseInt(result["execs_per_second"], 10, 32)
+	interesting, _ := strconv.Atoi(result["new_interesting"])
+	corpusSize, _ := strconv.ParseInt(result["total"], 10, 32)
+
+	// Go doesn't print the number of covered edges, so we use the
+	// number of new interesting inputs to determine when the fuzzer
+	// made progress the last time
+	if interesting != p.lastInteresting {
+		p.lastInteresting = interesting
+		p.lastInterestingAge = elapsed
+	}
+	secondsSinceLastProgress := uint64((elapsed - p.lastInterestingAge).Seconds())
+
+	return &report.FuzzingMetric{
+		Timestamp:               time.Now(),
+		ExecutionsPerSecond:     int32(execsPerSecond),
+		CorpusSize:              int32(corpusSize),
+		SecondsSinceLastFeature: secondsSinceLastProgress,
+		TotalExecutions:         execs,
+		SecondsSinceLastEdge:    secondsSinceLastProgress,
+	}
+}
+
+func (p *parser) parseFindingLine(line string) {
+	f := p.pendingFinding
+	f.Logs = append(f.Logs, line)
+
+	if result, found := regexutil.FindNamedGroupsMatch(failingInputPattern, line); found {
+		f.InputFile = result["path"]
+		if !filepath.IsAbs(f.InputFile) && p.PackageDir != "" {
+			f.InputFile = filepath.Join(p.PackageDir, f.InputFile)
+		}
+		return
+	}
+
+	if f.Details == "" {
+		if result, found := regexutil.FindNamedGroupsMatch(panicPattern, line); found {
+			f.Type = finding.ErrorTypeCrash
+			f.Details = result["message"]
+			return
+		}
+		if result, found := regexutil.FindNamedGroupsMatch(hungPattern, line); found {
+			f.Type = finding.ErrorTypeCrash
+			f.Details = result["message"]
+			return
+		}
+		if result, found := regexutil.FindNamedGroupsMatch(messagePattern, line); found {
+			f.Details = result["message"]
+			return
+		}
+	}
+
+	if result, found := regexutil.FindNamedGroupsMatch(stackFrameFunctionPattern, line); found {
+		p.pendingStackFrameFunction = result["function"]
+		return
+	}
+	if result, found := regexutil.FindNamedGroupsMatch(stackFrameLocationPattern, line); found {
+		function := p.pendingStackFrameFunction
+		p.pendingStackFrameFunction = ""
+		if function == "" || isIgnoredStackFrame(function) {
+			return
+		}
+		lineNumber, err := strconv.ParseUint(result["line"], 10, 32)
+		if err != nil {
+			return
+		}
+		f.StackTrace = append(f.StackTrace, &stacktrace.StackFrame{
+			SourceFile:  p.relativeSourceFile(result["file"]),
+			Line:        uint32(lineNumber),
+			FrameNumber: uint32(len(f.StackTrace)),
+			Function:    function,
+		})
+	}
+}
+
+func (p *parser) relativeSourceFile(path string) string {
+	if p.ProjectDir == "" {
+		return path
+	}
+	relPath, err := filepath.Rel(p.ProjectDir, path)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return path
+	}
+	return filepath.ToSlash(relPath)
+}
+
+func isIgnoredStackFrame(function string) bool {
+	for _, prefix := range ignoredStackFramePrefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) sendFinding(ctx context.Context) error {
+	f := p.pendingFinding
+	p.pendingFinding = nil
+
+	if f.InputFile != "" {
+		var err error
+		f.InputData, err = os.ReadFile(f.InputFile)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	f.MoreDetails = &finding.ErrorDetails{
+		ID: errorid.ForFinding(f),
+	}
+
+	p.FindingReported = true
+	return p.send(ctx, &report.Report{
+		Status:  report.RunStatusRunning,
+		Finding: f,
+	})
+}
+
+func (p *parser) send(ctx context.Context, report *report.Report) error {
+	select {
+	case p.reportsCh <- report:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
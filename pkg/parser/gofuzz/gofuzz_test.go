@@ -0,0 +1,148 @@
+package gofuzz
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/testutil"
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/parser/libfuzzer/stacktrace"
+	"code-intelligence.com/cifuzz/pkg/report"
+)
+
+const panicOutput = `fuzz: elapsed: 0s, gathering baseline coverage: 0/2 completed
+fuzz: elapsed: 0s, gathering baseline coverage: 2/2 completed, now fuzzing with 8 workers
+fuzz: elapsed: 3s, execs: 203768 (67920/sec), new interesting: 2 (total: 4)
+fuzz: elapsed: 6s, execs: 408251 (68149/sec), new interesting: 2 (total: 4)
+fuzz: minimizing 30-byte failing input file
+fuzz: elapsed: 6s, minimizing
+--- FAIL: FuzzParse (6.47s)
+    --- FAIL: FuzzParse (0.00s)
+        testing.go:2076: panic: runtime error: index out of range [3] with length 0
+            goroutine 29991 [running]:
+            runtime/debug.Stack()
+            	/usr/local/go/src/runtime/debug/stack.go:26 +0x9b
+            testing.tRunner.func1()
+            	/usr/local/go/src/testing/testing.go:2076 +0x1b0
+            panic({0x859090?, 0x58c8a10e1f8?})
+            	/usr/local/go/src/runtime/panic.go:859 +0x125
+            example.com/project/parser.Parse(...)
+            	/project/parser/parser.go:6
+            example.com/project/parser.FuzzParse.func1(0x0?, {0x58c94024630, 0x3, 0x48c213?})
+            	/project/parser/parser_test.go:8 +0x13d
+            reflect.Value.call({0x826b78?, 0x8680e8?, 0x13?}, {0x64b397, 0x4}, {0x58c94035500, 0x2, 0x2?})
+            	/usr/local/go/src/reflect/value.go:586 +0xed9
+            testing.(*F).Fuzz.func1.1(0x58c94076008?)
+            	/usr/local/go/src/testing/fuzz.go:341 +0x312
+            created by testing.(*F).Fuzz.func1 in goroutine 6
+            	/usr/local/go/src/testing/fuzz.go:328 +0x678
+
+    Failing input written to testdata/fuzz/FuzzParse/d8a2d69b45e4a09a
+    To re-run:
+    go test -run=FuzzParse/d8a2d69b45e4a09a
+FAIL
+exit status 1
+FAIL	example.com/project/parser	6.471s
+`
+
+const failureOutput = `warning: starting with empty corpus
+fuzz: elapsed: 0s, execs: 0 (0/sec), new interesting: 0 (total: 0)
+fuzz: minimizing 52-byte failing input file
+fuzz: elapsed: 0s, minimizing
+--- FAIL: FuzzFail (0.03s)
+    --- FAIL: FuzzFail (0.00s)
+        parser_test.go:15: bad input "x000"
+
+    Failing input written to testdata/fuzz/FuzzFail/1de061fa29cfbb3d
+    To re-run:
+    go test -run=FuzzFail/1de061fa29cfbb3d
+FAIL
+exit status 1
+FAIL	example.com/project/parser	0.036s
+`
+
+func parse(t *testing.T, packageDir string, output string) []*report.Report {
+	reportsCh := make(chan *report.Report, 100)
+	p := NewOutputParser(&Options{PackageDir: packageDir, ProjectDir: "/project"})
+	err := p.Parse(context.Background(), strings.NewReader(output), reportsCh)
+	require.NoError(t, err)
+
+	var reports []*report.Report
+	for r := range reportsCh {
+		reports = append(reports, r)
+	}
+	return reports
+}
+
+func writeInput(t *testing.T, packageDir string, path string, content string) {
+	path = filepath.Join(packageDir, path)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestParse_Panic(t *testing.T) {
+	packageDir := testutil.MkdirTemp(t, "", "gofuzz-parser-test-")
+	input := "go test fuzz v1\n[]byte(\"FU0\")\n"
+	writeInput(t, packageDir, "testdata/fuzz/FuzzParse/d8a2d69b45e4a09a", input)
+
+	reports := parse(t, packageDir, panicOutput)
+	require.Len(t, reports, 4)
+
+	assert.Equal(t, report.RunStatusInitializing, reports[0].Status)
+	assert.EqualValues(t, 2, reports[0].NumSeeds)
+
+	assert.EqualValues(t, 203768, reports[1].Metric.TotalExecutions)
+	assert.EqualValues(t, 67920, reports[1].Metric.ExecutionsPerSecond)
+	assert.EqualValues(t, 4, reports[1].Metric.CorpusSize)
+	assert.EqualValues(t, 0, reports[1].Metric.SecondsSinceLastFeature)
+	// No new interesting inputs were found in the last three seconds
+	assert.EqualValues(t, 3, reports[2].Metric.SecondsSinceLastFeature)
+
+	f := reports[3].Finding
+	require.NotNil(t, f)
+	assert.Equal(t, finding.ErrorTypeCrash, f.Type)
+	assert.Equal(t, "panic: runtime error: index out of range [3] with length 0", f.Details)
+	assert.Equal(t, "out_of_bounds", f.MoreDetails.ID)
+	assert.Equal(t, filepath.Join(packageDir, "testdata/fuzz/FuzzParse/d8a2d69b45e4a09a"), f.InputFile)
+	assert.Equal(t, []byte(input), f.InputData)
+	assert.Equal(t, []*stacktrace.StackFrame{
+		{
+			SourceFile:  "parser/parser.go",
+			Line:        6,
+			FrameNumber: 0,
+			Function:    "example.com/project/parser.Parse",
+		},
+		{
+			SourceFile:  "parser/parser_test.go",
+			Line:        8,
+			FrameNumber: 1,
+			Function:    "example.com/project/parser.FuzzParse.func1",
+		},
+	}, f.StackTrace)
+	assert.Equal(t, "--- FAIL: FuzzParse (6.47s)", f.Logs[0])
+	assert.Equal(t, "    go test -run=FuzzParse/d8a2d69b45e4a09a", f.Logs[len(f.Logs)-1])
+}
+
+func TestParse_TestFailure(t *testing.T) {
+	packageDir := testutil.MkdirTemp(t, "", "gofuzz-parser-test-")
+	writeInput(t, packageDir, "testdata/fuzz/FuzzFail/1de061fa29cfbb3d", "go test fuzz v1\nstring(\"x000\")\n")
+
+	reports := parse(t, packageDir, failureOutput)
+	require.Len(t, reports, 3)
+
+	assert.Equal(t, report.RunStatusInitializing, reports[0].Status)
+	assert.EqualValues(t, 0, reports[0].NumSeeds)
+
+	f := reports[2].Finding
+	require.NotNil(t, f)
+	assert.Equal(t, finding.ErrorTypeRuntimeError, f.Type)
+	assert.Equal(t, `bad input "x000"`, f.Details)
+	assert.Empty(t, f.StackTrace)
+	assert.NotEmpty(t, f.InputData)
+}
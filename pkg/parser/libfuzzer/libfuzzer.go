@@ -63,6 +63,12 @@ var (
 		`FAIL Jazzer\.js`,
 	)
 
+	// Atheris prints the exception on the line following this one,
+	// followed by the traceback
+	atherisUncaughtExceptionPattern = regexp.MustCompile(
+		`=== Uncaught Python exception: ===`,
+	)
+
 	// Examples for matching strings:
 	// #2	INITED cov: 10 ft: 11 corp: 1/1b exec/s: 0 rss: 30Mb
 	// #670	REDUCE cov: 13 ft: 15 corp: 4/5b lim: 8 exec/s: 0 rss: 31Mb L: 1/2 MS: 2 CopyPart-EraseBytes-
@@ -73,8 +79,28 @@ var (
 	slowInputPattern = regexp.MustCompile(
 		`\s*Slowest unit: (?P<duration>\d+) s.*`)
 	goPanicPattern = regexp.MustCompile(`^panic:\s+\S+`)
+	// Before Rust 1.73, panics are printed with the message in the
+	// same line as
+	// thread '<unnamed>' panicked at 'attempt to add with overflow', src/lib.rs:3:5
+	// If the message spans multiple lines, the location is printed
+	// after the last line of the message instead.
+	rustPanicPattern          = regexp.MustCompile(`^thread '[^']*' panicked at '(?P<message>.*)', \S+:\d+:\d+$`)
+	rustMultilinePanicPattern = regexp.MustCompile(`^thread '[^']*' panicked at '(?P<message>.*)$`)
+	// Since Rust 1.73, the message is printed on the line following
+	// the location as
+	// thread '<unnamed>' panicked at src/lib.rs:3:5:
+	// attempt to add with overflow
+	rustPanicLocationPattern = regexp.MustCompile(`^thread '[^']*' panicked at \S+:\d+:\d+:$`)
 )
 
+// The details of a Rust panic finding for which the panic message was
+// not parsed yet
+const rustPanicDetails = "Rust panic"
+
+// The details of an Atheris finding for which the exception was not
+// parsed yet
+const pythonExceptionDetails = "Uncaught Python exception"
+
 var errNotFound = errors.New("not found")
 
 type parser struct {
@@ -99,6 +125,10 @@ type parser struct {
 
 	foundBeginningOfJestReport bool
 
+	// Whether the pending finding is a Rust panic whose message is
+	// printed on the next line
+	expectRustPanicMessage bool
+
 	lastNewFeatureTime time.Time // Timestamp representing the point when the last new feature was reported
 	lastFeatures       int       // Last features reported by Libfuzzer
 	lastNewEdgeTime    time.Time // Timestamp representing the point when the last new edge was reported
@@ -108,6 +138,7 @@ type parser struct {
 type Options struct {
 	SupportJazzer   bool
 	SupportJazzerJS bool
+	SupportAtheris  bool
 	KeepColor       bool
 	// The parser writes all parsed lines to StartupOutputWriter up to
 	// the point where the fuzzer has completed initialization.
@@ -255,7 +286,7 @@ func (p *parser) parseLine(ctx context.Context, line string) error {
 
 	finding := p.parseAsNewFinding(line)
 
-	if finding != nil && !p.libFuzzerErrorFollowingGoPanic(finding) {
+	if finding != nil && !p.libFuzzerErrorFollowingPanic(finding) {
 		// If there is still a pending finding, send it now, because
 		// we'll treat all further output lines as belonging to the new
 		// finding.
@@ -268,6 +299,7 @@ func (p *parser) parseLine(ctx context.Context, line string) error {
 		// parsing more output lines which might belong to the error
 		// report and contain relevant info.
 		p.pendingFinding = finding
+		p.expectRustPanicMessage = finding.Details == rustPanicDetails
 
 		return nil
 	}
@@ -278,6 +310,19 @@ func (p *parser) parseLine(ctx context.Context, line string) error {
 		if !minijail.IsIgnoredLine(line) && !p.foundBeginningOfJestReport {
 			p.pendingFinding.Logs = append(p.pendingFinding.Logs, line)
 		}
+
+		// Recent Rust versions print the panic message on the line
+		// following the panic location
+		if p.expectRustPanicMessage {
+			p.expectRustPanicMessage = false
+			p.pendingFinding.Details += ": " + line
+		}
+
+		// The line following the Atheris exception header contains the
+		// type and message of the exception, e.g. "ValueError: message"
+		if p.pendingFinding.Details == pythonExceptionDetails && strings.TrimSpace(line) != "" {
+			p.pendingFinding.Details = strings.TrimSpace(line)
+		}
 	}
 
 	// Check if the line contains the path to the test input file (which
@@ -317,16 +362,28 @@ func (p *parser) parseAsNewFinding(line string) *finding.Finding {
 		}
 	}
 
+	if p.SupportAtheris {
+		finding := p.parseAsAtherisFinding(line)
+		if finding != nil {
+			return finding
+		}
+	}
+
 	finding := p.parseAsGoFinding(line)
 	if finding != nil {
 		return finding
 	}
 
+	finding = p.parseAsRustFinding(line)
+	if finding != nil {
+		return finding
+	}
+
 	finding = p.parseAsLibfuzzerFinding(line)
 	if finding != nil {
-		// If JazzerJS is supported, the libfuzzer finding is part of a
-		// JazzerJS finding and should not be treated as a new finding
-		if p.SupportJazzerJS && p.pendingFinding != nil {
+		// If JazzerJS or Atheris is supported, the libfuzzer finding is
+		// part of their finding and should not be treated as a new finding
+		if (p.SupportJazzerJS || p.SupportAtheris) && p.pendingFinding != nil {
 			return nil
 		}
 		return finding
@@ -364,8 +421,38 @@ func (p *parser) parseAsGoFinding(line string) *finding.Finding {
 	return nil
 }
 
-func (p *parser) libFuzzerErrorFollowingGoPanic(report *finding.Finding) bool {
-	return p.pendingFinding.GetDetails() == "Go Panic" && report.GetDetails() != "Go Panic"
+func (p *parser) parseAsRustFinding(line string) *finding.Finding {
+	details := rustPanicDetails
+	switch {
+	case rustPanicLocationPattern.MatchString(line):
+		// The message is printed on the next line, which is added to
+		// the details once it's parsed
+	case rustPanicPattern.MatchString(line):
+		result, _ := regexutil.FindNamedGroupsMatch(rustPanicPattern, line)
+		details += ": " + result["message"]
+	case rustMultilinePanicPattern.MatchString(line):
+		result, _ := regexutil.FindNamedGroupsMatch(rustMultilinePanicPattern, line)
+		details += ": " + result["message"]
+	default:
+		return nil
+	}
+	return &finding.Finding{
+		Type:    finding.ErrorTypeCrash,
+		Details: details,
+		Logs:    []string{line},
+	}
+}
+
+// libFuzzerErrorFollowingPanic returns true if the given finding is the
+// libFuzzer error which is reported after a Go or Rust panic aborted
+// the fuzz test. The panic itself is already reported as the pending
+// finding in that case.
+func (p *parser) libFuzzerErrorFollowingPanic(report *finding.Finding) bool {
+	if p.pendingFinding.GetDetails() == "Go Panic" && report.GetDetails() != "Go Panic" {
+		return true
+	}
+	return strings.HasPrefix(p.pendingFinding.GetDetails(), rustPanicDetails) &&
+		!strings.HasPrefix(report.GetDetails(), rustPanicDetails)
 }
 
 func (p *parser) parseAsLibfuzzerFinding(line string) *finding.Finding {
@@ -444,6 +531,17 @@ func (p *parser) parseAsJazzerFinding(line string) *finding.Finding {
 	return nil
 }
 
+func (p *parser) parseAsAtherisFinding(line string) *finding.Finding {
+	if atherisUncaughtExceptionPattern.MatchString(line) {
+		return &finding.Finding{
+			Type:    finding.ErrorTypeWarning, // aka Bug
+			Details: pythonExceptionDetails,
+			Logs:    []string{line},
+		}
+	}
+	return nil
+}
+
 func (p *parser) parseAsJazzerJSFinding(line string) *finding.Finding {
 	_, found := regexutil.FindNamedGroupsMatch(beginningOfJestReportPattern, line)
 	if found {
@@ -631,6 +729,7 @@ func (p *parser) finalizeAndSendPendingFinding(ctx context.Context) error {
 		SourceMap:       p.SourceMap,
 		SupportJazzer:   p.SupportJazzer,
 		SupportJazzerJS: p.SupportJazzerJS,
+		SupportAtheris:  p.SupportAtheris,
 	}
 	parser, err := stacktrace.NewParser(parserOpts)
 	if err != nil {
@@ -640,6 +739,11 @@ func (p *parser) finalizeAndSendPendingFinding(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	p.pendingFinding.AuxiliaryStackTraces, err = parser.ParseAuxiliaryStackTraces(p.pendingFinding.Logs)
+	if err != nil {
+		return err
+	}
+	p.pendingFinding.SanitizerReport = sanitizer.ParseReport(p.pendingFinding.Logs)
 
 	p.pendingFinding.MoreDetails = &finding.ErrorDetails{
 		ID: errorid.ForFinding(p.pendingFinding),
@@ -54,6 +54,7 @@ func TestLibFuzzerAdapter_ReportsParsing(t *testing.T) {
 		name            string
 		supportJazzer   bool
 		supportJazzerJS bool
+		supportAtheris  bool
 		logs            string
 		sourceMap       *sourcemap.SourceMap
 		expected        []*report.Report
@@ -136,6 +137,12 @@ error info 2`,
 					Finding: &finding.Finding{
 						Type:    finding.ErrorTypeCrash,
 						Details: "global-buffer-overflow on address 0x00",
+						SanitizerReport: &finding.SanitizerReport{
+							Sanitizer: "AddressSanitizer",
+							BugType:   "global-buffer-overflow",
+							Message:   "global-buffer-overflow on address 0x00",
+							Address:   "0x00",
+						},
 						Logs: []string{
 							"==8141==ERROR: AddressSanitizer: global-buffer-overflow on address 0x00",
 							"error info 1",
@@ -190,6 +197,12 @@ SUMMARY: UndefinedBehaviorSanitizer: undefined-behavior fuzz_targets/manual.cpp:
 					Finding: &finding.Finding{
 						Type:    finding.ErrorTypeRuntimeError,
 						Details: "undefined behavior: signed integer overflow",
+						SanitizerReport: &finding.SanitizerReport{
+							Sanitizer: "UndefinedBehaviorSanitizer",
+							BugType:   "signed integer overflow",
+							Message:   "signed integer overflow: 2147483647 + 1 cannot be represented in type 'int'",
+							Summary:   "UndefinedBehaviorSanitizer: undefined-behavior fuzz_targets/manual.cpp:6:5 in",
+						},
 						Logs: []string{
 							"fuzz_targets/manual.cpp:6:5: runtime error: signed integer overflow: 2147483647 + 1 cannot be represented in type 'int'",
 							"SUMMARY: UndefinedBehaviorSanitizer: undefined-behavior fuzz_targets/manual.cpp:6:5 in",
@@ -306,6 +319,12 @@ Base64: i/SIw2hR3wI=`,
 					Finding: &finding.Finding{
 						Type:    finding.ErrorTypeCrash,
 						Details: "stack-buffer-overflow on address 0x7fffb9492184 at pc 0x0000004969aa bp 0x7fffb9492150 sp 0x7fffb9491918",
+						SanitizerReport: &finding.SanitizerReport{
+							Sanitizer: "AddressSanitizer",
+							BugType:   "stack-buffer-overflow",
+							Message:   "stack-buffer-overflow on address 0x7fffb9492184 at pc 0x0000004969aa bp 0x7fffb9492150 sp 0x7fffb9491918",
+							Address:   "0x7fffb9492184",
+						},
 						Logs: []string{
 							"==16==ERROR: AddressSanitizer: stack-buffer-overflow on address 0x7fffb9492184 at pc 0x0000004969aa bp 0x7fffb9492150 sp 0x7fffb9491918",
 							"[...]",
@@ -324,6 +343,13 @@ Base64: i/SIw2hR3wI=`,
 						InputData: testInput,
 						InputFile: testInputFile.Name(),
 						Details:   "SEGV on unknown address 0x000000000000 (pc 0x000000000000 bp 0x7fffb9492290 sp 0x7fffb9492158 T0)",
+						SanitizerReport: &finding.SanitizerReport{
+							Sanitizer: "AddressSanitizer",
+							BugType:   "SEGV",
+							Message:   "SEGV on unknown address 0x000000000000 (pc 0x000000000000 bp 0x7fffb9492290 sp 0x7fffb9492158 T0)",
+							Address:   "0x000000000000",
+							Thread:    "T0",
+						},
 						Logs: []string{
 							"==16==ERROR: AddressSanitizer: SEGV on unknown address 0x000000000000 (pc 0x000000000000 bp 0x7fffb9492290 sp 0x7fffb9492158 T0)",
 							"[...]",
@@ -365,6 +391,11 @@ error info 2`,
 					Finding: &finding.Finding{
 						Type:    finding.ErrorTypeCrash,
 						Details: "use-of-uninitialized-value",
+						SanitizerReport: &finding.SanitizerReport{
+							Sanitizer: "MemorySanitizer",
+							BugType:   "use-of-uninitialized-value",
+							Message:   "use-of-uninitialized-value",
+						},
 						Logs: []string{
 							"==2248837==WARNING: MemorySanitizer: use-of-uninitialized-value",
 							"error info 1",
@@ -400,8 +431,13 @@ Base64: ZGVhZGJlZWY=`,
 				{
 					Status: report.RunStatusRunning,
 					Finding: &finding.Finding{
-						Type:      finding.ErrorTypeWarning,
-						Details:   "java.lang.ArrayIndexOutOfBoundsException: Index 22 out of bounds for length 8",
+						Type:    finding.ErrorTypeWarning,
+						Details: "java.lang.ArrayIndexOutOfBoundsException: Index 22 out of bounds for length 8",
+						SanitizerReport: &finding.SanitizerReport{
+							Sanitizer: "Jazzer",
+							BugType:   "java.lang.ArrayIndexOutOfBoundsException",
+							Message:   "Index 22 out of bounds for length 8",
+						},
 						InputData: testInput,
 						InputFile: testInputFile.Name(),
 						Logs: []string{
@@ -460,8 +496,13 @@ Base64: UVFcb1w8L1xzY3JpcHQt`,
 				{
 					Status: report.RunStatusRunning,
 					Finding: &finding.Finding{
-						Type:      finding.ErrorTypeCrash,
-						Details:   "Security Issue: Output contains </script",
+						Type:    finding.ErrorTypeCrash,
+						Details: "Security Issue: Output contains </script",
+						SanitizerReport: &finding.SanitizerReport{
+							Sanitizer: "Jazzer",
+							BugType:   "com.code_intelligence.jazzer.api.FuzzerSecurityIssueHigh",
+							Message:   "Output contains </script",
+						},
 						InputData: testInput,
 						InputFile: testInputFile.Name(),
 						Logs: []string{
@@ -513,8 +554,13 @@ Base64: QGphei5aZXIKLR8AACEAHw==`,
 				{
 					Status: report.RunStatusRunning,
 					Finding: &finding.Finding{
-						Type:      finding.ErrorTypeCrash,
-						Details:   "Security Issue: Remote Code Execution",
+						Type:    finding.ErrorTypeCrash,
+						Details: "Security Issue: Remote Code Execution",
+						SanitizerReport: &finding.SanitizerReport{
+							Sanitizer: "Jazzer",
+							BugType:   "com.code_intelligence.jazzer.api.FuzzerSecurityIssueHigh",
+							Message:   "Remote Code Execution",
+						},
 						InputData: testInput,
 						InputFile: testInputFile.Name(),
 						Logs: []string{
@@ -682,6 +728,12 @@ Base64: i/SIw2hR3wI=`,
 					Finding: &finding.Finding{
 						Type:    finding.ErrorTypeCrash,
 						Details: "stack-buffer-overflow on address 0x7fffb9492184 at pc 0x0000004969aa bp 0x7fffb9492150 sp 0x7fffb9491918",
+						SanitizerReport: &finding.SanitizerReport{
+							Sanitizer: "AddressSanitizer",
+							BugType:   "stack-buffer-overflow",
+							Message:   "stack-buffer-overflow on address 0x7fffb9492184 at pc 0x0000004969aa bp 0x7fffb9492150 sp 0x7fffb9491918",
+							Address:   "0x7fffb9492184",
+						},
 						Logs: []string{
 							"==16==ERROR: AddressSanitizer: stack-buffer-overflow on address 0x7fffb9492184 at pc 0x0000004969aa bp 0x7fffb9492150 sp 0x7fffb9491918",
 							"[...end of report not detected...]",
@@ -696,6 +748,13 @@ Base64: i/SIw2hR3wI=`,
 						InputData: testInput,
 						InputFile: testInputFile.Name(),
 						Details:   "SEGV on unknown address 0x000000000000 (pc 0x000000000000 bp 0x7fffb9492290 sp 0x7fffb9492158 T0)",
+						SanitizerReport: &finding.SanitizerReport{
+							Sanitizer: "AddressSanitizer",
+							BugType:   "SEGV",
+							Message:   "SEGV on unknown address 0x000000000000 (pc 0x000000000000 bp 0x7fffb9492290 sp 0x7fffb9492158 T0)",
+							Address:   "0x000000000000",
+							Thread:    "T0",
+						},
 						Logs: []string{
 							"==16==ERROR: AddressSanitizer: SEGV on unknown address 0x000000000000 (pc 0x000000000000 bp 0x7fffb9492290 sp 0x7fffb9492158 T0)",
 							"[...]",
@@ -974,6 +1033,186 @@ Base64: RnV6eg==`, testInputFile.Name()),
 				},
 			},
 		},
+		{
+			name: "Rust panic",
+			logs: fmt.Sprintf(`
+INFO: A corpus is not provided, starting from an empty corpus
+thread '<unnamed>' panicked at src/lib.rs:12:9:
+index out of bounds: the len is 3 but the index is 5
+note: run with `+"`RUST_BACKTRACE=1`"+` environment variable to display a backtrace
+==1234== ERROR: libFuzzer: deadly signal
+artifact_prefix='./'; Test unit written to %s`, testInputFile.Name()),
+			expected: []*report.Report{
+				{Status: report.RunStatusInitializing},
+				{
+					Status: report.RunStatusRunning,
+					Finding: &finding.Finding{
+						Type:      finding.ErrorTypeCrash,
+						Details:   "Rust panic: index out of bounds: the len is 3 but the index is 5",
+						InputData: testInput,
+						InputFile: testInputFile.Name(),
+						Logs: []string{
+							"thread '<unnamed>' panicked at src/lib.rs:12:9:",
+							"index out of bounds: the len is 3 but the index is 5",
+							"note: run with `RUST_BACKTRACE=1` environment variable to display a backtrace",
+							"==1234== ERROR: libFuzzer: deadly signal",
+							fmt.Sprintf("artifact_prefix='./'; Test unit written to %s", testInputFile.Name()),
+						},
+						StackTrace: []*stacktrace.StackFrame{{
+							SourceFile: "src/lib.rs",
+							Line:       12,
+							Column:     9,
+						}},
+					},
+				},
+			},
+		},
+		{
+			name:           "Atheris uncaught exception",
+			supportAtheris: true,
+			logs: fmt.Sprintf(`
+INFO: A corpus is not provided, starting from an empty corpus
+
+ === Uncaught Python exception: ===
+ValueError: unexpected input
+Traceback (most recent call last):
+  File "fuzz_parse.py", line 11, in TestOneInput
+    parse(data)
+  File "parser/__init__.py", line 3, in parse
+    raise ValueError("unexpected input")
+ValueError: unexpected input
+
+==1234== ERROR: libFuzzer: fuzz target exited
+SUMMARY: libFuzzer: fuzz target exited
+artifact_prefix='./'; Test unit written to %s`, testInputFile.Name()),
+			expected: []*report.Report{
+				{Status: report.RunStatusInitializing},
+				{
+					Status: report.RunStatusRunning,
+					Finding: &finding.Finding{
+						Type:      finding.ErrorTypeWarning,
+						Details:   "ValueError: unexpected input",
+						InputData: testInput,
+						InputFile: testInputFile.Name(),
+						Logs: []string{
+							" === Uncaught Python exception: ===",
+							"ValueError: unexpected input",
+							"Traceback (most recent call last):",
+							`  File "fuzz_parse.py", line 11, in TestOneInput`,
+							"    parse(data)",
+							`  File "parser/__init__.py", line 3, in parse`,
+							`    raise ValueError("unexpected input")`,
+							"ValueError: unexpected input",
+							"",
+							"==1234== ERROR: libFuzzer: fuzz target exited",
+							"SUMMARY: libFuzzer: fuzz target exited",
+							fmt.Sprintf("artifact_prefix='./'; Test unit written to %s", testInputFile.Name()),
+						},
+						StackTrace: []*stacktrace.StackFrame{
+							{
+								SourceFile: "parser/__init__.py",
+								Line:       3,
+								Function:   "parse",
+							},
+							{
+								SourceFile: "fuzz_parse.py",
+								Line:       11,
+								Function:   "TestOneInput",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Rust panic with message in the first line",
+			logs: fmt.Sprintf(`
+INFO: A corpus is not provided, starting from an empty corpus
+thread '<unnamed>' panicked at 'attempt to add with overflow', src/lib.rs:3:5
+==1234== ERROR: libFuzzer: deadly signal
+artifact_prefix='./'; Test unit written to %s`, testInputFile.Name()),
+			expected: []*report.Report{
+				{Status: report.RunStatusInitializing},
+				{
+					Status: report.RunStatusRunning,
+					Finding: &finding.Finding{
+						Type:      finding.ErrorTypeCrash,
+						Details:   "Rust panic: attempt to add with overflow",
+						InputData: testInput,
+						InputFile: testInputFile.Name(),
+						Logs: []string{
+							"thread '<unnamed>' panicked at 'attempt to add with overflow', src/lib.rs:3:5",
+							"==1234== ERROR: libFuzzer: deadly signal",
+							fmt.Sprintf("artifact_prefix='./'; Test unit written to %s", testInputFile.Name()),
+						},
+						StackTrace: []*stacktrace.StackFrame{{
+							SourceFile: "src/lib.rs",
+							Line:       3,
+							Column:     5,
+						}},
+					},
+				},
+			},
+		},
+		{
+			name: "Rust panic with empty message in the first line",
+			logs: fmt.Sprintf(`
+INFO: A corpus is not provided, starting from an empty corpus
+thread 'main' panicked at '', src/lib.rs:3:5
+note: run with `+"`RUST_BACKTRACE=1`"+` environment variable to display a backtrace
+==1234== ERROR: libFuzzer: deadly signal
+artifact_prefix='./'; Test unit written to %s`, testInputFile.Name()),
+			expected: []*report.Report{
+				{Status: report.RunStatusInitializing},
+				{
+					Status: report.RunStatusRunning,
+					Finding: &finding.Finding{
+						Type:      finding.ErrorTypeCrash,
+						Details:   "Rust panic: ",
+						InputData: testInput,
+						InputFile: testInputFile.Name(),
+						Logs: []string{
+							"thread 'main' panicked at '', src/lib.rs:3:5",
+							"note: run with `RUST_BACKTRACE=1` environment variable to display a backtrace",
+							"==1234== ERROR: libFuzzer: deadly signal",
+							fmt.Sprintf("artifact_prefix='./'; Test unit written to %s", testInputFile.Name()),
+						},
+						StackTrace: []*stacktrace.StackFrame{{
+							SourceFile: "src/lib.rs",
+							Line:       3,
+							Column:     5,
+						}},
+					},
+				},
+			},
+		},
+		{
+			name: "Rust panic with multi-line message in the first line",
+			logs: fmt.Sprintf(`
+INFO: A corpus is not provided, starting from an empty corpus
+thread '<unnamed>' panicked at 'invalid header
+expected magic bytes', src/lib.rs:7:13
+==1234== ERROR: libFuzzer: deadly signal
+artifact_prefix='./'; Test unit written to %s`, testInputFile.Name()),
+			expected: []*report.Report{
+				{Status: report.RunStatusInitializing},
+				{
+					Status: report.RunStatusRunning,
+					Finding: &finding.Finding{
+						Type:      finding.ErrorTypeCrash,
+						Details:   "Rust panic: invalid header",
+						InputData: testInput,
+						InputFile: testInputFile.Name(),
+						Logs: []string{
+							"thread '<unnamed>' panicked at 'invalid header",
+							"expected magic bytes', src/lib.rs:7:13",
+							"==1234== ERROR: libFuzzer: deadly signal",
+							fmt.Sprintf("artifact_prefix='./'; Test unit written to %s", testInputFile.Name()),
+						},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -983,7 +1222,7 @@ Base64: RnV6eg==`, testInputFile.Name()),
 				assert.True(t, true)
 
 			}
-			options := &Options{SupportJazzer: tt.supportJazzer, SupportJazzerJS: tt.supportJazzerJS, SourceMap: tt.sourceMap, ProjectDir: projectDir}
+			options := &Options{SupportJazzer: tt.supportJazzer, SupportJazzerJS: tt.supportJazzerJS, SupportAtheris: tt.supportAtheris, SourceMap: tt.sourceMap, ProjectDir: projectDir}
 			reporter := NewLibfuzzerOutputParser(options)
 			reportsCh := make(chan *report.Report, maxBufferedReports)
 			reporterErrCh := make(chan error)
@@ -1050,6 +1289,12 @@ func TestBufferOverflowCrashLogs(t *testing.T) {
 			"error info 1",
 			"artifact_prefix='./'; Test unit written to " + expectedCrashFile.Name(),
 			"Base64: Aio=",
+		},
+		&finding.SanitizerReport{
+			Sanitizer: "AddressSanitizer",
+			BugType:   "global-buffer-overflow",
+			Message:   "global-buffer-overflow on address 0x00",
+			Address:   "0x00",
 		})
 }
 
@@ -1072,10 +1317,11 @@ func TestOOMCrashLogs(t *testing.T) {
 			"error info 1",
 			"artifact_prefix='./'; Test unit written to " + expectedCrashFile.Name(),
 			"Base64: Aio=",
-		})
+		},
+		nil)
 }
 
-func assertCorrectCrashesParsing(t *testing.T, errorDetails, errorID, crashFile string, crashingInput []byte, logs []string) {
+func assertCorrectCrashesParsing(t *testing.T, errorDetails, errorID, crashFile string, crashingInput []byte, logs []string, sanitizerReport *finding.SanitizerReport) {
 	expectedReports := []*report.Report{
 		{
 			Status: report.RunStatusRunning,
@@ -1088,6 +1334,7 @@ func assertCorrectCrashesParsing(t *testing.T, errorDetails, errorID, crashFile
 				MoreDetails: &finding.ErrorDetails{
 					ID: errorID,
 				},
+				SanitizerReport: sanitizerReport,
 			},
 		},
 	}
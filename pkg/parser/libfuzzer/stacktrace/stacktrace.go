@@ -10,18 +10,33 @@ import (
 	"github.com/pkg/errors"
 
 	"code-intelligence.com/cifuzz/pkg/java/sourcemap"
+	"code-intelligence.com/cifuzz/util/fileutil"
 	"code-intelligence.com/cifuzz/util/regexutil"
 )
 
 var framePattern = regexp.MustCompile(
 	`#(?P<frame_number>\d+)\s+0x[a-fA-F0-9]+\s+in\s+(?P<function>(\(anonymous namespace\))?[^(\s]+).*\s(?P<source_file>\S+?):(?P<line>\d+):?(?P<column>\d*)`)
 
+// TSan prints frames without the program counter and with the module
+// and offset at the end, e.g.
+// "#0 Thread1(void*) /src/race.cpp:6:10 (race+0xd1b2e)"
+var framePatternTSan = regexp.MustCompile(
+	`#(?P<frame_number>\d+)\s+(?P<function>(\(anonymous namespace\))?[^(\s]+)(\S*\s+|.*\s)(?P<source_file>\S+?):(?P<line>\d+):?(?P<column>\d*)\s+\(\S+\+0x[a-fA-F0-9]+\)$`)
+
 // Special pattern for Java stack traces
 var framePatternJava = regexp.MustCompile(`^\s*at\s+(?P<function>[^(]*)\((?P<source_file>[^:]*):(?P<line>\d*)\)\s*$`)
 
 // Special pattern for Node stack traces
 var framePatternNode = regexp.MustCompile(`\s*at\s((?P<function>\S+)\s+(\[.*\])?\s*\()?(?P<source_file>\S+?):(?P<line>\d+):?(?P<column>\d*)\)?`)
 
+// Special pattern for the frames of Python tracebacks, which are
+// followed by a line containing the source code of the frame
+var framePatternPython = regexp.MustCompile(`^\s*File "(?P<source_file>[^"]+)", line (?P<line>\d+), in (?P<function>\S+)`)
+
+// Python prints a traceback for each exception in a chain of exceptions,
+// each starting with this line
+var pythonTracebackPattern = regexp.MustCompile(`^Traceback \(most recent call last\):`)
+
 // This matches diagnostic messages printed by UBSan when it reports an
 // error. UBSan doesn't always print a stack trace, so we extract the
 // source file from this line.
@@ -29,6 +44,34 @@ var framePatternNode = regexp.MustCompile(`\s*at\s((?P<function>\S+)\s+(\[.*\])?
 // store in the finding
 var ubSanDiagPattern = regexp.MustCompile(`^(?P<source_file>\S+?):((?P<line>\d+):)?((?P<column>\d+):)? runtime error: (?P<message>.*)$`)
 
+// This matches the line printed by Rust when a thread panics, which
+// contains the source location of the panic. We use it if the stack
+// trace printed by ASan lacks source locations, which is the case if
+// the Rust fuzz target was built without debug info.
+var rustPanicPattern = regexp.MustCompile(`^thread '[^']*' panicked at (?:'.*', )?(?P<source_file>\S+?):(?P<line>\d+):(?P<column>\d+):?$`)
+
+// Symbol names of Rust functions end with a hash, like in
+// parser::parse::h5c5b1a8ce4b8f1d3, which we strip from the function
+// names in the stack trace to make it independent of the build.
+var rustSymbolHashPattern = regexp.MustCompile(`::h[0-9a-f]{16}$`)
+
+// Sanitizer reports contain further stack traces after the one of the
+// error itself, which are introduced by one of these lines, e.g.
+// "freed by thread T0 here:" in ASan reports, "Previous write of size 4
+// at 0x7b0400000010 by thread T1:" in TSan reports or "Uninitialized
+// value was created by a heap allocation" in MSan reports.
+var auxiliaryStackTracePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^\s*(?P<name>(previously )?allocated by thread \S+)( here)?:\s*$`),
+	regexp.MustCompile(`^\s*(?P<name>freed by thread \S+)( here)?:\s*$`),
+	regexp.MustCompile(`^\s*(?P<name>Previous (atomic )?(read|write) of size \d+ at \S+ by .+?):\s*$`),
+	regexp.MustCompile(`^\s*(?P<name>(Thread|Mutex) \S+ .*created( by .+?)?)( here| at)?:\s*$`),
+	regexp.MustCompile(`^\s*(?P<name>Uninitialized value was (created|stored to memory) .*?):?\s*$`),
+}
+
+// Matches any line of a stack trace printed by a sanitizer, including
+// frames which can't be parsed as a StackFrame
+var anyFramePattern = regexp.MustCompile(`^\s*#\d+\s`)
+
 // A StackFrame represents an element of the stack trace
 type StackFrame struct {
 	SourceFile  string
@@ -38,6 +81,14 @@ type StackFrame struct {
 	Function    string
 }
 
+// An AuxiliaryStackTrace is a stack trace in a sanitizer report which
+// doesn't belong to the error itself, like the stack trace of the site
+// where the memory accessed by a use-after-free was freed
+type AuxiliaryStackTrace struct {
+	Name       string        `json:"name"`
+	StackTrace []*StackFrame `json:"stack_trace"`
+}
+
 func EncodeStackTrace(stacktrace []*StackFrame) []byte {
 	out := []byte("")
 	for _, sf := range stacktrace {
@@ -51,6 +102,7 @@ type ParserOptions struct {
 	SourceMap       *sourcemap.SourceMap
 	SupportJazzer   bool
 	SupportJazzerJS bool
+	SupportAtheris  bool
 }
 
 type parser struct {
@@ -83,7 +135,85 @@ func (p *parser) Parse(logs []string) ([]*StackFrame, error) {
 	return p.parseSourceLocation(logs)
 }
 
+// ParseAuxiliaryStackTraces parses the stack traces which follow the
+// stack trace of the error in a sanitizer report. Stack traces which
+// don't contain any frames from source files in the project directory
+// are omitted.
+func (p *parser) ParseAuxiliaryStackTraces(logs []string) ([]*AuxiliaryStackTrace, error) {
+	if p.SupportJazzer || p.SupportJazzerJS || p.SupportAtheris {
+		return nil, nil
+	}
+
+	var traces []*AuxiliaryStackTrace
+	var current *AuxiliaryStackTrace
+	// Whether the current stack trace reached the fuzz test entry point
+	var reachedEntry bool
+	for _, line := range logs {
+		name := auxiliaryStackTraceName(line)
+		if name != "" {
+			current = &AuxiliaryStackTrace{Name: name}
+			traces = append(traces, current)
+			reachedEntry = false
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if !anyFramePattern.MatchString(line) {
+			// The stack trace ends with the first line which is not a
+			// stack frame
+			current = nil
+			continue
+		}
+		if reachedEntry {
+			continue
+		}
+
+		frame, err := p.stackFrameFromLine(line)
+		if err != nil {
+			return nil, err
+		}
+		if frame == nil {
+			continue
+		}
+		current.StackTrace = append(current.StackTrace, frame)
+		if frame.Function == "LLVMFuzzerTestOneInputNoReturn" || frame.Function == "LLVMFuzzerTestOneInput" {
+			reachedEntry = true
+		}
+	}
+
+	var result []*AuxiliaryStackTrace
+	for _, trace := range traces {
+		if len(trace.StackTrace) > 0 {
+			result = append(result, trace)
+		}
+	}
+	return result, nil
+}
+
+func auxiliaryStackTraceName(line string) string {
+	for _, pattern := range auxiliaryStackTracePatterns {
+		matches, found := regexutil.FindNamedGroupsMatch(pattern, line)
+		if found {
+			return matches["name"]
+		}
+	}
+	return ""
+}
+
 func (p *parser) parseStackTrace(logs []string) ([]*StackFrame, error) {
+	if p.SupportAtheris {
+		frames, err := p.parsePythonTraceback(logs)
+		if err != nil {
+			return nil, err
+		}
+		// Crashes in native extensions are reported by a sanitizer
+		// instead, so we fall back to parsing its stack trace
+		if len(frames) > 0 {
+			return frames, nil
+		}
+	}
+
 	var frames []*StackFrame
 	for _, line := range logs {
 		frame, err := p.stackFrameFromLine(line)
@@ -119,6 +249,52 @@ func (p *parser) parseStackTrace(logs []string) ([]*StackFrame, error) {
 	return frames, nil
 }
 
+// parsePythonTraceback parses the last traceback in the logs, which is
+// the one of the exception which was not caught by the fuzz test. In
+// contrast to sanitizer stack traces, Python tracebacks list the most
+// recent call last, so we reverse the order of the frames.
+func (p *parser) parsePythonTraceback(logs []string) ([]*StackFrame, error) {
+	var frames []*StackFrame
+	for _, line := range logs {
+		if pythonTracebackPattern.MatchString(line) {
+			frames = nil
+			continue
+		}
+
+		matches, found := regexutil.FindNamedGroupsMatch(framePatternPython, line)
+		if !found {
+			continue
+		}
+
+		// Frames of code which is not stored in a file, e.g. frozen
+		// modules of the standard library, have names like
+		// "<frozen importlib._bootstrap>"
+		if strings.HasPrefix(matches["source_file"], "<") {
+			continue
+		}
+		sourceFile := p.validateSourceFile(matches["source_file"], matches["function"])
+		if sourceFile == "" {
+			continue
+		}
+
+		lineNumber, err := strconv.ParseUint(matches["line"], 10, 32)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		frames = append(frames, &StackFrame{
+			SourceFile: filepath.ToSlash(sourceFile),
+			Line:       uint32(lineNumber),
+			Function:   matches["function"],
+		})
+	}
+
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return frames, nil
+}
+
 func (p *parser) parseSourceLocation(logs []string) ([]*StackFrame, error) {
 	for _, line := range logs {
 		sourceLocation, err := p.sourceLocationFromLine(line)
@@ -135,6 +311,9 @@ func (p *parser) parseSourceLocation(logs []string) ([]*StackFrame, error) {
 func (p *parser) stackFrameFromLine(line string) (*StackFrame, error) {
 	var err error
 	matches, found := regexutil.FindNamedGroupsMatch(framePattern, line)
+	if !found {
+		matches, found = regexutil.FindNamedGroupsMatch(framePatternTSan, line)
+	}
 	if !found && p.SupportJazzer {
 		matches, found = regexutil.FindNamedGroupsMatch(framePatternJava, line)
 		if !found {
@@ -184,7 +363,7 @@ func (p *parser) stackFrameFromLine(line string) (*StackFrame, error) {
 		Line:        uint32(lineNumber),
 		Column:      uint32(column),
 		FrameNumber: uint32(frameNumber),
-		Function:    matches["function"],
+		Function:    rustSymbolHashPattern.ReplaceAllString(matches["function"], ""),
 	}
 
 	return stackFrame, nil
@@ -251,13 +430,25 @@ func (p *parser) validateSourceFile(sourceFile string, function string) string {
 		}
 	}
 
+	// Ignore installed packages, e.g. from a virtual environment in
+	// the project directory
+	if p.SupportAtheris {
+		if strings.Contains(path, "site-packages") || strings.Contains(path, "dist-packages") {
+			return ""
+		}
+	}
+
 	return path
 }
 
 func (p *parser) sourceLocationFromLine(line string) (*StackFrame, error) {
 	matches, found := regexutil.FindNamedGroupsMatch(ubSanDiagPattern, line)
 	if !found {
-		return nil, nil
+		matches, found = regexutil.FindNamedGroupsMatch(rustPanicPattern, line)
+		if !found {
+			return nil, nil
+		}
+		matches["source_file"] = p.rustSourceFilePath(matches["source_file"])
 	}
 
 	sourceFile := p.validateSourceFile(matches["source_file"], matches["function"])
@@ -287,29 +478,29 @@ func (p *parser) sourceLocationFromLine(line string) (*StackFrame, error) {
 	}, nil
 }
 
-func (p *parser) getJavaSourceFilePath(sourceFile string, function string) string {
-	// remove function and class name
-	possiblePackageName := removeLastPart(removeLastPart(function))
-	// In the case of nested classes we are not at the true package
-	// name yet. Remove possible class suffixes until we find the
-	// Java package that matches.
-
-	for possiblePackageName != "" {
-		for _, relFile := range p.SourceMap.JavaPackages[possiblePackageName] {
-			if filepath.Base(relFile) == sourceFile {
-				return relFile
-			}
-		}
-		possiblePackageName = removeLastPart(possiblePackageName)
+// rustSourceFilePath returns the path of a source file of a Rust fuzz
+// target relative to the project directory. rustc prints the paths of
+// source files of the crates in the cargo workspace relative to the
+// workspace root, which is the fuzz/ directory in case of fuzz targets
+// created by cargo-fuzz.
+func (p *parser) rustSourceFilePath(sourceFile string) string {
+	if filepath.IsAbs(sourceFile) || p.ProjectDir == "" {
+		return sourceFile
+	}
+	if exists, _ := fileutil.Exists(filepath.Join(p.ProjectDir, sourceFile)); exists {
+		return sourceFile
+	}
+	fuzzCrateSourceFile := filepath.Join("fuzz", sourceFile)
+	if exists, _ := fileutil.Exists(filepath.Join(p.ProjectDir, fuzzCrateSourceFile)); exists {
+		return fuzzCrateSourceFile
 	}
-
 	return sourceFile
 }
 
-func removeLastPart(packageName string) string {
-	sepIndex := strings.LastIndex(packageName, ".")
-	if sepIndex > 0 {
-		return packageName[0:sepIndex]
+func (p *parser) getJavaSourceFilePath(sourceFile string, function string) string {
+	path := p.SourceMap.FindSourceFile(sourceFile, function)
+	if path == "" {
+		return sourceFile
 	}
-	return ""
+	return path
 }
@@ -145,6 +145,33 @@ func TestStackTrace(t *testing.T) {
 			},
 			defaultStackTrace,
 		},
+		{
+			"msan_stack_trace_and_origin",
+			[]string{
+				"==3310==WARNING: MemorySanitizer: use-of-uninitialized-value",
+				fmt.Sprintf("    #0 0x530ce7 in DoStuff(std::__cxx11::basic_string<char, std::char_traits<char>, std::allocator<char> > const&) %s:24:10", sourceFile),
+				fmt.Sprintf("    #1 0x52fde5 in LLVMFuzzerTestOneInput %s/fuzz_targets/do_stuff_fuzzer.cpp:11:3", projectDir),
+				"",
+				"  Uninitialized value was created by a heap allocation",
+				"    #0 0x4a1d3f in malloc /llvm/compiler-rt/lib/msan/msan_interceptors.cpp:932:3",
+				fmt.Sprintf("    #1 0x530a10 in DoStuff(std::__cxx11::basic_string<char, std::char_traits<char>, std::allocator<char> > const&) %s:20:14", sourceFile),
+			},
+			defaultStackTrace,
+		},
+		{
+			"tsan_data_race",
+			[]string{
+				"WARNING: ThreadSanitizer: data race (pid=21640)",
+				"  Write of size 4 at 0x7b0400000010 by thread T2:",
+				fmt.Sprintf("    #0 DoStuff(std::__cxx11::basic_string<char, std::char_traits<char>, std::allocator<char> > const&) %s:24:10 (do_stuff_fuzzer+0xd1b2e)", sourceFile),
+				fmt.Sprintf("    #1 LLVMFuzzerTestOneInput %s/fuzz_targets/do_stuff_fuzzer.cpp:11:3 (do_stuff_fuzzer+0xd1c01)", projectDir),
+				"",
+				"  Previous write of size 4 at 0x7b0400000010 by thread T1:",
+				fmt.Sprintf("    #0 DoStuff(std::__cxx11::basic_string<char, std::char_traits<char>, std::allocator<char> > const&) %s:24:10 (do_stuff_fuzzer+0xd1b2e)", sourceFile),
+				"    #1 <null> <null> (libtsan.so.0+0x2e3f2)",
+			},
+			defaultStackTrace,
+		},
 		{
 			"stack_trace_without_column",
 			[]string{
@@ -169,6 +196,55 @@ func TestStackTrace(t *testing.T) {
 				Column:     18,
 			}},
 		},
+		{
+			"rust_stack_trace",
+			[]string{
+				"==1234== ERROR: libFuzzer: deadly signal",
+				"    #0 0x55d0b1 in __sanitizer_print_stack_trace /rustc/llvm/src/llvm-project/compiler-rt/lib/asan/asan_stack.cpp:87:3",
+				"    #1 0x5a3c2e in std::panicking::rust_panic_with_hook::h0a3c6e8b1d6f4e21 /rustc/4b91a6ea7258a947e59c6522cd5898e7c0a6a88f/library/std/src/panicking.rs:781:13",
+				fmt.Sprintf("    #2 0x55f2a0 in parser::parse::h5c5b1a8ce4b8f1d3 %s:24:10", sourceFile),
+				fmt.Sprintf("    #3 0x55f3b1 in parse_input::_::__libfuzzer_sys_run::h9e0f64d2c1a7b835 %s/fuzz/fuzz_targets/parse_input.rs:7:5", projectDir),
+				"    #4 0x561a12 in rust_fuzzer_test_input /home/user/.cargo/registry/src/index.crates.io-6f17d22bba15001f/libfuzzer-sys-0.4.7/src/lib.rs:297:60",
+			},
+			[]*StackFrame{{
+				FrameNumber: 2,
+				SourceFile:  "api.cpp",
+				Function:    "parser::parse",
+				Line:        24,
+				Column:      10,
+			}, {
+				FrameNumber: 3,
+				SourceFile:  "fuzz/fuzz_targets/parse_input.rs",
+				Function:    "parse_input::_::__libfuzzer_sys_run",
+				Line:        7,
+				Column:      5,
+			}},
+		},
+		{
+			"rust_panic_location",
+			[]string{
+				"thread '<unnamed>' panicked at src/lib.rs:12:9:",
+				"index out of bounds: the len is 3 but the index is 5",
+				"==1234== ERROR: libFuzzer: deadly signal",
+				"    #0 0x55d0b1 in __sanitizer_print_stack_trace (/project/fuzz/target/x86_64-unknown-linux-gnu/release/parse_input+0x55d0b1)",
+			},
+			[]*StackFrame{{
+				SourceFile: "src/lib.rs",
+				Line:       12,
+				Column:     9,
+			}},
+		},
+		{
+			"rust_panic_location_with_message",
+			[]string{
+				"thread '<unnamed>' panicked at 'attempt to add with overflow', src/lib.rs:3:5",
+			},
+			[]*StackFrame{{
+				SourceFile: "src/lib.rs",
+				Line:       3,
+				Column:     5,
+			}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -179,6 +255,171 @@ func TestStackTrace(t *testing.T) {
 	}
 }
 
+func TestAuxiliaryStackTraces(t *testing.T) {
+	projectDir := os.TempDir()
+	parser, err := NewParser(&ParserOptions{ProjectDir: projectDir})
+	require.NoError(t, err)
+	sourceFile := filepath.Join(projectDir, "api.cpp")
+
+	tests := []struct {
+		name           string
+		logs           []string
+		expectedTraces []*AuxiliaryStackTrace
+	}{
+		{
+			"asan_use_after_free",
+			[]string{
+				"==19426==ERROR: AddressSanitizer: heap-use-after-free on address 0x602000000010 at pc 0x55f2a0 bp 0x7ffd sp 0x7ffd",
+				"READ of size 4 at 0x602000000010 thread T0",
+				fmt.Sprintf("    #0 0x530ce7 in DoStuff(std::__cxx11::basic_string<char, std::char_traits<char>, std::allocator<char> > const&) %s:24:10", sourceFile),
+				fmt.Sprintf("    #1 0x52fde5 in LLVMFuzzerTestOneInput %s/fuzz_targets/do_stuff_fuzzer.cpp:11:3", projectDir),
+				"",
+				"0x602000000010 is located 0 bytes inside of 4-byte region [0x602000000010,0x602000000014)",
+				"freed by thread T0 here:",
+				"    #0 0x52c960 in operator delete(void*) /llvm/compiler-rt/lib/asan/asan_new_delete.cpp:152:3",
+				fmt.Sprintf("    #1 0x530b12 in DoStuff(std::__cxx11::basic_string<char, std::char_traits<char>, std::allocator<char> > const&) %s:20:5", sourceFile),
+				fmt.Sprintf("    #2 0x52fde5 in LLVMFuzzerTestOneInput %s/fuzz_targets/do_stuff_fuzzer.cpp:11:3", projectDir),
+				"    #3 0x54bf7b in fuzzer::Fuzzer::ExecuteCallback(unsigned char const*, unsigned long) /llvm/compiler-rt/lib/fuzzer/FuzzerLoop.cpp:576:17",
+				"",
+				"previously allocated by thread T0 here:",
+				"    #0 0x52c1a0 in operator new(unsigned long) /llvm/compiler-rt/lib/asan/asan_new_delete.cpp:95:3",
+				fmt.Sprintf("    #1 0x530a10 in DoStuff(std::__cxx11::basic_string<char, std::char_traits<char>, std::allocator<char> > const&) %s:18:14", sourceFile),
+				"",
+				"SUMMARY: AddressSanitizer: heap-use-after-free api.cpp:24:10 in DoStuff",
+			},
+			[]*AuxiliaryStackTrace{{
+				Name: "freed by thread T0",
+				StackTrace: []*StackFrame{{
+					FrameNumber: 1,
+					SourceFile:  "api.cpp",
+					Function:    "DoStuff",
+					Line:        20,
+					Column:      5,
+				}, {
+					FrameNumber: 2,
+					SourceFile:  "fuzz_targets/do_stuff_fuzzer.cpp",
+					Function:    "LLVMFuzzerTestOneInput",
+					Line:        11,
+					Column:      3,
+				}},
+			}, {
+				Name: "previously allocated by thread T0",
+				StackTrace: []*StackFrame{{
+					FrameNumber: 1,
+					SourceFile:  "api.cpp",
+					Function:    "DoStuff",
+					Line:        18,
+					Column:      14,
+				}},
+			}},
+		},
+		{
+			"tsan_data_race",
+			[]string{
+				"WARNING: ThreadSanitizer: data race (pid=21640)",
+				"  Write of size 4 at 0x7b0400000010 by thread T2:",
+				fmt.Sprintf("    #0 Worker(void*) %s:24:10 (do_stuff_fuzzer+0xd1b2e)", sourceFile),
+				"",
+				"  Previous write of size 4 at 0x7b0400000010 by thread T1:",
+				fmt.Sprintf("    #0 Worker(void*) %s:24:10 (do_stuff_fuzzer+0xd1b2e)", sourceFile),
+				"",
+				"  Thread T2 (tid=21643, running) created by main thread at:",
+				"    #0 pthread_create /llvm/compiler-rt/lib/tsan/rtl/tsan_interceptors_posix.cpp:1022:3 (do_stuff_fuzzer+0x4f5a1)",
+				fmt.Sprintf("    #1 DoStuff %s:30:3 (do_stuff_fuzzer+0xd1c01)", sourceFile),
+			},
+			[]*AuxiliaryStackTrace{{
+				Name: "Previous write of size 4 at 0x7b0400000010 by thread T1",
+				StackTrace: []*StackFrame{{
+					SourceFile: "api.cpp",
+					Function:   "Worker",
+					Line:       24,
+					Column:     10,
+				}},
+			}, {
+				Name: "Thread T2 (tid=21643, running) created by main thread",
+				StackTrace: []*StackFrame{{
+					FrameNumber: 1,
+					SourceFile:  "api.cpp",
+					Function:    "DoStuff",
+					Line:        30,
+					Column:      3,
+				}},
+			}},
+		},
+		{
+			"msan_origin",
+			[]string{
+				"==3310==WARNING: MemorySanitizer: use-of-uninitialized-value",
+				fmt.Sprintf("    #0 0x530ce7 in DoStuff %s:24:10", sourceFile),
+				"",
+				"  Uninitialized value was created by a heap allocation",
+				"    #0 0x4a1d3f in malloc /llvm/compiler-rt/lib/msan/msan_interceptors.cpp:932:3",
+				fmt.Sprintf("    #1 0x530a10 in DoStuff %s:20:14", sourceFile),
+			},
+			[]*AuxiliaryStackTrace{{
+				Name: "Uninitialized value was created by a heap allocation",
+				StackTrace: []*StackFrame{{
+					FrameNumber: 1,
+					SourceFile:  "api.cpp",
+					Function:    "DoStuff",
+					Line:        20,
+					Column:      14,
+				}},
+			}},
+		},
+		{
+			"no_frames_in_project",
+			[]string{
+				"previously allocated by thread T0 here:",
+				"    #0 0x52c1a0 in operator new(unsigned long) /llvm/compiler-rt/lib/asan/asan_new_delete.cpp:95:3",
+			},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traces, err := parser.ParseAuxiliaryStackTraces(tt.logs)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedTraces, traces)
+		})
+	}
+}
+
+func TestStackTrace_Python(t *testing.T) {
+	projectDir := os.TempDir()
+	parser, err := NewParser(&ParserOptions{ProjectDir: projectDir, SupportAtheris: true})
+	require.NoError(t, err)
+
+	logs := []string{
+		" === Uncaught Python exception: ===",
+		"KeyError: 'name'",
+		"Traceback (most recent call last):",
+		fmt.Sprintf(`  File "%s", line 11, in TestOneInput`, filepath.Join(projectDir, "fuzz_parse.py")),
+		"    parse(data)",
+		fmt.Sprintf(`  File "%s", line 5, in parse`, filepath.Join(projectDir, "parser", "__init__.py")),
+		"    return json.loads(data)['name']",
+		fmt.Sprintf(`  File "%s", line 346, in loads`, filepath.Join(projectDir, ".venv", "lib", "python3.11", "site-packages", "json", "__init__.py")),
+		"    return _default_decoder.decode(s)",
+		"KeyError: 'name'",
+	}
+	trace, err := parser.Parse(logs)
+	require.NoError(t, err)
+	// The most recent call comes first and installed packages are
+	// filtered
+	require.Equal(t, []*StackFrame{
+		{
+			SourceFile: "parser/__init__.py",
+			Line:       5,
+			Function:   "parse",
+		},
+		{
+			SourceFile: "fuzz_parse.py",
+			Line:       11,
+			Function:   "TestOneInput",
+		},
+	}, trace)
+}
+
 func TestGetJavaSourceFilePath(t *testing.T) {
 	sourceFilePath := filepath.Join("src", "main", "java", "com", "example", "ExploreMe.java")
 	sourceMap := sourcemap.SourceMap{
@@ -217,14 +458,6 @@ func TestGetJavaSourceFilePath(t *testing.T) {
 	}
 }
 
-func TestRemoveLastPart(t *testing.T) {
-	result := removeLastPart("com.example")
-	assert.Equal(t, "com", result)
-
-	result = removeLastPart(result)
-	assert.Equal(t, "", result)
-}
-
 func TestEncodeStackTrace_Empty(t *testing.T) {
 	st := []*StackFrame{}
 	result := EncodeStackTrace(st)
@@ -0,0 +1,167 @@
+package sanitizer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/util/regexutil"
+)
+
+var (
+	reportHeaderPattern = regexp.MustCompile(
+		`==\d+==\s*(ERROR|WARNING): (?P<sanitizer>\w+Sanitizer): (?P<message>.+)$`,
+	)
+	threadSanitizerHeaderPattern = regexp.MustCompile(
+		`^WARNING: (?P<sanitizer>ThreadSanitizer): (?P<message>.+?)(?: \(pid=\d+\))?$`,
+	)
+	// UBSan prefixes runtime errors with the source location or, if
+	// there are no debug symbols, with the module and offset, which
+	// distinguishes them from Go panics like "panic: runtime error: ..."
+	runtimeErrorPattern = regexp.MustCompile(
+		`^(?:\S+:\d+(?::\d+)?|\(\S+\+0x[0-9a-fA-F]+\)): runtime error: (?P<message>(?P<bug_type>[^:]+).*)$`,
+	)
+	javaExceptionPattern = regexp.MustCompile(
+		`^== Java Exception:\s*(?P<bug_type>[\w.$]+)(?::\s*(?P<message>.*))?$`,
+	)
+
+	// Matches the address in the first line of ASan reports, e.g.
+	// "heap-buffer-overflow on address 0x602000000e31 at pc ..." or
+	// "attempting double-free on 0x6020000422b0 in thread T0:"
+	headerAddressPattern = regexp.MustCompile(`\bon (?:unknown )?(?:address )?(?P<address>0x[0-9a-fA-F]+)`)
+	// Matches the thread in the first line of ASan reports, e.g.
+	// "(pc 0x55d0b1 bp 0x7ffd sp 0x7ffd T0)" or "in thread T0:"
+	headerThreadPattern = regexp.MustCompile(`(?:in thread |\s)(?P<thread>T\d+)\)?:?$`)
+	// Matches the memory access in ASan, MSan and TSan reports, e.g.
+	// "READ of size 4 at 0x603000001044 thread T0" or
+	// "Write of size 4 at 0x7b0400000010 by thread T2:"
+	accessPattern = regexp.MustCompile(
+		`^\s*(?P<access_type>READ|WRITE|Read|Write|Atomic read|Atomic write) of size (?P<access_size>\d+) at (?P<address>0x[0-9a-fA-F]+) (?:by )?(?:thread (?P<thread>T\d+)|(?P<main_thread>main thread))`,
+	)
+	// ASan reports of segmentation faults don't contain an access line,
+	// but this one
+	signalAccessPattern      = regexp.MustCompile(`The signal is caused by a (?P<access_type>READ|WRITE) memory access`)
+	shadowMemoryStartPattern = regexp.MustCompile(`^Shadow bytes around the buggy address:`)
+	shadowMemoryRowPattern   = regexp.MustCompile(`^\s*(=>)?0x[0-9a-fA-F]+:`)
+	summaryPattern           = regexp.MustCompile(`^SUMMARY: (?P<summary>.+)$`)
+	// The bug type in the summary line is more precise than the
+	// description in the first line of the report for some bugs, e.g.
+	// "SUMMARY: AddressSanitizer: allocation-size-too-big ..."
+	summaryBugTypePattern = regexp.MustCompile(`^\w+Sanitizer: (?P<bug_type>[a-zA-Z][\w-]*(?: race)?)\b`)
+)
+
+// ParseReport parses the error report of a sanitizer or of Jazzer in
+// the logs of a finding into a finding.SanitizerReport. It returns nil
+// if the logs don't contain such an error report.
+func ParseReport(logs []string) *finding.SanitizerReport {
+	var report *finding.SanitizerReport
+	inShadowMemory := false
+
+	for _, line := range logs {
+		if report == nil {
+			report = parseReportHeader(line)
+			continue
+		}
+
+		if inShadowMemory {
+			if shadowMemoryRowPattern.MatchString(line) {
+				report.ShadowMemory = append(report.ShadowMemory, strings.TrimSpace(line))
+				continue
+			}
+			inShadowMemory = false
+		}
+		if shadowMemoryStartPattern.MatchString(line) && report.ShadowMemory == nil {
+			inShadowMemory = true
+			continue
+		}
+
+		if report.AccessType == "" {
+			if matches, found := regexutil.FindNamedGroupsMatch(accessPattern, line); found {
+				report.AccessType = strings.ToUpper(matches["access_type"])
+				// The regex only matches digits
+				report.AccessSize, _ = strconv.ParseUint(matches["access_size"], 10, 64)
+				report.Address = matches["address"]
+				if matches["main_thread"] != "" {
+					report.Thread = matches["main_thread"]
+				} else if matches["thread"] != "" {
+					report.Thread = matches["thread"]
+				}
+				continue
+			}
+			if matches, found := regexutil.FindNamedGroupsMatch(signalAccessPattern, line); found {
+				report.AccessType = matches["access_type"]
+				continue
+			}
+		}
+
+		if report.Summary == "" {
+			if matches, found := regexutil.FindNamedGroupsMatch(summaryPattern, line); found {
+				report.Summary = matches["summary"]
+				if report.Sanitizer == "UndefinedBehaviorSanitizer" {
+					continue
+				}
+				if matches, found = regexutil.FindNamedGroupsMatch(summaryBugTypePattern, report.Summary); found {
+					report.BugType = matches["bug_type"]
+				}
+			}
+		}
+	}
+
+	return report
+}
+
+func parseReportHeader(line string) *finding.SanitizerReport {
+	if matches, found := regexutil.FindNamedGroupsMatch(javaExceptionPattern, line); found {
+		return &finding.SanitizerReport{
+			Sanitizer: "Jazzer",
+			BugType:   matches["bug_type"],
+			Message:   strings.TrimSpace(matches["message"]),
+		}
+	}
+
+	if matches, found := regexutil.FindNamedGroupsMatch(runtimeErrorPattern, line); found {
+		return &finding.SanitizerReport{
+			Sanitizer: "UndefinedBehaviorSanitizer",
+			BugType:   matches["bug_type"],
+			Message:   matches["message"],
+		}
+	}
+
+	matches, found := regexutil.FindNamedGroupsMatch(reportHeaderPattern, line)
+	if !found {
+		matches, found = regexutil.FindNamedGroupsMatch(threadSanitizerHeaderPattern, line)
+		if !found {
+			return nil
+		}
+	}
+	message := strings.TrimSpace(matches["message"])
+	report := &finding.SanitizerReport{
+		Sanitizer: matches["sanitizer"],
+		BugType:   bugTypeFromMessage(message),
+		Message:   message,
+	}
+	if matches, found := regexutil.FindNamedGroupsMatch(headerAddressPattern, message); found {
+		report.Address = matches["address"]
+	}
+	if matches, found := regexutil.FindNamedGroupsMatch(headerThreadPattern, message); found {
+		report.Thread = matches["thread"]
+	}
+	return report
+}
+
+// bugTypeFromMessage extracts the type of the bug from the first line
+// of a sanitizer report, e.g. "heap-buffer-overflow" from
+// "heap-buffer-overflow on address 0x602000000e31 at pc ...".
+func bugTypeFromMessage(message string) string {
+	if message == "detected memory leaks" {
+		return "memory-leak"
+	}
+	bugType := strings.TrimPrefix(message, "attempting ")
+	for _, sep := range []string{" on ", " ("} {
+		if i := strings.Index(bugType, sep); i != -1 {
+			bugType = bugType[:i]
+		}
+	}
+	return bugType
+}
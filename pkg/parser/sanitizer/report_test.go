@@ -0,0 +1,155 @@
+package sanitizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/pkg/finding"
+)
+
+func TestParseReport(t *testing.T) {
+	tests := []struct {
+		desc     string
+		logs     []string
+		expected *finding.SanitizerReport
+	}{
+		{
+			desc: "ASan heap buffer overflow",
+			logs: []string{
+				"==17==ERROR: AddressSanitizer: heap-buffer-overflow on address 0x602000000e31 at pc 0x55657aa63e9f bp 0x7ffdae3791b0 sp 0x7ffdae378970",
+				"READ of size 1 at 0x602000000e31 thread T0",
+				"    #0 0x55657aa63e9e in parse /src/parser.cpp:12:7",
+				"",
+				"SUMMARY: AddressSanitizer: heap-buffer-overflow /src/parser.cpp:12:7 in parse",
+				"Shadow bytes around the buggy address:",
+				"  0x0c047fff8170: fa fa fd fa fa fa fd fa fa fa fd fa fa fa fd fa",
+				"=>0x0c047fff81c0: fa fa 00 fa fa fa[01]fa fa fa fa fa fa fa fa fa",
+				"  0x0c047fff81d0: fa fa fa fa fa fa fa fa fa fa fa fa fa fa fa fa",
+				"Shadow byte legend (one shadow byte represents 8 application bytes):",
+				"  Addressable:           00",
+			},
+			expected: &finding.SanitizerReport{
+				Sanitizer:  "AddressSanitizer",
+				BugType:    "heap-buffer-overflow",
+				Message:    "heap-buffer-overflow on address 0x602000000e31 at pc 0x55657aa63e9f bp 0x7ffdae3791b0 sp 0x7ffdae378970",
+				AccessType: "READ",
+				AccessSize: 1,
+				Address:    "0x602000000e31",
+				Thread:     "T0",
+				ShadowMemory: []string{
+					"0x0c047fff8170: fa fa fd fa fa fa fd fa fa fa fd fa fa fa fd fa",
+					"=>0x0c047fff81c0: fa fa 00 fa fa fa[01]fa fa fa fa fa fa fa fa fa",
+					"0x0c047fff81d0: fa fa fa fa fa fa fa fa fa fa fa fa fa fa fa fa",
+				},
+				Summary: "AddressSanitizer: heap-buffer-overflow /src/parser.cpp:12:7 in parse",
+			},
+		},
+		{
+			desc: "ASan segmentation fault",
+			logs: []string{
+				"==13==ERROR: AddressSanitizer: SEGV on unknown address 0x000000000000 (pc 0x55d0b1 bp 0x7ffd sp 0x7ffd T0)",
+				"==13==The signal is caused by a WRITE memory access.",
+				"==13==Hint: address points to the zero page.",
+				"SUMMARY: AddressSanitizer: SEGV /src/parser.cpp:20:5 in parse",
+			},
+			expected: &finding.SanitizerReport{
+				Sanitizer:  "AddressSanitizer",
+				BugType:    "SEGV",
+				Message:    "SEGV on unknown address 0x000000000000 (pc 0x55d0b1 bp 0x7ffd sp 0x7ffd T0)",
+				AccessType: "WRITE",
+				Address:    "0x000000000000",
+				Thread:     "T0",
+				Summary:    "AddressSanitizer: SEGV /src/parser.cpp:20:5 in parse",
+			},
+		},
+		{
+			desc: "ASan double free",
+			logs: []string{
+				"==16==ERROR: AddressSanitizer: attempting double-free on 0x6020000422b0 in thread T0:",
+				"SUMMARY: AddressSanitizer: double-free (/src/fuzz_test+0x4c5d8e) in free",
+			},
+			expected: &finding.SanitizerReport{
+				Sanitizer: "AddressSanitizer",
+				BugType:   "double-free",
+				Message:   "attempting double-free on 0x6020000422b0 in thread T0:",
+				Address:   "0x6020000422b0",
+				Thread:    "T0",
+				Summary:   "AddressSanitizer: double-free (/src/fuzz_test+0x4c5d8e) in free",
+			},
+		},
+		{
+			desc: "LSan memory leak",
+			logs: []string{
+				"==7829==ERROR: LeakSanitizer: detected memory leaks",
+				"Direct leak of 24 byte(s) in 1 object(s) allocated from:",
+				"SUMMARY: AddressSanitizer: 24 byte(s) leaked in 1 allocation(s).",
+			},
+			expected: &finding.SanitizerReport{
+				Sanitizer: "LeakSanitizer",
+				BugType:   "memory-leak",
+				Message:   "detected memory leaks",
+				Summary:   "AddressSanitizer: 24 byte(s) leaked in 1 allocation(s).",
+			},
+		},
+		{
+			desc: "UBSan runtime error",
+			logs: []string{
+				"/src/parser.cpp:7:11: runtime error: signed integer overflow: 2147483647 + 1 cannot be represented in type 'int'",
+				"SUMMARY: UndefinedBehaviorSanitizer: undefined-behavior /src/parser.cpp:7:11 in",
+			},
+			expected: &finding.SanitizerReport{
+				Sanitizer: "UndefinedBehaviorSanitizer",
+				BugType:   "signed integer overflow",
+				Message:   "signed integer overflow: 2147483647 + 1 cannot be represented in type 'int'",
+				Summary:   "UndefinedBehaviorSanitizer: undefined-behavior /src/parser.cpp:7:11 in",
+			},
+		},
+		{
+			desc: "TSan data race",
+			logs: []string{
+				"WARNING: ThreadSanitizer: data race (pid=21640)",
+				"  Write of size 4 at 0x7b0400000010 by thread T2:",
+				"    #0 Worker(void*) /src/race.cpp:6:10 (fuzz_test+0xd1b2e)",
+				"",
+				"  Previous read of size 4 at 0x7b0400000010 by main thread:",
+				"SUMMARY: ThreadSanitizer: data race /src/race.cpp:6:10 in Worker(void*)",
+			},
+			expected: &finding.SanitizerReport{
+				Sanitizer:  "ThreadSanitizer",
+				BugType:    "data race",
+				Message:    "data race",
+				AccessType: "WRITE",
+				AccessSize: 4,
+				Address:    "0x7b0400000010",
+				Thread:     "T2",
+				Summary:    "ThreadSanitizer: data race /src/race.cpp:6:10 in Worker(void*)",
+			},
+		},
+		{
+			desc: "Jazzer exception",
+			logs: []string{
+				"== Java Exception: java.lang.ArrayIndexOutOfBoundsException: Index 22 out of bounds for length 8",
+				"\tat com.example.parser.Parser.parseBytes(Parser.java:11)",
+			},
+			expected: &finding.SanitizerReport{
+				Sanitizer: "Jazzer",
+				BugType:   "java.lang.ArrayIndexOutOfBoundsException",
+				Message:   "Index 22 out of bounds for length 8",
+			},
+		},
+		{
+			desc: "no report",
+			logs: []string{
+				"panic: runtime error: index out of range [3] with length 3",
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expected, ParseReport(tc.logs))
+		})
+	}
+}
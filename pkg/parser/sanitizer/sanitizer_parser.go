@@ -11,6 +11,12 @@ var (
 	errorPattern = regexp.MustCompile(
 		`==\d+==\s*(ERROR|WARNING):.*Sanitizer:\s(?P<error_type>.+)`,
 	)
+	// TSan doesn't prefix its reports with the PID like the other
+	// sanitizers, but prints it after the error type, e.g.
+	// "WARNING: ThreadSanitizer: data race (pid=12345)"
+	threadSanitizerErrorPattern = regexp.MustCompile(
+		`^WARNING: ThreadSanitizer: (?P<error_type>.+?)(?: \(pid=\d+\))?$`,
+	)
 	runtimeErrorStartPattern = regexp.MustCompile(
 		`\S+ runtime error: (?P<error_type>[^:]+)`,
 	)
@@ -23,6 +29,7 @@ func ParseAsFinding(line string) *finding.Finding {
 	parsers := []func(string) *finding.Finding{
 		parseAsRuntimeReport,
 		parseAsErrorReport,
+		parseAsThreadSanitizerReport,
 		parseAsFatalErrorReport,
 	}
 	for _, parser := range parsers {
@@ -46,6 +53,19 @@ func parseAsErrorReport(log string) *finding.Finding {
 	return nil
 }
 
+func parseAsThreadSanitizerReport(log string) *finding.Finding {
+	result, found := regexutil.FindNamedGroupsMatch(threadSanitizerErrorPattern, log)
+	if found {
+		return &finding.Finding{
+			Type:    finding.ErrorTypeCrash,
+			Details: result["error_type"],
+			Logs:    []string{log},
+		}
+	}
+
+	return nil
+}
+
 func parseAsFatalErrorReport(log string) *finding.Finding {
 	found := fatalErrorPattern.MatchString(log)
 	if found {
@@ -20,6 +20,9 @@ func TestParseAsFinding(t *testing.T) {
 	tests := []test{
 		{desc: "LSAN fatal error", error: finding.ErrorTypeCrash, details: "", input: "==14237==LeakSanitizer has encountered a fatal error."},
 		{desc: "LSAN memory leak", error: finding.ErrorTypeCrash, details: "detected memory leaks", input: "==7829==ERROR: LeakSanitizer: detected memory leaks"},
+		{desc: "MSAN uninitialized value", error: finding.ErrorTypeCrash, details: "use-of-uninitialized-value", input: "==3310==WARNING: MemorySanitizer: use-of-uninitialized-value"},
+		{desc: "TSAN data race", error: finding.ErrorTypeCrash, details: "data race", input: "WARNING: ThreadSanitizer: data race (pid=21640)"},
+		{desc: "TSAN lock order inversion", error: finding.ErrorTypeCrash, details: "lock-order-inversion (potential deadlock)", input: "WARNING: ThreadSanitizer: lock-order-inversion (potential deadlock) (pid=21640)"},
 	}
 
 	for _, tc := range tests {
@@ -90,6 +90,11 @@ func (f RunfilesFinderImpl) GenHTMLPath() (string, error) {
 	return path, errors.WithStack(err)
 }
 
+func (f RunfilesFinderImpl) AFLFuzzPath() (string, error) {
+	path, err := exec.LookPath("afl-fuzz")
+	return path, errors.WithStack(err)
+}
+
 func (f RunfilesFinderImpl) PerlPath() (string, error) {
 	path, err := exec.LookPath("perl")
 	return path, errors.WithStack(err)
@@ -123,6 +128,37 @@ func (f RunfilesFinderImpl) NodePath() (string, error) {
 	return path, errors.WithStack(err)
 }
 
+func (f RunfilesFinderImpl) GoPath() (string, error) {
+	path, err := exec.LookPath("go")
+	return path, errors.WithStack(err)
+}
+
+func (f RunfilesFinderImpl) MesonPath() (string, error) {
+	path, err := exec.LookPath("meson")
+	return path, errors.WithStack(err)
+}
+
+func (f RunfilesFinderImpl) CargoPath() (string, error) {
+	path, err := exec.LookPath("cargo")
+	return path, errors.WithStack(err)
+}
+
+func (f RunfilesFinderImpl) CargoFuzzPath() (string, error) {
+	path, err := exec.LookPath("cargo-fuzz")
+	return path, errors.WithStack(err)
+}
+
+func (f RunfilesFinderImpl) PythonPath() (string, error) {
+	path, err := exec.LookPath("python3")
+	if err == nil {
+		return path, nil
+	}
+	// Some installations, e.g. virtual environments on Windows, only
+	// provide a "python" executable
+	path, err = exec.LookPath("python")
+	return path, errors.WithStack(err)
+}
+
 func (f RunfilesFinderImpl) Minijail0Path() (string, error) {
 	return f.findFollowSymlinks("bin/minijail0")
 }
@@ -8,12 +8,14 @@ import (
 )
 
 type RunfilesFinder interface {
+	AFLFuzzPath() (string, error)
 	BazelPath() (string, error)
 	CIFuzzIncludePath() (string, error)
 	CIFuzzLinuxExecutablePath() (string, error)
 	ClangPath() (string, error)
 	CMakePath() (string, error)
 	CMakePresetsPath() (string, error)
+	MesonPath() (string, error)
 	JacocoAgentJarPath() (string, error)
 	JacocoCLIJarPath() (string, error)
 	LLVMCovPath() (string, error)
@@ -34,6 +36,10 @@ type RunfilesFinder interface {
 	JavaPath() (string, error)
 	JavaHomePath() (string, error)
 	NodePath() (string, error)
+	GoPath() (string, error)
+	CargoPath() (string, error)
+	CargoFuzzPath() (string, error)
+	PythonPath() (string, error)
 	ErrorDetailsPath() (string, error)
 }
 
@@ -0,0 +1,589 @@
+package aflpp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/log"
+	aflpp_parser "code-intelligence.com/cifuzz/pkg/parser/aflpp"
+	"code-intelligence.com/cifuzz/pkg/parser/errorid"
+	libfuzzer_parser "code-intelligence.com/cifuzz/pkg/parser/libfuzzer"
+	"code-intelligence.com/cifuzz/pkg/report"
+	"code-intelligence.com/cifuzz/pkg/runfiles"
+	fuzzer_runner "code-intelligence.com/cifuzz/pkg/runner"
+	"code-intelligence.com/cifuzz/util/envutil"
+	"code-intelligence.com/cifuzz/util/executil"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+const (
+	// pollInterval is the interval in which the output directory of
+	// afl-fuzz is checked for new statistics, crashes and corpus
+	// entries.
+	pollInterval = time.Second
+	// ExitGracePeriod is the time we give afl-fuzz to exit after the
+	// timeout passed via -V was exceeded.
+	ExitGracePeriod = time.Second * 5
+	// afl-fuzz skips inputs larger than 1 MB, so we don't copy those
+	// into the input directory.
+	maxInputSize = 1024 * 1024
+)
+
+type RunnerOptions struct {
+	Dictionary         string
+	EngineArgs         []string
+	EnvVars            []string
+	FuzzTarget         string
+	GeneratedCorpusDir string
+	KeepColor          bool
+	LibraryDirs        []string
+	LogOutput          io.Writer
+	ProjectDir         string
+	ReportHandler      report.Handler
+	SeedCorpusDirs     []string
+	// SkipBinaryCheck must be set if the fuzz target is a script which
+	// executes the instrumented binary (as is the case with Bazel),
+	// because afl-fuzz otherwise refuses to run it.
+	SkipBinaryCheck bool
+	Timeout         time.Duration
+	UseMinijail     bool
+	Verbose         bool
+}
+
+func (options *RunnerOptions) ValidateOptions() error {
+	if options.UseMinijail {
+		return errors.New("Running AFL++ in the sandbox is not supported")
+	}
+
+	var err error
+	options.FuzzTarget, err = filepath.Abs(options.FuzzTarget)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if options.LogOutput == nil {
+		options.LogOutput = os.Stderr
+	}
+
+	return nil
+}
+
+type Runner struct {
+	*RunnerOptions
+
+	started chan struct{}
+	cmd     *executil.Cmd
+	workDir string
+}
+
+func NewRunner(options *RunnerOptions) *Runner {
+	return &Runner{
+		RunnerOptions: options,
+		started:       make(chan struct{}, 1),
+	}
+}
+
+func (r *Runner) Run(ctx context.Context) error {
+	err := r.ValidateOptions()
+	if err != nil {
+		return err
+	}
+
+	aflFuzz, err := runfiles.Finder.AFLFuzzPath()
+	if err != nil {
+		return err
+	}
+
+	r.workDir, err = os.MkdirTemp("", "aflpp-")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(r.workDir)
+
+	// afl-fuzz reads its initial inputs from a single directory, so we
+	// collect the seeds and the generated corpus in one directory
+	inputDir := filepath.Join(r.workDir, "input")
+	corpusDirs := append(append([]string{}, r.SeedCorpusDirs...), r.GeneratedCorpusDir)
+	numSeeds, err := PrepareInputDir(inputDir, corpusDirs...)
+	if err != nil {
+		return err
+	}
+	outputDir := filepath.Join(r.workDir, "output")
+
+	args := []string{aflFuzz, "-i", inputDir, "-o", outputDir}
+
+	// Tell afl-fuzz to exit after the timeout
+	if r.Timeout > 0 {
+		args = append(args, "-V", strconv.FormatInt(int64(r.Timeout.Seconds()), 10))
+	}
+
+	// Tell afl-fuzz which dictionary it should use
+	if r.Dictionary != "" {
+		args = append(args, "-x", r.Dictionary)
+	}
+
+	// Add user-specified afl-fuzz options
+	args = append(args, r.EngineArgs...)
+
+	args = append(args, "--", r.FuzzTarget)
+
+	env, err := r.FuzzerEnvironment()
+	if err != nil {
+		return err
+	}
+
+	return r.runAndReport(ctx, args, env, outputDir, numSeeds)
+}
+
+func (r *Runner) runAndReport(ctx context.Context, args []string, env []string, outputDir string, numSeeds uint) error {
+	var err error
+
+	// Ideally, afl-fuzz exits on its own after the timeout, because we
+	// specified -V above. For the case that it does not, we still set
+	// up a timeout handler here which terminates it.
+	var cmdCtx context.Context
+	var cancelCmdCtx context.CancelFunc
+	if r.Timeout > 0 {
+		cmdCtx, cancelCmdCtx = context.WithTimeout(ctx, r.Timeout+ExitGracePeriod)
+	} else {
+		cmdCtx, cancelCmdCtx = context.WithCancel(ctx)
+	}
+	defer cancelCmdCtx()
+	r.cmd = executil.CommandContext(cmdCtx, args[0], args[1:]...)
+	r.cmd.Env, err = envutil.Copy(os.Environ(), env)
+	if err != nil {
+		return err
+	}
+
+	// afl-fuzz prints its status and any errors to stdout. We only
+	// print that in verbose mode, but keep it to provide users with
+	// some context if afl-fuzz exits unexpectedly.
+	var output bytes.Buffer
+	if r.Verbose {
+		ptermWriter := log.NewPTermWriter(r.LogOutput)
+		r.cmd.Stdout = io.MultiWriter(ptermWriter, &output)
+		r.cmd.Stderr = io.MultiWriter(ptermWriter, &output)
+	} else {
+		r.cmd.Stdout = &output
+		r.cmd.Stderr = &output
+	}
+
+	log.Debugf("Command: %s", envutil.QuotedCommandWithEnv(r.cmd.Args, env))
+	err = r.cmd.Start()
+	if err != nil {
+		return err
+	}
+	r.started <- struct{}{}
+
+	err = r.ReportHandler.Handle(&report.Report{
+		Status:   report.RunStatusInitializing,
+		NumSeeds: numSeeds,
+	})
+	if err != nil {
+		return err
+	}
+
+	parser := aflpp_parser.NewOutputParser(outputDir)
+	waitErrCh := make(chan error, 1)
+	go func() {
+		waitErrCh <- r.cmd.Wait()
+	}()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-waitErrCh:
+			// Report everything afl-fuzz wrote before it exited
+			pollErr := r.poll(ctx, parser)
+			if pollErr != nil {
+				return pollErr
+			}
+			if err == nil || r.cmd.TerminatedAfterContextDone() {
+				return nil
+			}
+			if !r.Verbose {
+				log.Print(output.String())
+			}
+			return cmdutils.WrapExecError(errors.WithStack(err), r.cmd.Cmd)
+		case <-ticker.C:
+			err := r.poll(ctx, parser)
+			if err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// poll reports the current metric and any new crashes and stores new
+// corpus entries in the generated corpus directory.
+func (r *Runner) poll(ctx context.Context, parser *aflpp_parser.OutputParser) error {
+	metric, err := parser.Metric()
+	if err != nil {
+		return err
+	}
+	if metric != nil {
+		err = r.ReportHandler.Handle(&report.Report{
+			Status: report.RunStatusRunning,
+			Metric: metric,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	queueEntries, err := parser.NewQueueEntries()
+	if err != nil {
+		return err
+	}
+	for _, entry := range queueEntries {
+		err = addToCorpus(entry, r.GeneratedCorpusDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	crashes, err := parser.NewCrashes()
+	if err != nil {
+		return err
+	}
+	for _, crash := range crashes {
+		f, err := r.reproduceCrash(ctx, crash)
+		if err != nil {
+			return err
+		}
+		err = r.ReportHandler.Handle(&report.Report{
+			Status:  report.RunStatusRunning,
+			Finding: f,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reproduceCrash runs the fuzz target on the crashing input to get the
+// sanitizer report, which afl-fuzz doesn't store, and parses it into a
+// finding.
+func (r *Runner) reproduceCrash(ctx context.Context, aflCrashFile string) (*finding.Finding, error) {
+	input, err := os.ReadFile(aflCrashFile)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// The names of the files written by afl-fuzz contain colons and
+	// commas, so we store the input under a name like libFuzzer would
+	// use, which is also used when the input is added to the seed
+	// corpus
+	hash := sha1.Sum(input) //nolint:gosec // Only used to name files
+	crashFile := filepath.Join(r.workDir, "crash-"+hex.EncodeToString(hash[:]))
+	err = os.WriteFile(crashFile, input, 0o644)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	env, err := r.ReproducerEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	// The AFL++ driver executes the inputs passed as arguments once
+	cmd := executil.CommandContext(ctx, r.FuzzTarget, crashFile)
+	cmd.Env, err = envutil.Copy(os.Environ(), env)
+	if err != nil {
+		return nil, err
+	}
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	log.Debugf("Command: %s", envutil.QuotedCommandWithEnv(cmd.Args, env))
+	// The command is expected to fail, we only need its output
+	_ = cmd.Run()
+	if ctx.Err() != nil {
+		return nil, errors.WithStack(ctx.Err())
+	}
+
+	// The output is read twice, by the parser and to create the logs of
+	// findings which the parser doesn't detect
+	outputBytes := output.Bytes()
+	f, err := r.parseFinding(ctx, bytes.NewReader(outputBytes))
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		// The crash couldn't be reproduced or didn't produce a sanitizer
+		// report (e.g. because the fuzz target was killed by a signal
+		// which is not handled by the sanitizers)
+		f = &finding.Finding{
+			Type:    finding.ErrorTypeCrash,
+			Details: "deadly signal",
+			Logs:    readLines(bytes.NewReader(outputBytes)),
+		}
+		if signal := aflpp_parser.CrashSignal(aflCrashFile); signal != 0 {
+			f.Logs = append([]string{fmt.Sprintf("The fuzz test was killed by signal %d", signal)}, f.Logs...)
+		}
+		f.MoreDetails = &finding.ErrorDetails{
+			ID: errorid.ForFinding(f),
+		}
+	}
+	f.InputData = input
+	f.InputFile = crashFile
+	return f, nil
+}
+
+func (r *Runner) parseFinding(ctx context.Context, output io.Reader) (*finding.Finding, error) {
+	reportsCh := make(chan *report.Report, 10)
+	parser := libfuzzer_parser.NewLibfuzzerOutputParser(&libfuzzer_parser.Options{
+		KeepColor:  r.KeepColor,
+		ProjectDir: r.ProjectDir,
+	})
+
+	routines, routinesCtx := errgroup.WithContext(ctx)
+	routines.Go(func() error {
+		return parser.Parse(routinesCtx, output, reportsCh)
+	})
+
+	var f *finding.Finding
+	for rep := range reportsCh {
+		// Only use the first finding, which is the one that caused the
+		// crash
+		if rep.Finding != nil && f == nil {
+			f = rep.Finding
+		}
+	}
+
+	err := routines.Wait()
+	if err != nil {
+		// Routines.Wait() returns an error created by us so it already
+		// has a stack trace and we don't want to add another one here
+		// nolint: wrapcheck
+		return nil, err
+	}
+	return f, nil
+}
+
+// FuzzerEnvironment returns the environment in which afl-fuzz is run.
+func (r *Runner) FuzzerEnvironment() ([]string, error) {
+	env, err := r.commonEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	aflEnv := map[string]string{
+		// Print status updates instead of the interactive UI
+		"AFL_NO_UI": "1",
+		// Stop fuzzing after the first crash, like libFuzzer does
+		"AFL_BENCH_UNTIL_CRASH": "1",
+		// Don't fail if the CPU frequency scaling or the core dump
+		// handling of the system is not optimal for fuzzing, which
+		// users often can't change
+		"AFL_SKIP_CPUFREQ":                      "1",
+		"AFL_I_DONT_CARE_ABOUT_MISSING_CRASHES": "1",
+	}
+	if r.SkipBinaryCheck {
+		aflEnv["AFL_SKIP_BIN_CHECK"] = "1"
+	}
+	for key, val := range aflEnv {
+		env, err = envutil.Setenv(env, key, val)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// afl-fuzz detects crashes via signals and refuses to run if the
+	// sanitizers are not configured to abort. Symbolizing stack traces
+	// is only slowing down fuzzing, we get them when reproducing the
+	// crash.
+	overrideOptions := map[string]string{
+		"abort_on_error": "1",
+		"symbolize":      "0",
+	}
+	env, err = fuzzer_runner.SetASANOptions(env, nil, overrideOptions)
+	if err != nil {
+		return nil, err
+	}
+	ubsanOptions := envutil.Getenv(env, "UBSAN_OPTIONS")
+	ubsanOptions = fuzzer_runner.SetSanitizerOptions(ubsanOptions, nil, map[string]string{
+		"halt_on_error":  "1",
+		"abort_on_error": "1",
+		"symbolize":      "0",
+	})
+	return envutil.Setenv(env, "UBSAN_OPTIONS", ubsanOptions)
+}
+
+// ReproducerEnvironment returns the environment in which the fuzz
+// target is run to reproduce crashes found by afl-fuzz.
+func (r *Runner) ReproducerEnvironment() ([]string, error) {
+	env, err := r.commonEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	overrideOptions := map[string]string{
+		"abort_on_error": "0",
+	}
+	return fuzzer_runner.SetASANOptions(env, nil, overrideOptions)
+}
+
+func (r *Runner) commonEnvironment() ([]string, error) {
+	env, err := fuzzer_runner.FuzzerEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	env, err = fuzzer_runner.SetLDLibraryPath(env, r.LibraryDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the user-specified environment variables before setting the
+	// sanitizer options, so that we can override the options which we
+	// need to override and keep the others.
+	if os.Getenv("ASAN_OPTIONS") != "" {
+		env, err = envutil.Setenv(env, "ASAN_OPTIONS", os.Getenv("ASAN_OPTIONS"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if os.Getenv("UBSAN_OPTIONS") != "" {
+		env, err = envutil.Setenv(env, "UBSAN_OPTIONS", os.Getenv("UBSAN_OPTIONS"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	env, err = fuzzer_runner.AddEnvFlags(env, r.EnvVars)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err = fuzzer_runner.SetCommonUBSANOptions(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return fuzzer_runner.SetCommonASANOptions(env)
+}
+
+func (r *Runner) Cleanup(ctx context.Context) {
+	// Wait until the command has been started, else we can't terminate it
+	select {
+	case <-ctx.Done():
+		return
+	case <-r.started:
+		err := r.cmd.TerminateProcessGroup()
+		if err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+// PrepareInputDir copies the inputs from the given corpus directories
+// into the input directory, which is created if it doesn't exist, and
+// returns the number of inputs. afl-fuzz requires at least one input,
+// so if the corpus directories don't contain any, an input consisting
+// of a single newline is created.
+func PrepareInputDir(inputDir string, corpusDirs ...string) (uint, error) {
+	err := os.MkdirAll(inputDir, 0o755)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	var numInputs uint
+	for _, dir := range corpusDirs {
+		exists, err := fileutil.Exists(dir)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			continue
+		}
+		err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if info.Size() > maxInputSize {
+				log.Debugf("Skipping input %s, which is larger than 1 MB", path)
+				return nil
+			}
+			added, err := addFileByContentHash(path, inputDir)
+			if err != nil {
+				return err
+			}
+			if added {
+				numInputs++
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if numInputs == 0 {
+		err = os.WriteFile(filepath.Join(inputDir, "empty"), []byte("\n"), 0o644)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+	}
+	return numInputs, nil
+}
+
+// addToCorpus stores the given input in the corpus directory. Like
+// libFuzzer, we name corpus entries after the SHA-1 of their content,
+// so that the same input is only stored once.
+func addToCorpus(input string, corpusDir string) error {
+	_, err := addFileByContentHash(input, corpusDir)
+	return err
+}
+
+func addFileByContentHash(path string, dir string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	hash := sha1.Sum(content) //nolint:gosec // Only used to name files
+	target := filepath.Join(dir, hex.EncodeToString(hash[:]))
+	exists, err := fileutil.Exists(target)
+	if err != nil || exists {
+		return false, err
+	}
+	err = os.WriteFile(target, content, 0o644)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func readLines(r io.Reader) []string {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
@@ -0,0 +1,172 @@
+package aflpp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/testutil"
+	"code-intelligence.com/cifuzz/pkg/finding"
+	aflpp_parser "code-intelligence.com/cifuzz/pkg/parser/aflpp"
+	"code-intelligence.com/cifuzz/pkg/report"
+	"code-intelligence.com/cifuzz/pkg/runfiles"
+)
+
+func TestPrepareInputDir(t *testing.T) {
+	tempDir := testutil.MkdirTemp(t, "", "aflpp-runner-test-")
+	seedCorpusDir := filepath.Join(tempDir, "seeds")
+	generatedCorpusDir := filepath.Join(tempDir, "generated")
+	require.NoError(t, os.MkdirAll(filepath.Join(seedCorpusDir, "subdir"), 0o755))
+	require.NoError(t, os.MkdirAll(generatedCorpusDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(seedCorpusDir, "seed1"), []byte("foo"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(seedCorpusDir, "subdir", "seed2"), []byte("bar"), 0o644))
+	// Inputs with the same content are only added once
+	require.NoError(t, os.WriteFile(filepath.Join(generatedCorpusDir, "input"), []byte("foo"), 0o644))
+
+	inputDir := filepath.Join(tempDir, "input")
+	numInputs, err := PrepareInputDir(inputDir, seedCorpusDir, generatedCorpusDir, filepath.Join(tempDir, "does-not-exist"))
+	require.NoError(t, err)
+	require.EqualValues(t, 2, numInputs)
+	entries, err := os.ReadDir(inputDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// afl-fuzz needs at least one input, so one is created if the
+	// corpus directories are empty
+	emptyInputDir := filepath.Join(tempDir, "empty-input")
+	numInputs, err = PrepareInputDir(emptyInputDir, filepath.Join(tempDir, "does-not-exist"))
+	require.NoError(t, err)
+	require.EqualValues(t, 0, numInputs)
+	entries, err = os.ReadDir(emptyInputDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+// fakeRunfilesFinder returns a fake llvm-symbolizer, which is only
+// passed to the fuzz target via the environment
+type fakeRunfilesFinder struct {
+	runfiles.RunfilesFinderImpl
+	llvmSymbolizerPath string
+}
+
+func (f fakeRunfilesFinder) LLVMSymbolizerPath() (string, error) {
+	return f.llvmSymbolizerPath, nil
+}
+
+// newTestRunner returns a runner for a fake fuzz target, which is a
+// shell script with the given content
+func newTestRunner(t *testing.T, script string) (*Runner, *testReportHandler) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The fake fuzz target is a shell script")
+	}
+
+	tempDir := testutil.MkdirTemp(t, "", "aflpp-runner-test-")
+	llvmSymbolizer := filepath.Join(tempDir, "llvm-symbolizer")
+	require.NoError(t, os.WriteFile(llvmSymbolizer, nil, 0o755))
+	origFinder := runfiles.Finder
+	runfiles.Finder = fakeRunfilesFinder{llvmSymbolizerPath: llvmSymbolizer}
+	t.Cleanup(func() { runfiles.Finder = origFinder })
+
+	fuzzTarget := filepath.Join(tempDir, "fuzz_target")
+	require.NoError(t, os.WriteFile(fuzzTarget, []byte("#!/bin/sh\n"+script), 0o755))
+
+	handler := &testReportHandler{}
+	r := NewRunner(&RunnerOptions{
+		FuzzTarget:         fuzzTarget,
+		GeneratedCorpusDir: filepath.Join(tempDir, "generated"),
+		ProjectDir:         tempDir,
+		ReportHandler:      handler,
+	})
+	r.workDir = filepath.Join(tempDir, "work")
+	require.NoError(t, os.MkdirAll(r.workDir, 0o755))
+	require.NoError(t, os.MkdirAll(r.GeneratedCorpusDir, 0o755))
+	return r, handler
+}
+
+type testReportHandler struct {
+	reports []*report.Report
+}
+
+func (h *testReportHandler) Handle(r *report.Report) error {
+	h.reports = append(h.reports, r)
+	return nil
+}
+
+// writeCrash writes a crashing input to the crashes directory of the
+// afl-fuzz output directory and returns its path
+func writeCrash(t *testing.T, outputDir string, name string, content string) string {
+	crashesDir := filepath.Join(outputDir, aflpp_parser.DefaultFuzzerName, "crashes")
+	require.NoError(t, os.MkdirAll(crashesDir, 0o755))
+	path := filepath.Join(crashesDir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestReproduceCrash_SanitizerReport(t *testing.T) {
+	r, _ := newTestRunner(t, `
+echo "INFO: Running with entropic power schedule"
+echo "==1234==ERROR: AddressSanitizer: heap-buffer-overflow on address 0x602000000011"
+echo "READ of size 1 at 0x602000000011 thread T0"
+echo "    #0 0x55d5 in parse /src/parser.c:12:3"
+echo "SUMMARY: AddressSanitizer: heap-buffer-overflow /src/parser.c:12:3 in parse"
+exit 1
+`)
+	crashFile := writeCrash(t, filepath.Join(r.workDir, "output"), "id:000000,sig:06,src:000000,time:12,execs:34,op:havoc,rep:2", "crash")
+
+	f, err := r.reproduceCrash(context.Background(), crashFile)
+	require.NoError(t, err)
+	require.NotNil(t, f)
+	require.Equal(t, finding.ErrorTypeCrash, f.Type)
+	require.Contains(t, f.Details, "heap-buffer-overflow")
+	require.Equal(t, []byte("crash"), f.InputData)
+	require.FileExists(t, f.InputFile)
+	require.NotContains(t, filepath.Base(f.InputFile), ":")
+}
+
+func TestReproduceCrash_Signal(t *testing.T) {
+	// The fuzz target is killed by a signal which isn't handled by the
+	// sanitizers, so there is no sanitizer report
+	r, _ := newTestRunner(t, `
+echo "Processing input"
+echo "last words"
+kill -9 $$
+`)
+	crashFile := writeCrash(t, filepath.Join(r.workDir, "output"), "id:000000,sig:09,src:000000,time:12,execs:34,op:havoc,rep:2", "crash")
+
+	f, err := r.reproduceCrash(context.Background(), crashFile)
+	require.NoError(t, err)
+	require.NotNil(t, f)
+	require.Equal(t, finding.ErrorTypeCrash, f.Type)
+	require.Equal(t, "deadly signal", f.Details)
+	// The logs contain the output of the fuzz target
+	require.Equal(t, []string{"The fuzz test was killed by signal 9", "Processing input", "last words"}, f.Logs)
+}
+
+func TestPoll_ReportsCrashes(t *testing.T) {
+	r, handler := newTestRunner(t, `
+echo "==1234==ERROR: AddressSanitizer: SEGV on unknown address 0x000000000000"
+echo "SUMMARY: AddressSanitizer: SEGV /src/parser.c:12:3 in parse"
+exit 1
+`)
+	outputDir := filepath.Join(r.workDir, "output")
+	writeCrash(t, outputDir, "id:000000,sig:11,src:000000,time:12,execs:34,op:havoc,rep:2", "crash1")
+	writeCrash(t, outputDir, "id:000001,sig:11,src:000000,time:13,execs:35,op:havoc,rep:2", "crash2")
+	// Other files in the crashes directory are ignored
+	writeCrash(t, outputDir, "README.txt", "")
+
+	parser := aflpp_parser.NewOutputParser(outputDir)
+	err := r.poll(context.Background(), parser)
+	require.NoError(t, err)
+	require.Len(t, handler.reports, 2)
+	require.Equal(t, []byte("crash1"), handler.reports[0].Finding.InputData)
+	require.Equal(t, []byte("crash2"), handler.reports[1].Finding.InputData)
+
+	// Crashes are only reported once
+	err = r.poll(context.Background(), parser)
+	require.NoError(t, err)
+	require.Len(t, handler.reports, 2)
+}
@@ -0,0 +1,126 @@
+package atheris
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/pkg/options"
+	"code-intelligence.com/cifuzz/pkg/runfiles"
+	"code-intelligence.com/cifuzz/pkg/runner/libfuzzer"
+	"code-intelligence.com/cifuzz/util/envutil"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+type RunnerOptions struct {
+	LibfuzzerOptions *libfuzzer.RunnerOptions
+	// The Python script which runs the fuzz test via atheris.Fuzz
+	FuzzTestScript string
+}
+
+func (options *RunnerOptions) ValidateOptions() error {
+	err := options.LibfuzzerOptions.ValidateOptions()
+	if err != nil {
+		return err
+	}
+
+	if options.FuzzTestScript == "" {
+		return errors.New("Fuzz test script must be specified")
+	}
+	if options.LibfuzzerOptions.UseMinijail {
+		return errors.New("Minijail is not supported for Python fuzz tests")
+	}
+
+	return nil
+}
+
+type Runner struct {
+	*RunnerOptions
+	*libfuzzer.Runner
+}
+
+func NewRunner(options *RunnerOptions) *Runner {
+	libfuzzerRunner := libfuzzer.NewRunner(options.LibfuzzerOptions)
+	libfuzzerRunner.SupportAtheris = true
+	return &Runner{options, libfuzzerRunner}
+}
+
+func (r *Runner) Run(ctx context.Context) error {
+	err := r.ValidateOptions()
+	if err != nil {
+		return err
+	}
+
+	pythonBin, err := runfiles.Finder.PythonPath()
+	if err != nil {
+		return err
+	}
+
+	// Atheris passes the arguments of the script to libFuzzer
+	args := []string{pythonBin, r.FuzzTestScript}
+
+	// -------------------------
+	// --- libfuzzer options ---
+	// -------------------------
+	// Tell libfuzzer to exit after the timeout
+	timeoutSeconds := strconv.FormatInt(int64(r.Timeout.Seconds()), 10)
+	args = append(args, options.LibFuzzerMaxTotalTimeFlag(timeoutSeconds))
+
+	// Tell libfuzzer which dictionary it should use
+	if r.Dictionary != "" {
+		args = append(args, options.LibFuzzerDictionaryFlag(r.Dictionary))
+	}
+
+	// Add user-specified libfuzzer options
+	args = append(args, r.EngineArgs...)
+
+	// Tell libfuzzer which corpus directory it should use
+	args = append(args, r.GeneratedCorpusDir)
+
+	// Add any seed corpus directories as further positional arguments
+	args = append(args, r.SeedCorpusDirs...)
+
+	// Set the directory in which fuzzing artifacts (e.g. crashes) are
+	// stored. This must be an absolute path, because else crash files
+	// are created in the working directory of the script.
+	outputDir, err := os.MkdirTemp("", "atheris-out-")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(outputDir)
+	args = append(args, options.LibFuzzerArtifactPrefixFlag(outputDir+"/"))
+
+	env, err := r.FuzzerEnvironment()
+	if err != nil {
+		return err
+	}
+
+	return r.RunLibfuzzerAndReport(ctx, args, env)
+}
+
+func (r *Runner) FuzzerEnvironment() ([]string, error) {
+	env, err := r.Runner.FuzzerEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	// Python adds the directory of the script to the module search
+	// path, but fuzz tests are usually located in a subdirectory of
+	// the project and import the modules of the project by their path
+	// relative to the project directory.
+	if r.ProjectDir != "" {
+		pythonPath := envutil.AppendToPathList(envutil.Getenv(env, "PYTHONPATH"), r.ProjectDir)
+		env, err = envutil.Setenv(env, "PYTHONPATH", pythonPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return env, nil
+}
+
+func (r *Runner) Cleanup(ctx context.Context) {
+	r.Runner.Cleanup(ctx)
+}
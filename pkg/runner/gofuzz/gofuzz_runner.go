@@ -0,0 +1,341 @@
+package gofuzz
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/pkg/log"
+	gofuzz_parser "code-intelligence.com/cifuzz/pkg/parser/gofuzz"
+	"code-intelligence.com/cifuzz/pkg/report"
+	fuzzer_runner "code-intelligence.com/cifuzz/pkg/runner"
+	"code-intelligence.com/cifuzz/util/envutil"
+	"code-intelligence.com/cifuzz/util/executil"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+const (
+	maxBufferedReports = 10
+	// ExitGracePeriod is the time we give `go test` to exit after the
+	// timeout passed via -fuzztime was exceeded.
+	ExitGracePeriod = time.Second * 5
+)
+
+// All files in a Go fuzz corpus start with this header
+var corpusFileHeader = []byte("go test fuzz v1\n")
+
+type RunnerOptions struct {
+	Dictionary         string
+	EngineArgs         []string
+	EnvVars            []string
+	GeneratedCorpusDir string
+	LogOutput          io.Writer
+	// The import path of the package containing the fuzz test
+	Package string
+	// The directory of the package containing the fuzz test
+	PackageDir     string
+	ProjectDir     string
+	ReportHandler  report.Handler
+	SeedCorpusDirs []string
+	// The name of the fuzz test function
+	TestName    string
+	Timeout     time.Duration
+	UseMinijail bool
+	Verbose     bool
+}
+
+func (options *RunnerOptions) ValidateOptions() error {
+	if options.UseMinijail {
+		return errors.New("Running Go fuzz tests in the sandbox is not supported")
+	}
+	if options.Package == "" || options.TestName == "" {
+		return errors.New("Package and name of the fuzz test must be specified")
+	}
+	if options.LogOutput == nil {
+		options.LogOutput = os.Stderr
+	}
+	return nil
+}
+
+type Runner struct {
+	*RunnerOptions
+
+	started chan struct{}
+	cmd     *executil.Cmd
+	workDir string
+}
+
+func NewRunner(options *RunnerOptions) *Runner {
+	return &Runner{
+		RunnerOptions: options,
+		started:       make(chan struct{}, 1),
+	}
+}
+
+func (r *Runner) Run(ctx context.Context) error {
+	err := r.ValidateOptions()
+	if err != nil {
+		return err
+	}
+
+	if r.Dictionary != "" {
+		log.Warn("Go fuzz tests don't support dictionaries, ignoring the dictionary")
+	}
+
+	r.workDir, err = os.MkdirTemp("", "gofuzz-")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(r.workDir)
+
+	// `go test -fuzz` only reads the seed corpus from testdata/fuzz and
+	// the generated corpus from the build cache, so we add the inputs
+	// of the user-specified seed corpus dirs to the generated corpus
+	err = CopyToCorpus(r.GeneratedCorpusDir, r.SeedCorpusDirs...)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"go", "test", "-run", "^$", "-fuzz", "^" + r.TestName + "$"}
+
+	// Tell `go test` to stop fuzzing after the timeout. The test binary
+	// is killed after 10 minutes by default, so we disable that.
+	args = append(args, "-timeout", "0")
+	if r.Timeout > 0 {
+		args = append(args, "-fuzztime", strconv.FormatInt(int64(r.Timeout.Seconds()), 10)+"s")
+	}
+
+	// Add user-specified `go test` options
+	args = append(args, r.EngineArgs...)
+
+	args = append(args, r.Package)
+
+	env, err := fuzzer_runner.AddEnvFlags(os.Environ(), r.EnvVars)
+	if err != nil {
+		return err
+	}
+
+	return r.runAndReport(ctx, args, env)
+}
+
+func (r *Runner) runAndReport(ctx context.Context, args []string, env []string) error {
+	var cmdCtx context.Context
+	var cancelCmdCtx context.CancelFunc
+	if r.Timeout > 0 {
+		cmdCtx, cancelCmdCtx = context.WithTimeout(ctx, r.Timeout+ExitGracePeriod)
+	} else {
+		cmdCtx, cancelCmdCtx = context.WithCancel(ctx)
+	}
+	defer cancelCmdCtx()
+	r.cmd = executil.CommandContext(cmdCtx, args[0], args[1:]...)
+	r.cmd.Dir = r.ProjectDir
+	r.cmd.Env = env
+
+	// `go test` prints the fuzzing status and the failures to stdout
+	// and build errors to stderr, we parse both. The output is only
+	// printed in verbose mode, but kept to provide users with some
+	// context if `go test` exits unexpectedly.
+	var output bytes.Buffer
+	var outputWriter io.Writer = &output
+	if r.Verbose {
+		outputWriter = io.MultiWriter(log.NewPTermWriter(r.LogOutput), &output)
+	}
+	outputPipe, err := r.cmd.StdoutTeePipe(io.Discard)
+	if err != nil {
+		return err
+	}
+	r.cmd.Stderr = r.cmd.Stdout
+
+	log.Debugf("Command: %s", envutil.QuotedCommandWithEnv(r.cmd.Args, r.EnvVars))
+	err = r.cmd.Start()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	r.started <- struct{}{}
+
+	parser := gofuzz_parser.NewOutputParser(&gofuzz_parser.Options{
+		PackageDir:   r.PackageDir,
+		ProjectDir:   r.ProjectDir,
+		OutputWriter: outputWriter,
+	})
+	reportsCh := make(chan *report.Report, maxBufferedReports)
+
+	routines, routinesCtx := errgroup.WithContext(ctx)
+	routines.Go(func() error {
+		waitErrCh := make(chan error, 1)
+		go func() {
+			waitErrCh <- r.cmd.Wait()
+		}()
+
+		err := parser.Parse(routinesCtx, outputPipe, reportsCh)
+		if err != nil {
+			return err
+		}
+		closeErr := outputPipe.Close()
+		if closeErr != nil {
+			return errors.WithStack(closeErr)
+		}
+
+		select {
+		case err := <-waitErrCh:
+			if err == nil || r.cmd.TerminatedAfterContextDone() {
+				return nil
+			}
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && parser.FindingReported {
+				// `go test` exits with a non-zero exit code when it
+				// found a failing input, which is expected
+				return nil
+			}
+			if !r.Verbose {
+				log.Print(output.String())
+			}
+			return cmdutils.WrapExecError(errors.WithStack(err), r.cmd.Cmd)
+		case <-routinesCtx.Done():
+			return routinesCtx.Err()
+		}
+	})
+
+	routines.Go(func() error {
+		for rep := range reportsCh {
+			if rep.Finding != nil {
+				err := r.moveFailingInput(rep)
+				if err != nil {
+					return err
+				}
+			}
+			err := r.ReportHandler.Handle(rep)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	// Routines.Wait() returns an error created by us so it already has a
+	// stack trace and we don't want to add another one here
+	// nolint: wrapcheck
+	return routines.Wait()
+}
+
+// moveFailingInput moves the failing input which `go test` wrote to the
+// testdata directory of the package to the work directory. The report
+// handler then stores it in the seed corpus under the name of the
+// finding, so that the input is not stored twice.
+func (r *Runner) moveFailingInput(rep *report.Report) error {
+	f := rep.Finding
+	if f.InputFile == "" {
+		return nil
+	}
+	exists, err := fileutil.Exists(f.InputFile)
+	if err != nil || !exists {
+		return err
+	}
+
+	newPath := filepath.Join(r.workDir, filepath.Base(f.InputFile))
+	err = os.WriteFile(newPath, f.InputData, 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = os.Remove(f.InputFile)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	f.InputFile = newPath
+	return nil
+}
+
+func (r *Runner) Cleanup(ctx context.Context) {
+	// Wait until the command has been started, else we can't terminate it
+	select {
+	case <-ctx.Done():
+		return
+	case <-r.started:
+		err := r.cmd.TerminateProcessGroup()
+		if err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+// CopyToCorpus copies the inputs in the given seed corpus directories
+// to the corpus directory, which is created if it doesn't exist. Only
+// files in the Go fuzz corpus format are copied, other files are
+// skipped with a warning.
+func CopyToCorpus(corpusDir string, seedCorpusDirs ...string) error {
+	var skipped []string
+	for _, dir := range seedCorpusDirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			isCorpusFile, err := hasCorpusFileHeader(path)
+			if err != nil {
+				return err
+			}
+			if !isCorpusFile {
+				skipped = append(skipped, path)
+				return nil
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			err = os.MkdirAll(corpusDir, 0o755)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			// Name the file like `go test` does, so that the same
+			// input is not added twice
+			name := fmt.Sprintf("%x", sha256.Sum256(content))[:16]
+			err = os.WriteFile(filepath.Join(corpusDir, name), content, 0o644)
+			return errors.WithStack(err)
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if len(skipped) > 0 {
+		log.Warnf("Skipped %d seed inputs which are not in the Go fuzz corpus format (%q)",
+			len(skipped), string(bytes.TrimSpace(corpusFileHeader)))
+		for _, path := range skipped {
+			log.Debugf("Skipped seed input %s", path)
+		}
+	}
+	return nil
+}
+
+func hasCorpusFileHeader(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	defer file.Close()
+	header := make([]byte, len(corpusFileHeader))
+	_, err = io.ReadFull(bufio.NewReader(file), header)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return bytes.Equal(header, corpusFileHeader), nil
+}
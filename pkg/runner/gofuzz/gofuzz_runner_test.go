@@ -0,0 +1,33 @@
+package gofuzz
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/testutil"
+)
+
+func TestCopyToCorpus(t *testing.T) {
+	tempDir := testutil.MkdirTemp(t, "", "gofuzz-runner-test-")
+	seedCorpusDir := filepath.Join(tempDir, "seeds")
+	require.NoError(t, os.MkdirAll(filepath.Join(seedCorpusDir, "subdir"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(seedCorpusDir, "seed1"), []byte("go test fuzz v1\n[]byte(\"foo\")\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(seedCorpusDir, "subdir", "seed2"), []byte("go test fuzz v1\nstring(\"bar\")\n"), 0o644))
+	// Inputs with the same content are only added once
+	require.NoError(t, os.WriteFile(filepath.Join(seedCorpusDir, "subdir", "seed3"), []byte("go test fuzz v1\nstring(\"bar\")\n"), 0o644))
+	// Inputs which are not in the Go corpus format are skipped
+	require.NoError(t, os.WriteFile(filepath.Join(seedCorpusDir, "raw"), []byte("foo"), 0o644))
+
+	corpusDir := filepath.Join(tempDir, "corpus")
+	err := CopyToCorpus(corpusDir, seedCorpusDir)
+	require.NoError(t, err)
+	entries, err := os.ReadDir(corpusDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		require.Len(t, entry.Name(), 16)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"code-intelligence.com/cifuzz/internal/build/nodejs"
 	"code-intelligence.com/cifuzz/pkg/dependencies"
 	"code-intelligence.com/cifuzz/pkg/log"
 	"code-intelligence.com/cifuzz/pkg/options"
@@ -24,6 +25,9 @@ type RunnerOptions struct {
 	TestPathPattern  string
 	TestNamePattern  string
 	PackageManager   string
+	// The directory of the package containing the fuzz test, in which
+	// Jest is executed. Defaults to the project directory.
+	PackageDir string
 }
 
 func (options *RunnerOptions) ValidateOptions() error {
@@ -46,6 +50,10 @@ type Runner struct {
 }
 
 func NewRunner(options *RunnerOptions) *Runner {
+	if options.PackageDir == "" {
+		options.PackageDir = options.LibfuzzerOptions.ProjectDir
+	}
+	options.LibfuzzerOptions.WorkDir = options.PackageDir
 	libfuzzerRunner := libfuzzer.NewRunner(options.LibfuzzerOptions)
 	// TODO: handle different fuzzers properly
 	libfuzzerRunner.SupportJazzer = false
@@ -62,7 +70,7 @@ func (r *Runner) Run(ctx context.Context) error {
 	// Print version information for debugging purposes
 	r.printDebugVersionInfos()
 
-	args := []string{"npx", "jest"}
+	args := nodejs.JestCommand(r.PackageManager)
 
 	// ---------------------------
 	// --- fuzz target arguments -
@@ -91,7 +99,7 @@ func (r *Runner) FuzzerEnvironment() ([]string, error) {
 		return nil, err
 	}
 
-	if len(r.LibfuzzerOptions.EngineArgs) > 0 {
+	if len(r.LibfuzzerOptions.EngineArgs) > 0 || r.GeneratedCorpusDir != "" || len(r.SeedCorpusDirs) > 0 || r.Dictionary != "" {
 		env, err = r.setEngineArgsAsJazzerFlags(env)
 		if err != nil {
 			return nil, err
@@ -130,16 +138,18 @@ type jazzerJSRC struct {
 	Timeout       int32    `json:"timeout"`
 }
 
-// setEngineArgsAsJazzerFlags sets the engine args for libfuzzer with the
-// environment variables JAZZER_FUZZER_OPTIONS and JAZZER_TIMEOUT.
+// setEngineArgsAsJazzerFlags sets the engine args, the dictionary and
+// the corpus dirs for libfuzzer with the environment variables
+// JAZZER_FUZZER_OPTIONS and JAZZER_TIMEOUT.
 // It checks if a .jazzerjsrc file exists in the project and prioritizes
 // those values over the engine args. Setting the JAZZER_FUZZER_OPTIONS or
 // JAZZER_TIMEOUT environment variable will make Jazzer.js ignore the values
 // from the .jazzerjsrc so those values have to be added in the env too.
 func (r *Runner) setEngineArgsAsJazzerFlags(env []string) ([]string, error) {
-	// Check if .jazzerjsrc exists and store values
+	// Check if .jazzerjsrc exists and store values. Jazzer.js reads it
+	// from the directory of the package containing the fuzz test.
 	var rc jazzerJSRC
-	jazzerJSRCPath := filepath.Join(r.ProjectDir, ".jazzerjsrc")
+	jazzerJSRCPath := filepath.Join(r.PackageDir, ".jazzerjsrc")
 	exist, err := fileutil.Exists(jazzerJSRCPath)
 	if err != nil {
 		return nil, err
@@ -155,8 +165,19 @@ func (r *Runner) setEngineArgsAsJazzerFlags(env []string) ([]string, error) {
 		}
 	}
 
+	engineArgs := append([]string{}, r.LibfuzzerOptions.EngineArgs...)
+	if r.Dictionary != "" {
+		// Jest is executed in the package directory, so relative paths
+		// would be resolved relative to that directory
+		dict, err := filepath.Abs(r.Dictionary)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		engineArgs = append(engineArgs, "-dict="+dict)
+	}
+
 	fuzzerOptions := rc.FuzzerOptions
-	for _, arg := range r.LibfuzzerOptions.EngineArgs {
+	for _, arg := range engineArgs {
 		flag, value, found := strings.Cut(arg, "=")
 		if !found {
 			continue
@@ -195,6 +216,23 @@ func (r *Runner) setEngineArgsAsJazzerFlags(env []string) ([]string, error) {
 		}
 	}
 
+	// The corpus dirs are passed to libfuzzer as positional arguments,
+	// in addition to the corpus directory of Jazzer.js. The generated
+	// corpus dir comes first, because libfuzzer stores new inputs in
+	// the first corpus dir.
+	var corpusDirs []string
+	if r.GeneratedCorpusDir != "" {
+		corpusDirs = append(corpusDirs, r.GeneratedCorpusDir)
+	}
+	corpusDirs = append(corpusDirs, r.SeedCorpusDirs...)
+	for _, dir := range corpusDirs {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		fuzzerOptions = append(fuzzerOptions, absDir)
+	}
+
 	// If no new flag has been added, we don't need to set the environment variable
 	// because Jazzer.js will take the values from the .jazzerjsrc automatically
 	if sliceutil.Equal(fuzzerOptions, rc.FuzzerOptions) {
@@ -1,6 +1,7 @@
 package jazzerjs
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -79,3 +80,57 @@ func TestRunner_FuzzerEnvironmentWithJazzerJSRC_OnlyDuplicates(t *testing.T) {
 	assert.Len(t, env, 1)
 	assert.Contains(t, env, "JAZZER_FUZZ=1")
 }
+
+// TestRunner_FuzzerEnvironmentWithJazzerJSRCInPackage checks that the
+// .jazzerjsrc of the workspace package containing the fuzz test is used.
+func TestRunner_FuzzerEnvironmentWithJazzerJSRCInPackage(t *testing.T) {
+	tempDir := testutil.MkdirTemp(t, "", "nodets-test-*")
+	packageDir := filepath.Join(tempDir, "packages", "parser")
+	err := os.MkdirAll(packageDir, 0o755)
+	require.NoError(t, err)
+
+	jazzerJSRCContent := `{
+	"fuzzerOptions": ["-seed=10"]
+}`
+	err = os.WriteFile(filepath.Join(packageDir, ".jazzerjsrc"), []byte(jazzerJSRCContent), 0o644)
+	require.NoError(t, err)
+
+	r := NewRunner(&RunnerOptions{
+		PackageDir: packageDir,
+		LibfuzzerOptions: &libfuzzer.RunnerOptions{
+			ProjectDir: tempDir,
+			EngineArgs: []string{"-seed=1", "-runs=1"},
+		},
+	})
+
+	env, err := r.FuzzerEnvironment()
+	require.NoError(t, err)
+
+	assert.Contains(t, env, "JAZZER_FUZZER_OPTIONS=[\"-seed=10\",\"-runs=1\"]")
+	assert.Equal(t, packageDir, r.WorkDir)
+}
+
+// TestRunner_FuzzerEnvironmentWithSeedsAndDictionary checks that the
+// corpus dirs and the dictionary are passed to libfuzzer.
+func TestRunner_FuzzerEnvironmentWithSeedsAndDictionary(t *testing.T) {
+	tempDir := testutil.MkdirTemp(t, "", "nodets-test-*")
+	generatedCorpusDir := filepath.Join(tempDir, "generated")
+	seedCorpusDir := filepath.Join(tempDir, "seeds")
+	dictionary := filepath.Join(tempDir, "dict")
+
+	r := NewRunner(&RunnerOptions{
+		LibfuzzerOptions: &libfuzzer.RunnerOptions{
+			ProjectDir:         tempDir,
+			GeneratedCorpusDir: generatedCorpusDir,
+			SeedCorpusDirs:     []string{seedCorpusDir},
+			Dictionary:         dictionary,
+		},
+	})
+
+	env, err := r.FuzzerEnvironment()
+	require.NoError(t, err)
+
+	fuzzerOptions, err := json.Marshal([]string{"-dict=" + dictionary, generatedCorpusDir, seedCorpusDir})
+	require.NoError(t, err)
+	assert.Contains(t, env, "JAZZER_FUZZER_OPTIONS="+string(fuzzerOptions))
+}
@@ -55,6 +55,9 @@ type RunnerOptions struct {
 	Timeout            time.Duration
 	UseMinijail        bool
 	Verbose            bool
+	// The working directory of the fuzzer. If empty, the fuzzer is
+	// executed in the current working directory.
+	WorkDir string
 	// The path to the coverage binary to use to produce a coverage
 	// report after the fuzzer has finished. If empty, no coverage
 	// report is produced.
@@ -93,6 +96,7 @@ type Runner struct {
 	*RunnerOptions
 	SupportJazzer   bool
 	SupportJazzerJS bool
+	SupportAtheris  bool
 
 	started chan struct{}
 	cmd     *executil.Cmd
@@ -207,6 +211,7 @@ func (r *Runner) RunLibfuzzerAndReport(ctx context.Context, args []string, env [
 	}
 	defer cancelCmdCtx()
 	r.cmd = executil.CommandContext(cmdCtx, args[0], args[1:]...)
+	r.cmd.Dir = r.WorkDir
 	r.cmd.Env, err = envutil.Copy(os.Environ(), env)
 	if err != nil {
 		return err
@@ -266,6 +271,7 @@ func (r *Runner) RunLibfuzzerAndReport(ctx context.Context, args []string, env [
 	reporter := libfuzzer_parser.NewLibfuzzerOutputParser(&libfuzzer_parser.Options{
 		SupportJazzer:       r.SupportJazzer,
 		SupportJazzerJS:     r.SupportJazzerJS,
+		SupportAtheris:      r.SupportAtheris,
 		KeepColor:           r.KeepColor,
 		StartupOutputWriter: startupOutputWriter,
 		ProjectDir:          r.ProjectDir,
@@ -395,6 +401,18 @@ func (r *Runner) FuzzerEnvironment() ([]string, error) {
 			return nil, err
 		}
 	}
+	if os.Getenv("MSAN_OPTIONS") != "" {
+		env, err = envutil.Setenv(env, "MSAN_OPTIONS", os.Getenv("MSAN_OPTIONS"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if os.Getenv("TSAN_OPTIONS") != "" {
+		env, err = envutil.Setenv(env, "TSAN_OPTIONS", os.Getenv("TSAN_OPTIONS"))
+		if err != nil {
+			return nil, err
+		}
+	}
 	env, err = fuzzer_runner.AddEnvFlags(env, r.EnvVars)
 	if err != nil {
 		return nil, err
@@ -410,6 +428,16 @@ func (r *Runner) FuzzerEnvironment() ([]string, error) {
 		return nil, err
 	}
 
+	env, err = fuzzer_runner.SetCommonMSANOptions(env)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err = fuzzer_runner.SetCommonTSANOptions(env)
+	if err != nil {
+		return nil, err
+	}
+
 	overrideOptions := map[string]string{
 		// Per default this is set to false, except for darwin.
 		// To have consistent behavior on all supported operating systems
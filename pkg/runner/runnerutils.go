@@ -162,6 +162,58 @@ func SetCommonUBSANOptions(env []string) ([]string, error) {
 	return envutil.Setenv(env, "UBSAN_OPTIONS", options)
 }
 
+func SetCommonMSANOptions(env []string) ([]string, error) {
+	defaultOptions := maps.Clone(defaultSanitizerOptions)
+	overrideOptions := map[string]string{
+		// Use the same exit code as ASan, see SetCommonASANOptions
+		"exitcode": strconv.Itoa(SanitizerErrorExitCode),
+		// Logs must be written to stderr for us to parse them.
+		"log_path": "stderr",
+	}
+
+	// Do this check here because the flag is not yet set at the init phase
+	// where the default options are determined
+	if log.PlainStyle() {
+		overrideOptions["color"] = "never"
+	}
+
+	options := envutil.Getenv(env, "MSAN_OPTIONS")
+	options = SetSanitizerOptions(options, defaultOptions, overrideOptions)
+	return envutil.Setenv(env, "MSAN_OPTIONS", options)
+}
+
+func SetCommonTSANOptions(env []string) ([]string, error) {
+	defaultOptions := maps.Clone(defaultSanitizerOptions)
+	maps.Copy(defaultOptions, map[string]string{
+		// TSan continues execution after reporting a data race by
+		// default, which would cause the race to be reported without
+		// the input which triggered it.
+		"halt_on_error": "1",
+	})
+	// In contrast to ASan and MSan, TSan doesn't support an environment
+	// variable which specifies the path to llvm-symbolizer.
+	if symbolizer := envutil.Getenv(env, "ASAN_SYMBOLIZER_PATH"); symbolizer != "" {
+		defaultOptions["external_symbolizer_path"] = symbolizer
+	}
+
+	overrideOptions := map[string]string{
+		// Use the same exit code as ASan, see SetCommonASANOptions
+		"exitcode": strconv.Itoa(SanitizerErrorExitCode),
+		// Logs must be written to stderr for us to parse them.
+		"log_path": "stderr",
+	}
+
+	// Do this check here because the flag is not yet set at the init phase
+	// where the default options are determined
+	if log.PlainStyle() {
+		overrideOptions["color"] = "never"
+	}
+
+	options := envutil.Getenv(env, "TSAN_OPTIONS")
+	options = SetSanitizerOptions(options, defaultOptions, overrideOptions)
+	return envutil.Setenv(env, "TSAN_OPTIONS", options)
+}
+
 func AddEnvFlags(env []string, envVars []string) ([]string, error) {
 	var err error
 	for _, e := range envVars {
@@ -203,6 +255,10 @@ func FuzzerEnvironment() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	env, err = envutil.Setenv(env, "MSAN_SYMBOLIZER_PATH", resolvedLLVMSymbolizerPath)
+	if err != nil {
+		return nil, err
+	}
 
 	// Tell llvm-symbolizer to strip the build dir from paths, to have
 	// stack traces printed in the logs with relative paths, which are
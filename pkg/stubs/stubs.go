@@ -28,6 +28,9 @@ var javaScriptStub []byte
 //go:embed test.fuzz.ts.tmpl
 var typeScriptStub []byte
 
+//go:embed fuzz_test.py.tmpl
+var pythonStub []byte
+
 // Create creates a stub based for the given test type
 func Create(path string, testType config.FuzzTestType) error {
 	exists, err := fileutil.Exists(path)
@@ -75,6 +78,8 @@ func Create(path string, testType config.FuzzTestType) error {
 		content = javaScriptStub
 	case config.TypeScript:
 		content = typeScriptStub
+	case config.Python:
+		content = pythonStub
 	}
 
 	// write stub
@@ -113,6 +118,10 @@ func FuzzTestFilename(testType config.FuzzTestType) (string, error) {
 		basename = "myTest"
 		ext = "fuzz.ts"
 		filePattern = "%s%d.%s"
+	case config.Python:
+		basename = "fuzz_test"
+		ext = "py"
+		filePattern = "%s_%d.%s"
 	default:
 		return "", errors.New("unable to suggest filename: unknown test type")
 	}
@@ -66,6 +66,15 @@ func TestCreate(t *testing.T) {
 	exists, err = fileutil.Exists(stubFile)
 	assert.NoError(t, err)
 	assert.True(t, exists)
+
+	// Test .py files
+	stubFile = filepath.Join(projectDir, "fuzz_test.py")
+	err = Create(stubFile, config.Python)
+	assert.NoError(t, err)
+
+	exists, err = fileutil.Exists(stubFile)
+	assert.NoError(t, err)
+	assert.True(t, exists)
 }
 
 func TestCreate_Exists(t *testing.T) {
@@ -189,6 +198,18 @@ func TestSuggestFilename(t *testing.T) {
 	filename8, err := FuzzTestFilename(config.TypeScript)
 	assert.NoError(t, err)
 	assert.Equal(t, filepath.Join(".", "myTest2.fuzz.ts"), filename8)
+
+	// Test .py files
+	filename9, err := FuzzTestFilename(config.Python)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(".", "fuzz_test_1.py"), filename9)
+
+	err = os.WriteFile(filename9, []byte("TEST"), 0o644)
+	require.NoError(t, err)
+
+	filename10, err := FuzzTestFilename(config.Python)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(".", "fuzz_test_2.py"), filename10)
 }
 
 func TestCreateJavaFileAndClassName(t *testing.T) {
@@ -0,0 +1,188 @@
+package symbolizer
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+)
+
+// symbolizeWithDWARF resolves the specified offsets into the module
+// using the DWARF debug info of the module. In contrast to
+// llvm-symbolizer, function names are not demangled.
+func symbolizeWithDWARF(module string, offsets []uint64) ([]*location, error) {
+	file, err := elf.Open(module)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer file.Close()
+
+	data, err := file.DWARF()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	locations := make([]*location, len(offsets))
+	for i, offset := range offsets {
+		locations[i], err = lookupPC(data, offset)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return locations, nil
+}
+
+func lookupPC(data *dwarf.Data, pc uint64) (*location, error) {
+	reader := data.Reader()
+	compileUnit, err := reader.SeekPC(pc)
+	if errors.Is(err, dwarf.ErrUnknownPC) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	lineReader, err := data.LineReader(compileUnit)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if lineReader == nil {
+		// The compile unit has no line table
+		return nil, nil
+	}
+	var lineEntry dwarf.LineEntry
+	err = lineReader.SeekPC(pc, &lineEntry)
+	if errors.Is(err, dwarf.ErrUnknownPC) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if lineEntry.File == nil || lineEntry.Line == 0 {
+		return nil, nil
+	}
+
+	function, err := functionName(data, reader, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &location{
+		function: function,
+		file:     lineEntry.File.Name,
+		line:     uint64(lineEntry.Line),
+		column:   uint64(lineEntry.Column),
+	}, nil
+}
+
+// functionName returns the name of the outermost function containing
+// the pc in the compile unit the reader is positioned in.
+func functionName(data *dwarf.Data, reader *dwarf.Reader, pc uint64) (string, error) {
+	depth := 0
+	for {
+		entry, err := reader.Next()
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		if entry == nil {
+			return "", nil
+		}
+		if entry.Tag == 0 {
+			// End of the children of the previous entry
+			depth--
+			if depth < 0 {
+				return "", nil
+			}
+			continue
+		}
+
+		if entry.Tag == dwarf.TagSubprogram {
+			ranges, err := data.Ranges(entry)
+			if err != nil {
+				return "", errors.WithStack(err)
+			}
+			for _, r := range ranges {
+				if pc >= r[0] && pc < r[1] {
+					return entryName(data, entry)
+				}
+			}
+			// Don't descend into functions which don't contain the pc
+			if entry.Children {
+				reader.SkipChildren()
+			}
+			continue
+		}
+
+		if entry.Children {
+			depth++
+		}
+	}
+}
+
+// entryName returns the name of the entry, which is stored in the
+// declaration of the function for out-of-line definitions of C++
+// member functions and inlined functions.
+func entryName(data *dwarf.Data, entry *dwarf.Entry) (string, error) {
+	for i := 0; i < 2; i++ {
+		if name, ok := entry.Val(dwarf.AttrName).(string); ok {
+			return name, nil
+		}
+		ref, ok := entry.Val(dwarf.AttrSpecification).(dwarf.Offset)
+		if !ok {
+			ref, ok = entry.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset)
+		}
+		if !ok {
+			return "", nil
+		}
+		reader := data.Reader()
+		reader.Seek(ref)
+		var err error
+		entry, err = reader.Next()
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		if entry == nil {
+			return "", nil
+		}
+	}
+	return "", nil
+}
+
+// buildID returns the GNU build ID of the ELF file at the specified
+// path, or an empty string if the file doesn't have a build ID or is
+// not an ELF file.
+func buildID(path string) (string, error) {
+	file, err := elf.Open(path)
+	var formatErr *elf.FormatError
+	if errors.As(err, &formatErr) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer file.Close()
+
+	section := file.Section(".note.gnu.build-id")
+	if section == nil {
+		return "", nil
+	}
+	note, err := section.Data()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	// The note starts with the size of the name, the size of the
+	// descriptor (the build ID) and the type, followed by the name
+	// "GNU\0", padded to 4 bytes, and the descriptor
+	if len(note) < 12 {
+		return "", nil
+	}
+	nameSize := uint64(file.ByteOrder.Uint32(note[0:4]))
+	descSize := uint64(file.ByteOrder.Uint32(note[4:8]))
+	start := 12 + (nameSize+3)&^3
+	if uint64(len(note)) < start+descSize {
+		return "", nil
+	}
+	return hex.EncodeToString(note[start : start+descSize]), nil
+}
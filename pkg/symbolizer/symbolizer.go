@@ -0,0 +1,268 @@
+package symbolizer
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/parser/libfuzzer/stacktrace"
+	"code-intelligence.com/cifuzz/pkg/runfiles"
+	"code-intelligence.com/cifuzz/util/fileutil"
+	"code-intelligence.com/cifuzz/util/regexutil"
+)
+
+// Matches stack frames which were printed by a sanitizer without
+// symbolizing them, e.g.
+// "#0 0x4f6bc1  (/out/fuzz_test+0x4f6bc1)",
+// "#1 0x4f6c2e in LLVMFuzzerTestOneInput (/out/fuzz_test+0x4f6c2e)" or
+// "#2 0x7f5e in __libc_start_main (/lib/libc.so.6+0x29d90) (BuildId: 69389d48)"
+var rawFramePattern = regexp.MustCompile(
+	`^(?P<indent>\s*)#(?P<frame_number>\d+)\s+(?:(?P<pc>0x[0-9a-fA-F]+)\s+)?(?:in\s+)?(?P<function>.*?)\s*\((?P<module>[^()]+)\+(?P<offset>0x[0-9a-fA-F]+)\)(?:\s+\(BuildId: (?P<build_id>[0-9a-fA-F]+)\))?\s*$`)
+
+// TSan prints the module and offset also for symbolized frames, which
+// we recognize by the source location before it
+var sourceLocationSuffixPattern = regexp.MustCompile(`\S+:\d+(:\d+)?$`)
+
+// Matches the source location printed by llvm-symbolizer, e.g.
+// "/src/fuzz_test.cpp:12:5"
+var llvmSymbolizerLocationPattern = regexp.MustCompile(`^(?P<file>.+):(?P<line>\d+)(?::(?P<column>\d+))?$`)
+
+type Options struct {
+	// The fuzz test binary. Frames of modules with the same base name
+	// are resolved using this binary, which allows symbolizing findings
+	// of fuzz tests which were executed at a different path, e.g. in a
+	// container.
+	Binary string
+	// Directories which are searched for the shared libraries which
+	// appear in the stack traces
+	LibraryDirs []string
+	ProjectDir  string
+}
+
+type location struct {
+	function string
+	file     string
+	line     uint64
+	column   uint64
+}
+
+type rawFrame struct {
+	index       int
+	indent      string
+	frameNumber string
+	pc          string
+	function    string
+	offset      uint64
+}
+
+// SymbolizeFinding resolves the raw addresses of the unsymbolized stack
+// frames in the logs of the finding, which sanitizers print if
+// llvm-symbolizer was not available when the fuzz test was executed.
+// The symbolized frames replace the raw frames in the logs and the
+// stack traces of the finding are parsed again. Addresses are resolved
+// with llvm-symbolizer if it's available and via the DWARF debug info
+// of the module otherwise.
+// Returns true if any frame was symbolized.
+func SymbolizeFinding(f *finding.Finding, opts *Options) (bool, error) {
+	// Group the raw frames by module, so that each module only has to
+	// be symbolized once
+	framesByModule := map[string][]*rawFrame{}
+	var modules []string
+	for i, line := range f.Logs {
+		matches, found := regexutil.FindNamedGroupsMatch(rawFramePattern, line)
+		if !found || sourceLocationSuffixPattern.MatchString(matches["function"]) {
+			continue
+		}
+		// The regex only matches hex digits
+		offset, _ := strconv.ParseUint(strings.TrimPrefix(matches["offset"], "0x"), 16, 64)
+		frame := &rawFrame{
+			index:       i,
+			indent:      matches["indent"],
+			frameNumber: matches["frame_number"],
+			pc:          matches["pc"],
+			function:    matches["function"],
+			offset:      offset,
+		}
+		if frame.pc == "" {
+			frame.pc = matches["offset"]
+		}
+
+		module, err := findModule(matches["module"], opts)
+		if err != nil {
+			return false, err
+		}
+		if module == "" {
+			log.Debugf("Module %s not found, not symbolizing frame %q", matches["module"], line)
+			continue
+		}
+		if matches["build_id"] != "" {
+			id, err := buildID(module)
+			if err != nil {
+				return false, err
+			}
+			if id != "" && !strings.EqualFold(id, matches["build_id"]) {
+				log.Debugf("Build ID of %s (%s) doesn't match the one of frame %q", module, id, line)
+				continue
+			}
+		}
+
+		if _, exists := framesByModule[module]; !exists {
+			modules = append(modules, module)
+		}
+		framesByModule[module] = append(framesByModule[module], frame)
+	}
+	if len(modules) == 0 {
+		return false, nil
+	}
+
+	llvmSymbolizer, err := runfiles.Finder.LLVMSymbolizerPath()
+	if err != nil {
+		log.Debugf("llvm-symbolizer not found, using DWARF debug info instead: %v", err)
+		llvmSymbolizer = ""
+	}
+
+	logs := make([]string, len(f.Logs))
+	copy(logs, f.Logs)
+	symbolized := false
+	for _, module := range modules {
+		frames := framesByModule[module]
+		var offsets []uint64
+		for _, frame := range frames {
+			offsets = append(offsets, frame.offset)
+		}
+
+		var locations []*location
+		if llvmSymbolizer != "" {
+			locations, err = symbolizeWithLLVMSymbolizer(llvmSymbolizer, module, offsets)
+		} else {
+			locations, err = symbolizeWithDWARF(module, offsets)
+		}
+		if err != nil {
+			// Symbolizing the frames of other modules might still
+			// succeed
+			log.Debugf("Failed to symbolize frames of %s: %+v", module, err)
+			continue
+		}
+
+		for i, frame := range frames {
+			loc := locations[i]
+			if loc == nil {
+				continue
+			}
+			function := loc.function
+			if function == "" {
+				function = frame.function
+			}
+			if function == "" || function == "<null>" {
+				function = "??"
+			}
+			sourceLocation := fmt.Sprintf("%s:%d", loc.file, loc.line)
+			if loc.column != 0 {
+				sourceLocation += fmt.Sprintf(":%d", loc.column)
+			}
+			logs[frame.index] = fmt.Sprintf("%s#%s %s in %s %s", frame.indent, frame.frameNumber, frame.pc, function, sourceLocation)
+			symbolized = true
+		}
+	}
+	if !symbolized {
+		return false, nil
+	}
+
+	f.Logs = logs
+
+	parser, err := stacktrace.NewParser(&stacktrace.ParserOptions{ProjectDir: opts.ProjectDir})
+	if err != nil {
+		return false, err
+	}
+	stackTrace, err := parser.Parse(f.Logs)
+	if err != nil {
+		return false, err
+	}
+	if len(stackTrace) > 0 {
+		f.StackTrace = stackTrace
+	}
+	f.AuxiliaryStackTraces, err = parser.ParseAuxiliaryStackTraces(f.Logs)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// findModule returns the path of the module with the specified path
+// on the system where the fuzz test was executed, or an empty string if
+// the module can't be found.
+func findModule(path string, opts *Options) (string, error) {
+	if opts.Binary != "" && filepath.Base(path) == filepath.Base(opts.Binary) {
+		return opts.Binary, nil
+	}
+	for _, dir := range opts.LibraryDirs {
+		candidate := filepath.Join(dir, filepath.Base(path))
+		exists, err := fileutil.Exists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return candidate, nil
+		}
+	}
+	exists, err := fileutil.Exists(path)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return path, nil
+	}
+	return "", nil
+}
+
+func symbolizeWithLLVMSymbolizer(llvmSymbolizer, module string, offsets []uint64) ([]*location, error) {
+	args := []string{"--obj=" + module, "--no-inlines", "--output-style=LLVM"}
+	for _, offset := range offsets {
+		args = append(args, fmt.Sprintf("0x%x", offset))
+	}
+	cmd := exec.Command(llvmSymbolizer, args...)
+	log.Debugf("Command: %s", cmd.String())
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+
+	// llvm-symbolizer prints the function and the source location of
+	// each address, followed by an empty line
+	blocks := strings.Split(strings.TrimSpace(strings.ReplaceAll(string(out), "\r\n", "\n")), "\n\n")
+	if len(blocks) != len(offsets) {
+		return nil, errors.Errorf("llvm-symbolizer returned %d locations for %d addresses", len(blocks), len(offsets))
+	}
+
+	locations := make([]*location, len(offsets))
+	for i, block := range blocks {
+		lines := strings.Split(block, "\n")
+		if len(lines) < 2 {
+			continue
+		}
+		matches, found := regexutil.FindNamedGroupsMatch(llvmSymbolizerLocationPattern, lines[1])
+		if !found || matches["file"] == "??" || matches["line"] == "0" {
+			continue
+		}
+		loc := &location{file: matches["file"]}
+		// The regex only matches digits
+		loc.line, _ = strconv.ParseUint(matches["line"], 10, 32)
+		if matches["column"] != "" {
+			loc.column, _ = strconv.ParseUint(matches["column"], 10, 32)
+		}
+		if lines[0] != "??" {
+			loc.function = lines[0]
+		}
+		locations[i] = loc
+	}
+	return locations, nil
+}
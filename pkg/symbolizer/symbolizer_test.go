@@ -0,0 +1,147 @@
+package symbolizer
+
+import (
+	"debug/elf"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/pkg/finding"
+)
+
+const testSource = `#include <stdio.h>
+
+__attribute__((noinline)) int compute(int x) {
+  return x * 2;
+}
+
+int main(int argc, char **argv) {
+  printf("%d\n", compute(argc));
+  return 0;
+}
+`
+
+// buildTestBinary compiles a C program with debug info and returns the
+// project directory, the path to the binary and the offsets of the
+// functions "compute" and "main".
+func buildTestBinary(t *testing.T) (string, string, uint64, uint64) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Symbolizing via DWARF is only supported for ELF binaries")
+	}
+	cc, err := exec.LookPath("cc")
+	if err != nil {
+		t.Skip("No C compiler found")
+	}
+
+	projectDir := t.TempDir()
+	source := filepath.Join(projectDir, "test.c")
+	err = os.WriteFile(source, []byte(testSource), 0o644)
+	require.NoError(t, err)
+	binary := filepath.Join(projectDir, "test")
+	cmd := exec.Command(cc, "-g", "-O0", "-Wl,--build-id", "-o", binary, source)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	file, err := elf.Open(binary)
+	require.NoError(t, err)
+	defer file.Close()
+	symbols, err := file.Symbols()
+	require.NoError(t, err)
+	var compute, main uint64
+	for _, s := range symbols {
+		switch s.Name {
+		case "compute":
+			compute = s.Value
+		case "main":
+			main = s.Value
+		}
+	}
+	require.NotZero(t, compute)
+	require.NotZero(t, main)
+
+	return projectDir, binary, compute, main
+}
+
+func TestSymbolizeFinding(t *testing.T) {
+	projectDir, binary, compute, main := buildTestBinary(t)
+
+	f := &finding.Finding{
+		Logs: []string{
+			"==1==ERROR: AddressSanitizer: SEGV on unknown address 0x000000000000",
+			// The fuzz test was executed at a different path, e.g. in a
+			// container
+			fmt.Sprintf("    #0 0x55d0b1 (/out/test+0x%x)", compute),
+			fmt.Sprintf("    #1 0x55d0b2 in main (/out/test+0x%x)", main),
+			"    #2 0x7f5e in __libc_start_main (/lib/x86_64-linux-gnu/libc.so.6+0x29d90) (BuildId: 0123456789abcdef)",
+			"SUMMARY: AddressSanitizer: SEGV (/out/test+0x1147)",
+		},
+	}
+
+	symbolized, err := SymbolizeFinding(f, &Options{Binary: binary, ProjectDir: projectDir})
+	require.NoError(t, err)
+	require.True(t, symbolized)
+
+	require.Len(t, f.StackTrace, 2)
+	assert.Equal(t, "compute", f.StackTrace[0].Function)
+	assert.Equal(t, "test.c", f.StackTrace[0].SourceFile)
+	assert.EqualValues(t, 3, f.StackTrace[0].Line)
+	assert.EqualValues(t, 0, f.StackTrace[0].FrameNumber)
+	assert.Equal(t, "main", f.StackTrace[1].Function)
+	assert.EqualValues(t, 7, f.StackTrace[1].Line)
+	assert.EqualValues(t, 1, f.StackTrace[1].FrameNumber)
+
+	assert.Contains(t, f.Logs[1], "#0 0x55d0b1 in compute "+filepath.Join(projectDir, "test.c")+":3")
+	// Frames of modules with a different build ID are not symbolized
+	assert.Contains(t, f.Logs[3], "(/lib/x86_64-linux-gnu/libc.so.6+0x29d90)")
+}
+
+func TestSymbolizeFinding_NoRawFrames(t *testing.T) {
+	f := &finding.Finding{
+		Logs: []string{
+			"==1==ERROR: AddressSanitizer: SEGV on unknown address 0x000000000000",
+			"    #0 0x55d0b1 in compute /src/test.c:3:10",
+			"    #0 Thread1(void*) /src/race.cpp:6:10 (race+0xd1b2e)",
+		},
+	}
+	symbolized, err := SymbolizeFinding(f, &Options{Binary: "/does/not/exist/race"})
+	require.NoError(t, err)
+	assert.False(t, symbolized)
+}
+
+func TestSymbolizeWithDWARF(t *testing.T) {
+	_, binary, compute, main := buildTestBinary(t)
+
+	locations, err := symbolizeWithDWARF(binary, []uint64{compute, main, 0x1})
+	require.NoError(t, err)
+	require.Len(t, locations, 3)
+	require.NotNil(t, locations[0])
+	assert.Equal(t, "compute", locations[0].function)
+	assert.Equal(t, "test.c", filepath.Base(locations[0].file))
+	assert.EqualValues(t, 3, locations[0].line)
+	require.NotNil(t, locations[1])
+	assert.Equal(t, "main", locations[1].function)
+	assert.EqualValues(t, 7, locations[1].line)
+	assert.Nil(t, locations[2])
+}
+
+func TestBuildID(t *testing.T) {
+	_, binary, _, _ := buildTestBinary(t)
+
+	id, err := buildID(binary)
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f]{40}$`, id)
+
+	// Files which are not ELF files don't have a build ID
+	notELF := filepath.Join(t.TempDir(), "not-elf")
+	err = os.WriteFile(notELF, []byte("foo"), 0o644)
+	require.NoError(t, err)
+	id, err = buildID(notELF)
+	require.NoError(t, err)
+	assert.Empty(t, id)
+}
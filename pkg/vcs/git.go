@@ -2,6 +2,7 @@ package vcs
 
 import (
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -9,6 +10,8 @@ import (
 	"code-intelligence.com/cifuzz/internal/bundler/archive"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/util/fileutil"
+	"code-intelligence.com/cifuzz/util/sliceutil"
 )
 
 // GitCommit returns the full SHA of the current commit if the working directory is contained in a Git repository.
@@ -44,6 +47,37 @@ func GitIsDirty() bool {
 	return len(strings.TrimSpace(string(commit))) != 0
 }
 
+// GitListFiles returns the paths of all files below dir which are not
+// ignored by Git, i.e. tracked files and untracked files which are not
+// gitignored, relative to dir. Tracked files which were deleted are
+// not included. It returns an error if dir is not contained in a Git
+// repository.
+func GitListFiles(dir string) ([]string, error) {
+	cmd := exec.Command("git", "ls-files", "--cached", "--others", "--exclude-standard", "-z")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
+	}
+	var files []string
+	for _, file := range strings.Split(string(out), "\x00") {
+		if file == "" {
+			continue
+		}
+		file = filepath.FromSlash(file)
+		exists, err := fileutil.Exists(filepath.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		files = append(files, file)
+	}
+	// Files which are both tracked and modified are listed twice
+	return sliceutil.RemoveDuplicates(files), nil
+}
+
 // CodeRevision tries to read the current revision from git. If this is not possible the functions returns
 // nil instead of an error.
 func CodeRevision() *archive.CodeRevision {
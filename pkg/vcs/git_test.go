@@ -71,6 +71,32 @@ func TestGitIsDirty(t *testing.T) {
 	require.True(t, vcs.GitIsDirty())
 }
 
+func TestGitListFiles(t *testing.T) {
+	repo := createGitRepoWithCommits(t)
+	err := os.WriteFile(filepath.Join(repo, ".gitignore"), []byte("ignored_file\n"), 0o644)
+	require.NoError(t, err)
+	err = fileutil.Touch(filepath.Join(repo, "ignored_file"))
+	require.NoError(t, err)
+	err = os.MkdirAll(filepath.Join(repo, "dir"), 0o755)
+	require.NoError(t, err)
+	err = fileutil.Touch(filepath.Join(repo, "dir", "untracked_file"))
+	require.NoError(t, err)
+	err = os.Remove(filepath.Join(repo, "other_file"))
+	require.NoError(t, err)
+
+	files, err := vcs.GitListFiles(repo)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{".gitignore", "empty_file", filepath.Join("dir", "untracked_file")}, files)
+
+	// Paths are relative to the specified directory
+	files, err = vcs.GitListFiles(filepath.Join(repo, "dir"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"untracked_file"}, files)
+
+	_, err = vcs.GitListFiles(testutil.MkdirTemp(t, "", "no-git-repo-"))
+	require.Error(t, err)
+}
+
 func TestCodeRevision(t *testing.T) {
 	repo := createGitRepoWithCommits(t)
 	err := os.Chdir(repo)
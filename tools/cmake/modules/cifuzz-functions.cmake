@@ -105,6 +105,22 @@ function(enable_fuzz_testing)
       if(NOT WIN32)
         add_link_options(-fsanitize=undefined)
       endif()
+    elseif(sanitizer STREQUAL memory)
+      if(NOT CMAKE_SYSTEM_NAME STREQUAL "Linux")
+        message(FATAL_ERROR "cifuzz: MemorySanitizer is only supported on Linux")
+      endif()
+      add_compile_options(
+          -fsanitize=memory
+          # Report where uninitialized values were created.
+          -fsanitize-memory-track-origins
+      )
+      add_link_options(-fsanitize=memory)
+    elseif(sanitizer STREQUAL thread)
+      if(WIN32)
+        message(FATAL_ERROR "cifuzz: ThreadSanitizer is not supported on Windows")
+      endif()
+      add_compile_options(-fsanitize=thread)
+      add_link_options(-fsanitize=thread)
     elseif(sanitizer STREQUAL coverage)
       add_compile_options(
           -fprofile-instr-generate
@@ -240,7 +256,8 @@ function(add_fuzz_test name)
                                   "-fno-profile-instr-generate -fno-coverage-mapping")
     endif()
     target_sources("${name}" PRIVATE "${_launcher_src}")
-    if((address IN_LIST CIFUZZ_SANITIZERS) OR (undefined IN_LIST CIFUZZ_SANITIZERS))
+    if((address IN_LIST CIFUZZ_SANITIZERS) OR (undefined IN_LIST CIFUZZ_SANITIZERS) OR
+       (memory IN_LIST CIFUZZ_SANITIZERS) OR (thread IN_LIST CIFUZZ_SANITIZERS))
       # The macOS linker doesn't support --wrap, so we fall back to a different strategy that doesn't require any linker
       # flags.
       # See src/dumper.c for details.
@@ -269,6 +286,17 @@ function(add_fuzz_test name)
       endif()
       target_sources("${name}" PRIVATE "${_dumper_src}")
     endif()
+  elseif(CIFUZZ_ENGINE STREQUAL aflpp)
+    # The AFL++ compiler wrappers add the AFL++ instrumentation to all compiled code on their own. Linking with
+    # -fsanitize=fuzzer makes them link in the AFL++ driver, which calls LLVMFuzzerTestOneInput, instead of libFuzzer.
+    # The launcher and the dumper rely on libFuzzer internals and are therefore not used with AFL++.
+    if(CMAKE_CXX_COMPILER MATCHES "afl-" OR ((NOT "CXX" IN_LIST _enabled_languages) AND (CMAKE_C_COMPILER MATCHES "afl-")))
+      target_link_options("${name}" PRIVATE -fsanitize=fuzzer)
+    else()
+      message(FATAL_ERROR "cifuzz: ${CMAKE_CXX_COMPILER} is not supported with the aflpp engine.\n"
+        "Either specify the full path to afl-clang-fast/afl-clang-fast++ in CC/CXX or ensure that they are contained in your PATH.\n"
+        "After that remove ${CMAKE_BINARY_DIR} and try again.")
+    endif()
   else()
     message(FATAL_ERROR "cifuzz: Unsupported value for CIFUZZ_ENGINE: ${CIFUZZ_ENGINE}")
   endif()
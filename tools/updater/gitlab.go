@@ -10,6 +10,7 @@ import (
 
 	"github.com/Masterminds/semver"
 
+	"code-intelligence.com/cifuzz/internal/transport"
 	"code-intelligence.com/cifuzz/pkg/log"
 )
 
@@ -47,8 +48,12 @@ func findLatestVersion(dep string) string {
 		os.Exit(1)
 	}
 
-	// build request
-	client := &http.Client{Timeout: 10 * time.Second}
+	// build request, using the proxy and certificates configured via
+	// the CIFUZZ_PROXY, CIFUZZ_CA_CERT, CIFUZZ_CLIENT_CERT and
+	// CIFUZZ_CLIENT_KEY environment variables
+	httpTransport, err := transport.Default()
+	handleErr(err)
+	client := &http.Client{Transport: httpTransport, Timeout: 10 * time.Second}
 	req, err := http.NewRequest("GET", "https://gitlab.code-intelligence.com/api/v4/projects/89/packages", nil)
 	handleErr(err)
 	req.Header.Add("PRIVATE-TOKEN", token)
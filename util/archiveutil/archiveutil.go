@@ -25,6 +25,7 @@ func UntarFile(source, dest string) error {
 // Untar extracts a tar archive to a destination directory
 func Untar(r io.Reader, dest string) error {
 	hardlinks := make(map[string]string)
+	symlinks := make(map[string]string)
 	tr := tar.NewReader(r)
 	for {
 		var header *tar.Header
@@ -66,6 +67,21 @@ func Untar(r io.Reader, dest string) error {
 			targetpath := filepath.Join(dest, header.Linkname)
 			linkpath := filepath.Join(dest, header.Name)
 			hardlinks[linkpath] = targetpath
+		case tar.TypeSymlink:
+			// Only relative symlinks which point to a path inside the
+			// destination directory are supported, so that extracting
+			// an archive can't write outside of it. They are created
+			// after all other files were extracted, so that no files
+			// are written through them. The target is cleaned, so
+			// that it can only contain ".." elements at the beginning,
+			// which can't be resolved via other symlinks.
+			linkpath := filepath.Join(dest, header.Name)
+			target := filepath.Clean(filepath.FromSlash(header.Linkname))
+			resolved := filepath.Join(filepath.Dir(linkpath), target)
+			if filepath.IsAbs(target) || !isBelow(resolved, dest) {
+				return errors.Errorf("symlink %s points outside of the archive: %s", header.Name, header.Linkname)
+			}
+			symlinks[linkpath] = target
 		default:
 			return errors.Errorf("unsupported file type: %d", header.Typeflag)
 		}
@@ -79,9 +95,53 @@ func Untar(r io.Reader, dest string) error {
 		}
 	}
 
+	// Create the symlinks. A symlink must not be created in a directory
+	// which is reached via another symlink, because its target would
+	// then be resolved relative to a different directory than the one
+	// which was checked above.
+	if len(symlinks) == 0 {
+		return nil
+	}
+	err := os.MkdirAll(dest, 0755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	realDest, err := filepath.EvalSymlinks(dest)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for linkpath, target := range symlinks {
+		parent := filepath.Dir(linkpath)
+		err := os.MkdirAll(parent, 0755)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		relParent, err := filepath.Rel(dest, parent)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		realParent, err := filepath.EvalSymlinks(parent)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if realParent != filepath.Join(realDest, relParent) {
+			return errors.Errorf("symlink %s is inside of another symlink", linkpath)
+		}
+		err = os.Symlink(target, linkpath)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
 	return nil
 }
 
+// isBelow returns true if the cleaned path is the directory or below it
+func isBelow(path string, dir string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // Unzip extracts a ZIP archive to a destination directory
 // Based on: https://stackoverflow.com/a/24792688/2804197
 // Original author: https://stackoverflow.com/users/1316499/astockwell